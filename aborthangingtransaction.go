@@ -0,0 +1,88 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/PerchSecurity/kafka-go/protocol/writetxnmarkers"
+)
+
+// AbortHangingTransactionRequest represents a request sent to a kafka cluster
+// to abort a transaction left open on a partition.
+//
+// The producer fields are usually obtained from the DescribeProducers API, a
+// transaction is hanging when the producer has a CurrentTxnStartOffset on the
+// partition for longer than the transaction timeout, but the coordinator does
+// not know about the transaction anymore (see KIP-664).
+type AbortHangingTransactionRequest struct {
+	// Address of the kafka cluster to send the request to.
+	Addr net.Addr
+
+	// Topic partition that the transaction is hanging on.
+	Topic     string
+	Partition int
+
+	// ID and epoch of the producer that started the transaction.
+	ProducerID    int64
+	ProducerEpoch int
+
+	// Epoch of the transaction coordinator that last wrote a transaction
+	// marker for the producer on the partition.
+	CoordinatorEpoch int
+}
+
+// AbortHangingTransactionResponse represents a response from a kafka cluster
+// to an abort hanging transaction request.
+type AbortHangingTransactionResponse struct {
+	// An error that may have occurred while attempting to abort the
+	// transaction.
+	//
+	// The error contains the kafka error code. Programs may use the standard
+	// errors.Is function to test the error against kafka error codes.
+	Error error
+}
+
+// AbortHangingTransaction writes an abort marker for a transaction on the
+// leader of a partition, which unblocks the consumers reading the partition
+// with the ReadCommitted isolation level.
+//
+// The request is sent with the WriteTxnMarkers API, and requires the client to
+// have the ClusterAction permission on the cluster. Aborting a transaction
+// which is still in progress breaks its atomicity guarantees, programs should
+// only abort transactions that were first verified to be hanging.
+//
+// This API requires kafka 3.0 or above.
+func (c *Client) AbortHangingTransaction(ctx context.Context, req *AbortHangingTransactionRequest) (*AbortHangingTransactionResponse, error) {
+	m, err := c.roundTrip(ctx, req.Addr, &writetxnmarkers.Request{
+		Markers: []writetxnmarkers.RequestMarker{{
+			ProducerID:        req.ProducerID,
+			ProducerEpoch:     int16(req.ProducerEpoch),
+			TransactionResult: false, // abort
+			Topics: []writetxnmarkers.RequestTopic{{
+				Name:             req.Topic,
+				PartitionIndexes: []int32{int32(req.Partition)},
+			}},
+			CoordinatorEpoch: int32(req.CoordinatorEpoch),
+		}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("kafka.(*Client).AbortHangingTransaction: %w", err)
+	}
+
+	res := m.(*writetxnmarkers.Response)
+	ret := &AbortHangingTransactionResponse{}
+
+	for _, marker := range res.Markers {
+		for _, t := range marker.Topics {
+			for _, p := range t.Partitions {
+				if t.Name == req.Topic && int(p.PartitionIndex) == req.Partition {
+					ret.Error = makeError(p.ErrorCode, "")
+					return ret, nil
+				}
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("kafka.(*Client).AbortHangingTransaction: the response did not contain the result for partition %d of %s", req.Partition, req.Topic)
+}
@@ -0,0 +1,173 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/PerchSecurity/kafka-go/protocol/alterreplicalogdirs"
+)
+
+// AlterReplicaLogDirsRequest represents a request sent to a kafka cluster to
+// move partition replicas between the log directories of their brokers.
+type AlterReplicaLogDirsRequest struct {
+	// Address of the kafka cluster to send the request to.
+	Addr net.Addr
+
+	// List of replica assignments to apply. Assignments are grouped by broker
+	// and one request is sent to each of the brokers.
+	Assignments []AlterReplicaLogDirsAssignment
+}
+
+// AlterReplicaLogDirsAssignment represents the log directory that a partition
+// replica should be moved to.
+type AlterReplicaLogDirsAssignment struct {
+	// ID of the broker hosting the replica.
+	Broker int
+
+	// Name of the topic that the partition belongs to.
+	Topic string
+
+	// ID of the partition.
+	Partition int
+
+	// Absolute path of the log directory to move the replica to.
+	Path string
+}
+
+// AlterReplicaLogDirsResponse represents a response from a kafka cluster to an
+// alter replica log dirs request. The results of all brokers are merged into a
+// single response.
+type AlterReplicaLogDirsResponse struct {
+	// The amount of time that the brokers throttled the request.
+	Throttle time.Duration
+
+	// List of results for each of the partition replicas, sorted by broker,
+	// topic and partition.
+	Partitions []AlterReplicaLogDirsResponsePartition
+}
+
+// AlterReplicaLogDirsResponsePartition represents the result of moving a
+// partition replica to a new log directory.
+type AlterReplicaLogDirsResponsePartition struct {
+	// ID of the broker hosting the replica.
+	Broker int
+
+	// Name of the topic that the partition belongs to.
+	Topic string
+
+	// ID of the partition.
+	Partition int
+
+	// An error that may have occurred while moving the replica.
+	//
+	// The error contains the kafka error code. Programs may use the standard
+	// errors.Is function to test the error against kafka error codes.
+	Error error
+}
+
+// AlterReplicaLogDirs sends alter replica log dirs requests to the brokers
+// hosting the replicas of the request assignments, and returns the merged
+// response.
+func (c *Client) AlterReplicaLogDirs(ctx context.Context, req *AlterReplicaLogDirsRequest) (*AlterReplicaLogDirsResponse, error) {
+	type dirTopic struct {
+		path  string
+		topic string
+	}
+
+	brokers := make(map[int]map[dirTopic][]int32)
+
+	for _, a := range req.Assignments {
+		dirs := brokers[a.Broker]
+		if dirs == nil {
+			dirs = make(map[dirTopic][]int32)
+			brokers[a.Broker] = dirs
+		}
+		key := dirTopic{path: a.Path, topic: a.Topic}
+		dirs[key] = append(dirs[key], int32(a.Partition))
+	}
+
+	requests := make([]*alterreplicalogdirs.Request, 0, len(brokers))
+
+	for brokerID, dirs := range brokers {
+		r := &alterreplicalogdirs.Request{BrokerID: int32(brokerID)}
+		paths := make(map[string]int)
+
+		for key, partitions := range dirs {
+			i, ok := paths[key.path]
+			if !ok {
+				i = len(r.Dirs)
+				paths[key.path] = i
+				r.Dirs = append(r.Dirs, alterreplicalogdirs.RequestDir{Path: key.path})
+			}
+			r.Dirs[i].Topics = append(r.Dirs[i].Topics, alterreplicalogdirs.RequestTopic{
+				Name:       key.topic,
+				Partitions: partitions,
+			})
+		}
+
+		requests = append(requests, r)
+	}
+
+	// Log directories are local to each broker, the requests are sent
+	// concurrently to avoid serializing the round trips.
+	responses := make([]*alterreplicalogdirs.Response, len(requests))
+	errs := make([]error, len(requests))
+	wg := sync.WaitGroup{}
+
+	for i, r := range requests {
+		wg.Add(1)
+		go func(i int, r *alterreplicalogdirs.Request) {
+			defer wg.Done()
+			m, err := c.roundTrip(ctx, req.Addr, r)
+			if err != nil {
+				errs[i] = fmt.Errorf("broker %d: %w", r.BrokerID, err)
+			} else {
+				responses[i] = m.(*alterreplicalogdirs.Response)
+			}
+		}(i, r)
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("kafka.(*Client).AlterReplicaLogDirs: %w", err)
+		}
+	}
+
+	ret := &AlterReplicaLogDirsResponse{}
+
+	for i, res := range responses {
+		if throttle := makeDuration(res.ThrottleTimeMs); throttle > ret.Throttle {
+			ret.Throttle = throttle
+		}
+
+		for _, t := range res.Results {
+			for _, p := range t.Partitions {
+				ret.Partitions = append(ret.Partitions, AlterReplicaLogDirsResponsePartition{
+					Broker:    int(requests[i].BrokerID),
+					Topic:     t.TopicName,
+					Partition: int(p.PartitionIndex),
+					Error:     makeError(p.ErrorCode, ""),
+				})
+			}
+		}
+	}
+
+	sort.Slice(ret.Partitions, func(i, j int) bool {
+		p1, p2 := &ret.Partitions[i], &ret.Partitions[j]
+		if p1.Broker != p2.Broker {
+			return p1.Broker < p2.Broker
+		}
+		if p1.Topic != p2.Topic {
+			return p1.Topic < p2.Topic
+		}
+		return p1.Partition < p2.Partition
+	})
+
+	return ret, nil
+}
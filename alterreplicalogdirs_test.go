@@ -0,0 +1,64 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	ktesting "github.com/PerchSecurity/kafka-go/testing"
+)
+
+func TestClientAlterReplicaLogDirs(t *testing.T) {
+	if !ktesting.KafkaIsAtLeast("1.1.0") {
+		return
+	}
+
+	ctx := context.Background()
+	client, shutdown := newLocalClient()
+	defer shutdown()
+
+	topic := makeTopic()
+	createTopic(t, topic, 1)
+	defer deleteTopic(t, topic)
+
+	dirs, err := client.DescribeLogDirs(ctx, &DescribeLogDirsRequest{
+		Topics: map[string][]int{topic: {0}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(dirs.LogDirs) == 0 {
+		t.Fatal("expected at least one log directory in the response")
+	}
+
+	broker := dirs.LogDirs[0].Broker
+
+	res, err := client.AlterReplicaLogDirs(ctx, &AlterReplicaLogDirsRequest{
+		Assignments: []AlterReplicaLogDirsAssignment{
+			{
+				Broker:    broker,
+				Topic:     topic,
+				Partition: 0,
+				Path:      "/this/log/dir/does/not/exist",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Partitions) != 1 {
+		t.Fatalf("expected 1 partition result, got %d", len(res.Partitions))
+	}
+
+	p := res.Partitions[0]
+
+	if p.Broker != broker || p.Topic != topic || p.Partition != 0 {
+		t.Errorf("unexpected partition result: %+v", p)
+	}
+
+	if !errors.Is(p.Error, LogDirNotFound) {
+		t.Errorf("expected %v, got %v", LogDirNotFound, p.Error)
+	}
+}
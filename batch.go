@@ -38,6 +38,14 @@ type Batch struct {
 	// we get an EOF we do not get the lastOffset. So there is a mismatch
 	// between when we receive it and need to use it.
 	lastOffset int64
+	// The partition leader epoch of the record batch that the last message
+	// was read from, or -1 if unknown.
+	leaderEpoch int32
+	// True if the broker expects the client to wait for the throttle time
+	// before sending the next fetch request (KIP-219).
+	clientThrottle bool
+	// True if message keys and values are read into pooled buffers.
+	pooled bool
 }
 
 // Throttle gives the throttling duration applied by the kafka server on the
@@ -85,8 +93,7 @@ func (batch *Batch) close() (err error) {
 	}
 
 	if batch.msgs != nil && batch.msgs.decompressed != nil {
-		releaseBuffer(batch.msgs.decompressed)
-		batch.msgs.decompressed = nil
+		batch.msgs.releaseBuffers()
 	}
 
 	if err = batch.err; errors.Is(batch.err, io.EOF) {
@@ -189,6 +196,10 @@ func (batch *Batch) Read(b []byte) (int, error) {
 // Because this method allocate memory buffers for the message key and value
 // it is less memory-efficient than Read, but has the advantage of never
 // failing with io.ErrShortBuffer.
+//
+// When the batch was created with ReadBatchConfig.PooledBuffers set, the key
+// and value of the message alias pooled buffers instead, and the program
+// should call the message's Release method when it is done using it.
 func (batch *Batch) ReadMessage() (Message, error) {
 	msg := Message{}
 	batch.mutex.Lock()
@@ -197,32 +208,39 @@ func (batch *Batch) ReadMessage() (Message, error) {
 	var headers []Header
 	var err error
 
-	offset, timestamp, headers, err = batch.readMessage(
-		func(r *bufio.Reader, size int, nbytes int) (remain int, err error) {
-			msg.Key, remain, err = readNewBytes(r, size, nbytes)
+	readKey := func(r *bufio.Reader, size int, nbytes int) (remain int, err error) {
+		msg.Key, remain, err = readNewBytes(r, size, nbytes)
+		return
+	}
+	readValue := func(r *bufio.Reader, size int, nbytes int) (remain int, err error) {
+		msg.Value, remain, err = readNewBytes(r, size, nbytes)
+		return
+	}
+
+	if batch.pooled {
+		readKey = func(r *bufio.Reader, size int, nbytes int) (remain int, err error) {
+			msg.Key, remain, err = batch.msgs.readLeasedBytes(&msg.lease, r, size, nbytes)
 			return
-		},
-		func(r *bufio.Reader, size int, nbytes int) (remain int, err error) {
-			msg.Value, remain, err = readNewBytes(r, size, nbytes)
+		}
+		readValue = func(r *bufio.Reader, size int, nbytes int) (remain int, err error) {
+			msg.Value, remain, err = batch.msgs.readLeasedBytes(&msg.lease, r, size, nbytes)
 			return
-		},
-	)
+		}
+	}
+
+	offset, timestamp, headers, err = batch.readMessage(readKey, readValue)
 	// A batch may start before the requested offset so skip messages
 	// until the requested offset is reached.
 	for batch.conn != nil && offset < batch.conn.offset {
 		if err != nil {
 			break
 		}
-		offset, timestamp, headers, err = batch.readMessage(
-			func(r *bufio.Reader, size int, nbytes int) (remain int, err error) {
-				msg.Key, remain, err = readNewBytes(r, size, nbytes)
-				return
-			},
-			func(r *bufio.Reader, size int, nbytes int) (remain int, err error) {
-				msg.Value, remain, err = readNewBytes(r, size, nbytes)
-				return
-			},
-		)
+		msg.Release()
+		offset, timestamp, headers, err = batch.readMessage(readKey, readValue)
+	}
+
+	if err != nil {
+		msg.Release()
 	}
 
 	batch.mutex.Unlock()
@@ -250,6 +268,7 @@ func (batch *Batch) readMessage(
 	case err == nil:
 		batch.offset = offset + 1
 		batch.lastOffset = lastOffset
+		batch.leaderEpoch = batch.msgs.leaderEpoch()
 	case errors.Is(err, errShortRead):
 		// As an "optimization" kafka truncates the returned response after
 		// producing MaxBytes, which could then cause the code to return
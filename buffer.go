@@ -3,6 +3,7 @@ package kafka
 import (
 	"bytes"
 	"sync"
+	"sync/atomic"
 )
 
 var bufferPool = sync.Pool{
@@ -25,3 +26,46 @@ func releaseBuffer(b *bytes.Buffer) {
 		bufferPool.Put(b)
 	}
 }
+
+var bufferLeasePool = sync.Pool{
+	New: func() interface{} { return &bufferLease{buffer: new(bytes.Buffer)} },
+}
+
+// bufferLease is a reference counted buffer, it is used when reading messages
+// with pooled buffers to track when the keys and values aliasing the buffer
+// are not referenced by the program anymore.
+//
+// The buffer is returned to the pool when the last reference is released.
+type bufferLease struct {
+	refc   int32
+	buffer *bytes.Buffer
+}
+
+func acquireBufferLease() *bufferLease {
+	l := bufferLeasePool.Get().(*bufferLease)
+	l.refc = 1
+	return l
+}
+
+func (l *bufferLease) ref() { atomic.AddInt32(&l.refc, 1) }
+
+func (l *bufferLease) unref() {
+	if atomic.AddInt32(&l.refc, -1) == 0 {
+		l.buffer.Reset()
+		bufferLeasePool.Put(l)
+	}
+}
+
+// messageLease is the reference that a message read with pooled buffers holds
+// on the buffer lease its key and value alias. Copies of the message share the
+// same messageLease, which releases the reference at most once.
+type messageLease struct {
+	released int32
+	buffer   *bufferLease
+}
+
+func (l *messageLease) release() {
+	if atomic.CompareAndSwapInt32(&l.released, 0, 1) {
+		l.buffer.unref()
+	}
+}
@@ -42,6 +42,19 @@ type Client struct {
 	//
 	// If nil, DefaultTransport is used.
 	Transport RoundTripper
+
+	// The policy used to decide whether requests that failed should be
+	// retried. Retries are bounded by the deadline of the context passed to
+	// the client methods, and by the client Timeout, which applies to all the
+	// attempts made to complete a request.
+	//
+	// If nil, requests are attempted only once. DefaultRetryPolicy may be used
+	// to enable retries with exponential backoff.
+	//
+	// When set, the automatic retries of the transport (see Transport.Retry)
+	// are disabled for the requests sent by the client, each attempt results
+	// in a single request sent to the kafka cluster.
+	RetryPolicy RetryPolicy
 }
 
 // A ConsumerGroup and Topic as these are both strings we define a type for
@@ -112,7 +125,42 @@ func (c *Client) roundTrip(ctx context.Context, addr net.Addr, msg protocol.Mess
 		}
 	}
 
-	return c.transport().RoundTrip(ctx, addr, msg)
+	if c.RetryPolicy == nil {
+		return c.transport().RoundTrip(ctx, addr, msg)
+	}
+
+	ctx = withClientRetries(ctx)
+
+	for attempt := 1; ; attempt++ {
+		res, err := c.transport().RoundTrip(ctx, addr, msg)
+		retryErr := err
+
+		if err == nil {
+			if resErr := makeResponseError(res); resErr != nil {
+				retryErr = resErr
+			} else {
+				return res, nil
+			}
+		}
+
+		backoff, retry := c.RetryPolicy.Retry(msg, attempt, retryErr)
+		if !retry {
+			return res, err
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < backoff {
+			// There is not enough time left to make another attempt.
+			return res, err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return res, err
+		}
+	}
 }
 
 func (c *Client) transport() RoundTripper {
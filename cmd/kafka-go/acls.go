@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/PerchSecurity/kafka-go"
+)
+
+var aclsCommands = []command{
+	{name: "create", usage: "create an ACL", run: aclsCreate},
+	{name: "describe", usage: "describe the ACLs matching a filter", run: aclsDescribe},
+	{name: "delete", usage: "delete the ACLs matching a filter", run: aclsDelete},
+}
+
+type acl struct {
+	ResourceType        kafka.ResourceType      `json:"resource_type"`
+	ResourceName        string                  `json:"resource_name"`
+	ResourcePatternType kafka.PatternType       `json:"resource_pattern_type"`
+	Principal           string                  `json:"principal"`
+	Host                string                  `json:"host"`
+	Operation           kafka.ACLOperationType  `json:"operation"`
+	PermissionType      kafka.ACLPermissionType `json:"permission_type"`
+	Error               string                  `json:"error,omitempty"`
+}
+
+// aclFlags registers the flags describing an ACL, or a filter matching ACLs,
+// on flags. The fields of the returned value hold the defaults passed as a
+// until the flags are parsed.
+func aclFlags(flags *flag.FlagSet, a acl) *acl {
+	flags.Var(textValue{&a.ResourceType}, "resource-type", "type of resource (any, topic, group, cluster, transactionalid, delegationtoken)")
+	flags.StringVar(&a.ResourceName, "resource-name", a.ResourceName, "name of the resource")
+	flags.Var(textValue{&a.ResourcePatternType}, "pattern-type", "pattern type of the resource name (any, match, literal, prefixed)")
+	flags.StringVar(&a.Principal, "principal", a.Principal, "principal, e.g. User:alice")
+	flags.StringVar(&a.Host, "host", a.Host, "host the principal connects from")
+	flags.Var(textValue{&a.Operation}, "operation", "operation (any, all, read, write, create, delete, alter, describe, clusteraction, describeconfigs, alterconfigs, idempotentwrite)")
+	flags.Var(textValue{&a.PermissionType}, "permission", "permission type (any, allow, deny)")
+	return &a
+}
+
+func aclsCreate(ctx context.Context, c *cli, args []string) error {
+	flags := c.flags("acls create", "")
+	a := aclFlags(flags, acl{
+		ResourceType:        kafka.ResourceTypeTopic,
+		ResourcePatternType: kafka.PatternTypeLiteral,
+		Host:                "*",
+		Operation:           kafka.ACLOperationTypeAll,
+		PermissionType:      kafka.ACLPermissionTypeAllow,
+	})
+	if err := c.parse(flags, args, 0); err != nil {
+		return err
+	}
+
+	res, err := c.client.CreateACLs(ctx, &kafka.CreateACLsRequest{
+		ACLs: []kafka.ACLEntry{{
+			ResourceType:        a.ResourceType,
+			ResourceName:        a.ResourceName,
+			ResourcePatternType: a.ResourcePatternType,
+			Principal:           a.Principal,
+			Host:                a.Host,
+			Operation:           a.Operation,
+			PermissionType:      a.PermissionType,
+		}},
+	})
+	if err != nil {
+		return err
+	}
+
+	results := make([]acl, len(res.Errors))
+	for i, err := range res.Errors {
+		results[i] = *a
+		results[i].Error = errorString(err)
+	}
+
+	if err := c.print(results); err != nil {
+		return err
+	}
+	return failed(res.Errors...)
+}
+
+// anyACL is the default filter of the describe and delete commands, matching
+// all ACLs.
+var anyACL = acl{
+	ResourceType:        kafka.ResourceTypeAny,
+	ResourcePatternType: kafka.PatternTypeAny,
+	Operation:           kafka.ACLOperationTypeAny,
+	PermissionType:      kafka.ACLPermissionTypeAny,
+}
+
+func aclsDescribe(ctx context.Context, c *cli, args []string) error {
+	flags := c.flags("acls describe", "")
+	f := aclFlags(flags, anyACL)
+	if err := c.parse(flags, args, 0); err != nil {
+		return err
+	}
+
+	res, err := c.client.DescribeACLs(ctx, &kafka.DescribeACLsRequest{
+		Filter: kafka.ACLFilter{
+			ResourceTypeFilter:        f.ResourceType,
+			ResourceNameFilter:        f.ResourceName,
+			ResourcePatternTypeFilter: f.ResourcePatternType,
+			PrincipalFilter:           f.Principal,
+			HostFilter:                f.Host,
+			Operation:                 f.Operation,
+			PermissionType:            f.PermissionType,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if res.Error != nil {
+		return res.Error
+	}
+
+	acls := []acl{}
+	for _, r := range res.Resources {
+		for _, d := range r.ACLs {
+			acls = append(acls, acl{
+				ResourceType:        r.ResourceType,
+				ResourceName:        r.ResourceName,
+				ResourcePatternType: r.PatternType,
+				Principal:           d.Principal,
+				Host:                d.Host,
+				Operation:           d.Operation,
+				PermissionType:      d.PermissionType,
+			})
+		}
+	}
+
+	return c.print(acls)
+}
+
+func aclsDelete(ctx context.Context, c *cli, args []string) error {
+	flags := c.flags("acls delete", "")
+	f := aclFlags(flags, anyACL)
+	if err := c.parse(flags, args, 0); err != nil {
+		return err
+	}
+
+	res, err := c.client.DeleteACLs(ctx, &kafka.DeleteACLsRequest{
+		Filters: []kafka.DeleteACLsFilter{{
+			ResourceTypeFilter:        f.ResourceType,
+			ResourceNameFilter:        f.ResourceName,
+			ResourcePatternTypeFilter: f.ResourcePatternType,
+			PrincipalFilter:           f.Principal,
+			HostFilter:                f.Host,
+			Operation:                 f.Operation,
+			PermissionType:            f.PermissionType,
+		}},
+	})
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	deleted := []acl{}
+	for _, r := range res.Results {
+		if r.Error != nil {
+			return r.Error
+		}
+		for _, m := range r.MatchingACLs {
+			deleted = append(deleted, acl{
+				ResourceType:        m.ResourceType,
+				ResourceName:        m.ResourceName,
+				ResourcePatternType: m.ResourcePatternType,
+				Principal:           m.Principal,
+				Host:                m.Host,
+				Operation:           m.Operation,
+				PermissionType:      m.PermissionType,
+				Error:               errorString(m.Error),
+			})
+			errs = append(errs, m.Error)
+		}
+	}
+
+	if err := c.print(deleted); err != nil {
+		return err
+	}
+	return failed(errs...)
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PerchSecurity/kafka-go"
+)
+
+func consume(ctx context.Context, c *cli, args []string) error {
+	flags := c.flags("consume", "")
+	topic := flags.String("topic", "", "topic to consume the messages of")
+	partition := flags.Int("partition", 0, "partition to consume the messages of, ignored when -group is set")
+	group := flags.String("group", "", "consumer group to join, committing the offsets of the messages printed")
+	offset := flags.String("offset", "first", "offset to start consuming from: first, last, or an offset; groups only start from first or last when they have no committed offsets")
+	count := flags.Int("count", 0, "exit after printing this number of messages, 0 consumes until interrupted")
+	format := flags.String("format", formatText, "format of the messages printed to stdout (text, json)")
+	encoding := flags.String("encoding", "string", "encoding of keys and values (string, base64)")
+	printKey := flags.Bool("print-key", false, "print the key of messages in the text format")
+	printHeaders := flags.Bool("print-headers", false, "print the headers of messages in the text format, as comma-separated key=value pairs")
+	separator := flags.String("separator", "\t", "separator of the headers, key, and value of messages in the text format")
+	if err := c.parse(flags, args, 0); err != nil {
+		return err
+	}
+
+	if *topic == "" {
+		fmt.Fprintf(c.stderr, "kafka-go consume: -topic is required\n")
+		return errUsage
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+	codec, err := newCodec(*encoding)
+	if err != nil {
+		return err
+	}
+
+	startOffset, err := parseStartOffset(*offset)
+	if err != nil {
+		return err
+	}
+
+	config := kafka.ReaderConfig{
+		Brokers:       c.brokers,
+		Topic:         *topic,
+		Dialer:        c.dialer(),
+		MaxWait:       time.Second,
+		PooledBuffers: true,
+	}
+
+	if *group != "" {
+		if startOffset != kafka.FirstOffset && startOffset != kafka.LastOffset {
+			return fmt.Errorf("consumer groups can only start from the first or last offset, not %d", startOffset)
+		}
+		config.GroupID = *group
+		config.StartOffset = startOffset
+	} else {
+		config.Partition = *partition
+	}
+
+	r := kafka.NewReader(config)
+	defer r.Close()
+
+	if *group == "" {
+		if err := r.SetOffset(startOffset); err != nil {
+			return err
+		}
+	}
+
+	w := bufio.NewWriter(c.stdout)
+	defer w.Flush()
+
+	var write func(kafka.Message) error
+	switch *format {
+	case formatText:
+		write = func(msg kafka.Message) error {
+			return writeTextMessage(w, codec, msg, *printKey, *printHeaders, *separator)
+		}
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		write = func(msg kafka.Message) error {
+			return writeJSONMessage(enc, codec, msg)
+		}
+	}
+
+	for n := 0; *count == 0 || n < *count; n++ {
+		msg, err := r.ReadMessage(ctx)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return err
+		}
+
+		err = write(msg)
+		msg.Release()
+		if err == nil {
+			// Messages are flushed one by one so they are visible when the
+			// output is piped to another program.
+			err = w.Flush()
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func parseStartOffset(s string) (int64, error) {
+	switch s {
+	case "first":
+		return kafka.FirstOffset, nil
+	case "last":
+		return kafka.LastOffset, nil
+	}
+	offset, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid offset to consume from: %s", s)
+	}
+	return offset, nil
+}
+
+// writeTextMessage writes msg on a line of text, optionally prefixing the
+// value with the headers and key of the message.
+func writeTextMessage(w io.Writer, codec codec, msg kafka.Message, printKey, printHeaders bool, separator string) error {
+	var fields []string
+
+	if printHeaders {
+		headers := make([]string, len(msg.Headers))
+		for i, h := range msg.Headers {
+			headers[i] = h.Key + "=" + codec.encode(h.Value)
+		}
+		fields = append(fields, strings.Join(headers, ","))
+	}
+
+	if printKey {
+		fields = append(fields, codec.encode(msg.Key))
+	}
+
+	fields = append(fields, codec.encode(msg.Value))
+	_, err := fmt.Fprintln(w, strings.Join(fields, separator))
+	return err
+}
+
+func writeJSONMessage(enc *json.Encoder, codec codec, msg kafka.Message) error {
+	m := jsonMessage{
+		Topic:     msg.Topic,
+		Partition: &msg.Partition,
+		Offset:    &msg.Offset,
+		Key:       codec.encodeBytes(msg.Key),
+		Value:     codec.encodeBytes(msg.Value),
+	}
+
+	if !msg.Time.IsZero() {
+		m.Time = &msg.Time
+	}
+
+	for _, h := range msg.Headers {
+		m.Headers = append(m.Headers, jsonHeader{Key: h.Key, Value: codec.encodeBytes(h.Value)})
+	}
+
+	return enc.Encode(m)
+}
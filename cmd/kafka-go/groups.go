@@ -0,0 +1,398 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PerchSecurity/kafka-go"
+)
+
+var groupsCommands = []command{
+	{name: "list", usage: "list the consumer groups of the cluster", run: groupsList},
+	{name: "describe", usage: "describe the state and members of consumer groups", run: groupsDescribe},
+	{name: "lag", usage: "show the committed offsets and lag of consumer groups", run: groupsLag},
+	{name: "reset", usage: "reset the committed offsets of an inactive consumer group", run: groupsReset},
+}
+
+type groupListing struct {
+	GroupID     string `json:"group_id"`
+	Coordinator int    `json:"coordinator"`
+}
+
+func groupsList(ctx context.Context, c *cli, args []string) error {
+	flags := c.flags("groups list", "")
+	if err := c.parse(flags, args, 0); err != nil {
+		return err
+	}
+
+	meta, err := c.client.Metadata(ctx, &kafka.MetadataRequest{})
+	if err != nil {
+		return err
+	}
+
+	// Each broker only lists the groups that it coordinates.
+	groups := []groupListing{}
+	for _, b := range meta.Brokers {
+		addr := kafka.TCP(net.JoinHostPort(b.Host, strconv.Itoa(b.Port)))
+		res, err := c.client.ListGroups(ctx, &kafka.ListGroupsRequest{Addr: addr})
+		if err != nil {
+			return fmt.Errorf("listing groups of broker %d: %w", b.ID, err)
+		}
+		if res.Error != nil {
+			return fmt.Errorf("listing groups of broker %d: %w", b.ID, res.Error)
+		}
+		for _, g := range res.Groups {
+			groups = append(groups, groupListing{GroupID: g.GroupID, Coordinator: g.Coordinator})
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].GroupID < groups[j].GroupID })
+
+	return c.print(groups)
+}
+
+type groupDescription struct {
+	GroupID string        `json:"group_id"`
+	State   string        `json:"state,omitempty"`
+	Members []groupMember `json:"members,omitempty"`
+	Error   string        `json:"error,omitempty"`
+}
+
+type groupMember struct {
+	MemberID    string           `json:"member_id"`
+	ClientID    string           `json:"client_id"`
+	ClientHost  string           `json:"client_host"`
+	Assignments map[string][]int `json:"assignments"`
+}
+
+func groupsDescribe(ctx context.Context, c *cli, args []string) error {
+	flags := c.flags("groups describe", "group...")
+	if err := c.parse(flags, args, 1); err != nil {
+		return err
+	}
+
+	res, err := c.client.DescribeGroups(ctx, &kafka.DescribeGroupsRequest{GroupIDs: flags.Args()})
+	if err != nil {
+		return err
+	}
+
+	groups := make([]groupDescription, len(res.Groups))
+	errs := make([]error, len(res.Groups))
+	for i, g := range res.Groups {
+		groups[i] = groupDescription{
+			GroupID: g.GroupID,
+			State:   g.GroupState,
+			Error:   errorString(g.Error),
+		}
+		for _, m := range g.Members {
+			member := groupMember{
+				MemberID:    m.MemberID,
+				ClientID:    m.ClientID,
+				ClientHost:  m.ClientHost,
+				Assignments: make(map[string][]int),
+			}
+			for _, t := range m.MemberAssignments.Topics {
+				member.Assignments[t.Topic] = t.Partitions
+			}
+			groups[i].Members = append(groups[i].Members, member)
+		}
+		errs[i] = g.Error
+	}
+
+	if err := c.print(groups); err != nil {
+		return err
+	}
+	return failed(errs...)
+}
+
+type partitionLag struct {
+	GroupID         string `json:"group_id"`
+	Topic           string `json:"topic"`
+	Partition       int    `json:"partition"`
+	CommittedOffset int64  `json:"committed_offset"`
+	LogEndOffset    int64  `json:"log_end_offset"`
+	Lag             int64  `json:"lag"`
+}
+
+func groupsLag(ctx context.Context, c *cli, args []string) error {
+	flags := c.flags("groups lag", "group...")
+	topic := flags.String("topic", "", "only show the lag on this topic")
+	if err := c.parse(flags, args, 1); err != nil {
+		return err
+	}
+
+	lags := []partitionLag{}
+
+	for _, group := range flags.Args() {
+		req := &kafka.OffsetFetchRequest{GroupID: group}
+		if *topic != "" {
+			t, err := lookupTopic(ctx, c, *topic)
+			if err != nil {
+				return err
+			}
+			req.Topics = map[string][]int{*topic: partitionIDs(t)}
+		}
+
+		committed, err := c.client.OffsetFetch(ctx, req)
+		if err != nil {
+			return err
+		}
+		if committed.Error != nil {
+			return fmt.Errorf("%s: %w", group, committed.Error)
+		}
+
+		partitions := make(map[string][]int)
+		for topic, offsets := range committed.Topics {
+			for _, p := range offsets {
+				if p.Error != nil {
+					return fmt.Errorf("%s: %s[%d]: %w", group, topic, p.Partition, p.Error)
+				}
+				if p.CommittedOffset >= 0 {
+					partitions[topic] = append(partitions[topic], p.Partition)
+				}
+			}
+		}
+
+		if len(partitions) == 0 {
+			continue
+		}
+
+		ends, err := listOffsets(ctx, c, partitions, kafka.LastOffsetOf)
+		if err != nil {
+			return err
+		}
+
+		for topic, offsets := range committed.Topics {
+			for _, p := range offsets {
+				if p.CommittedOffset < 0 {
+					continue
+				}
+				end := ends[topic][p.Partition].LastOffset
+				lags = append(lags, partitionLag{
+					GroupID:         group,
+					Topic:           topic,
+					Partition:       p.Partition,
+					CommittedOffset: p.CommittedOffset,
+					LogEndOffset:    end,
+					Lag:             end - p.CommittedOffset,
+				})
+			}
+		}
+	}
+
+	sort.Slice(lags, func(i, j int) bool {
+		if lags[i].GroupID != lags[j].GroupID {
+			return lags[i].GroupID < lags[j].GroupID
+		}
+		if lags[i].Topic != lags[j].Topic {
+			return lags[i].Topic < lags[j].Topic
+		}
+		return lags[i].Partition < lags[j].Partition
+	})
+
+	return c.print(lags)
+}
+
+type offsetReset struct {
+	Topic          string `json:"topic"`
+	Partition      int    `json:"partition"`
+	PreviousOffset int64  `json:"previous_offset"`
+	Offset         int64  `json:"offset"`
+	Error          string `json:"error,omitempty"`
+}
+
+func groupsReset(ctx context.Context, c *cli, args []string) error {
+	flags := c.flags("groups reset", "group")
+	topic := flags.String("topic", "", "topic to reset the offsets of")
+	partitionList := flags.String("partitions", "", "comma-separated list of partitions to reset, all partitions when empty")
+	to := flags.String("to", "", "offset to reset to: earliest, latest, an offset, or an RFC 3339 time")
+	dryRun := flags.Bool("dry-run", false, "show the new offsets without committing them")
+	if err := c.parse(flags, args, 1); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 || *topic == "" || *to == "" {
+		fmt.Fprintf(c.stderr, "kafka-go groups reset: a group, -topic, and -to are required\n")
+		return errUsage
+	}
+	group := flags.Arg(0)
+
+	partitions, err := parseInts(*partitionList)
+	if err != nil {
+		return err
+	}
+	if len(partitions) == 0 {
+		t, err := lookupTopic(ctx, c, *topic)
+		if err != nil {
+			return err
+		}
+		partitions = partitionIDs(t)
+	}
+
+	topicPartitions := map[string][]int{*topic: partitions}
+
+	committed, err := c.client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{
+		GroupID: group,
+		Topics:  topicPartitions,
+	})
+	if err != nil {
+		return err
+	}
+	if committed.Error != nil {
+		return fmt.Errorf("%s: %w", group, committed.Error)
+	}
+
+	offsets, err := resetOffsets(ctx, c, *topic, partitions, *to)
+	if err != nil {
+		return err
+	}
+
+	resets := make([]offsetReset, len(partitions))
+	for i, p := range partitions {
+		resets[i] = offsetReset{Topic: *topic, Partition: p, PreviousOffset: -1, Offset: offsets[p]}
+		for _, offset := range committed.Topics[*topic] {
+			if offset.Partition == p {
+				resets[i].PreviousOffset = offset.CommittedOffset
+			}
+		}
+	}
+
+	if *dryRun {
+		return c.print(resets)
+	}
+
+	commits := make([]kafka.OffsetCommit, len(resets))
+	for i, r := range resets {
+		commits[i] = kafka.OffsetCommit{Partition: r.Partition, Offset: r.Offset}
+	}
+
+	// Committing offsets outside of a generation of the group is only allowed
+	// by the coordinator when the group has no active members.
+	res, err := c.client.OffsetCommit(ctx, &kafka.OffsetCommitRequest{
+		GroupID:      group,
+		GenerationID: -1,
+		Topics:       map[string][]kafka.OffsetCommit{*topic: commits},
+	})
+	if err != nil {
+		return err
+	}
+
+	errs := make([]error, len(resets))
+	for _, p := range res.Topics[*topic] {
+		for i := range resets {
+			if resets[i].Partition == p.Partition {
+				resets[i].Error, errs[i] = errorString(p.Error), p.Error
+			}
+		}
+	}
+
+	if err := c.print(resets); err != nil {
+		return err
+	}
+	return failed(errs...)
+}
+
+// resetOffsets resolves the offsets that the partitions of topic should be
+// reset to, explicit offsets are clamped to the range of offsets available in
+// the partitions.
+func resetOffsets(ctx context.Context, c *cli, topic string, partitions []int, to string) (map[int]int64, error) {
+	offsets := make(map[int]int64, len(partitions))
+	topicPartitions := map[string][]int{topic: partitions}
+
+	first, err := listOffsets(ctx, c, topicPartitions, kafka.FirstOffsetOf)
+	if err != nil {
+		return nil, err
+	}
+	last, err := listOffsets(ctx, c, topicPartitions, kafka.LastOffsetOf)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(to) {
+	case "earliest":
+		for _, p := range partitions {
+			offsets[p] = first[topic][p].FirstOffset
+		}
+
+	case "latest":
+		for _, p := range partitions {
+			offsets[p] = last[topic][p].LastOffset
+		}
+
+	default:
+		if offset, err := strconv.ParseInt(to, 10, 64); err == nil {
+			for _, p := range partitions {
+				min, max := first[topic][p].FirstOffset, last[topic][p].LastOffset
+				switch {
+				case offset < min:
+					offsets[p] = min
+				case offset > max:
+					offsets[p] = max
+				default:
+					offsets[p] = offset
+				}
+			}
+			break
+		}
+
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return nil, fmt.Errorf("invalid offset to reset to: %s", to)
+		}
+
+		at, err := listOffsets(ctx, c, topicPartitions, func(partition int) kafka.OffsetRequest {
+			return kafka.TimeOffsetOf(partition, t)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range partitions {
+			// Partitions which have no messages after the time are reset to
+			// their end.
+			offsets[p] = last[topic][p].LastOffset
+			for offset := range at[topic][p].Offsets {
+				if offset >= 0 {
+					offsets[p] = offset
+				}
+			}
+		}
+	}
+
+	return offsets, nil
+}
+
+// listOffsets lists the offsets of partitions, the request for each partition
+// is constructed by calling makeRequest.
+//
+// Brokers reject requests that ask for multiple offsets of the same partition
+// so the first, last, and time based offsets have to be listed separately.
+func listOffsets(ctx context.Context, c *cli, partitions map[string][]int, makeRequest func(int) kafka.OffsetRequest) (map[string]map[int]kafka.PartitionOffsets, error) {
+	req := &kafka.ListOffsetsRequest{Topics: make(map[string][]kafka.OffsetRequest, len(partitions))}
+	for topic, ids := range partitions {
+		for _, id := range ids {
+			req.Topics[topic] = append(req.Topics[topic], makeRequest(id))
+		}
+	}
+
+	res, err := c.client.ListOffsets(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	offsets := make(map[string]map[int]kafka.PartitionOffsets, len(res.Topics))
+	for topic, partitionOffsets := range res.Topics {
+		offsets[topic] = make(map[int]kafka.PartitionOffsets, len(partitionOffsets))
+		for _, p := range partitionOffsets {
+			if p.Error != nil {
+				return nil, fmt.Errorf("%s[%d]: %w", topic, p.Partition, p.Error)
+			}
+			offsets[topic][p.Partition] = p
+		}
+	}
+	return offsets, nil
+}
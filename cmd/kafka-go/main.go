@@ -0,0 +1,389 @@
+// Command kafka-go exposes the kafka-go Client API on the command line, it is
+// intended for operating kafka clusters from environments where the JVM tools
+// are not available.
+//
+// Usage:
+//
+//	kafka-go [flags] <command> [subcommand] [arguments]
+//
+// The commands are:
+//
+//	topics         list, create, describe, delete, and alter-config
+//	reassignments  alter and list partition reassignments
+//	acls           create, describe, and delete ACLs
+//	quotas         alter and describe client quotas
+//	scram          upsert, delete, and describe SCRAM credentials
+//	groups         list, describe, lag, and reset consumer groups
+//	produce        write messages read from stdin to a topic
+//	consume        print messages read from a topic to stdout
+//
+// Administrative commands print their results as JSON documents on stdout,
+// and exit with a non-zero status if any of the operations failed.
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/PerchSecurity/kafka-go"
+	"github.com/PerchSecurity/kafka-go/sasl"
+	"github.com/PerchSecurity/kafka-go/sasl/plain"
+	"github.com/PerchSecurity/kafka-go/sasl/scram"
+)
+
+var (
+	// errUsage is returned when the command line could not be parsed, the
+	// error has already been reported when it is returned.
+	errUsage = errors.New("usage")
+
+	// errFailed is returned when the command completed but some of the
+	// operations it carried were rejected by the cluster.
+	errFailed = errors.New("some operations failed")
+)
+
+func main() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		// Stop capturing signals so a second interrupt terminates the program
+		// if it does not exit promptly.
+		signal.Stop(signals)
+		cancel()
+	}()
+
+	switch err := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr); {
+	case err == nil:
+	case errors.Is(err, errUsage):
+		os.Exit(2)
+	default:
+		fmt.Fprintf(os.Stderr, "kafka-go: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// command is a node in the tree of commands of the program, either a leaf
+// with a run function, or a group of subcommands.
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, c *cli, args []string) error
+	sub   []command
+}
+
+var commands = []command{
+	{name: "topics", usage: "list, create, describe, delete, and alter-config", sub: topicsCommands},
+	{name: "reassignments", usage: "alter and list partition reassignments", sub: reassignmentsCommands},
+	{name: "acls", usage: "create, describe, and delete ACLs", sub: aclsCommands},
+	{name: "quotas", usage: "alter and describe client quotas", sub: quotasCommands},
+	{name: "scram", usage: "upsert, delete, and describe SCRAM credentials", sub: scramCommands},
+	{name: "groups", usage: "list, describe, lag, and reset consumer groups", sub: groupsCommands},
+	{name: "produce", usage: "write messages read from stdin to a topic", run: produce},
+	{name: "consume", usage: "print messages read from a topic to stdout", run: consume},
+}
+
+// cli carries the state shared by all commands.
+type cli struct {
+	brokers   []string
+	timeout   time.Duration
+	clientID  string
+	tls       *tls.Config
+	sasl      sasl.Mechanism
+	client    *kafka.Client
+	transport *kafka.Transport
+	stdin     io.Reader
+	stdout    io.Writer
+	stderr    io.Writer
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	c := &cli{
+		stdin:  stdin,
+		stdout: stdout,
+		stderr: stderr,
+	}
+
+	var (
+		brokers       = envOr("KAFKA_BROKERS", "localhost:9092")
+		tlsEnabled    bool
+		tlsCA         string
+		tlsInsecure   bool
+		saslMechanism string
+		saslUsername  = os.Getenv("KAFKA_SASL_USERNAME")
+		saslPassword  = os.Getenv("KAFKA_SASL_PASSWORD")
+	)
+
+	flags := flag.NewFlagSet("kafka-go", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&brokers, "brokers", brokers, "comma-separated list of bootstrap brokers (env: KAFKA_BROKERS)")
+	flags.DurationVar(&c.timeout, "timeout", 30*time.Second, "timeout of administrative requests")
+	flags.StringVar(&c.clientID, "client-id", "kafka-go", "client ID sent to the brokers")
+	flags.BoolVar(&tlsEnabled, "tls", false, "connect to the brokers with TLS")
+	flags.StringVar(&tlsCA, "tls-ca", "", "path to a PEM file of certificate authorities to verify the brokers with")
+	flags.BoolVar(&tlsInsecure, "tls-insecure", false, "skip verification of the broker certificates")
+	flags.StringVar(&saslMechanism, "sasl", "", "SASL mechanism to authenticate with (plain, scram-sha-256, scram-sha-512)")
+	flags.StringVar(&saslUsername, "sasl-username", saslUsername, "SASL username (env: KAFKA_SASL_USERNAME)")
+	flags.StringVar(&saslPassword, "sasl-password", saslPassword, "SASL password (env: KAFKA_SASL_PASSWORD)")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: kafka-go [flags] <command> [subcommand] [arguments]\n\nCommands:\n")
+		printCommands(stderr, commands)
+		fmt.Fprintf(stderr, "\nFlags:\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errUsage
+	}
+
+	c.brokers = splitList(brokers)
+	if len(c.brokers) == 0 {
+		fmt.Fprintf(stderr, "kafka-go: no brokers were configured\n")
+		return errUsage
+	}
+
+	if tlsEnabled || tlsCA != "" || tlsInsecure {
+		config, err := newTLSConfig(tlsCA, tlsInsecure)
+		if err != nil {
+			return err
+		}
+		c.tls = config
+	}
+
+	if saslMechanism != "" {
+		mechanism, err := newSASLMechanism(saslMechanism, saslUsername, saslPassword)
+		if err != nil {
+			return err
+		}
+		c.sasl = mechanism
+	}
+
+	c.transport = &kafka.Transport{
+		ClientID: c.clientID,
+		TLS:      c.tls,
+		SASL:     c.sasl,
+	}
+	defer c.transport.CloseIdleConnections()
+
+	c.client = &kafka.Client{
+		Addr:      kafka.TCP(c.brokers...),
+		Timeout:   c.timeout,
+		Transport: c.transport,
+	}
+
+	return dispatch(ctx, c, "kafka-go", commands, flags.Args())
+}
+
+func dispatch(ctx context.Context, c *cli, prefix string, cmds []command, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprintf(c.stderr, "Usage: %s <command>\n\nCommands:\n", prefix)
+		printCommands(c.stderr, cmds)
+		return errUsage
+	}
+
+	for _, cmd := range cmds {
+		if cmd.name == args[0] {
+			if cmd.sub != nil {
+				return dispatch(ctx, c, prefix+" "+cmd.name, cmd.sub, args[1:])
+			}
+			return cmd.run(ctx, c, args[1:])
+		}
+	}
+
+	fmt.Fprintf(c.stderr, "%s: unknown command %q\n\nCommands:\n", prefix, args[0])
+	printCommands(c.stderr, cmds)
+	return errUsage
+}
+
+func printCommands(w io.Writer, cmds []command) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for _, cmd := range cmds {
+		fmt.Fprintf(tw, "  %s\t%s\n", cmd.name, cmd.usage)
+	}
+	tw.Flush()
+}
+
+// flags returns a flag set for the subcommand name, the arguments describes
+// the positional arguments accepted by the subcommand in its usage message.
+func (c *cli) flags(name, arguments string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(c.stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: kafka-go %s [flags] %s\n", name, arguments)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parse parses the arguments of a subcommand, and checks that at least min
+// positional arguments remain after the flags.
+func (c *cli) parse(flags *flag.FlagSet, args []string, min int) error {
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	if flags.NArg() < min {
+		flags.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+// print writes v to the standard output of the program as an indented JSON
+// document.
+func (c *cli) print(v interface{}) error {
+	enc := json.NewEncoder(c.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// dialer returns a dialer configured with the connection settings of the
+// program, for use with readers which do not go through the transport.
+func (c *cli) dialer() *kafka.Dialer {
+	return &kafka.Dialer{
+		ClientID:      c.clientID,
+		Timeout:       c.timeout,
+		DualStack:     true,
+		TLS:           c.tls,
+		SASLMechanism: c.sasl,
+	}
+}
+
+func newTLSConfig(caFile string, insecure bool) (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: insecure}
+
+	if caFile != "" {
+		pem, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", caFile)
+		}
+	}
+
+	return config, nil
+}
+
+func newSASLMechanism(name, username, password string) (sasl.Mechanism, error) {
+	switch strings.ToLower(name) {
+	case "plain":
+		return plain.Mechanism{Username: username, Password: password}, nil
+	case "scram-sha-256":
+		return scram.Mechanism(scram.SHA256, username, password)
+	case "scram-sha-512":
+		return scram.Mechanism(scram.SHA512, username, password)
+	default:
+		return nil, fmt.Errorf("unsupported SASL mechanism: %s", name)
+	}
+}
+
+// errorString returns the message of err, or an empty string if err is nil,
+// errors are reported as strings in the JSON output of the commands.
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// failed returns errFailed if any of the errors passed as argument is not
+// nil.
+func failed(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return errFailed
+		}
+	}
+	return nil
+}
+
+func envOr(name, defaultValue string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// parseInts parses a comma-separated list of integers.
+func parseInts(s string) ([]int, error) {
+	var list []int
+	for _, item := range splitList(s) {
+		i, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer in list %q: %s", s, item)
+		}
+		list = append(list, i)
+	}
+	return list, nil
+}
+
+// parseKeyValue splits s in a key and value separated by '='.
+func parseKeyValue(s string) (key, value string, err error) {
+	i := strings.IndexByte(s, '=')
+	if i <= 0 {
+		return "", "", fmt.Errorf("expected key=value but got %q", s)
+	}
+	return s[:i], s[i+1:], nil
+}
+
+// stringList is a flag.Value which accumulates the values of a flag passed
+// multiple times on the command line.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+// textValue adapts types implementing encoding.TextMarshaler and
+// encoding.TextUnmarshaler to the flag.Value interface.
+type textValue struct {
+	v interface {
+		encoding.TextMarshaler
+		encoding.TextUnmarshaler
+	}
+}
+
+func (t textValue) String() string {
+	if t.v == nil {
+		return ""
+	}
+	b, _ := t.v.MarshalText()
+	return string(b)
+}
+
+func (t textValue) Set(s string) error { return t.v.UnmarshalText([]byte(s)) }
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PerchSecurity/kafka-go"
+	"github.com/PerchSecurity/kafka-go/kafkatest"
+)
+
+func newTestCluster(t *testing.T) *kafkatest.Cluster {
+	t.Helper()
+	cluster, err := kafkatest.NewCluster(kafkatest.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cluster.Close() })
+	return cluster
+}
+
+// runCommand runs the program against the cluster with the arguments and
+// standard input passed to the function, and returns its standard output.
+func runCommand(t *testing.T, cluster *kafkatest.Cluster, stdin string, args ...string) (string, error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	args = append([]string{"-brokers", strings.Join(cluster.Brokers(), ",")}, args...)
+	err := run(ctx, args, strings.NewReader(stdin), stdout, stderr)
+	if err != nil {
+		t.Logf("kafka-go %s: %s", strings.Join(args, " "), stderr)
+	}
+	return stdout.String(), err
+}
+
+func decodeOutput(t *testing.T, output string, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal([]byte(output), v); err != nil {
+		t.Fatalf("decoding %q: %v", output, err)
+	}
+}
+
+func TestTopics(t *testing.T) {
+	cluster := newTestCluster(t)
+
+	out, err := runCommand(t, cluster, "", "topics", "create", "-partitions", "3", "topic-A", "topic-B")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var created []topicResult
+	decodeOutput(t, out, &created)
+	if want := []topicResult{{Topic: "topic-A"}, {Topic: "topic-B"}}; !reflect.DeepEqual(created, want) {
+		t.Errorf("created topics mismatch: want=%+v got=%+v", want, created)
+	}
+
+	out, err = runCommand(t, cluster, "", "topics", "create", "-partitions", "3", "topic-A")
+	if !errors.Is(err, errFailed) {
+		t.Errorf("creating an existing topic must fail, got %v", err)
+	}
+	decodeOutput(t, out, &created)
+	if len(created) != 1 || created[0].Error == "" {
+		t.Errorf("creating an existing topic must report an error: %+v", created)
+	}
+
+	out, err = runCommand(t, cluster, "", "topics", "list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var listed []topicListing
+	decodeOutput(t, out, &listed)
+	if len(listed) != 2 || listed[0].Name != "topic-A" || listed[1].Name != "topic-B" || listed[0].Partitions != 3 {
+		t.Errorf("listed topics mismatch: %+v", listed)
+	}
+
+	out, err = runCommand(t, cluster, "", "topics", "describe", "-configs=false", "topic-A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var described []topicDescription
+	decodeOutput(t, out, &described)
+	if len(described) != 1 || len(described[0].Partitions) != 3 {
+		t.Fatalf("described topics mismatch: %+v", described)
+	}
+	for i, p := range described[0].Partitions {
+		if p.ID != i || len(p.Replicas) == 0 {
+			t.Errorf("partition %d mismatch: %+v", i, p)
+		}
+	}
+
+	if _, err := runCommand(t, cluster, "", "topics", "delete", "topic-B"); err != nil {
+		t.Fatal(err)
+	}
+	if topics := cluster.Topics(); !reflect.DeepEqual(topics, []string{"topic-A"}) {
+		t.Errorf("topics after deletion mismatch: %v", topics)
+	}
+}
+
+func TestProduceConsumeJSON(t *testing.T) {
+	cluster := newTestCluster(t)
+	if err := cluster.CreateTopic("events", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	input := `{"key":"k1","value":"hello","headers":[{"key":"h","value":"1"}]}
+{"key":null,"value":"world"}
+`
+	if _, err := runCommand(t, cluster, input, "produce", "-topic", "events", "-format", "json", "-header", "source=test"); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := runCommand(t, cluster, "", "consume", "-topic", "events", "-format", "json", "-count", "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var msgs []jsonMessage
+	d := json.NewDecoder(strings.NewReader(out))
+	for d.More() {
+		var m jsonMessage
+		if err := d.Decode(&m); err != nil {
+			t.Fatal(err)
+		}
+		msgs = append(msgs, m)
+	}
+
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 messages but got %d: %s", len(msgs), out)
+	}
+
+	str := func(s string) *string { return &s }
+	want := []jsonMessage{
+		{
+			Key:     str("k1"),
+			Value:   str("hello"),
+			Headers: []jsonHeader{{Key: "h", Value: str("1")}, {Key: "source", Value: str("test")}},
+		},
+		{
+			Value:   str("world"),
+			Headers: []jsonHeader{{Key: "source", Value: str("test")}},
+		},
+	}
+
+	for i, m := range msgs {
+		if m.Topic != "events" || m.Partition == nil || *m.Partition != 0 || m.Offset == nil || *m.Offset != int64(i) {
+			t.Errorf("message %d position mismatch: topic=%q partition=%v offset=%v", i, m.Topic, m.Partition, m.Offset)
+		}
+		m.Topic, m.Partition, m.Offset, m.Time = "", nil, nil, nil
+		if !reflect.DeepEqual(m, want[i]) {
+			got, _ := json.Marshal(m)
+			exp, _ := json.Marshal(want[i])
+			t.Errorf("message %d mismatch:\nwant: %s\ngot:  %s", i, exp, got)
+		}
+	}
+}
+
+func TestProduceConsumeText(t *testing.T) {
+	cluster := newTestCluster(t)
+	if err := cluster.CreateTopic("lines", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	input := "a:1\nb:2\nc:3\n"
+	if _, err := runCommand(t, cluster, input, "produce", "-topic", "lines", "-key-separator", ":"); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := runCommand(t, cluster, "", "consume", "-topic", "lines", "-offset", "1", "-count", "2", "-print-key", "-separator", "=")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "b=2\nc=3\n"; out != want {
+		t.Errorf("consumed messages mismatch:\nwant: %q\ngot:  %q", want, out)
+	}
+
+	if _, err := runCommand(t, cluster, "no separator\n", "produce", "-topic", "lines", "-key-separator", ":"); err == nil {
+		t.Error("producing a line without the key separator must fail")
+	}
+}
+
+func TestGroupsLagAndReset(t *testing.T) {
+	cluster := newTestCluster(t)
+	if err := cluster.CreateTopic("orders", 2); err != nil {
+		t.Fatal(err)
+	}
+
+	input := strings.Repeat("message\n", 10)
+	for _, partition := range []string{"0", "1"} {
+		if _, err := runCommand(t, cluster, input, "produce", "-topic", "orders", "-partition", partition); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out, err := runCommand(t, cluster, "", "groups", "reset", "-topic", "orders", "-to", "4", "billing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resets []offsetReset
+	decodeOutput(t, out, &resets)
+	want := []offsetReset{
+		{Topic: "orders", Partition: 0, PreviousOffset: -1, Offset: 4},
+		{Topic: "orders", Partition: 1, PreviousOffset: -1, Offset: 4},
+	}
+	if !reflect.DeepEqual(resets, want) {
+		t.Errorf("offset resets mismatch: want=%+v got=%+v", want, resets)
+	}
+	for partition := 0; partition < 2; partition++ {
+		if offset := cluster.CommittedOffset("billing", "orders", partition); offset != 4 {
+			t.Errorf("committed offset of partition %d mismatch: want=4 got=%d", partition, offset)
+		}
+	}
+
+	out, err = runCommand(t, cluster, "", "groups", "lag", "billing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lags []partitionLag
+	decodeOutput(t, out, &lags)
+	if len(lags) != 2 {
+		t.Fatalf("expected the lag of 2 partitions but got %+v", lags)
+	}
+	for i, lag := range lags {
+		want := partitionLag{GroupID: "billing", Topic: "orders", Partition: i, CommittedOffset: 4, LogEndOffset: 10, Lag: 6}
+		if lag != want {
+			t.Errorf("lag mismatch: want=%+v got=%+v", want, lag)
+		}
+	}
+
+	if _, err := runCommand(t, cluster, "", "groups", "reset", "-topic", "orders", "-partitions", "1", "-to", "latest", "billing"); err != nil {
+		t.Fatal(err)
+	}
+	if offset := cluster.CommittedOffset("billing", "orders", 1); offset != 10 {
+		t.Errorf("committed offset after reset to latest mismatch: want=10 got=%d", offset)
+	}
+
+	out, err = runCommand(t, cluster, "", "groups", "reset", "-topic", "orders", "-to", "100", "-dry-run", "billing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	decodeOutput(t, out, &resets)
+	if resets[0].Offset != 10 || resets[1].PreviousOffset != 10 {
+		t.Errorf("offsets past the end of partitions must be clamped: %+v", resets)
+	}
+	if offset := cluster.CommittedOffset("billing", "orders", 0); offset != 4 {
+		t.Errorf("dry runs must not commit offsets, got %d", offset)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"unknown"},
+		{"topics"},
+		{"topics", "create"},
+		{"produce"},
+		{"groups", "reset", "group"},
+	} {
+		err := run(context.Background(), args, strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{})
+		if !errors.Is(err, errUsage) {
+			t.Errorf("kafka-go %s: expected a usage error but got %v", strings.Join(args, " "), err)
+		}
+	}
+}
+
+func TestSaltPassword(t *testing.T) {
+	// PBKDF2-HMAC-SHA256 test vectors.
+	tests := []struct {
+		password   string
+		salt       string
+		iterations int
+		key        string
+	}{
+		{"password", "salt", 1, "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b"},
+		{"password", "salt", 2, "ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43"},
+		{"password", "salt", 4096, "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a"},
+	}
+
+	for _, test := range tests {
+		key := saltPassword(sha256.New, []byte(test.password), []byte(test.salt), test.iterations)
+		if got := hex.EncodeToString(key); got != test.key {
+			t.Errorf("iterations=%d: want=%s got=%s", test.iterations, test.key, got)
+		}
+	}
+}
+
+func TestParseReplicaAssignment(t *testing.T) {
+	a, err := parseReplicaAssignment("2=1,3,5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (kafka.ReplicaAssignment{Partition: 2, Replicas: []int{1, 3, 5}}); !reflect.DeepEqual(a, want) {
+		t.Errorf("want=%+v got=%+v", want, a)
+	}
+
+	for _, s := range []string{"", "2", "x=1", "2=", "2=a"} {
+		if _, err := parseReplicaAssignment(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+func TestParseQuotaComponent(t *testing.T) {
+	tests := []struct {
+		in  string
+		out kafka.DescribeClientQuotasRequestComponent
+	}{
+		{"user=alice", kafka.DescribeClientQuotasRequestComponent{EntityType: "user", MatchType: matchExact, Match: "alice"}},
+		{"user=", kafka.DescribeClientQuotasRequestComponent{EntityType: "user", MatchType: matchDefault}},
+		{"client-id", kafka.DescribeClientQuotasRequestComponent{EntityType: "client-id", MatchType: matchAny}},
+	}
+
+	for _, test := range tests {
+		if got := parseQuotaComponent(test.in); got != test.out {
+			t.Errorf("%q: want=%+v got=%+v", test.in, test.out, got)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+)
+
+// Formats of the messages read by the produce command and printed by the
+// consume command.
+const (
+	// Messages are lines of text, optionally prefixed by a key and headers.
+	formatText = "text"
+	// Messages are JSON objects, one per line, see jsonMessage.
+	formatJSON = "json"
+)
+
+// jsonMessage is the representation of messages in the JSON format, the
+// consume command prints messages in a form that the produce command accepts
+// so the output of one can be piped into the other.
+type jsonMessage struct {
+	Topic     string       `json:"topic,omitempty"`
+	Partition *int         `json:"partition,omitempty"`
+	Offset    *int64       `json:"offset,omitempty"`
+	Time      *time.Time   `json:"time,omitempty"`
+	Key       *string      `json:"key"`
+	Value     *string      `json:"value"`
+	Headers   []jsonHeader `json:"headers,omitempty"`
+}
+
+type jsonHeader struct {
+	Key   string  `json:"key"`
+	Value *string `json:"value"`
+}
+
+// codec converts keys, values, and header values of messages to and from
+// strings, null values are represented by nil pointers.
+type codec struct {
+	encode func([]byte) string
+	decode func(string) ([]byte, error)
+}
+
+func newCodec(encoding string) (codec, error) {
+	switch encoding {
+	case "string":
+		return codec{
+			encode: func(b []byte) string { return string(b) },
+			decode: func(s string) ([]byte, error) { return []byte(s), nil },
+		}, nil
+	case "base64":
+		return codec{
+			encode: base64.StdEncoding.EncodeToString,
+			decode: base64.StdEncoding.DecodeString,
+		}, nil
+	default:
+		return codec{}, fmt.Errorf("unsupported encoding: %s", encoding)
+	}
+}
+
+func (c codec) encodeBytes(b []byte) *string {
+	if b == nil {
+		return nil
+	}
+	s := c.encode(b)
+	return &s
+}
+
+func (c codec) decodeBytes(s *string) ([]byte, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return c.decode(*s)
+}
+
+func checkFormat(format string) error {
+	switch format {
+	case formatText, formatJSON:
+		return nil
+	default:
+		return fmt.Errorf("unsupported format: %s", format)
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/PerchSecurity/kafka-go"
+)
+
+func produce(ctx context.Context, c *cli, args []string) error {
+	var headers stringList
+	acks := kafka.RequireAll
+	compression := kafka.Compression(0)
+
+	flags := c.flags("produce", "")
+	topic := flags.String("topic", "", "topic to produce the messages to")
+	partition := flags.Int("partition", -1, "partition to produce the messages to, -1 partitions messages by key")
+	format := flags.String("format", formatText, "format of the messages read from stdin (text, json)")
+	encoding := flags.String("encoding", "string", "encoding of keys and values (string, base64)")
+	key := flags.String("key", "", "key of the messages in the text format")
+	keySeparator := flags.String("key-separator", "", "separator of the key and value of messages in the text format, the key is not read from the input when empty")
+	flags.Var(&headers, "header", "header added to every message as key=value, may be repeated")
+	flags.Var(textValue{&acks}, "acks", "acknowledgements required from the brokers (none, one, all)")
+	flags.Var(textValue{&compression}, "compression", "compression codec (none, gzip, snappy, lz4, zstd)")
+	batchSize := flags.Int("batch-size", 100, "maximum number of messages sent in a single batch")
+	if err := c.parse(flags, args, 0); err != nil {
+		return err
+	}
+
+	if *topic == "" {
+		fmt.Fprintf(c.stderr, "kafka-go produce: -topic is required\n")
+		return errUsage
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+	codec, err := newCodec(*encoding)
+	if err != nil {
+		return err
+	}
+
+	var extraHeaders []kafka.Header
+	for _, h := range headers {
+		k, v, err := parseKeyValue(h)
+		if err != nil {
+			return err
+		}
+		extraHeaders = append(extraHeaders, kafka.Header{Key: k, Value: []byte(v)})
+	}
+
+	var balancer kafka.Balancer = &kafka.Murmur2Balancer{}
+	if p := *partition; p >= 0 {
+		balancer = kafka.BalancerFunc(func(kafka.Message, ...int) int { return p })
+	}
+
+	w := &kafka.Writer{
+		Addr:         kafka.TCP(c.brokers...),
+		Topic:        *topic,
+		Balancer:     balancer,
+		BatchSize:    *batchSize,
+		BatchTimeout: 10 * time.Millisecond,
+		RequiredAcks: acks,
+		Compression:  compression,
+		Transport:    c.transport,
+	}
+	defer w.Close()
+
+	r := bufio.NewReader(c.stdin)
+	var decode func() (kafka.Message, error)
+
+	switch *format {
+	case formatText:
+		var defaultKey []byte
+		if *key != "" {
+			if defaultKey, err = codec.decode(*key); err != nil {
+				return fmt.Errorf("decoding key: %w", err)
+			}
+		}
+		decode = func() (kafka.Message, error) {
+			return readTextMessage(r, codec, defaultKey, *keySeparator)
+		}
+	case formatJSON:
+		d := json.NewDecoder(r)
+		decode = func() (kafka.Message, error) {
+			return readJSONMessage(d, codec)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	msgs := make(chan kafka.Message, *batchSize)
+	errc := make(chan error, 1)
+
+	// Input is read concurrently so messages are sent as soon as they are
+	// available when the program is used interactively, and in batches when
+	// the input is piped.
+	go func() {
+		defer close(msgs)
+		for n := 1; ; n++ {
+			msg, err := decode()
+			if err != nil {
+				if err != io.EOF {
+					errc <- fmt.Errorf("reading message %d: %w", n, err)
+				}
+				return
+			}
+			msg.Headers = append(msg.Headers, extraHeaders...)
+			select {
+			case msgs <- msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	batch := make([]kafka.Message, 0, *batchSize)
+	for msg := range msgs {
+		batch = append(batch[:0], msg)
+	fill:
+		for len(batch) < *batchSize {
+			select {
+			case msg, ok := <-msgs:
+				if !ok {
+					break fill
+				}
+				batch = append(batch, msg)
+			default:
+				break fill
+			}
+		}
+		if err := w.WriteMessages(ctx, batch...); err != nil {
+			return err
+		}
+	}
+
+	select {
+	case err := <-errc:
+		return err
+	default:
+		return nil
+	}
+}
+
+// readTextMessage reads a message from a line of text, the key is split from
+// the value at the first occurrence of the separator if it is not empty.
+func readTextMessage(r *bufio.Reader, codec codec, key []byte, separator string) (kafka.Message, error) {
+	line, err := r.ReadString('\n')
+	if line == "" {
+		return kafka.Message{}, err
+	}
+	line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+	msg := kafka.Message{Key: key}
+	value := line
+
+	if separator != "" {
+		i := strings.Index(line, separator)
+		if i < 0 {
+			return msg, fmt.Errorf("key separator %q not found in %q", separator, line)
+		}
+		if msg.Key, err = codec.decode(line[:i]); err != nil {
+			return msg, fmt.Errorf("decoding key: %w", err)
+		}
+		value = line[i+len(separator):]
+	}
+
+	if msg.Value, err = codec.decode(value); err != nil {
+		return msg, fmt.Errorf("decoding value: %w", err)
+	}
+	return msg, nil
+}
+
+// readJSONMessage reads a message from a JSON object, the topic, partition,
+// and offset of the object are ignored.
+func readJSONMessage(d *json.Decoder, codec codec) (kafka.Message, error) {
+	var m jsonMessage
+	if err := d.Decode(&m); err != nil {
+		return kafka.Message{}, err
+	}
+
+	var msg kafka.Message
+	var err error
+
+	if msg.Key, err = codec.decodeBytes(m.Key); err != nil {
+		return msg, fmt.Errorf("decoding key: %w", err)
+	}
+	if msg.Value, err = codec.decodeBytes(m.Value); err != nil {
+		return msg, fmt.Errorf("decoding value: %w", err)
+	}
+	if m.Time != nil {
+		msg.Time = *m.Time
+	}
+
+	for _, h := range m.Headers {
+		value, err := codec.decodeBytes(h.Value)
+		if err != nil {
+			return msg, fmt.Errorf("decoding header %q: %w", h.Key, err)
+		}
+		msg.Headers = append(msg.Headers, kafka.Header{Key: h.Key, Value: value})
+	}
+
+	return msg, nil
+}
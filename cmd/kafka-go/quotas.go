@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/PerchSecurity/kafka-go"
+)
+
+var quotasCommands = []command{
+	{name: "alter", usage: "set or remove client quotas", run: quotasAlter},
+	{name: "describe", usage: "describe the client quotas matching a filter", run: quotasDescribe},
+}
+
+// Match types of the components of a DescribeClientQuotas request.
+const (
+	matchExact   int8 = 0
+	matchDefault int8 = 1
+	matchAny     int8 = 2
+)
+
+type quotaEntity struct {
+	Type string `json:"type"`
+	// The name of the entity is omitted for the default entity of a type.
+	Name string `json:"name,omitempty"`
+}
+
+type quotaResult struct {
+	Entities []quotaEntity      `json:"entities"`
+	Values   map[string]float64 `json:"values,omitempty"`
+	Error    string             `json:"error,omitempty"`
+}
+
+const entityUsage = "entity as type=name, or type= for the default entity of the type (types: user, client-id, ip), may be repeated"
+
+func quotasAlter(ctx context.Context, c *cli, args []string) error {
+	var entities, set, remove stringList
+	flags := c.flags("quotas alter", "")
+	flags.Var(&entities, "entity", entityUsage)
+	flags.Var(&set, "set", "set a quota as key=value, may be repeated")
+	flags.Var(&remove, "remove", "remove a quota, may be repeated")
+	validateOnly := flags.Bool("validate-only", false, "validate the request without altering the quotas")
+	if err := c.parse(flags, args, 0); err != nil {
+		return err
+	}
+
+	if len(entities) == 0 || len(set)+len(remove) == 0 {
+		fmt.Fprintf(c.stderr, "kafka-go quotas alter: at least one -entity and one -set or -remove are required\n")
+		return errUsage
+	}
+
+	entry := kafka.AlterClientQuotaEntry{}
+
+	for _, e := range entities {
+		entityType, entityName, err := parseKeyValue(e)
+		if err != nil {
+			return err
+		}
+		entry.Entities = append(entry.Entities, kafka.AlterClientQuotaEntity{
+			EntityType: entityType,
+			EntityName: entityName,
+		})
+	}
+
+	for _, s := range set {
+		key, value, err := parseKeyValue(s)
+		if err != nil {
+			return err
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid quota value %q: %w", s, err)
+		}
+		entry.Ops = append(entry.Ops, kafka.AlterClientQuotaOps{Key: key, Value: f})
+	}
+
+	for _, key := range remove {
+		entry.Ops = append(entry.Ops, kafka.AlterClientQuotaOps{Key: key, Remove: true})
+	}
+
+	res, err := c.client.AlterClientQuotas(ctx, &kafka.AlterClientQuotasRequest{
+		Entries:      []kafka.AlterClientQuotaEntry{entry},
+		ValidateOnly: *validateOnly,
+	})
+	if err != nil {
+		return err
+	}
+
+	results := make([]quotaResult, len(res.Entries))
+	errs := make([]error, len(res.Entries))
+	for i, e := range res.Entries {
+		results[i].Entities = make([]quotaEntity, len(e.Entities))
+		for j, entity := range e.Entities {
+			results[i].Entities[j] = quotaEntity{Type: entity.EntityType, Name: entity.EntityName}
+		}
+		results[i].Error, errs[i] = errorString(e.Error), e.Error
+	}
+
+	if err := c.print(results); err != nil {
+		return err
+	}
+	return failed(errs...)
+}
+
+func quotasDescribe(ctx context.Context, c *cli, args []string) error {
+	var entities stringList
+	flags := c.flags("quotas describe", "")
+	flags.Var(&entities, "entity", "entity to match as type=name, type= for the default entity, or type for any entity of the type, may be repeated")
+	strict := flags.Bool("strict", false, "only match quotas of entities which have no other types than the ones given")
+	if err := c.parse(flags, args, 0); err != nil {
+		return err
+	}
+
+	req := &kafka.DescribeClientQuotasRequest{Strict: *strict}
+	for _, e := range entities {
+		req.Components = append(req.Components, parseQuotaComponent(e))
+	}
+
+	res, err := c.client.DescribeClientQuotas(ctx, req)
+	if err != nil {
+		return err
+	}
+	if res.Error != nil {
+		return res.Error
+	}
+
+	results := make([]quotaResult, len(res.Entries))
+	for i, e := range res.Entries {
+		results[i].Entities = make([]quotaEntity, len(e.Entities))
+		for j, entity := range e.Entities {
+			results[i].Entities[j] = quotaEntity{Type: entity.EntityType, Name: entity.EntityName}
+		}
+		results[i].Values = make(map[string]float64, len(e.Values))
+		for _, v := range e.Values {
+			results[i].Values[v.Key] = v.Value
+		}
+	}
+
+	return c.print(results)
+}
+
+// parseQuotaComponent parses a filter on quota entities, type=name matches
+// the entity exactly, type= matches the default entity of the type, and type
+// alone matches any entity of the type.
+func parseQuotaComponent(s string) kafka.DescribeClientQuotasRequestComponent {
+	i := strings.IndexByte(s, '=')
+	switch {
+	case i < 0:
+		return kafka.DescribeClientQuotasRequestComponent{EntityType: s, MatchType: matchAny}
+	case i == len(s)-1:
+		return kafka.DescribeClientQuotasRequestComponent{EntityType: s[:i], MatchType: matchDefault}
+	default:
+		return kafka.DescribeClientQuotasRequestComponent{EntityType: s[:i], MatchType: matchExact, Match: s[i+1:]}
+	}
+}
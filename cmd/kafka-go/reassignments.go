@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/PerchSecurity/kafka-go"
+)
+
+var reassignmentsCommands = []command{
+	{name: "alter", usage: "move partitions to a new set of replicas", run: reassignmentsAlter},
+	{name: "list", usage: "list the ongoing partition reassignments", run: reassignmentsList},
+}
+
+// reassignmentPlan is the JSON document describing partition reassignments,
+// it uses the same format as the kafka-reassign-partitions.sh tool so plans
+// can be shared between the two.
+type reassignmentPlan struct {
+	Version    int                     `json:"version"`
+	Partitions []reassignmentPartition `json:"partitions"`
+}
+
+type reassignmentPartition struct {
+	Topic     string `json:"topic"`
+	Partition int    `json:"partition"`
+	Replicas  []int  `json:"replicas"`
+}
+
+type reassignmentResult struct {
+	Topic     string `json:"topic"`
+	Partition int    `json:"partition"`
+	Error     string `json:"error,omitempty"`
+}
+
+func reassignmentsAlter(ctx context.Context, c *cli, args []string) error {
+	flags := c.flags("reassignments alter", "")
+	file := flags.String("file", "-", "path to the JSON reassignment plan, - reads the plan from stdin")
+	topic := flags.String("topic", "", "topic of the partition to reassign, instead of a plan")
+	partition := flags.Int("partition", 0, "partition to reassign, instead of a plan")
+	replicas := flags.String("replicas", "", "comma-separated list of broker IDs to assign the partition to, instead of a plan")
+	if err := c.parse(flags, args, 0); err != nil {
+		return err
+	}
+
+	var plan reassignmentPlan
+
+	if *topic != "" {
+		brokers, err := parseInts(*replicas)
+		if err != nil {
+			return err
+		}
+		if len(brokers) == 0 {
+			fmt.Fprintf(c.stderr, "kafka-go reassignments alter: -replicas is required with -topic\n")
+			return errUsage
+		}
+		plan.Partitions = []reassignmentPartition{{
+			Topic:     *topic,
+			Partition: *partition,
+			Replicas:  brokers,
+		}}
+	} else {
+		r := c.stdin
+		if *file != "-" {
+			f, err := os.Open(*file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			r = f
+		}
+		p, err := readReassignmentPlan(r)
+		if err != nil {
+			return err
+		}
+		plan = p
+	}
+
+	req := &kafka.AlterPartitionReassignmentsRequest{Timeout: c.timeout}
+	for _, p := range plan.Partitions {
+		req.Assignments = append(req.Assignments, kafka.AlterPartitionReassignmentsRequestAssignment{
+			Topic:       p.Topic,
+			PartitionID: p.Partition,
+			BrokerIDs:   p.Replicas,
+		})
+	}
+
+	res, err := c.client.AlterPartitionReassignments(ctx, req)
+	if err != nil {
+		return err
+	}
+	if res.Error != nil {
+		return res.Error
+	}
+
+	results := make([]reassignmentResult, len(res.PartitionResults))
+	errs := make([]error, len(res.PartitionResults))
+	for i, r := range res.PartitionResults {
+		results[i] = reassignmentResult{Topic: r.Topic, Partition: r.PartitionID, Error: errorString(r.Error)}
+		errs[i] = r.Error
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Topic != results[j].Topic {
+			return results[i].Topic < results[j].Topic
+		}
+		return results[i].Partition < results[j].Partition
+	})
+
+	if err := c.print(results); err != nil {
+		return err
+	}
+	return failed(errs...)
+}
+
+type reassignmentStatus struct {
+	Topic            string `json:"topic"`
+	Partition        int    `json:"partition"`
+	Replicas         []int  `json:"replicas"`
+	AddingReplicas   []int  `json:"adding_replicas"`
+	RemovingReplicas []int  `json:"removing_replicas"`
+}
+
+func reassignmentsList(ctx context.Context, c *cli, args []string) error {
+	flags := c.flags("reassignments list", "[topic...]")
+	if err := c.parse(flags, args, 0); err != nil {
+		return err
+	}
+
+	req := &kafka.ListPartitionReassignmentsRequest{Timeout: c.timeout}
+
+	if flags.NArg() != 0 {
+		req.Topics = make(map[string]kafka.ListPartitionReassignmentsRequestTopic, flags.NArg())
+		for _, topic := range flags.Args() {
+			t, err := lookupTopic(ctx, c, topic)
+			if err != nil {
+				return err
+			}
+			req.Topics[topic] = kafka.ListPartitionReassignmentsRequestTopic{
+				PartitionIndexes: partitionIDs(t),
+			}
+		}
+	}
+
+	res, err := c.client.ListPartitionReassignments(ctx, req)
+	if err != nil {
+		return err
+	}
+	if res.Error != nil {
+		return res.Error
+	}
+
+	statuses := []reassignmentStatus{}
+	for topic, t := range res.Topics {
+		for _, p := range t.Partitions {
+			statuses = append(statuses, reassignmentStatus{
+				Topic:            topic,
+				Partition:        p.PartitionIndex,
+				Replicas:         p.Replicas,
+				AddingReplicas:   p.AddingReplicas,
+				RemovingReplicas: p.RemovingReplicas,
+			})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Topic != statuses[j].Topic {
+			return statuses[i].Topic < statuses[j].Topic
+		}
+		return statuses[i].Partition < statuses[j].Partition
+	})
+
+	return c.print(statuses)
+}
+
+func readReassignmentPlan(r io.Reader) (reassignmentPlan, error) {
+	var plan reassignmentPlan
+	if err := json.NewDecoder(r).Decode(&plan); err != nil {
+		return plan, fmt.Errorf("decoding reassignment plan: %w", err)
+	}
+	if len(plan.Partitions) == 0 {
+		return plan, fmt.Errorf("the reassignment plan contains no partitions")
+	}
+	for _, p := range plan.Partitions {
+		if p.Topic == "" || len(p.Replicas) == 0 {
+			return plan, fmt.Errorf("invalid partition in reassignment plan: topic=%q partition=%d replicas=%v", p.Topic, p.Partition, p.Replicas)
+		}
+	}
+	return plan, nil
+}
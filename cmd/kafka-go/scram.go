@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"strings"
+
+	"github.com/PerchSecurity/kafka-go"
+	"github.com/xdg-go/pbkdf2"
+)
+
+var scramCommands = []command{
+	{name: "upsert", usage: "create or update the SCRAM credentials of a user", run: scramUpsert},
+	{name: "delete", usage: "delete the SCRAM credentials of users", run: scramDelete},
+	{name: "describe", usage: "describe the SCRAM credentials of users", run: scramDescribe},
+}
+
+// scramMechanism wraps kafka.ScramMechanism to give it the text
+// representation used in kafka configurations.
+type scramMechanism kafka.ScramMechanism
+
+func (m scramMechanism) String() string {
+	switch kafka.ScramMechanism(m) {
+	case kafka.ScramMechanismSha256:
+		return "SCRAM-SHA-256"
+	case kafka.ScramMechanismSha512:
+		return "SCRAM-SHA-512"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+func (m scramMechanism) MarshalText() ([]byte, error) { return []byte(m.String()), nil }
+
+func (m *scramMechanism) UnmarshalText(b []byte) error {
+	switch strings.ToUpper(string(b)) {
+	case "SCRAM-SHA-256":
+		*m = scramMechanism(kafka.ScramMechanismSha256)
+	case "SCRAM-SHA-512":
+		*m = scramMechanism(kafka.ScramMechanismSha512)
+	default:
+		return fmt.Errorf("unsupported SCRAM mechanism: %s", b)
+	}
+	return nil
+}
+
+// hash returns the hash function used by the mechanism.
+func (m scramMechanism) hash() func() hash.Hash {
+	if kafka.ScramMechanism(m) == kafka.ScramMechanismSha512 {
+		return sha512.New
+	}
+	return sha256.New
+}
+
+type scramResult struct {
+	User  string `json:"user"`
+	Error string `json:"error,omitempty"`
+}
+
+func scramUpsert(ctx context.Context, c *cli, args []string) error {
+	mechanism := scramMechanism(kafka.ScramMechanismSha512)
+	flags := c.flags("scram upsert", "user")
+	flags.Var(textValue{&mechanism}, "mechanism", "SCRAM mechanism (SCRAM-SHA-256, SCRAM-SHA-512)")
+	iterations := flags.Int("iterations", 8192, "number of iterations of the password hash")
+	password := flags.String("password", "", "password of the user, read from the first line of stdin when empty")
+	if err := c.parse(flags, args, 1); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errUsage
+	}
+
+	if *password == "" {
+		line, err := bufio.NewReader(c.stdin).ReadString('\n')
+		if line = strings.TrimRight(line, "\r\n"); line == "" {
+			if err == nil {
+				err = fmt.Errorf("empty password")
+			}
+			return fmt.Errorf("reading password from stdin: %w", err)
+		}
+		*password = line
+	}
+
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+
+	user := flags.Arg(0)
+	res, err := c.client.AlterUserScramCredentials(ctx, &kafka.AlterUserScramCredentialsRequest{
+		Upsertions: []kafka.UserScramCredentialsUpsertion{{
+			Name:           user,
+			Mechanism:      kafka.ScramMechanism(mechanism),
+			Iterations:     *iterations,
+			Salt:           salt,
+			SaltedPassword: saltPassword(mechanism.hash(), []byte(*password), salt, *iterations),
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	return printScramResults(c, res)
+}
+
+func scramDelete(ctx context.Context, c *cli, args []string) error {
+	mechanism := scramMechanism(kafka.ScramMechanismSha512)
+	flags := c.flags("scram delete", "user...")
+	flags.Var(textValue{&mechanism}, "mechanism", "SCRAM mechanism (SCRAM-SHA-256, SCRAM-SHA-512)")
+	if err := c.parse(flags, args, 1); err != nil {
+		return err
+	}
+
+	req := &kafka.AlterUserScramCredentialsRequest{}
+	for _, user := range flags.Args() {
+		req.Deletions = append(req.Deletions, kafka.UserScramCredentialsDeletion{
+			Name:      user,
+			Mechanism: kafka.ScramMechanism(mechanism),
+		})
+	}
+
+	res, err := c.client.AlterUserScramCredentials(ctx, req)
+	if err != nil {
+		return err
+	}
+	return printScramResults(c, res)
+}
+
+func printScramResults(c *cli, res *kafka.AlterUserScramCredentialsResponse) error {
+	results := make([]scramResult, len(res.Results))
+	errs := make([]error, len(res.Results))
+	for i, r := range res.Results {
+		results[i] = scramResult{User: r.User, Error: errorString(r.Error)}
+		errs[i] = r.Error
+	}
+	if err := c.print(results); err != nil {
+		return err
+	}
+	return failed(errs...)
+}
+
+type scramUser struct {
+	User        string            `json:"user"`
+	Credentials []scramCredential `json:"credentials,omitempty"`
+	Error       string            `json:"error,omitempty"`
+}
+
+type scramCredential struct {
+	Mechanism  scramMechanism `json:"mechanism"`
+	Iterations int            `json:"iterations"`
+}
+
+func scramDescribe(ctx context.Context, c *cli, args []string) error {
+	flags := c.flags("scram describe", "[user...]")
+	if err := c.parse(flags, args, 0); err != nil {
+		return err
+	}
+
+	req := &kafka.DescribeUserScramCredentialsRequest{}
+	for _, user := range flags.Args() {
+		req.Users = append(req.Users, kafka.UserScramCredentialsUser{Name: user})
+	}
+
+	res, err := c.client.DescribeUserScramCredentials(ctx, req)
+	if err != nil {
+		return err
+	}
+	if res.Error != nil {
+		return res.Error
+	}
+
+	users := make([]scramUser, len(res.Results))
+	errs := make([]error, len(res.Results))
+	for i, r := range res.Results {
+		users[i] = scramUser{User: r.User, Error: errorString(r.Error)}
+		for _, info := range r.CredentialInfos {
+			users[i].Credentials = append(users[i].Credentials, scramCredential{
+				Mechanism:  scramMechanism(info.Mechanism),
+				Iterations: info.Iterations,
+			})
+		}
+		errs[i] = r.Error
+	}
+
+	if err := c.print(users); err != nil {
+		return err
+	}
+	return failed(errs...)
+}
+
+// saltPassword computes the SaltedPassword of RFC 5802, which is PBKDF2 with
+// the HMAC of the hash function, and a key the size of the hash output.
+//
+// Like the kafka tools, the password is used as is rather than normalized
+// with SASLprep.
+func saltPassword(h func() hash.Hash, password, salt []byte, iterations int) []byte {
+	return pbkdf2.Key(password, salt, iterations, h().Size(), h)
+}
@@ -0,0 +1,384 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/PerchSecurity/kafka-go"
+)
+
+var topicsCommands = []command{
+	{name: "list", usage: "list the topics of the cluster", run: topicsList},
+	{name: "create", usage: "create topics", run: topicsCreate},
+	{name: "describe", usage: "describe the partitions and configuration of topics", run: topicsDescribe},
+	{name: "delete", usage: "delete topics", run: topicsDelete},
+	{name: "alter-config", usage: "alter the configuration of topics", run: topicsAlterConfig},
+}
+
+type topicResult struct {
+	Topic string `json:"topic"`
+	Error string `json:"error,omitempty"`
+}
+
+type topicListing struct {
+	Name       string `json:"name"`
+	ID         string `json:"id,omitempty"`
+	Internal   bool   `json:"internal,omitempty"`
+	Partitions int    `json:"partitions"`
+}
+
+func topicsList(ctx context.Context, c *cli, args []string) error {
+	flags := c.flags("topics list", "")
+	internal := flags.Bool("internal", false, "include internal topics")
+	if err := c.parse(flags, args, 0); err != nil {
+		return err
+	}
+
+	res, err := c.client.Metadata(ctx, &kafka.MetadataRequest{})
+	if err != nil {
+		return err
+	}
+
+	topics := make([]topicListing, 0, len(res.Topics))
+	for _, t := range res.Topics {
+		if t.Internal && !*internal {
+			continue
+		}
+		topics = append(topics, topicListing{
+			Name:       t.Name,
+			ID:         topicID(t),
+			Internal:   t.Internal,
+			Partitions: len(t.Partitions),
+		})
+	}
+	sort.Slice(topics, func(i, j int) bool { return topics[i].Name < topics[j].Name })
+	return c.print(topics)
+}
+
+func topicsCreate(ctx context.Context, c *cli, args []string) error {
+	var configs, assignments stringList
+	flags := c.flags("topics create", "topic...")
+	partitions := flags.Int("partitions", -1, "number of partitions, -1 uses the broker default")
+	replicationFactor := flags.Int("replication-factor", -1, "replication factor, -1 uses the broker default")
+	validateOnly := flags.Bool("validate-only", false, "validate the request without creating the topics")
+	flags.Var(&configs, "config", "topic configuration as name=value, may be repeated")
+	flags.Var(&assignments, "replica-assignment", "replicas of a partition as partition=broker,broker,..., may be repeated")
+	if err := c.parse(flags, args, 1); err != nil {
+		return err
+	}
+
+	configEntries := make([]kafka.ConfigEntry, 0, len(configs))
+	for _, config := range configs {
+		name, value, err := parseKeyValue(config)
+		if err != nil {
+			return err
+		}
+		configEntries = append(configEntries, kafka.ConfigEntry{ConfigName: name, ConfigValue: value})
+	}
+
+	replicaAssignments := make([]kafka.ReplicaAssignment, 0, len(assignments))
+	for _, assignment := range assignments {
+		a, err := parseReplicaAssignment(assignment)
+		if err != nil {
+			return err
+		}
+		replicaAssignments = append(replicaAssignments, a)
+	}
+
+	req := &kafka.CreateTopicsRequest{ValidateOnly: *validateOnly}
+	for _, topic := range flags.Args() {
+		req.Topics = append(req.Topics, kafka.TopicConfig{
+			Topic:              topic,
+			NumPartitions:      *partitions,
+			ReplicationFactor:  *replicationFactor,
+			ReplicaAssignments: replicaAssignments,
+			ConfigEntries:      configEntries,
+		})
+	}
+
+	res, err := c.client.CreateTopics(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	results := make([]topicResult, len(flags.Args()))
+	errs := make([]error, len(flags.Args()))
+	for i, topic := range flags.Args() {
+		errs[i] = res.Errors[topic]
+		results[i] = topicResult{Topic: topic, Error: errorString(errs[i])}
+	}
+
+	if err := c.print(results); err != nil {
+		return err
+	}
+	return failed(errs...)
+}
+
+type topicDescription struct {
+	Name       string                 `json:"name"`
+	ID         string                 `json:"id,omitempty"`
+	Internal   bool                   `json:"internal,omitempty"`
+	Partitions []partitionDescription `json:"partitions,omitempty"`
+	Configs    map[string]string      `json:"configs,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+type partitionDescription struct {
+	ID              int    `json:"id"`
+	Leader          int    `json:"leader"`
+	LeaderEpoch     int    `json:"leader_epoch"`
+	Replicas        []int  `json:"replicas"`
+	Isr             []int  `json:"isr"`
+	OfflineReplicas []int  `json:"offline_replicas,omitempty"`
+	Error           string `json:"error,omitempty"`
+}
+
+func topicsDescribe(ctx context.Context, c *cli, args []string) error {
+	flags := c.flags("topics describe", "topic...")
+	configs := flags.Bool("configs", true, "describe the configuration of the topics")
+	allConfigs := flags.Bool("all-configs", false, "include configuration entries which have default values")
+	if err := c.parse(flags, args, 1); err != nil {
+		return err
+	}
+
+	meta, err := c.client.Metadata(ctx, &kafka.MetadataRequest{Topics: flags.Args()})
+	if err != nil {
+		return err
+	}
+
+	topics := make([]topicDescription, len(meta.Topics))
+	errs := make([]error, len(meta.Topics))
+
+	for i, t := range meta.Topics {
+		desc := topicDescription{
+			Name:     t.Name,
+			ID:       topicID(t),
+			Internal: t.Internal,
+			Error:    errorString(t.Error),
+		}
+		for _, p := range t.Partitions {
+			desc.Partitions = append(desc.Partitions, partitionDescription{
+				ID:              p.ID,
+				Leader:          p.Leader.ID,
+				LeaderEpoch:     p.LeaderEpoch,
+				Replicas:        brokerIDs(p.Replicas),
+				Isr:             brokerIDs(p.Isr),
+				OfflineReplicas: brokerIDs(p.OfflineReplicas),
+				Error:           errorString(p.Error),
+			})
+		}
+		sort.Slice(desc.Partitions, func(i, j int) bool {
+			return desc.Partitions[i].ID < desc.Partitions[j].ID
+		})
+		topics[i], errs[i] = desc, t.Error
+	}
+
+	if *configs {
+		req := &kafka.DescribeConfigsRequest{}
+		for _, t := range meta.Topics {
+			if t.Error == nil {
+				req.Resources = append(req.Resources, kafka.DescribeConfigRequestResource{
+					ResourceType: kafka.ResourceTypeTopic,
+					ResourceName: t.Name,
+				})
+			}
+		}
+
+		if len(req.Resources) != 0 {
+			res, err := c.client.DescribeConfigs(ctx, req)
+			if err != nil {
+				return err
+			}
+
+			for _, r := range res.Resources {
+				for i := range topics {
+					if topics[i].Name != r.ResourceName {
+						continue
+					}
+					if r.Error != nil {
+						topics[i].Error, errs[i] = errorString(r.Error), r.Error
+						continue
+					}
+					topics[i].Configs = make(map[string]string)
+					for _, entry := range r.ConfigEntries {
+						if *allConfigs || !entry.IsDefault {
+							topics[i].Configs[entry.ConfigName] = entry.ConfigValue
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if err := c.print(topics); err != nil {
+		return err
+	}
+	return failed(errs...)
+}
+
+func topicsDelete(ctx context.Context, c *cli, args []string) error {
+	flags := c.flags("topics delete", "topic...")
+	if err := c.parse(flags, args, 1); err != nil {
+		return err
+	}
+
+	res, err := c.client.DeleteTopics(ctx, &kafka.DeleteTopicsRequest{Topics: flags.Args()})
+	if err != nil {
+		return err
+	}
+
+	results := make([]topicResult, len(flags.Args()))
+	errs := make([]error, len(flags.Args()))
+	for i, topic := range flags.Args() {
+		errs[i] = res.Errors[topic]
+		results[i] = topicResult{Topic: topic, Error: errorString(errs[i])}
+	}
+
+	if err := c.print(results); err != nil {
+		return err
+	}
+	return failed(errs...)
+}
+
+func topicsAlterConfig(ctx context.Context, c *cli, args []string) error {
+	var set, remove, appendValues, subtractValues stringList
+	flags := c.flags("topics alter-config", "topic...")
+	flags.Var(&set, "set", "set a configuration entry as name=value, may be repeated")
+	flags.Var(&remove, "delete", "reset a configuration entry to its default value, may be repeated")
+	flags.Var(&appendValues, "append", "append a value to a list configuration entry as name=value, may be repeated")
+	flags.Var(&subtractValues, "subtract", "remove a value from a list configuration entry as name=value, may be repeated")
+	validateOnly := flags.Bool("validate-only", false, "validate the request without altering the configuration")
+	if err := c.parse(flags, args, 1); err != nil {
+		return err
+	}
+
+	var configs []kafka.IncrementalAlterConfigsRequestConfig
+
+	for _, op := range []struct {
+		values    stringList
+		operation kafka.ConfigOperation
+	}{
+		{set, kafka.ConfigOperationSet},
+		{appendValues, kafka.ConfigOperationAppend},
+		{subtractValues, kafka.ConfigOperationSubtract},
+	} {
+		for _, v := range op.values {
+			name, value, err := parseKeyValue(v)
+			if err != nil {
+				return err
+			}
+			configs = append(configs, kafka.IncrementalAlterConfigsRequestConfig{
+				Name:            name,
+				Value:           value,
+				ConfigOperation: op.operation,
+			})
+		}
+	}
+
+	for _, name := range remove {
+		configs = append(configs, kafka.IncrementalAlterConfigsRequestConfig{
+			Name:            name,
+			ConfigOperation: kafka.ConfigOperationDelete,
+		})
+	}
+
+	if len(configs) == 0 {
+		fmt.Fprintf(c.stderr, "kafka-go topics alter-config: no configuration changes were given\n")
+		return errUsage
+	}
+
+	req := &kafka.IncrementalAlterConfigsRequest{ValidateOnly: *validateOnly}
+	for _, topic := range flags.Args() {
+		req.Resources = append(req.Resources, kafka.IncrementalAlterConfigsRequestResource{
+			ResourceType: kafka.ResourceTypeTopic,
+			ResourceName: topic,
+			Configs:      configs,
+		})
+	}
+
+	res, err := c.client.IncrementalAlterConfigs(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	results := make([]topicResult, len(res.Resources))
+	errs := make([]error, len(res.Resources))
+	for i, r := range res.Resources {
+		results[i] = topicResult{Topic: r.ResourceName, Error: errorString(r.Error)}
+		errs[i] = r.Error
+	}
+
+	if err := c.print(results); err != nil {
+		return err
+	}
+	return failed(errs...)
+}
+
+// parseReplicaAssignment parses the replicas of a partition in the form
+// partition=broker,broker,...
+func parseReplicaAssignment(s string) (kafka.ReplicaAssignment, error) {
+	partition, replicas, err := parseKeyValue(s)
+	if err != nil {
+		return kafka.ReplicaAssignment{}, err
+	}
+	id, err := strconv.Atoi(partition)
+	if err != nil {
+		return kafka.ReplicaAssignment{}, fmt.Errorf("invalid partition in replica assignment %q", s)
+	}
+	brokers, err := parseInts(replicas)
+	if err != nil {
+		return kafka.ReplicaAssignment{}, err
+	}
+	if len(brokers) == 0 {
+		return kafka.ReplicaAssignment{}, fmt.Errorf("no replicas in replica assignment %q", s)
+	}
+	return kafka.ReplicaAssignment{Partition: id, Replicas: brokers}, nil
+}
+
+func topicID(t kafka.Topic) string {
+	if t.ID.IsZero() {
+		return ""
+	}
+	return t.ID.String()
+}
+
+func brokerIDs(brokers []kafka.Broker) []int {
+	if brokers == nil {
+		return nil
+	}
+	ids := make([]int, len(brokers))
+	for i, b := range brokers {
+		ids[i] = b.ID
+	}
+	return ids
+}
+
+// partitionIDs returns the sorted list of partition IDs of a topic.
+func partitionIDs(t kafka.Topic) []int {
+	ids := make([]int, len(t.Partitions))
+	for i, p := range t.Partitions {
+		ids[i] = p.ID
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// lookupTopic returns the metadata of a topic, or an error if the topic does
+// not exist.
+func lookupTopic(ctx context.Context, c *cli, topic string) (kafka.Topic, error) {
+	meta, err := c.client.Metadata(ctx, &kafka.MetadataRequest{Topics: []string{topic}})
+	if err != nil {
+		return kafka.Topic{}, err
+	}
+	for _, t := range meta.Topics {
+		if t.Name == topic {
+			if t.Error != nil {
+				return t, fmt.Errorf("%s: %w", topic, t.Error)
+			}
+			return t, nil
+		}
+	}
+	return kafka.Topic{}, fmt.Errorf("%s: %w", topic, kafka.UnknownTopicOrPartition)
+}
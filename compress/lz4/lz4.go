@@ -9,12 +9,19 @@ import (
 
 var (
 	readerPool sync.Pool
-	writerPool sync.Pool
 )
 
 // Codec is the implementation of a compress.Codec which supports creating
 // readers and writers for kafka messages compressed with lz4.
-type Codec struct{}
+type Codec struct {
+	// The maximum size of the blocks written by writers created by the codec,
+	// it must be one of 64KB, 256KB, 1MB or 4MB.
+	//
+	// Default to 4MB.
+	BlockSize int
+
+	writerPool sync.Pool // *lz4.Writer
+}
 
 // Code implements the compress.Codec interface.
 func (c *Codec) Code() int8 { return 3 }
@@ -35,13 +42,18 @@ func (c *Codec) NewReader(r io.Reader) io.ReadCloser {
 
 // NewWriter implements the compress.Codec interface.
 func (c *Codec) NewWriter(w io.Writer) io.WriteCloser {
-	z, _ := writerPool.Get().(*lz4.Writer)
+	z, _ := c.writerPool.Get().(*lz4.Writer)
 	if z != nil {
 		z.Reset(w)
 	} else {
 		z = lz4.NewWriter(w)
+		if c.BlockSize != 0 {
+			// The error is retained by the writer, which returns it on the
+			// first call to Write or Close.
+			_ = z.Apply(lz4.BlockSizeOption(lz4.BlockSize(c.BlockSize)))
+		}
 	}
-	return &writer{Writer: z}
+	return &writer{Writer: z, pool: &c.writerPool}
 }
 
 type reader struct{ *lz4.Reader }
@@ -55,14 +67,20 @@ func (r *reader) Close() (err error) {
 	return
 }
 
-type writer struct{ *lz4.Writer }
+type writer struct {
+	*lz4.Writer
+	pool *sync.Pool
+}
 
 func (w *writer) Close() (err error) {
 	if z := w.Writer; z != nil {
 		w.Writer = nil
-		err = z.Close()
-		z.Reset(nil)
-		writerPool.Put(z)
+		// Writers which failed are not reused because they may not have been
+		// configured with the options of the codec.
+		if err = z.Close(); err == nil {
+			z.Reset(nil)
+			w.pool.Put(z)
+		}
 	}
 	return
 }
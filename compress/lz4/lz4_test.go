@@ -0,0 +1,64 @@
+package lz4
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestBlockSize(t *testing.T) {
+	value := bytes.Repeat([]byte("Hello World! "), 100000)
+
+	for _, test := range []struct {
+		blockSize int
+		// The block size index of the frame descriptor, see
+		// https://github.com/lz4/lz4/blob/dev/doc/lz4_Frame_format.md
+		index byte
+	}{
+		{blockSize: 0, index: 7},
+		{blockSize: 64 << 10, index: 4},
+		{blockSize: 256 << 10, index: 5},
+		{blockSize: 1 << 20, index: 6},
+		{blockSize: 4 << 20, index: 7},
+	} {
+		c := &Codec{BlockSize: test.blockSize}
+
+		for i := 0; i < 2; i++ { // the second pass uses a pooled writer
+			buf := new(bytes.Buffer)
+			w := c.NewWriter(buf)
+			if _, err := w.Write(value); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			b := buf.Bytes()
+			if index := (b[5] >> 4) & 7; index != test.index {
+				t.Errorf("block size %d: wrong block size index: %d", test.blockSize, index)
+			}
+
+			r := c.NewReader(bytes.NewReader(b))
+			v, err := ioutil.ReadAll(r)
+			r.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(v, value) {
+				t.Errorf("block size %d: wrong decompressed value", test.blockSize)
+			}
+		}
+	}
+}
+
+func TestInvalidBlockSize(t *testing.T) {
+	c := &Codec{BlockSize: 1000}
+	w := c.NewWriter(new(bytes.Buffer))
+	_, err := w.Write([]byte("Hello World!"))
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		t.Error("expected an error for an invalid block size")
+	}
+}
@@ -2,6 +2,8 @@
 package zstd
 
 import (
+	"encoding/binary"
+	"errors"
 	"io"
 	"sync"
 
@@ -16,6 +18,26 @@ type Codec struct {
 	// Default to 3.
 	Level int
 
+	// The number of goroutines that writers created by the codec use to
+	// compress data concurrently.
+	//
+	// Default to 1.
+	Concurrency int
+
+	// The window size of writers created by the codec, it must be a power of
+	// two between 1KB and 512MB.
+	//
+	// Default to the window size of the compression level.
+	WindowSize int
+
+	// A dictionary in the zstd format (as produced by "zstd --train") that
+	// writers created by the codec compress data with.
+	//
+	// The ID of the dictionary is recorded in the header of the compressed
+	// frames, readers decompress them with the dictionary of the same ID
+	// installed by RegisterDictionary.
+	Dictionary []byte
+
 	encoderPool sync.Pool // *encoder
 }
 
@@ -26,13 +48,21 @@ func (c *Codec) Code() int8 { return 4 }
 func (c *Codec) Name() string { return "zstd" }
 
 // NewReader implements the compress.Codec interface.
+//
+// Readers decompress data with the dictionaries installed by
+// RegisterDictionary, regardless of the dictionary configured on the codec.
 func (c *Codec) NewReader(r io.Reader) io.ReadCloser {
-	p := new(reader)
-	if p.dec, _ = decoderPool.Get().(*zstd.Decoder); p.dec != nil {
+	decoders.mutex.RLock()
+	pool, dicts := decoders.pool, decoders.dicts
+	decoders.mutex.RUnlock()
+
+	p := &reader{pool: pool}
+	if p.dec, _ = pool.Get().(*zstd.Decoder); p.dec != nil {
 		p.dec.Reset(r)
 	} else {
 		z, err := zstd.NewReader(r,
 			zstd.WithDecoderConcurrency(1),
+			zstd.WithDecoderDicts(dicts...),
 		)
 		if err != nil {
 			p.err = err
@@ -54,18 +84,92 @@ func (c *Codec) zstdLevel() zstd.EncoderLevel {
 	return zstd.EncoderLevelFromZstd(c.level())
 }
 
-var decoderPool sync.Pool // *zstd.Decoder
+func (c *Codec) concurrency() int {
+	if c.Concurrency != 0 {
+		return c.Concurrency
+	}
+	return 1
+}
+
+func (c *Codec) encoderOptions() []zstd.EOption {
+	options := []zstd.EOption{
+		zstd.WithEncoderLevel(c.zstdLevel()),
+		zstd.WithEncoderConcurrency(c.concurrency()),
+		zstd.WithZeroFrames(true),
+	}
+	if c.WindowSize != 0 {
+		options = append(options, zstd.WithWindowSize(c.WindowSize))
+	}
+	if c.Dictionary != nil {
+		options = append(options, zstd.WithEncoderDict(c.Dictionary))
+	}
+	return options
+}
+
+// The decoders are created with the set of registered dictionaries, the pool
+// is replaced when a dictionary is registered so decoders which do not know
+// about it are not reused.
+var decoders = struct {
+	mutex sync.RWMutex
+	dicts [][]byte
+	pool  *sync.Pool // *zstd.Decoder
+}{
+	pool: new(sync.Pool),
+}
+
+// RegisterDictionary installs a dictionary in the zstd format that readers use
+// to decompress frames which were compressed with it, the frames reference the
+// dictionary by the ID stored in its header. Registering a dictionary replaces
+// any previously registered dictionary of the same ID.
+//
+// Programs typically register their dictionaries when they start, before
+// reading any messages.
+func RegisterDictionary(dict []byte) error {
+	id, err := DictionaryID(dict)
+	if err != nil {
+		return err
+	}
+
+	decoders.mutex.Lock()
+	defer decoders.mutex.Unlock()
+
+	dicts := make([][]byte, 0, len(decoders.dicts)+1)
+	for _, d := range decoders.dicts {
+		if i, _ := DictionaryID(d); i != id {
+			dicts = append(dicts, d)
+		}
+	}
+
+	decoders.dicts = append(dicts, dict)
+	decoders.pool = new(sync.Pool)
+	return nil
+}
+
+// DictionaryID returns the ID of the dictionary in the zstd format passed as
+// argument, or an error if it is not a valid dictionary.
+func DictionaryID(dict []byte) (uint32, error) {
+	const magic = 0xEC30A437
+	if len(dict) < 8 || binary.LittleEndian.Uint32(dict) != magic {
+		return 0, errors.New("zstd: invalid dictionary: missing magic number")
+	}
+	id := binary.LittleEndian.Uint32(dict[4:])
+	if id == 0 {
+		return 0, errors.New("zstd: invalid dictionary: ID 0 is reserved")
+	}
+	return id, nil
+}
 
 type reader struct {
-	dec *zstd.Decoder
-	err error
+	dec  *zstd.Decoder
+	pool *sync.Pool
+	err  error
 }
 
 // Close implements the io.Closer interface.
 func (r *reader) Close() error {
 	if r.dec != nil {
 		r.dec.Reset(devNull{}) // don't retain the underlying reader
-		decoderPool.Put(r.dec)
+		r.pool.Put(r.dec)
 		r.dec = nil
 		r.err = io.ErrClosedPipe
 	}
@@ -98,11 +202,7 @@ func (r *reader) WriteTo(w io.Writer) (int64, error) {
 func (c *Codec) NewWriter(w io.Writer) io.WriteCloser {
 	p := new(writer)
 	if enc, _ := c.encoderPool.Get().(*zstd.Encoder); enc == nil {
-		z, err := zstd.NewWriter(w,
-			zstd.WithEncoderLevel(c.zstdLevel()),
-			zstd.WithEncoderConcurrency(1),
-			zstd.WithZeroFrames(true),
-		)
+		z, err := zstd.NewWriter(w, c.encoderOptions()...)
 		if err != nil {
 			p.err = err
 		} else {
@@ -0,0 +1,160 @@
+package zstd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func compress(c *Codec, b []byte) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	w := c.NewWriter(buf)
+	if _, err := w.Write(b); err != nil {
+		w.Close()
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompress(c *Codec, b []byte) ([]byte, error) {
+	r := c.NewReader(bytes.NewReader(b))
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+func makeEvent(i int) []byte {
+	// The format matches the samples that testdata/events.dict was trained on.
+	return []byte(fmt.Sprintf(`{"id": %d, "type": "page_view", "user": "alice", "session": "%08x", "path": "/products/%d", "ts": %d, "agent": "Mozilla/5.0 (X11; Linux x86_64)", "valid": true}`,
+		i, uint32(i*2654435761), i%500, 1700000000+i))
+}
+
+func TestDictionary(t *testing.T) {
+	dict, err := ioutil.ReadFile("testdata/events.dict")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := DictionaryID(dict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 42 {
+		t.Fatalf("wrong dictionary ID: %d", id)
+	}
+
+	event := makeEvent(1)
+	compressed, err := compress(&Codec{Level: 7, Dictionary: dict}, event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plain, err := compress(&Codec{Level: 7}, event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(compressed) >= len(plain) {
+		t.Errorf("compressing with the dictionary did not reduce the size: %d >= %d", len(compressed), len(plain))
+	}
+
+	var h zstd.Header
+	if err := h.Decode(compressed); err != nil {
+		t.Fatal(err)
+	}
+	if h.DictionaryID != id {
+		t.Errorf("frame header references the wrong dictionary: %d", h.DictionaryID)
+	}
+
+	if _, err := decompress(new(Codec), compressed); !errors.Is(err, zstd.ErrUnknownDictionary) {
+		t.Fatalf("expected an unknown dictionary error before the dictionary is registered, got %v", err)
+	}
+
+	if err := RegisterDictionary(dict); err != nil {
+		t.Fatal(err)
+	}
+	// Registering the same dictionary again replaces it.
+	if err := RegisterDictionary(dict); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(decoders.dicts); n != 1 {
+		t.Errorf("wrong number of registered dictionaries: %d", n)
+	}
+
+	for i := 0; i < 3; i++ { // the decoders are reused after the first pass
+		b, err := decompress(new(Codec), compressed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b, event) {
+			t.Fatalf("wrong decompressed value: %q", b)
+		}
+	}
+
+	// Frames which were not compressed with a dictionary still decompress.
+	b, err := decompress(new(Codec), plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, event) {
+		t.Fatalf("wrong decompressed value: %q", b)
+	}
+}
+
+func TestDictionaryID(t *testing.T) {
+	for _, test := range []struct {
+		scenario string
+		dict     []byte
+	}{
+		{scenario: "empty", dict: nil},
+		{scenario: "short", dict: []byte{0x37, 0xA4, 0x30, 0xEC}},
+		{scenario: "raw content", dict: []byte(`{"id":1,"type":"click"}`)},
+		{scenario: "zero ID", dict: []byte{0x37, 0xA4, 0x30, 0xEC, 0, 0, 0, 0}},
+	} {
+		t.Run(test.scenario, func(t *testing.T) {
+			if _, err := DictionaryID(test.dict); err == nil {
+				t.Error("expected an error")
+			}
+			if err := RegisterDictionary(test.dict); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestCodecOptions(t *testing.T) {
+	value := bytes.Repeat(makeEvent(2), 1000)
+
+	c := &Codec{Level: 9, Concurrency: 2, WindowSize: 1 << 16}
+	b, err := compress(c, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var h zstd.Header
+	if err := h.Decode(b); err != nil {
+		t.Fatal(err)
+	}
+	if h.WindowSize > 1<<16 {
+		t.Errorf("window size is larger than configured: %d", h.WindowSize)
+	}
+
+	if b, err = decompress(new(Codec), b); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, value) {
+		t.Error("wrong decompressed value")
+	}
+
+	if _, err := compress(&Codec{WindowSize: 1000}, value); err == nil {
+		t.Error("expected an error for an invalid window size")
+	}
+}
+
+var _ io.ReadCloser = (*reader)(nil)
@@ -2,6 +2,7 @@ package kafka
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -12,6 +13,9 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/PerchSecurity/kafka-go/protocol"
+	"github.com/PerchSecurity/kafka-go/sasl"
 )
 
 var (
@@ -55,6 +59,10 @@ type Conn struct {
 	broker        int32
 	rack          string
 
+	// epoch of the partition leader sent in fetch requests, or -1 to disable
+	// the fencing of fetch requests by the broker.
+	leaderEpoch int32
+
 	// correlation ID generator (synchronized on wlock)
 	correlationID int32
 
@@ -65,6 +73,16 @@ type Conn struct {
 	apiVersions atomic.Value // apiVersionMap
 
 	transactionalID *string
+
+	// SASL session of the connection, which must be re-authenticated when its
+	// lifetime reported by the broker expires (KIP-368). Requests hold a read
+	// lock on saslMutex until their response was received, re-authentication
+	// acquires the write lock so it only happens once all in-flight requests
+	// have completed.
+	saslMutex      sync.RWMutex
+	saslMechanism  sasl.Mechanism
+	saslMetadata   *sasl.Metadata
+	saslReauthTime time.Time
 }
 
 type apiVersionMap map[apiKey]ApiVersion
@@ -123,6 +141,15 @@ type ReadBatchConfig struct {
 	// For backward compatibility, when this field is left zero, kafka-go will
 	// infer the max wait from the connection's read deadline.
 	MaxWait time.Duration
+
+	// PooledBuffers enables reading the keys and values of messages returned
+	// by (*Batch).ReadMessage into pooled buffers. Keys and values of messages
+	// in compressed batches alias the decompressed data instead of being
+	// copied out of it.
+	//
+	// The program must call Release on the messages once it is done with
+	// their keys and values for the buffers to be reused.
+	PooledBuffers bool
 }
 
 type IsolationLevel int8
@@ -179,6 +206,7 @@ func NewConnWith(conn net.Conn, config ConnConfig) *Conn {
 		partition:       int32(config.Partition),
 		broker:          int32(config.Broker),
 		rack:            config.Rack,
+		leaderEpoch:     -1,
 		offset:          FirstOffset,
 		requiredAcks:    -1,
 		transactionalID: emptyToNullable(config.TransactionalID),
@@ -209,7 +237,7 @@ func (c *Conn) negotiateVersion(key apiKey, sortedSupportedVersions ...apiVersio
 	}
 	a := v.negotiate(key, sortedSupportedVersions...)
 	if a < 0 {
-		return -1, fmt.Errorf("no matching versions were found between the client and the broker for API key %d", key)
+		return -1, fmt.Errorf("no matching versions were found between the client and the broker for API key %d: %w", key, UnsupportedVersion)
 	}
 	return a, nil
 }
@@ -235,6 +263,15 @@ func (c *Conn) loadVersions() (apiVersionMap, error) {
 	return v, nil
 }
 
+// setLeaderEpoch configures the epoch of the partition leader that the
+// connection sends in fetch requests. Kafka brokers reject the requests with
+// FencedLeaderEpoch or UnknownLeaderEpoch errors if the epoch does not match
+// their own, which is used to detect that the connection is talking to a stale
+// leader (see KIP-320).
+func (c *Conn) setLeaderEpoch(epoch int32) {
+	atomic.StoreInt32(&c.leaderEpoch, epoch)
+}
+
 // Broker returns a Broker value representing the kafka broker that this
 // connection was established to.
 func (c *Conn) Broker() Broker {
@@ -777,6 +814,10 @@ func (c *Conn) ReadBatchWith(cfg ReadBatchConfig) *Batch {
 		return &Batch{err: dontExpectEOF(err)}
 	}
 
+	if err := c.enterSASLSession(); err != nil {
+		return &Batch{err: dontExpectEOF(err)}
+	}
+
 	id, err := c.doRequest(&c.rdeadline, func(deadline time.Time, id int32) error {
 		now := time.Now()
 		var timeout time.Duration
@@ -805,6 +846,7 @@ func (c *Conn) ReadBatchWith(cfg ReadBatchConfig) *Batch {
 				cfg.MaxBytes+int(c.fetchMinSize),
 				timeout,
 				int8(cfg.IsolationLevel),
+				atomic.LoadInt32(&c.leaderEpoch),
 			)
 		case v5:
 			return c.wb.writeFetchRequestV5(
@@ -832,10 +874,15 @@ func (c *Conn) ReadBatchWith(cfg ReadBatchConfig) *Batch {
 		}
 	})
 	if err != nil {
+		c.leaveSASLSession()
 		return &Batch{err: dontExpectEOF(err)}
 	}
 
 	_, size, lock, err := c.waitResponse(&c.rdeadline, id)
+	// The read lock on the SASL session is only held for the exchange, the
+	// batch holds the lock on the read side of the connection which delays
+	// re-authentications until it is closed.
+	c.leaveSASLSession()
 	if err != nil {
 		return &Batch{err: dontExpectEOF(err)}
 	}
@@ -863,6 +910,7 @@ func (c *Conn) ReadBatchWith(cfg ReadBatchConfig) *Batch {
 		} else {
 			msgs, err = newMessageSetReader(&c.rbuf, remain)
 		}
+		msgs.pooled = cfg.PooledBuffers
 	}
 	if errors.Is(err, errShortRead) {
 		err = checkTimeoutErr(adjustedDeadline)
@@ -878,11 +926,14 @@ func (c *Conn) ReadBatchWith(cfg ReadBatchConfig) *Batch {
 		partition:     int(c.partition), // partition is copied to Batch to prevent race with Batch.close
 		offset:        offset,
 		highWaterMark: highWaterMark,
+		leaderEpoch:   -1,
 		// there shouldn't be a short read on initially setting up the batch.
 		// as such, any io.EOF is re-mapped to an io.ErrUnexpectedEOF so that we
 		// don't accidentally signal that we successfully reached the end of the
 		// batch.
-		err: dontExpectEOF(err),
+		err:            dontExpectEOF(err),
+		clientThrottle: protocol.Fetch.ClientSideThrottling(int16(fetchVersion)),
+		pooled:         cfg.PooledBuffers,
 	}
 }
 
@@ -970,7 +1021,7 @@ func (c *Conn) ReadPartitions(topics ...string) (partitions []Partition, err err
 			topics = nil
 		}
 	}
-	metadataVersion, err := c.negotiateVersion(metadata, v1, v6)
+	metadataVersion, err := c.negotiateVersion(metadata, v1, v6, v7)
 	if err != nil {
 		return nil, err
 	}
@@ -978,6 +1029,10 @@ func (c *Conn) ReadPartitions(topics ...string) (partitions []Partition, err err
 	err = c.readOperation(
 		func(deadline time.Time, id int32) error {
 			switch metadataVersion {
+			case v7:
+				// The request format of v7 is the same as v6, only the
+				// response has new fields.
+				return c.writeRequest(metadata, v7, id, topicMetadataRequestV6{Topics: topics, AllowAutoTopicCreation: true})
 			case v6:
 				return c.writeRequest(metadata, v6, id, topicMetadataRequestV6{Topics: topics, AllowAutoTopicCreation: true})
 			default:
@@ -994,6 +1049,13 @@ func (c *Conn) ReadPartitions(topics ...string) (partitions []Partition, err err
 
 func (c *Conn) readPartitionsResponse(metadataVersion apiVersion, size int) ([]Partition, error) {
 	switch metadataVersion {
+	case v7:
+		var res metadataResponseV7
+		if err := c.readResponse(size, &res); err != nil {
+			return nil, err
+		}
+		brokers := readBrokerMetadata(res.Brokers)
+		return c.readTopicMetadatav7(brokers, res.Topics)
 	case v6:
 		var res metadataResponseV6
 		if err := c.readResponse(size, &res); err != nil {
@@ -1040,6 +1102,7 @@ func (c *Conn) readTopicMetadatav1(brokers map[int32]Broker, topicMetadata []top
 				Isr:             makeBrokers(brokers, p.Isr...),
 				ID:              int(p.PartitionID),
 				OfflineReplicas: []Broker{},
+				LeaderEpoch:     -1,
 			})
 		}
 	}
@@ -1062,6 +1125,30 @@ func (c *Conn) readTopicMetadatav6(brokers map[int32]Broker, topicMetadata []top
 				Isr:             makeBrokers(brokers, p.Isr...),
 				ID:              int(p.PartitionID),
 				OfflineReplicas: makeBrokers(brokers, p.OfflineReplicas...),
+				LeaderEpoch:     -1,
+			})
+		}
+	}
+	return
+}
+
+func (c *Conn) readTopicMetadatav7(brokers map[int32]Broker, topicMetadata []topicMetadataV7) (partitions []Partition, err error) {
+	for _, t := range topicMetadata {
+		if t.TopicErrorCode != 0 && (c.topic == "" || t.TopicName == c.topic) {
+			// We only report errors if they happened for the topic of
+			// the connection, otherwise the topic will simply have no
+			// partitions in the result set.
+			return nil, Error(t.TopicErrorCode)
+		}
+		for _, p := range t.Partitions {
+			partitions = append(partitions, Partition{
+				Topic:           t.TopicName,
+				Leader:          brokers[p.Leader],
+				Replicas:        makeBrokers(brokers, p.Replicas...),
+				Isr:             makeBrokers(brokers, p.Isr...),
+				ID:              int(p.PartitionID),
+				OfflineReplicas: makeBrokers(brokers, p.OfflineReplicas...),
+				LeaderEpoch:     int(p.LeaderEpoch),
 			})
 		}
 	}
@@ -1340,6 +1427,16 @@ func (c *Conn) concurrency() int {
 }
 
 func (c *Conn) do(d *connDeadline, write func(time.Time, int32) error, read func(time.Time, int) error) error {
+	if err := c.enterSASLSession(); err != nil {
+		return err
+	}
+	defer c.leaveSASLSession()
+	return c.exchange(d, write, read)
+}
+
+// exchange sends a request and reads its response, it must only be called
+// directly when performing the SASL authentication of the connection.
+func (c *Conn) exchange(d *connDeadline, write func(time.Time, int32) error, read func(time.Time, int) error) error {
 	id, err := c.doRequest(d, write)
 	if err != nil {
 		return err
@@ -1445,6 +1542,11 @@ func (c *Conn) ApiVersions() ([]ApiVersion, error) {
 		deadline = &c.wdeadline
 	}
 
+	if err := c.enterSASLSession(); err != nil {
+		return nil, err
+	}
+	defer c.leaveSASLSession()
+
 	id, err := c.doRequest(deadline, func(_ time.Time, id int32) error {
 		h := requestHeader{
 			ApiKey:        int16(apiVersions),
@@ -1577,7 +1679,7 @@ func (c *Conn) saslHandshake(mechanism string) error {
 		return err
 	}
 
-	err = c.writeOperation(
+	err = c.exchange(&c.wdeadline,
 		func(deadline time.Time, id int32) error {
 			return c.writeRequest(saslHandshake, version, id, &saslHandshakeRequestV0{Mechanism: mechanism})
 		},
@@ -1596,50 +1698,152 @@ func (c *Conn) saslHandshake(mechanism string) error {
 // saslAuthenticate sends the SASL authenticate message.  This function must
 // be immediately preceded by a successful saslHandshake.
 //
+// The session lifetime is only reported by brokers supporting v1 of the API,
+// it is zero otherwise.
+//
 // See http://kafka.apache.org/protocol.html#The_Messages_SaslAuthenticate
-func (c *Conn) saslAuthenticate(data []byte) ([]byte, error) {
+func (c *Conn) saslAuthenticate(data []byte) ([]byte, time.Duration, error) {
 	// if we sent a v1 handshake, then we must encapsulate the authentication
 	// request in a saslAuthenticateRequest.  otherwise, we read and write raw
 	// bytes.
 	version, err := c.negotiateVersion(saslHandshake, v0, v1)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	if version == v1 {
+		authVersion, err := c.negotiateVersion(saslAuthenticate, v0, v1)
+		if err != nil {
+			return nil, 0, err
+		}
+
 		var request = saslAuthenticateRequestV0{Data: data}
-		var response saslAuthenticateResponseV0
+		var response saslAuthenticateResponseV1
 
-		err := c.writeOperation(
+		err = c.exchange(&c.wdeadline,
 			func(deadline time.Time, id int32) error {
-				return c.writeRequest(saslAuthenticate, v0, id, request)
+				return c.writeRequest(saslAuthenticate, authVersion, id, request)
 			},
 			func(deadline time.Time, size int) error {
-				return expectZeroSize(func() (remain int, err error) {
-					return (&response).readFrom(&c.rbuf, size)
-				}())
+				if authVersion == v0 {
+					return expectZeroSize((&response.saslAuthenticateResponseV0).readFrom(&c.rbuf, size))
+				}
+				return expectZeroSize((&response).readFrom(&c.rbuf, size))
 			},
 		)
 		if err == nil && response.ErrorCode != 0 {
 			err = Error(response.ErrorCode)
 		}
-		return response.Data, err
+		return response.Data, time.Duration(response.SessionLifetimeMs) * time.Millisecond, err
 	}
 
 	// fall back to opaque bytes on the wire.  the broker is expecting these if
 	// it just processed a v0 sasl handshake.
 	c.wb.writeInt32(int32(len(data)))
 	if _, err := c.wb.Write(data); err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	if err := c.wb.Flush(); err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	var respLen int32
 	if _, err := readInt32(&c.rbuf, 4, &respLen); err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	resp, _, err := readNewBytes(&c.rbuf, int(respLen), int(respLen))
-	return resp, err
+	return resp, 0, err
+}
+
+// authenticateSASL performs all of the required requests to authenticate the
+// connection with the given mechanism.  If any step fails, this function
+// returns with an error.  A nil error indicates successful authentication.
+//
+// When the broker reports a lifetime for the session, the connection records
+// the mechanism and the SASL metadata of ctx so it can re-authenticate before
+// the session expires.
+func (c *Conn) authenticateSASL(ctx context.Context, mechanism sasl.Mechanism) error {
+	if err := c.saslHandshake(mechanism.Name()); err != nil {
+		return fmt.Errorf("SASL handshake failed: %w", err)
+	}
+
+	sess, state, err := mechanism.Start(ctx)
+	if err != nil {
+		return fmt.Errorf("SASL authentication process could not be started: %w", err)
+	}
+
+	var sessionLifetime time.Duration
+
+	for completed := false; !completed; {
+		challenge, lifetime, err := c.saslAuthenticate(state)
+		switch {
+		case err == nil:
+		case errors.Is(err, io.EOF):
+			// the broker may communicate a failed exchange by closing the
+			// connection (esp. in the case where we're passing opaque sasl
+			// data over the wire since there's no protocol info).
+			return SASLAuthenticationFailed
+		default:
+			return err
+		}
+
+		sessionLifetime = lifetime
+		completed, state, err = sess.Next(ctx, challenge)
+		if err != nil {
+			return fmt.Errorf("SASL authentication process has failed: %w", err)
+		}
+	}
+
+	c.saslMechanism = mechanism
+	c.saslMetadata = sasl.MetadataFromContext(ctx)
+	c.saslReauthTime = reauthenticationTime(time.Now(), sessionLifetime)
+	return nil
+}
+
+// enterSASLSession must be called before sending a request on the connection,
+// it re-authenticates the connection first if its SASL session is about to
+// expire. The program must call leaveSASLSession once the response has been
+// received.
+func (c *Conn) enterSASLSession() error {
+	for {
+		c.saslMutex.RLock()
+		reauthTime := c.saslReauthTime
+
+		if reauthTime.IsZero() || time.Now().Before(reauthTime) {
+			return nil
+		}
+
+		c.saslMutex.RUnlock()
+
+		if err := c.reauthenticateSASL(); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Conn) leaveSASLSession() {
+	c.saslMutex.RUnlock()
+}
+
+func (c *Conn) reauthenticateSASL() error {
+	// Acquiring the write lock waits for in-flight requests to complete, and
+	// prevents new requests from being sent until the exchange is done.
+	c.saslMutex.Lock()
+	defer c.saslMutex.Unlock()
+
+	if c.saslReauthTime.IsZero() || time.Now().Before(c.saslReauthTime) {
+		// Another goroutine re-authenticated the connection already.
+		return nil
+	}
+
+	ctx := sasl.WithMetadata(context.Background(), c.saslMetadata)
+
+	if err := c.authenticateSASL(ctx, c.saslMechanism); err != nil {
+		// The connection can not be used anymore after failing to
+		// re-authenticate, the broker would close it on the next request.
+		c.conn.Close()
+		return fmt.Errorf("re-authenticating SASL session with kafka broker at %s: %w", c.RemoteAddr(), err)
+	}
+
+	return nil
 }
@@ -12,6 +12,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/PerchSecurity/kafka-go/kafkatest"
+	"github.com/PerchSecurity/kafka-go/protocol"
+	"github.com/PerchSecurity/kafka-go/sasl"
+	"github.com/PerchSecurity/kafka-go/sasl/plain"
 	ktesting "github.com/PerchSecurity/kafka-go/testing"
 )
 
@@ -1036,6 +1040,103 @@ func TestUnsupportedSASLMechanism(t *testing.T) {
 	}
 }
 
+func TestConnReauthenticateSASL(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, server := net.Pipe()
+	served := make(chan protocol.ApiKey, 10)
+	go serveSASLBroker(server, served, time.Hour)
+
+	conn := NewConnWith(client, ConnConfig{ClientID: "test"})
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	metadata := &sasl.Metadata{Host: "localhost", Port: 9092}
+	if err := conn.authenticateSASL(sasl.WithMetadata(ctx, metadata), plain.Mechanism{Username: "user", Password: "pass"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if lifetime := time.Until(conn.saslReauthTime); lifetime < 50*time.Minute || lifetime > 58*time.Minute {
+		t.Errorf("re-authentication scheduled outside of the session lifetime bounds: %s", lifetime)
+	}
+
+	// simulate the expiration of the session, the next request must be
+	// preceded by a new SASL exchange
+	conn.saslReauthTime = time.Now().Add(-time.Second)
+
+	if _, err := conn.ApiVersions(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.ApiVersions(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []protocol.ApiKey{
+		protocol.ApiVersions,
+		protocol.SaslHandshake,
+		protocol.SaslAuthenticate,
+		protocol.SaslHandshake,
+		protocol.SaslAuthenticate,
+		protocol.ApiVersions,
+		protocol.ApiVersions,
+	}
+	for _, apiKey := range expected {
+		if served := <-served; served != apiKey {
+			t.Fatalf("expected the broker to receive a %s request, got %s", apiKey, served)
+		}
+	}
+}
+
+func TestConnReadBatchReleasesSASLSession(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cluster, err := kafkatest.NewCluster(kafkatest.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cluster.Close()
+
+	const topic = "sasl-session"
+	if err := cluster.CreateTopic(topic, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := DefaultDialer.DialLeader(ctx, "tcp", cluster.Addr().String(), topic, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	if _, err := conn.WriteMessages(makeTestSequence(3)...); err != nil {
+		t.Fatal(err)
+	}
+
+	batch := conn.ReadBatch(1, 1e6)
+	defer batch.Close()
+
+	// Re-authentications must not wait for open batches to be closed, the
+	// programs reading them may be blocked on the re-authentication.
+	locked := make(chan struct{})
+	go func() {
+		conn.saslMutex.Lock()
+		conn.saslMutex.Unlock()
+		close(locked)
+	}()
+
+	select {
+	case <-locked:
+	case <-ctx.Done():
+		t.Fatal("the batch holds the SASL session of the connection")
+	}
+
+	if _, err := batch.ReadMessage(); err != nil {
+		t.Fatal(err)
+	}
+}
+
 const benchmarkMessageCount = 100
 
 func BenchmarkConn(b *testing.B) {
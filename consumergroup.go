@@ -149,6 +149,15 @@ type ConsumerGroupConfig struct {
 	// back to using Logger instead.
 	ErrorLogger Logger
 
+	// If not nil, specifies a logger used to report events with attributes
+	// (group, generation, member ID, etc...). When set, Logger and ErrorLogger
+	// are not used.
+	StructuredLogger StructuredLogger
+
+	// If not nil, specifies a recorder used to report metrics about the
+	// generations of the consumer group, labeled by group ID.
+	Metrics MetricsRecorder
+
 	// Timeout is the network timeout used when communicating with the consumer
 	// group coordinator.  This value should not be too small since errors
 	// communicating with the broker will generally cause a consumer group
@@ -335,8 +344,7 @@ type Generation struct {
 	joined   chan struct{}
 
 	retentionMillis int64
-	log             func(func(Logger))
-	logError        func(func(Logger))
+	log             logger
 }
 
 // close stops the generation and waits for all functions launched via Start to
@@ -443,7 +451,7 @@ func (g *Generation) CommitOffsets(offsets map[string]map[int]int64) error {
 	_, err := g.conn.offsetCommit(request)
 	if err == nil {
 		// if logging is enabled, print out the partitions that were committed.
-		g.log(func(l Logger) {
+		if g.log.enabled() {
 			var report []string
 			for _, t := range request.Topics {
 				report = append(report, fmt.Sprintf("\ttopic: %s", t.Topic))
@@ -451,8 +459,8 @@ func (g *Generation) CommitOffsets(offsets map[string]map[int]int64) error {
 					report = append(report, fmt.Sprintf("\t\tpartition %d: %d", p.Partition, p.Offset))
 				}
 			}
-			l.Printf("committed offsets for group %s: \n%s", g.GroupID, strings.Join(report, "\n"))
-		})
+			g.log.debugf("committed offsets for group %s: \n%s", g.GroupID, strings.Join(report, "\n"))
+		}
 	}
 
 	return err
@@ -463,12 +471,8 @@ func (g *Generation) CommitOffsets(offsets map[string]map[int]int64) error {
 // end of the generation.
 func (g *Generation) heartbeatLoop(interval time.Duration) {
 	g.Start(func(ctx context.Context) {
-		g.log(func(l Logger) {
-			l.Printf("started heartbeat for group, %v [%v]", g.GroupID, interval)
-		})
-		defer g.log(func(l Logger) {
-			l.Printf("stopped heartbeat for group %s\n", g.GroupID)
-		})
+		g.log.debugf("started heartbeat for group, %v [%v]", g.GroupID, interval)
+		defer g.log.debugf("stopped heartbeat for group %s\n", g.GroupID)
 
 		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
@@ -499,21 +503,16 @@ func (g *Generation) heartbeatLoop(interval time.Duration) {
 // establish a new connection to the coordinator.
 func (g *Generation) partitionWatcher(interval time.Duration, topic string) {
 	g.Start(func(ctx context.Context) {
-		g.log(func(l Logger) {
-			l.Printf("started partition watcher for group, %v, topic %v [%v]", g.GroupID, topic, interval)
-		})
-		defer g.log(func(l Logger) {
-			l.Printf("stopped partition watcher for group, %v, topic %v", g.GroupID, topic)
-		})
+		log := g.log.with(topicAttr(topic))
+		log.debugf("started partition watcher for group, %v, topic %v [%v]", g.GroupID, topic, interval)
+		defer log.debugf("stopped partition watcher for group, %v, topic %v", g.GroupID, topic)
 
 		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
 
 		ops, err := g.conn.readPartitions(topic)
 		if err != nil {
-			g.logError(func(l Logger) {
-				l.Printf("Problem getting partitions during startup, %v\n, Returning and setting up nextGeneration", err)
-			})
+			log.withError(err).warnf("Problem getting partitions during startup, %v\n, Returning and setting up nextGeneration", err)
 			return
 		}
 		oParts := len(ops)
@@ -526,16 +525,12 @@ func (g *Generation) partitionWatcher(interval time.Duration, topic string) {
 				switch {
 				case err == nil, errors.Is(err, UnknownTopicOrPartition):
 					if len(ops) != oParts {
-						g.log(func(l Logger) {
-							l.Printf("Partition changes found, rebalancing group: %v.", g.GroupID)
-						})
+						log.infof("Partition changes found, rebalancing group: %v.", g.GroupID)
 						return
 					}
 
 				default:
-					g.logError(func(l Logger) {
-						l.Printf("Problem getting partitions while checking for changes, %v", err)
-					})
+					log.withError(err).warnf("Problem getting partitions while checking for changes, %v", err)
 					var kafkaError Error
 					if errors.As(err, &kafkaError) {
 						continue
@@ -780,11 +775,15 @@ func (cg *ConsumerGroup) nextGeneration(memberID string) (string, error) {
 	// re-connect in certain cases, but that shouldn't be an issue given that
 	// rebalances are relatively infrequent under normal operating
 	// conditions.
+	start := time.Now()
+	metrics := metrics{recorder: cg.config.Metrics}
+
+	log := cg.log().with(memberIDAttr(memberID))
+
 	conn, err := cg.coordinator()
 	if err != nil {
-		cg.withErrorLogger(func(log Logger) {
-			log.Printf("Unable to establish connection to consumer group coordinator for group %s: %v", cg.config.ID, err)
-		})
+		log.withError(err).errorf("Unable to establish connection to consumer group coordinator for group %s: %v", cg.config.ID, err)
+		metrics.add(consumerGroupErrors, 1, cg.config.ID)
 		return memberID, err // a prior memberID may still be valid, so don't return ""
 	}
 	defer conn.Close()
@@ -797,21 +796,18 @@ func (cg *ConsumerGroup) nextGeneration(memberID string) (string, error) {
 	// consumer is elected leader.  it may also change or assign the member ID.
 	memberID, generationID, groupAssignments, err = cg.joinGroup(conn, memberID)
 	if err != nil {
-		cg.withErrorLogger(func(log Logger) {
-			log.Printf("Failed to join group %s: %v", cg.config.ID, err)
-		})
+		log.withError(err).errorf("Failed to join group %s: %v", cg.config.ID, err)
+		metrics.add(consumerGroupErrors, 1, cg.config.ID)
 		return memberID, err
 	}
-	cg.withLogger(func(log Logger) {
-		log.Printf("Joined group %s as member %s in generation %d", cg.config.ID, memberID, generationID)
-	})
+	log = cg.log().with(memberIDAttr(memberID), generationAttr(generationID))
+	log.infof("Joined group %s as member %s in generation %d", cg.config.ID, memberID, generationID)
 
 	// sync group
 	assignments, err = cg.syncGroup(conn, memberID, generationID, groupAssignments)
 	if err != nil {
-		cg.withErrorLogger(func(log Logger) {
-			log.Printf("Failed to sync group %s: %v", cg.config.ID, err)
-		})
+		log.withError(err).errorf("Failed to sync group %s: %v", cg.config.ID, err)
+		metrics.add(consumerGroupErrors, 1, cg.config.ID)
 		return memberID, err
 	}
 
@@ -819,12 +815,14 @@ func (cg *ConsumerGroup) nextGeneration(memberID string) (string, error) {
 	var offsets map[string]map[int]int64
 	offsets, err = cg.fetchOffsets(conn, assignments)
 	if err != nil {
-		cg.withErrorLogger(func(log Logger) {
-			log.Printf("Failed to fetch offsets for group %s: %v", cg.config.ID, err)
-		})
+		log.withError(err).errorf("Failed to fetch offsets for group %s: %v", cg.config.ID, err)
+		metrics.add(consumerGroupErrors, 1, cg.config.ID)
 		return memberID, err
 	}
 
+	metrics.add(consumerGroupGenerations, 1, cg.config.ID)
+	metrics.recordDuration(consumerGroupRebalanceDuration, time.Since(start), cg.config.ID)
+
 	// create the generation.
 	gen := Generation{
 		ID:              generationID,
@@ -835,8 +833,7 @@ func (cg *ConsumerGroup) nextGeneration(memberID string) (string, error) {
 		done:            make(chan struct{}),
 		joined:          make(chan struct{}),
 		retentionMillis: int64(cg.config.RetentionTime / time.Millisecond),
-		log:             cg.withLogger,
-		logError:        cg.withErrorLogger,
+		log:             log,
 	}
 
 	// spawn all of the go routines required to facilitate this generation.  if
@@ -948,9 +945,8 @@ func (cg *ConsumerGroup) joinGroup(conn coordinator, memberID string) (string, i
 	memberID = response.MemberID
 	generationID := response.GenerationID
 
-	cg.withLogger(func(l Logger) {
-		l.Printf("joined group %s as member %s in generation %d", cg.config.ID, memberID, generationID)
-	})
+	log := cg.log().with(memberIDAttr(memberID), generationAttr(generationID))
+	log.debugf("joined group %s as member %s in generation %d", cg.config.ID, memberID, generationID)
 
 	var assignments GroupMemberAssignments
 	if iAmLeader := response.MemberID == response.LeaderID; iAmLeader {
@@ -960,18 +956,16 @@ func (cg *ConsumerGroup) joinGroup(conn coordinator, memberID string) (string, i
 		}
 		assignments = v
 
-		cg.withLogger(func(l Logger) {
+		if log.enabled() {
 			for memberID, assignment := range assignments {
 				for topic, partitions := range assignment {
-					l.Printf("assigned member/topic/partitions %v/%v/%v", memberID, topic, partitions)
+					cg.log().with(memberIDAttr(memberID), generationAttr(generationID), topicAttr(topic)).debugf("assigned member/topic/partitions %v/%v/%v", memberID, topic, partitions)
 				}
 			}
-		})
+		}
 	}
 
-	cg.withLogger(func(l Logger) {
-		l.Printf("joinGroup succeeded for response, %v.  generationID=%v, memberID=%v", cg.config.ID, response.GenerationID, response.MemberID)
-	})
+	log.debugf("joinGroup succeeded for response, %v.  generationID=%v, memberID=%v", cg.config.ID, response.GenerationID, response.MemberID)
 
 	return memberID, generationID, assignments, nil
 }
@@ -1008,9 +1002,8 @@ func (cg *ConsumerGroup) makeJoinGroupRequestV1(memberID string) (joinGroupReque
 // assignTopicPartitions uses the selected GroupBalancer to assign members to
 // their various partitions.
 func (cg *ConsumerGroup) assignTopicPartitions(conn coordinator, group joinGroupResponseV1) (GroupMemberAssignments, error) {
-	cg.withLogger(func(l Logger) {
-		l.Printf("selected as leader for group, %s\n", cg.config.ID)
-	})
+	log := cg.log().with(memberIDAttr(group.MemberID), generationAttr(group.GenerationID))
+	log.infof("selected as leader for group, %s\n", cg.config.ID)
 
 	balancer, ok := findGroupBalancer(group.GroupProtocol, cg.config.GroupBalancers)
 	if !ok {
@@ -1036,15 +1029,15 @@ func (cg *ConsumerGroup) assignTopicPartitions(conn coordinator, group joinGroup
 		return nil, err
 	}
 
-	cg.withLogger(func(l Logger) {
-		l.Printf("using '%v' balancer to assign group, %v", group.GroupProtocol, cg.config.ID)
+	if log.enabled() {
+		log.debugf("using '%v' balancer to assign group, %v", group.GroupProtocol, cg.config.ID)
 		for _, member := range members {
-			l.Printf("found member: %v/%#v", member.ID, member.UserData)
+			log.debugf("found member: %v/%#v", member.ID, member.UserData)
 		}
 		for _, partition := range partitions {
-			l.Printf("found topic/partition: %v/%v", partition.Topic, partition.ID)
+			log.with(topicAttr(partition.Topic), partitionAttr(partition.ID)).debugf("found topic/partition: %v/%v", partition.Topic, partition.ID)
 		}
-	})
+	}
 
 	return balancer.AssignGroups(members, partitions), nil
 }
@@ -1094,15 +1087,13 @@ func (cg *ConsumerGroup) syncGroup(conn coordinator, memberID string, generation
 		return nil, err
 	}
 
+	log := cg.log().with(memberIDAttr(memberID), generationAttr(generationID))
+
 	if len(assignments.Topics) == 0 {
-		cg.withLogger(func(l Logger) {
-			l.Printf("received empty assignments for group, %v as member %s for generation %d", cg.config.ID, memberID, generationID)
-		})
+		log.infof("received empty assignments for group, %v as member %s for generation %d", cg.config.ID, memberID, generationID)
 	}
 
-	cg.withLogger(func(l Logger) {
-		l.Printf("sync group finished for group, %v", cg.config.ID)
-	})
+	log.debugf("sync group finished for group, %v", cg.config.ID)
 
 	return assignments.Topics, nil
 }
@@ -1135,9 +1126,7 @@ func (cg *ConsumerGroup) makeSyncGroupRequestV0(memberID string, generationID in
 			})
 		}
 
-		cg.withLogger(func(logger Logger) {
-			logger.Printf("Syncing %d assignments for generation %d as member %s", len(request.GroupAssignments), generationID, memberID)
-		})
+		cg.log().with(memberIDAttr(memberID), generationAttr(generationID)).debugf("Syncing %d assignments for generation %d as member %s", len(request.GroupAssignments), generationID, memberID)
 	}
 
 	return request
@@ -1208,9 +1197,8 @@ func (cg *ConsumerGroup) leaveGroup(memberID string) error {
 		return nil
 	}
 
-	cg.withLogger(func(log Logger) {
-		log.Printf("Leaving group %s, member %s", cg.config.ID, memberID)
-	})
+	log := cg.log().with(memberIDAttr(memberID))
+	log.infof("Leaving group %s, member %s", cg.config.ID, memberID)
 
 	// IMPORTANT : leaveGroup establishes its own connection to the coordinator
 	//             because it is often called after some other operation failed.
@@ -1227,9 +1215,7 @@ func (cg *ConsumerGroup) leaveGroup(memberID string) error {
 		MemberID: memberID,
 	})
 	if err != nil {
-		cg.withErrorLogger(func(log Logger) {
-			log.Printf("leave group failed for group, %v, and member, %v: %v", cg.config.ID, memberID, err)
-		})
+		log.withError(err).warnf("leave group failed for group, %v, and member, %v: %v", cg.config.ID, memberID, err)
 	}
 
 	_ = coordinator.Close()
@@ -1237,16 +1223,10 @@ func (cg *ConsumerGroup) leaveGroup(memberID string) error {
 	return err
 }
 
-func (cg *ConsumerGroup) withLogger(do func(Logger)) {
-	if cg.config.Logger != nil {
-		do(cg.config.Logger)
-	}
-}
-
-func (cg *ConsumerGroup) withErrorLogger(do func(Logger)) {
-	if cg.config.ErrorLogger != nil {
-		do(cg.config.ErrorLogger)
-	} else {
-		cg.withLogger(do)
-	}
+func (cg *ConsumerGroup) log() logger {
+	return logger{
+		structured:  cg.config.StructuredLogger,
+		logger:      cg.config.Logger,
+		errorLogger: cg.config.ErrorLogger,
+	}.with(groupAttr(cg.config.ID))
 }
@@ -628,11 +628,9 @@ func TestGenerationExitsOnPartitionChange(t *testing.T) {
 	watchTime := 500 * time.Millisecond
 
 	gen := Generation{
-		conn:     conn,
-		done:     make(chan struct{}),
-		joined:   make(chan struct{}),
-		log:      func(func(Logger)) {},
-		logError: func(func(Logger)) {},
+		conn:   conn,
+		done:   make(chan struct{}),
+		joined: make(chan struct{}),
 	}
 
 	done := make(chan struct{})
@@ -653,11 +651,9 @@ func TestGenerationExitsOnPartitionChange(t *testing.T) {
 
 func TestGenerationStartsFunctionAfterClosed(t *testing.T) {
 	gen := Generation{
-		conn:     &mockCoordinator{},
-		done:     make(chan struct{}),
-		joined:   make(chan struct{}),
-		log:      func(func(Logger)) {},
-		logError: func(func(Logger)) {},
+		conn:   &mockCoordinator{},
+		done:   make(chan struct{}),
+		joined: make(chan struct{}),
 	}
 
 	gen.close()
@@ -0,0 +1,159 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/PerchSecurity/kafka-go/protocol/createdelegationtoken"
+)
+
+// DelegationTokenPrincipal identifies a kafka principal that owns, requested
+// or is allowed to renew a delegation token.
+type DelegationTokenPrincipal struct {
+	// Type of the principal, which is "User" for most security setups.
+	Type string
+
+	// Name of the principal.
+	Name string
+}
+
+// DelegationToken represents a delegation token issued by a kafka cluster.
+//
+// Programs use delegation tokens to authenticate with SCRAM, passing the token
+// ID as username and the HMAC as password (see scram.TokenMechanism).
+type DelegationToken struct {
+	// Unique identifier of the token.
+	TokenID string
+
+	// HMAC of the token, which is the secret used to authenticate with it.
+	HMAC []byte
+
+	// Principal that the token was issued for.
+	Owner DelegationTokenPrincipal
+
+	// Principal that requested the creation of the token. This field requires
+	// kafka 3.3 or above, it is set to Owner when the broker does not report
+	// it.
+	Requester DelegationTokenPrincipal
+
+	// Time at which the token was issued.
+	IssueTime time.Time
+
+	// Time at which the token expires, unless it is renewed.
+	ExpiryTime time.Time
+
+	// Time beyond which the token cannot be renewed anymore.
+	MaxTime time.Time
+
+	// List of principals allowed to renew the token. The owner can always
+	// renew its own tokens.
+	Renewers []DelegationTokenPrincipal
+}
+
+// CreateDelegationTokenRequest represents a request sent to a kafka broker to
+// create a delegation token.
+type CreateDelegationTokenRequest struct {
+	// Address of the kafka broker to send the request to.
+	Addr net.Addr
+
+	// Principal to create the token for. When nil, the token is created for
+	// the principal that the client authenticated as.
+	//
+	// This field requires the kafka broker to support the CreateDelegationToken
+	// API in version 3 or above (otherwise the value is ignored).
+	Owner *DelegationTokenPrincipal
+
+	// List of principals allowed to renew the token.
+	Renewers []DelegationTokenPrincipal
+
+	// Maximum lifetime of the token. When zero, the broker uses the value of
+	// its delegation.token.max.lifetime.ms setting.
+	MaxLifetime time.Duration
+}
+
+// CreateDelegationTokenResponse represents a response from a kafka broker to a
+// create delegation token request.
+type CreateDelegationTokenResponse struct {
+	// The amount of time that the broker throttled the request.
+	Throttle time.Duration
+
+	// An error that may have occurred while attempting to create the token.
+	//
+	// The error contains the kafka error code. Programs may use the standard
+	// errors.Is function to test the error against kafka error codes.
+	Error error
+
+	// The token created by the kafka broker.
+	Token DelegationToken
+}
+
+// CreateDelegationToken sends a create delegation token request to a kafka
+// broker and returns the response.
+//
+// Delegation tokens are only issued to clients that authenticated with a
+// mechanism other than a delegation token, over a SASL or TLS connection.
+func (c *Client) CreateDelegationToken(ctx context.Context, req *CreateDelegationTokenRequest) (*CreateDelegationTokenResponse, error) {
+	renewers := make([]createdelegationtoken.RequestRenewer, len(req.Renewers))
+
+	for i, r := range req.Renewers {
+		renewers[i] = createdelegationtoken.RequestRenewer{
+			PrincipalType: r.Type,
+			PrincipalName: r.Name,
+		}
+	}
+
+	maxLifetimeMs := int64(-1)
+	if req.MaxLifetime > 0 {
+		maxLifetimeMs = int64(req.MaxLifetime / time.Millisecond)
+	}
+
+	r := &createdelegationtoken.Request{
+		Renewers:      renewers,
+		MaxLifetimeMs: maxLifetimeMs,
+	}
+
+	if req.Owner != nil {
+		r.OwnerPrincipalType = req.Owner.Type
+		r.OwnerPrincipalName = req.Owner.Name
+	}
+
+	m, err := c.roundTrip(ctx, req.Addr, r)
+	if err != nil {
+		return nil, fmt.Errorf("kafka.(*Client).CreateDelegationToken: %w", err)
+	}
+
+	res := m.(*createdelegationtoken.Response)
+	ret := &CreateDelegationTokenResponse{
+		Throttle: makeDuration(res.ThrottleTimeMs),
+		Error:    makeError(res.ErrorCode, ""),
+		Token: DelegationToken{
+			TokenID: res.TokenID,
+			HMAC:    res.HMAC,
+			Owner: DelegationTokenPrincipal{
+				Type: res.PrincipalType,
+				Name: res.PrincipalName,
+			},
+			Requester: makeDelegationTokenRequester(
+				res.PrincipalType,
+				res.PrincipalName,
+				res.TokenRequesterPrincipalType,
+				res.TokenRequesterPrincipalName,
+			),
+			IssueTime:  makeTime(res.IssueTimestampMs),
+			ExpiryTime: makeTime(res.ExpiryTimestampMs),
+			MaxTime:    makeTime(res.MaxTimestampMs),
+			Renewers:   req.Renewers,
+		},
+	}
+
+	return ret, nil
+}
+
+func makeDelegationTokenRequester(ownerType, ownerName, requesterType, requesterName string) DelegationTokenPrincipal {
+	if requesterType == "" && requesterName == "" {
+		return DelegationTokenPrincipal{Type: ownerType, Name: ownerName}
+	}
+	return DelegationTokenPrincipal{Type: requesterType, Name: requesterName}
+}
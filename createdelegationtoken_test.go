@@ -0,0 +1,155 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/PerchSecurity/kafka-go/protocol"
+	"github.com/PerchSecurity/kafka-go/protocol/createdelegationtoken"
+	"github.com/PerchSecurity/kafka-go/protocol/describedelegationtoken"
+	"github.com/PerchSecurity/kafka-go/protocol/expiredelegationtoken"
+	"github.com/PerchSecurity/kafka-go/protocol/renewdelegationtoken"
+)
+
+func TestClientCreateDelegationToken(t *testing.T) {
+	var sent *createdelegationtoken.Request
+
+	client := &Client{
+		Addr: TCP("localhost:9092"),
+		Transport: roundTripFunc(func(ctx context.Context, addr net.Addr, msg protocol.Message) (protocol.Message, error) {
+			sent = msg.(*createdelegationtoken.Request)
+			return &createdelegationtoken.Response{
+				PrincipalType:     "User",
+				PrincipalName:     "owner",
+				IssueTimestampMs:  1000,
+				ExpiryTimestampMs: 2000,
+				MaxTimestampMs:    3000,
+				TokenID:           "token",
+				HMAC:              []byte("hmac"),
+				ThrottleTimeMs:    10,
+			}, nil
+		}),
+	}
+
+	renewers := []DelegationTokenPrincipal{{Type: "User", Name: "renewer"}}
+	res, err := client.CreateDelegationToken(context.Background(), &CreateDelegationTokenRequest{
+		Owner:       &DelegationTokenPrincipal{Type: "User", Name: "owner"},
+		Renewers:    renewers,
+		MaxLifetime: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &createdelegationtoken.Request{
+		OwnerPrincipalType: "User",
+		OwnerPrincipalName: "owner",
+		Renewers:           []createdelegationtoken.RequestRenewer{{PrincipalType: "User", PrincipalName: "renewer"}},
+		MaxLifetimeMs:      3600000,
+	}
+	if !reflect.DeepEqual(sent, want) {
+		t.Errorf("request mismatch:\n got: %+v\nwant: %+v", sent, want)
+	}
+
+	owner := DelegationTokenPrincipal{Type: "User", Name: "owner"}
+	token := DelegationToken{
+		TokenID:    "token",
+		HMAC:       []byte("hmac"),
+		Owner:      owner,
+		Requester:  owner,
+		IssueTime:  makeTime(1000),
+		ExpiryTime: makeTime(2000),
+		MaxTime:    makeTime(3000),
+		Renewers:   renewers,
+	}
+	if res.Error != nil || res.Throttle != 10*time.Millisecond || !reflect.DeepEqual(res.Token, token) {
+		t.Errorf("unexpected response: %+v", res)
+	}
+}
+
+func TestClientDelegationTokenErrors(t *testing.T) {
+	tests := []struct {
+		scenario string
+		response protocol.Message
+		call     func(*Client) (resErr, err error)
+	}{
+		{
+			scenario: "create",
+			response: &createdelegationtoken.Response{ErrorCode: int16(DelegationTokenAuthDisabled)},
+			call: func(c *Client) (resErr, err error) {
+				res, err := c.CreateDelegationToken(context.Background(), &CreateDelegationTokenRequest{})
+				if err != nil {
+					return nil, err
+				}
+				return res.Error, nil
+			},
+		},
+		{
+			scenario: "describe",
+			response: &describedelegationtoken.Response{ErrorCode: int16(DelegationTokenAuthDisabled)},
+			call: func(c *Client) (resErr, err error) {
+				res, err := c.DescribeDelegationToken(context.Background(), &DescribeDelegationTokenRequest{})
+				if err != nil {
+					return nil, err
+				}
+				return res.Error, nil
+			},
+		},
+		{
+			scenario: "renew",
+			response: &renewdelegationtoken.Response{ErrorCode: int16(DelegationTokenAuthDisabled)},
+			call: func(c *Client) (resErr, err error) {
+				res, err := c.RenewDelegationToken(context.Background(), &RenewDelegationTokenRequest{})
+				if err != nil {
+					return nil, err
+				}
+				return res.Error, nil
+			},
+		},
+		{
+			scenario: "expire",
+			response: &expiredelegationtoken.Response{ErrorCode: int16(DelegationTokenAuthDisabled)},
+			call: func(c *Client) (resErr, err error) {
+				res, err := c.ExpireDelegationToken(context.Background(), &ExpireDelegationTokenRequest{})
+				if err != nil {
+					return nil, err
+				}
+				return res.Error, nil
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.scenario, func(t *testing.T) {
+			client := &Client{
+				Addr: TCP("localhost:9092"),
+				Transport: roundTripFunc(func(context.Context, net.Addr, protocol.Message) (protocol.Message, error) {
+					return test.response, nil
+				}),
+			}
+
+			// kafka errors are reported in the response
+			resErr, err := test.call(client)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !errors.Is(resErr, DelegationTokenAuthDisabled) {
+				t.Errorf("expected %v, got %v", DelegationTokenAuthDisabled, resErr)
+			}
+
+			// transport errors are returned
+			fault := errors.New("fault")
+			client.Transport = roundTripFunc(func(context.Context, net.Addr, protocol.Message) (protocol.Message, error) {
+				return nil, fault
+			})
+			if _, err := test.call(client); !errors.Is(err, fault) {
+				t.Errorf("expected the transport error, got %v", err)
+			}
+		})
+	}
+}
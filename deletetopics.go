@@ -7,6 +7,7 @@ import (
 	"net"
 	"time"
 
+	"github.com/PerchSecurity/kafka-go/protocol"
 	"github.com/PerchSecurity/kafka-go/protocol/deletetopics"
 )
 
@@ -18,6 +19,13 @@ type DeleteTopicsRequest struct {
 
 	// Names of topics to delete.
 	Topics []string
+
+	// IDs of topics to delete.
+	//
+	// Deleting topics by ID requires kafka brokers supporting DeleteTopics v6
+	// or above, the topics are reported with an UnsupportedVersion error in
+	// TopicIDErrors otherwise.
+	TopicIDs []protocol.UUID
 }
 
 // DeleteTopicsResponse represents a response from a kafka broker to a topic
@@ -35,13 +43,23 @@ type DeleteTopicsResponse struct {
 	// The errors contain the kafka error code. Programs may use the standard
 	// errors.Is function to test the error against kafka error codes.
 	Errors map[string]error
+
+	// Mapping of topic IDs to errors that occurred while attempting to delete
+	// the topics listed in TopicIDs.
+	TopicIDErrors map[protocol.UUID]error
 }
 
 // DeleteTopics sends a topic deletion request to a kafka broker and returns the
 // response.
 func (c *Client) DeleteTopics(ctx context.Context, req *DeleteTopicsRequest) (*DeleteTopicsResponse, error) {
+	topics := make([]deletetopics.RequestTopic, len(req.TopicIDs))
+	for i, id := range req.TopicIDs {
+		topics[i] = deletetopics.RequestTopic{TopicID: id}
+	}
+
 	m, err := c.roundTrip(ctx, req.Addr, &deletetopics.Request{
 		TopicNames: req.Topics,
+		Topics:     topics,
 		TimeoutMs:  c.timeoutMs(ctx, defaultDeleteTopicsTimeout),
 	})
 
@@ -55,11 +73,40 @@ func (c *Client) DeleteTopics(ctx context.Context, req *DeleteTopicsRequest) (*D
 		Errors:   make(map[string]error, len(res.Responses)),
 	}
 
+	names := make(map[string]bool, len(req.Topics))
+	for _, name := range req.Topics {
+		names[name] = true
+	}
+
+	ids := make(map[protocol.UUID]bool, len(req.TopicIDs))
+	for _, id := range req.TopicIDs {
+		ids[id] = true
+	}
+
+	if len(ids) != 0 {
+		ret.TopicIDErrors = make(map[protocol.UUID]error, len(ids))
+	}
+
+	// Responses to v6+ requests carry both the name and the ID of topics,
+	// the results are reported under the key that the topics were given by.
 	for _, t := range res.Responses {
-		if t.ErrorCode == 0 {
-			ret.Errors[t.Name] = nil
-		} else {
-			ret.Errors[t.Name] = Error(t.ErrorCode)
+		var err error
+		if t.ErrorCode != 0 {
+			err = Error(t.ErrorCode)
+		}
+		if names[t.Name] {
+			ret.Errors[t.Name] = err
+		}
+		if ids[t.TopicID] {
+			ret.TopicIDErrors[t.TopicID] = err
+		}
+	}
+
+	// Brokers which do not support DeleteTopics v6 never see the topics that
+	// were only given by ID.
+	for id := range ids {
+		if _, ok := ret.TopicIDErrors[id]; !ok {
+			ret.TopicIDErrors[id] = UnsupportedVersion
 		}
 	}
 
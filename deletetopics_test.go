@@ -4,8 +4,13 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"errors"
+	"net"
 	"reflect"
 	"testing"
+
+	"github.com/PerchSecurity/kafka-go/protocol"
+	"github.com/PerchSecurity/kafka-go/protocol/deletetopics"
 )
 
 func TestClientDeleteTopics(t *testing.T) {
@@ -28,6 +33,67 @@ func TestClientDeleteTopics(t *testing.T) {
 	}
 }
 
+func TestClientDeleteTopicsByID(t *testing.T) {
+	deletedID, unknownID := protocol.NewUUID(), protocol.NewUUID()
+
+	newClient := func(res *deletetopics.Response) *Client {
+		return &Client{
+			Addr: TCP("localhost:9092"),
+			Transport: roundTripFunc(func(context.Context, net.Addr, protocol.Message) (protocol.Message, error) {
+				return res, nil
+			}),
+		}
+	}
+
+	req := &DeleteTopicsRequest{
+		Topics:   []string{"by-name"},
+		TopicIDs: []protocol.UUID{deletedID, unknownID},
+	}
+
+	t.Run("results are reported under the keys of the request", func(t *testing.T) {
+		client := newClient(&deletetopics.Response{
+			Responses: []deletetopics.ResponseTopic{
+				{Name: "by-name", TopicID: protocol.NewUUID()},
+				{Name: "by-id", TopicID: deletedID},
+				{TopicID: unknownID, ErrorCode: int16(UnknownTopicID)},
+			},
+		})
+
+		res, err := client.DeleteTopics(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := map[string]error{"by-name": nil}; !reflect.DeepEqual(res.Errors, want) {
+			t.Errorf("errors mismatch: %v != %v", res.Errors, want)
+		}
+		if err, ok := res.TopicIDErrors[deletedID]; !ok || err != nil {
+			t.Errorf("unexpected error deleting the topic by ID: %v", err)
+		}
+		if err := res.TopicIDErrors[unknownID]; !errors.Is(err, UnknownTopicID) {
+			t.Errorf("unexpected error deleting an unknown topic by ID: %v", err)
+		}
+	})
+
+	t.Run("topic IDs are unsupported by brokers older than v6", func(t *testing.T) {
+		client := newClient(&deletetopics.Response{
+			Responses: []deletetopics.ResponseTopic{{Name: "by-name"}},
+		})
+
+		res, err := client.DeleteTopics(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err, ok := res.Errors["by-name"]; !ok || err != nil {
+			t.Errorf("unexpected error deleting the topic by name: %v", err)
+		}
+		for _, id := range req.TopicIDs {
+			if err := res.TopicIDErrors[id]; !errors.Is(err, UnsupportedVersion) {
+				t.Errorf("unexpected error deleting topic %v: %v", id, err)
+			}
+		}
+	})
+}
+
 func TestDeleteTopicsResponseV1(t *testing.T) {
 	item := deleteTopicsResponseV0{
 		TopicErrorCodes: []deleteTopicsResponseV0TopicErrorCode{
@@ -0,0 +1,121 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/PerchSecurity/kafka-go/protocol/describecluster"
+)
+
+// DescribeClusterRequest represents a request sent to a kafka broker to
+// describe the cluster that it belongs to.
+type DescribeClusterRequest struct {
+	// Address of the kafka broker to send the request to.
+	Addr net.Addr
+
+	// When true, the response includes the list of operations that the client
+	// is authorized to perform on the cluster.
+	IncludeAuthorizedOperations bool
+}
+
+// DescribeClusterResponse represents a response from a kafka broker to a
+// describe cluster request.
+type DescribeClusterResponse struct {
+	// The amount of time that the broker throttled the request.
+	Throttle time.Duration
+
+	// An error that may have occurred while attempting to describe the
+	// cluster.
+	//
+	// The error contains both the kafka error code, and an error message
+	// returned by the kafka broker. Programs may use the standard errors.Is
+	// function to test the error against kafka error codes.
+	Error error
+
+	// Unique identifier of the kafka cluster.
+	ClusterID string
+
+	// The broker which is currently the cluster controller.
+	Controller Broker
+
+	// List of brokers in the cluster, sorted by ID.
+	Brokers []Broker
+
+	// List of operations that the client is authorized to perform on the
+	// cluster. The list is nil unless IncludeAuthorizedOperations was set on
+	// the request.
+	AuthorizedOperations []ACLOperationType
+}
+
+// DescribeCluster sends a describe cluster request to a kafka broker and
+// returns the response.
+//
+// The request is served by the broker from its local metadata cache, which
+// makes it a cheap way to verify the health and identity of a cluster.
+//
+// This API requires kafka 2.8 or above.
+func (c *Client) DescribeCluster(ctx context.Context, req *DescribeClusterRequest) (*DescribeClusterResponse, error) {
+	m, err := c.roundTrip(ctx, req.Addr, &describecluster.Request{
+		IncludeClusterAuthorizedOperations: req.IncludeAuthorizedOperations,
+		EndpointType:                       1, // brokers
+	})
+	if err != nil {
+		return nil, fmt.Errorf("kafka.(*Client).DescribeCluster: %w", err)
+	}
+
+	res := m.(*describecluster.Response)
+	ret := &DescribeClusterResponse{
+		Throttle:  makeDuration(res.ThrottleTimeMs),
+		Error:     makeError(res.ErrorCode, res.ErrorMessage),
+		ClusterID: res.ClusterID,
+		Brokers:   make([]Broker, len(res.Brokers)),
+	}
+
+	for i, b := range res.Brokers {
+		broker := Broker{
+			ID:   int(b.BrokerID),
+			Host: b.Host,
+			Port: int(b.Port),
+			Rack: b.Rack,
+		}
+
+		if b.BrokerID == res.ControllerID {
+			ret.Controller = broker
+		}
+
+		ret.Brokers[i] = broker
+	}
+
+	sort.Slice(ret.Brokers, func(i, j int) bool {
+		return ret.Brokers[i].ID < ret.Brokers[j].ID
+	})
+
+	if req.IncludeAuthorizedOperations {
+		ret.AuthorizedOperations = makeAuthorizedOperations(res.ClusterAuthorizedOperations)
+	}
+
+	return ret, nil
+}
+
+// makeAuthorizedOperations converts the bit field of authorized operations
+// returned by kafka brokers into a list of operation types.
+func makeAuthorizedOperations(bits int32) []ACLOperationType {
+	// The broker sets the value to INT32_MIN when the authorized operations
+	// were not requested or could not be determined.
+	if bits == -2147483648 {
+		return nil
+	}
+
+	ops := []ACLOperationType{}
+
+	for op := ACLOperationTypeRead; op <= ACLOperationTypeIdempotentWrite; op++ {
+		if bits&(1<<uint(op)) != 0 {
+			ops = append(ops, op)
+		}
+	}
+
+	return ops
+}
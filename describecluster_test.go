@@ -0,0 +1,54 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	ktesting "github.com/PerchSecurity/kafka-go/testing"
+)
+
+func TestClientDescribeCluster(t *testing.T) {
+	if !ktesting.KafkaIsAtLeast("2.8.0") {
+		return
+	}
+
+	ctx := context.Background()
+	client, shutdown := newLocalClient()
+	defer shutdown()
+
+	res, err := client.DescribeCluster(ctx, &DescribeClusterRequest{
+		IncludeAuthorizedOperations: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Error != nil {
+		t.Fatal(res.Error)
+	}
+
+	if res.ClusterID == "" {
+		t.Error("expected a cluster ID in the response")
+	}
+
+	if len(res.Brokers) == 0 {
+		t.Fatal("expected at least one broker in the response")
+	}
+
+	if res.Controller.Host == "" {
+		t.Error("expected the controller to be one of the brokers")
+	}
+
+	if res.AuthorizedOperations == nil {
+		t.Error("expected authorized operations in the response")
+	}
+
+	meta, err := client.Metadata(ctx, &MetadataRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if meta.ClusterID != res.ClusterID {
+		t.Errorf("cluster ID mismatch: metadata=%q describe cluster=%q", meta.ClusterID, res.ClusterID)
+	}
+}
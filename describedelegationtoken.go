@@ -0,0 +1,100 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/PerchSecurity/kafka-go/protocol/describedelegationtoken"
+)
+
+// DescribeDelegationTokenRequest represents a request sent to a kafka broker
+// to describe delegation tokens.
+type DescribeDelegationTokenRequest struct {
+	// Address of the kafka broker to send the request to.
+	Addr net.Addr
+
+	// List of token owners to describe the tokens of. When nil, all the tokens
+	// that the client is allowed to describe are returned.
+	Owners []DelegationTokenPrincipal
+}
+
+// DescribeDelegationTokenResponse represents a response from a kafka broker to
+// a describe delegation token request.
+type DescribeDelegationTokenResponse struct {
+	// The amount of time that the broker throttled the request.
+	Throttle time.Duration
+
+	// An error that may have occurred while attempting to describe the tokens.
+	//
+	// The error contains the kafka error code. Programs may use the standard
+	// errors.Is function to test the error against kafka error codes.
+	Error error
+
+	// List of tokens returned by the kafka broker.
+	Tokens []DelegationToken
+}
+
+// DescribeDelegationToken sends a describe delegation token request to a kafka
+// broker and returns the response.
+func (c *Client) DescribeDelegationToken(ctx context.Context, req *DescribeDelegationTokenRequest) (*DescribeDelegationTokenResponse, error) {
+	var owners []describedelegationtoken.RequestOwner
+
+	if req.Owners != nil {
+		owners = make([]describedelegationtoken.RequestOwner, len(req.Owners))
+
+		for i, o := range req.Owners {
+			owners[i] = describedelegationtoken.RequestOwner{
+				PrincipalType: o.Type,
+				PrincipalName: o.Name,
+			}
+		}
+	}
+
+	m, err := c.roundTrip(ctx, req.Addr, &describedelegationtoken.Request{
+		Owners: owners,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("kafka.(*Client).DescribeDelegationToken: %w", err)
+	}
+
+	res := m.(*describedelegationtoken.Response)
+	ret := &DescribeDelegationTokenResponse{
+		Throttle: makeDuration(res.ThrottleTimeMs),
+		Error:    makeError(res.ErrorCode, ""),
+		Tokens:   make([]DelegationToken, len(res.Tokens)),
+	}
+
+	for i, t := range res.Tokens {
+		renewers := make([]DelegationTokenPrincipal, len(t.Renewers))
+
+		for j, r := range t.Renewers {
+			renewers[j] = DelegationTokenPrincipal{
+				Type: r.PrincipalType,
+				Name: r.PrincipalName,
+			}
+		}
+
+		ret.Tokens[i] = DelegationToken{
+			TokenID: t.TokenID,
+			HMAC:    t.HMAC,
+			Owner: DelegationTokenPrincipal{
+				Type: t.PrincipalType,
+				Name: t.PrincipalName,
+			},
+			Requester: makeDelegationTokenRequester(
+				t.PrincipalType,
+				t.PrincipalName,
+				t.TokenRequesterPrincipalType,
+				t.TokenRequesterPrincipalName,
+			),
+			IssueTime:  makeTime(t.IssueTimestampMs),
+			ExpiryTime: makeTime(t.ExpiryTimestampMs),
+			MaxTime:    makeTime(t.MaxTimestampMs),
+			Renewers:   renewers,
+		}
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,70 @@
+package kafka
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/PerchSecurity/kafka-go/protocol"
+	"github.com/PerchSecurity/kafka-go/protocol/describedelegationtoken"
+)
+
+func TestClientDescribeDelegationToken(t *testing.T) {
+	var sent []*describedelegationtoken.Request
+
+	client := &Client{
+		Addr: TCP("localhost:9092"),
+		Transport: roundTripFunc(func(ctx context.Context, addr net.Addr, msg protocol.Message) (protocol.Message, error) {
+			sent = append(sent, msg.(*describedelegationtoken.Request))
+			return &describedelegationtoken.Response{
+				Tokens: []describedelegationtoken.ResponseToken{{
+					PrincipalType:               "User",
+					PrincipalName:               "owner",
+					TokenRequesterPrincipalType: "User",
+					TokenRequesterPrincipalName: "requester",
+					IssueTimestampMs:            1000,
+					ExpiryTimestampMs:           2000,
+					MaxTimestampMs:              3000,
+					TokenID:                     "token",
+					HMAC:                        []byte("hmac"),
+					Renewers:                    []describedelegationtoken.ResponseRenewer{{PrincipalType: "User", PrincipalName: "renewer"}},
+				}},
+			}, nil
+		}),
+	}
+
+	owners := []DelegationTokenPrincipal{{Type: "User", Name: "owner"}}
+	res, err := client.DescribeDelegationToken(context.Background(), &DescribeDelegationTokenRequest{Owners: owners})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token := DelegationToken{
+		TokenID:    "token",
+		HMAC:       []byte("hmac"),
+		Owner:      DelegationTokenPrincipal{Type: "User", Name: "owner"},
+		Requester:  DelegationTokenPrincipal{Type: "User", Name: "requester"},
+		IssueTime:  makeTime(1000),
+		ExpiryTime: makeTime(2000),
+		MaxTime:    makeTime(3000),
+		Renewers:   []DelegationTokenPrincipal{{Type: "User", Name: "renewer"}},
+	}
+	if res.Error != nil || !reflect.DeepEqual(res.Tokens, []DelegationToken{token}) {
+		t.Errorf("unexpected response: %+v", res)
+	}
+
+	// A nil list of owners describes all the tokens, which the protocol
+	// represents with a null array rather than an empty one.
+	if _, err := client.DescribeDelegationToken(context.Background(), &DescribeDelegationTokenRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*describedelegationtoken.Request{
+		{Owners: []describedelegationtoken.RequestOwner{{PrincipalType: "User", PrincipalName: "owner"}}},
+		{Owners: nil},
+	}
+	if !reflect.DeepEqual(sent, want) {
+		t.Errorf("requests mismatch:\n got: %+v\nwant: %+v", sent, want)
+	}
+}
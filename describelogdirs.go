@@ -0,0 +1,159 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/PerchSecurity/kafka-go/protocol/describelogdirs"
+)
+
+// DescribeLogDirsRequest represents a request sent to a kafka cluster to
+// describe the log directories of every broker.
+type DescribeLogDirsRequest struct {
+	// Address of the kafka cluster to send the request to.
+	Addr net.Addr
+
+	// Set of topic partitions to describe. If nil, all the partitions hosted
+	// by the brokers are described.
+	Topics map[string][]int
+}
+
+// DescribeLogDirsResponse represents a response from a kafka cluster to a
+// describe log dirs request. The results of all brokers are merged into a
+// single response.
+type DescribeLogDirsResponse struct {
+	// The amount of time that the brokers throttled the request.
+	Throttle time.Duration
+
+	// An error that may have occurred while attempting to describe the log
+	// directories.
+	//
+	// The error contains both the kafka error code, and an error message
+	// returned by the kafka broker. Programs may use the standard errors.Is
+	// function to test the error against kafka error codes.
+	Error error
+
+	// List of log directories, sorted by broker ID and path.
+	LogDirs []DescribeLogDirsResponseLogDir
+}
+
+// DescribeLogDirsResponseLogDir represents the state of a log directory on a
+// kafka broker.
+type DescribeLogDirsResponseLogDir struct {
+	// ID of the broker that the log directory belongs to.
+	Broker int
+
+	// Absolute path of the log directory.
+	Path string
+
+	// Total size in bytes of the volume that the log directory is on. Brokers
+	// older than kafka 3.3 do not report it and leave this field zero, newer
+	// brokers set it to -1 if the size could not be determined.
+	TotalBytes int64
+
+	// Usable size in bytes of the volume that the log directory is on, with
+	// the same semantics as TotalBytes when the size is not reported.
+	UsableBytes int64
+
+	// List of partitions hosted in the log directory.
+	Partitions []DescribeLogDirsResponsePartition
+
+	// An error that may have occurred while describing the log directory.
+	Error error
+}
+
+// DescribeLogDirsResponsePartition represents the state of a partition replica
+// in a log directory.
+type DescribeLogDirsResponsePartition struct {
+	// Name of the topic that the partition belongs to.
+	Topic string
+
+	// ID of the partition.
+	Partition int
+
+	// Size in bytes of the partition replica.
+	Size int64
+
+	// Lag of the replica's log end offset relative to the partition's high
+	// watermark (or, for future replicas, to the current replica's log end
+	// offset).
+	OffsetLag int64
+
+	// IsFuture is true if the replica is a future replica, which is created
+	// while a partition is being moved between log directories.
+	IsFuture bool
+}
+
+// DescribeLogDirs sends a describe log dirs request to every broker of a kafka
+// cluster and returns the merged response.
+func (c *Client) DescribeLogDirs(ctx context.Context, req *DescribeLogDirsRequest) (*DescribeLogDirsResponse, error) {
+	var topics []describelogdirs.RequestTopic
+
+	if req.Topics != nil {
+		topics = make([]describelogdirs.RequestTopic, 0, len(req.Topics))
+
+		for topicName, partitions := range req.Topics {
+			indexes := make([]int32, len(partitions))
+
+			for i, p := range partitions {
+				indexes[i] = int32(p)
+			}
+
+			topics = append(topics, describelogdirs.RequestTopic{
+				Topic:      topicName,
+				Partitions: indexes,
+			})
+		}
+	}
+
+	m, err := c.roundTrip(ctx, req.Addr, &describelogdirs.Request{
+		Topics: topics,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("kafka.(*Client).DescribeLogDirs: %w", err)
+	}
+
+	res := m.(*describelogdirs.Response)
+	ret := &DescribeLogDirsResponse{
+		Throttle: makeDuration(res.ThrottleTimeMs),
+		Error:    makeError(res.ErrorCode, ""),
+		LogDirs:  make([]DescribeLogDirsResponseLogDir, 0, len(res.Results)),
+	}
+
+	for _, r := range res.Results {
+		logDir := DescribeLogDirsResponseLogDir{
+			Broker:      int(r.BrokerID),
+			Path:        r.LogDir,
+			TotalBytes:  r.TotalBytes,
+			UsableBytes: r.UsableBytes,
+			Error:       makeError(r.ErrorCode, ""),
+		}
+
+		for _, t := range r.Topics {
+			for _, p := range t.Partitions {
+				logDir.Partitions = append(logDir.Partitions, DescribeLogDirsResponsePartition{
+					Topic:     t.Name,
+					Partition: int(p.PartitionIndex),
+					Size:      p.PartitionSize,
+					OffsetLag: p.OffsetLag,
+					IsFuture:  p.IsFutureKey,
+				})
+			}
+		}
+
+		ret.LogDirs = append(ret.LogDirs, logDir)
+	}
+
+	sort.Slice(ret.LogDirs, func(i, j int) bool {
+		d1, d2 := &ret.LogDirs[i], &ret.LogDirs[j]
+		if d1.Broker != d2.Broker {
+			return d1.Broker < d2.Broker
+		}
+		return d1.Path < d2.Path
+	})
+
+	return ret, nil
+}
@@ -0,0 +1,59 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	ktesting "github.com/PerchSecurity/kafka-go/testing"
+)
+
+func TestClientDescribeLogDirs(t *testing.T) {
+	if !ktesting.KafkaIsAtLeast("1.0.0") {
+		return
+	}
+
+	ctx := context.Background()
+	client, shutdown := newLocalClient()
+	defer shutdown()
+
+	topic := makeTopic()
+	createTopic(t, topic, 2)
+	defer deleteTopic(t, topic)
+
+	res, err := client.DescribeLogDirs(ctx, &DescribeLogDirsRequest{
+		Topics: map[string][]int{topic: {0, 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Error != nil {
+		t.Fatal(res.Error)
+	}
+
+	if len(res.LogDirs) == 0 {
+		t.Fatal("expected at least one log directory in the response")
+	}
+
+	found := map[int]bool{}
+
+	for _, logDir := range res.LogDirs {
+		if logDir.Error != nil {
+			t.Errorf("unexpected error on log directory %q: %v", logDir.Path, logDir.Error)
+		}
+
+		for _, p := range logDir.Partitions {
+			if p.Topic != topic {
+				t.Errorf("unexpected topic in response: %q", p.Topic)
+			}
+			if p.Size < 0 {
+				t.Errorf("unexpected negative size for partition %d: %d", p.Partition, p.Size)
+			}
+			found[p.Partition] = true
+		}
+	}
+
+	if len(found) != 2 {
+		t.Errorf("expected 2 partitions in the response, got %d", len(found))
+	}
+}
@@ -0,0 +1,138 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/PerchSecurity/kafka-go/protocol/describeproducers"
+)
+
+// DescribeProducersRequest represents a request sent to a kafka cluster to
+// describe the active producers of topic partitions.
+type DescribeProducersRequest struct {
+	// Address of the kafka cluster to send the request to.
+	Addr net.Addr
+
+	// Set of topic partitions to describe the producers of.
+	Topics map[string][]int
+}
+
+// DescribeProducersResponse represents a response from a kafka cluster to a
+// describe producers request. The results of all partition leaders are merged
+// into a single response.
+type DescribeProducersResponse struct {
+	// The amount of time that the brokers throttled the request.
+	Throttle time.Duration
+
+	// Set of topic partitions that the kafka brokers have returned producer
+	// states for.
+	Topics map[string][]DescribeProducersResponsePartition
+}
+
+// DescribeProducersResponsePartition represents the active producers of a
+// partition.
+type DescribeProducersResponsePartition struct {
+	// ID of the partition.
+	Partition int
+
+	// List of producers that have written to the partition recently.
+	ActiveProducers []DescribeProducersResponseProducer
+
+	// An error that may have occurred while attempting to describe the
+	// producers of the partition.
+	//
+	// The error contains both the kafka error code, and an error message
+	// returned by the kafka broker. Programs may use the standard errors.Is
+	// function to test the error against kafka error codes.
+	Error error
+}
+
+// DescribeProducersResponseProducer represents the state of a producer on a
+// partition.
+type DescribeProducersResponseProducer struct {
+	// ID and epoch of the producer.
+	ProducerID    int64
+	ProducerEpoch int
+
+	// Sequence number of the last record written by the producer, or -1 if
+	// unknown.
+	LastSequence int
+
+	// Time of the last record written by the producer.
+	LastTimestamp time.Time
+
+	// Epoch of the transaction coordinator that wrote the last transaction
+	// marker of the producer, or -1 if unknown.
+	CoordinatorEpoch int
+
+	// Offset of the first record of the transaction currently running on the
+	// partition, or -1 if the producer has no open transaction. A transaction
+	// that stays open for longer than its timeout is hanging, and can be
+	// aborted with AbortHangingTransaction.
+	CurrentTxnStartOffset int64
+}
+
+// DescribeProducers sends describe producers requests to the leaders of the
+// requested partitions and returns the merged response.
+//
+// This API requires kafka 3.0 or above.
+func (c *Client) DescribeProducers(ctx context.Context, req *DescribeProducersRequest) (*DescribeProducersResponse, error) {
+	topics := make([]describeproducers.RequestTopic, 0, len(req.Topics))
+
+	for topicName, partitions := range req.Topics {
+		indexes := make([]int32, len(partitions))
+
+		for i, p := range partitions {
+			indexes[i] = int32(p)
+		}
+
+		topics = append(topics, describeproducers.RequestTopic{
+			Name:             topicName,
+			PartitionIndexes: indexes,
+		})
+	}
+
+	m, err := c.roundTrip(ctx, req.Addr, &describeproducers.Request{
+		Topics: topics,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("kafka.(*Client).DescribeProducers: %w", err)
+	}
+
+	res := m.(*describeproducers.Response)
+	ret := &DescribeProducersResponse{
+		Throttle: makeDuration(res.ThrottleTimeMs),
+		Topics:   make(map[string][]DescribeProducersResponsePartition, len(res.Topics)),
+	}
+
+	for _, t := range res.Topics {
+		partitions := make([]DescribeProducersResponsePartition, len(t.Partitions))
+
+		for i, p := range t.Partitions {
+			producers := make([]DescribeProducersResponseProducer, len(p.ActiveProducers))
+
+			for j, s := range p.ActiveProducers {
+				producers[j] = DescribeProducersResponseProducer{
+					ProducerID:            s.ProducerID,
+					ProducerEpoch:         int(s.ProducerEpoch),
+					LastSequence:          int(s.LastSequence),
+					LastTimestamp:         makeTime(s.LastTimestamp),
+					CoordinatorEpoch:      int(s.CoordinatorEpoch),
+					CurrentTxnStartOffset: s.CurrentTxnStartOffset,
+				}
+			}
+
+			partitions[i] = DescribeProducersResponsePartition{
+				Partition:       int(p.PartitionIndex),
+				ActiveProducers: producers,
+				Error:           makeError(p.ErrorCode, p.ErrorMessage),
+			}
+		}
+
+		ret.Topics[t.Name] = partitions
+	}
+
+	return ret, nil
+}
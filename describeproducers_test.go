@@ -0,0 +1,43 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	ktesting "github.com/PerchSecurity/kafka-go/testing"
+)
+
+func TestClientDescribeProducers(t *testing.T) {
+	if !ktesting.KafkaIsAtLeast("3.0.0") {
+		return
+	}
+
+	ctx := context.Background()
+	client, topic, shutdown := newLocalClientAndTopic()
+	defer shutdown()
+
+	produceRecords(t, 10, client.Addr, topic, nil)
+
+	res, err := client.DescribeProducers(ctx, &DescribeProducersRequest{
+		Topics: map[string][]int{topic: {0}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	partitions := res.Topics[topic]
+	if len(partitions) != 1 {
+		t.Fatalf("expected 1 partition in the response, got %d", len(partitions))
+	}
+
+	p := partitions[0]
+	if p.Error != nil {
+		t.Fatal(p.Error)
+	}
+
+	for _, producer := range p.ActiveProducers {
+		if producer.CurrentTxnStartOffset != -1 {
+			t.Errorf("unexpected open transaction for producer %d at offset %d", producer.ProducerID, producer.CurrentTxnStartOffset)
+		}
+	}
+}
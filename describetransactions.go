@@ -0,0 +1,107 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/PerchSecurity/kafka-go/protocol/describetransactions"
+)
+
+// DescribeTransactionsRequest represents a request sent to a kafka cluster to
+// describe the state of transactions.
+type DescribeTransactionsRequest struct {
+	// Address of the kafka cluster to send the request to.
+	Addr net.Addr
+
+	// List of transactional IDs to describe.
+	TransactionalIDs []string
+}
+
+// DescribeTransactionsResponse represents a response from a kafka cluster to a
+// describe transactions request.
+type DescribeTransactionsResponse struct {
+	// The amount of time that the brokers throttled the request.
+	Throttle time.Duration
+
+	// List of described transactions.
+	Transactions []DescribeTransactionsResponseTransaction
+}
+
+// DescribeTransactionsResponseTransaction represents the state of a
+// transaction as seen by its coordinator.
+type DescribeTransactionsResponseTransaction struct {
+	// Transactional ID of the producer.
+	TransactionalID string
+
+	// Current state of the transaction.
+	State string
+
+	// Timeout of the transaction, after which the coordinator aborts it.
+	Timeout time.Duration
+
+	// Time at which the transaction started, zero if no transaction is
+	// running.
+	StartTime time.Time
+
+	// ID and epoch of the producer running the transaction.
+	ProducerID    int64
+	ProducerEpoch int
+
+	// Set of topic partitions that are part of the transaction.
+	Topics map[string][]int
+
+	// An error that may have occurred while attempting to describe the
+	// transaction.
+	//
+	// The error contains the kafka error code. Programs may use the standard
+	// errors.Is function to test the error against kafka error codes.
+	Error error
+}
+
+// DescribeTransactions sends describe transactions requests to the coordinators
+// of the requested transactions and returns the merged response.
+//
+// This API requires kafka 3.0 or above.
+func (c *Client) DescribeTransactions(ctx context.Context, req *DescribeTransactionsRequest) (*DescribeTransactionsResponse, error) {
+	m, err := c.roundTrip(ctx, req.Addr, &describetransactions.Request{
+		TransactionalIDs: req.TransactionalIDs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("kafka.(*Client).DescribeTransactions: %w", err)
+	}
+
+	res := m.(*describetransactions.Response)
+	ret := &DescribeTransactionsResponse{
+		Throttle:     makeDuration(res.ThrottleTimeMs),
+		Transactions: make([]DescribeTransactionsResponseTransaction, len(res.TransactionStates)),
+	}
+
+	for i, t := range res.TransactionStates {
+		topics := make(map[string][]int, len(t.Topics))
+
+		for _, topic := range t.Topics {
+			partitions := make([]int, len(topic.Partitions))
+
+			for j, p := range topic.Partitions {
+				partitions[j] = int(p)
+			}
+
+			topics[topic.Topic] = partitions
+		}
+
+		ret.Transactions[i] = DescribeTransactionsResponseTransaction{
+			TransactionalID: t.TransactionalID,
+			State:           t.TransactionState,
+			Timeout:         makeDuration(t.TransactionTimeoutMs),
+			StartTime:       makeTime(t.TransactionStartTimeMs),
+			ProducerID:      t.ProducerID,
+			ProducerEpoch:   int(t.ProducerEpoch),
+			Topics:          topics,
+			Error:           makeError(t.ErrorCode, ""),
+		}
+	}
+
+	return ret, nil
+}
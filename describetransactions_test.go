@@ -0,0 +1,88 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	ktesting "github.com/PerchSecurity/kafka-go/testing"
+)
+
+func TestClientDescribeTransactions(t *testing.T) {
+	if !ktesting.KafkaIsAtLeast("3.0.0") {
+		return
+	}
+
+	client, shutdown := newLocalClient()
+	defer shutdown()
+
+	transactionalID := makeTransactionalID()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := waitForCoordinatorIndefinitely(ctx, client, &FindCoordinatorRequest{
+		Addr:    client.Addr,
+		Key:     transactionalID,
+		KeyType: CoordinatorKeyTypeTransaction,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ipResp, err := client.InitProducerID(ctx, &InitProducerIDRequest{
+		TransactionalID:      transactionalID,
+		TransactionTimeoutMs: 30000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ipResp.Error != nil {
+		t.Fatal(ipResp.Error)
+	}
+
+	res, err := client.DescribeTransactions(ctx, &DescribeTransactionsRequest{
+		TransactionalIDs: []string{transactionalID},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Transactions) != 1 {
+		t.Fatalf("expected 1 transaction in the response, got %d", len(res.Transactions))
+	}
+
+	txn := res.Transactions[0]
+	if txn.Error != nil {
+		t.Fatal(txn.Error)
+	}
+	if txn.TransactionalID != transactionalID {
+		t.Errorf("transactional ID mismatch: want=%q got=%q", transactionalID, txn.TransactionalID)
+	}
+	if txn.ProducerID != int64(ipResp.Producer.ProducerID) {
+		t.Errorf("producer ID mismatch: want=%d got=%d", ipResp.Producer.ProducerID, txn.ProducerID)
+	}
+	if txn.State != "Empty" {
+		t.Errorf("unexpected transaction state: %q", txn.State)
+	}
+
+	list, err := client.ListTransactions(ctx, &ListTransactionsRequest{
+		ProducerIDs: []int64{txn.ProducerID},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Error != nil {
+		t.Fatal(list.Error)
+	}
+
+	found := false
+	for _, txn := range list.Transactions {
+		if txn.TransactionalID == transactionalID {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("transaction %q was not listed", transactionalID)
+	}
+}
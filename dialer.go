@@ -3,9 +3,7 @@ package kafka
 import (
 	"context"
 	"crypto/tls"
-	"errors"
 	"fmt"
-	"io"
 	"net"
 	"strconv"
 	"strings"
@@ -307,35 +305,7 @@ func (d *Dialer) connect(ctx context.Context, network, address string, connCfg C
 // In case of error, this function *does not* close the connection.  That is the
 // responsibility of the caller.
 func (d *Dialer) authenticateSASL(ctx context.Context, conn *Conn) error {
-	if err := conn.saslHandshake(d.SASLMechanism.Name()); err != nil {
-		return fmt.Errorf("SASL handshake failed: %w", err)
-	}
-
-	sess, state, err := d.SASLMechanism.Start(ctx)
-	if err != nil {
-		return fmt.Errorf("SASL authentication process could not be started: %w", err)
-	}
-
-	for completed := false; !completed; {
-		challenge, err := conn.saslAuthenticate(state)
-		switch {
-		case err == nil:
-		case errors.Is(err, io.EOF):
-			// the broker may communicate a failed exchange by closing the
-			// connection (esp. in the case where we're passing opaque sasl
-			// data over the wire since there's no protocol info).
-			return SASLAuthenticationFailed
-		default:
-			return err
-		}
-
-		completed, state, err = sess.Next(ctx, challenge)
-		if err != nil {
-			return fmt.Errorf("SASL authentication process has failed: %w", err)
-		}
-	}
-
-	return nil
+	return conn.authenticateSASL(ctx, d.SASLMechanism)
 }
 
 func (d *Dialer) dialContext(ctx context.Context, network string, addr string) (net.Conn, error) {
@@ -660,6 +660,35 @@ func (e MessageTooLargeError) Unwrap() error {
 	return MessageSizeTooLarge
 }
 
+// LogTruncationError is reported by kafka.(*Reader) when it detects that the
+// log of a partition was truncated below the position of the reader, which
+// happens when an out-of-sync replica is elected leader of the partition
+// (unclean leader election). The messages consumed between DivergentOffset and
+// Offset do not exist anymore in the partition log.
+//
+// After reporting the error, the reader resumes consuming at DivergentOffset.
+type LogTruncationError struct {
+	// Topic and partition where the truncation was detected.
+	Topic     string
+	Partition int
+
+	// Offset of the next message that the reader expected to read.
+	Offset int64
+
+	// First offset at which the partition log diverges from the messages that
+	// were consumed by the reader.
+	DivergentOffset int64
+
+	// Leader epoch of the last message consumed by the reader before the
+	// divergent offset.
+	LeaderEpoch int
+}
+
+func (e *LogTruncationError) Error() string {
+	return fmt.Sprintf("log truncation detected on partition %d of %s: consumed up to offset %d but the partition log diverges at offset %d (leader epoch %d)",
+		e.Partition, e.Topic, e.Offset, e.DivergentOffset, e.LeaderEpoch)
+}
+
 func makeError(code int16, message string) error {
 	if code == 0 {
 		return nil
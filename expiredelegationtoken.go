@@ -0,0 +1,66 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/PerchSecurity/kafka-go/protocol/expiredelegationtoken"
+)
+
+// ExpireDelegationTokenRequest represents a request sent to a kafka broker to
+// change the expiry time of a delegation token.
+type ExpireDelegationTokenRequest struct {
+	// Address of the kafka broker to send the request to.
+	Addr net.Addr
+
+	// HMAC of the token to expire.
+	HMAC []byte
+
+	// Amount of time after which the token expires. When zero or negative, the
+	// token expires immediately.
+	ExpiryPeriod time.Duration
+}
+
+// ExpireDelegationTokenResponse represents a response from a kafka broker to
+// an expire delegation token request.
+type ExpireDelegationTokenResponse struct {
+	// The amount of time that the broker throttled the request.
+	Throttle time.Duration
+
+	// An error that may have occurred while attempting to expire the token.
+	//
+	// The error contains the kafka error code. Programs may use the standard
+	// errors.Is function to test the error against kafka error codes.
+	Error error
+
+	// The new expiry time of the token.
+	ExpiryTime time.Time
+}
+
+// ExpireDelegationToken sends an expire delegation token request to a kafka
+// broker and returns the response.
+func (c *Client) ExpireDelegationToken(ctx context.Context, req *ExpireDelegationTokenRequest) (*ExpireDelegationTokenResponse, error) {
+	expiryPeriodMs := int64(-1)
+	if req.ExpiryPeriod > 0 {
+		expiryPeriodMs = int64(req.ExpiryPeriod / time.Millisecond)
+	}
+
+	m, err := c.roundTrip(ctx, req.Addr, &expiredelegationtoken.Request{
+		HMAC:               req.HMAC,
+		ExpiryTimePeriodMs: expiryPeriodMs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("kafka.(*Client).ExpireDelegationToken: %w", err)
+	}
+
+	res := m.(*expiredelegationtoken.Response)
+	ret := &ExpireDelegationTokenResponse{
+		Throttle:   makeDuration(res.ThrottleTimeMs),
+		Error:      makeError(res.ErrorCode, ""),
+		ExpiryTime: makeTime(res.ExpiryTimestampMs),
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,48 @@
+package kafka
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/PerchSecurity/kafka-go/protocol"
+	"github.com/PerchSecurity/kafka-go/protocol/expiredelegationtoken"
+)
+
+func TestClientExpireDelegationToken(t *testing.T) {
+	var sent []*expiredelegationtoken.Request
+
+	client := &Client{
+		Addr: TCP("localhost:9092"),
+		Transport: roundTripFunc(func(ctx context.Context, addr net.Addr, msg protocol.Message) (protocol.Message, error) {
+			sent = append(sent, msg.(*expiredelegationtoken.Request))
+			return &expiredelegationtoken.Response{ExpiryTimestampMs: 2000}, nil
+		}),
+	}
+
+	res, err := client.ExpireDelegationToken(context.Background(), &ExpireDelegationTokenRequest{
+		HMAC:         []byte("hmac"),
+		ExpiryPeriod: time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Error != nil || !res.ExpiryTime.Equal(makeTime(2000)) {
+		t.Errorf("unexpected response: %+v", res)
+	}
+
+	// A zero period is sent as -1 to let the broker pick the default.
+	if _, err := client.ExpireDelegationToken(context.Background(), &ExpireDelegationTokenRequest{HMAC: []byte("hmac")}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*expiredelegationtoken.Request{
+		{HMAC: []byte("hmac"), ExpiryTimePeriodMs: 60000},
+		{HMAC: []byte("hmac"), ExpiryTimePeriodMs: -1},
+	}
+	if !reflect.DeepEqual(sent, want) {
+		t.Errorf("requests mismatch:\n got: %+v\nwant: %+v", sent, want)
+	}
+}
@@ -25,6 +25,16 @@ type FetchRequest struct {
 	Partition int
 	Offset    int64
 
+	// Unique identifier of the topic to retrieve records from.
+	//
+	// Programs may set either Topic or TopicID. The ID of a topic given by
+	// name is resolved from the metadata cached by the Transport, while the
+	// name of a topic given by ID is resolved with a metadata request. When
+	// both are set, kafka brokers supporting the Fetch API in version 13 or
+	// above reject the request if the topic was deleted and recreated since
+	// the program obtained the ID.
+	TopicID protocol.UUID
+
 	// Size and time limits of the response returned by the broker.
 	MinBytes int64
 	MaxBytes int64
@@ -49,6 +59,10 @@ type FetchResponse struct {
 	Topic     string
 	Partition int
 
+	// Unique identifier of the topic, which is zero if the kafka broker does
+	// not support topic IDs.
+	TopicID protocol.UUID
+
 	// Information about the topic partition layout returned from the broker.
 	//
 	// LastStableOffset requires the kafka broker to support the Fetch API in
@@ -93,10 +107,18 @@ func (c *Client) Fetch(ctx context.Context, req *FetchRequest) (*FetchResponse,
 		timeout = maxWait
 	}
 
+	topicName, topicID := req.Topic, req.TopicID
+	if topicName == "" {
+		var err error
+		if topicName, err = c.lookupTopic(ctx, req.Addr, topicID); err != nil {
+			return nil, fmt.Errorf("kafka.(*Client).Fetch: %w", err)
+		}
+	}
+
 	offset := req.Offset
 	switch offset {
 	case FirstOffset, LastOffset:
-		topic, partition := req.Topic, req.Partition
+		topic, partition := topicName, req.Partition
 
 		r, err := c.ListOffsets(ctx, &ListOffsetsRequest{
 			Addr: req.Addr,
@@ -127,7 +149,7 @@ func (c *Client) Fetch(ctx context.Context, req *FetchRequest) (*FetchResponse,
 		}
 	}
 
-	m, err := c.roundTrip(ctx, req.Addr, &fetchAPI.Request{
+	fetchRequest := &fetchAPI.Request{
 		ReplicaID:      -1,
 		MaxWaitTime:    milliseconds(timeout),
 		MinBytes:       int32(req.MinBytes),
@@ -136,17 +158,20 @@ func (c *Client) Fetch(ctx context.Context, req *FetchRequest) (*FetchResponse,
 		SessionID:      -1,
 		SessionEpoch:   -1,
 		Topics: []fetchAPI.RequestTopic{{
-			Topic: req.Topic,
+			Topic:   topicName,
+			TopicID: topicID,
 			Partitions: []fetchAPI.RequestPartition{{
 				Partition:          int32(req.Partition),
 				CurrentLeaderEpoch: -1,
 				FetchOffset:        offset,
+				LastFetchedEpoch:   -1,
 				LogStartOffset:     -1,
 				PartitionMaxBytes:  int32(req.MaxBytes),
 			}},
 		}},
-	})
+	}
 
+	m, err := c.roundTrip(ctx, req.Addr, fetchRequest)
 	if err != nil {
 		return nil, fmt.Errorf("kafka.(*Client).Fetch: %w", err)
 	}
@@ -163,7 +188,8 @@ func (c *Client) Fetch(ctx context.Context, req *FetchRequest) (*FetchResponse,
 
 	ret := &FetchResponse{
 		Throttle:         makeDuration(res.ThrottleTimeMs),
-		Topic:            topic.Topic,
+		Topic:            topicName, // responses to v13+ requests have no topic names
+		TopicID:          fetchRequest.Topics[0].TopicID,
 		Partition:        int(partition.Partition),
 		Error:            makeError(res.ErrorCode, ""),
 		HighWatermark:    partition.HighWatermark,
@@ -183,6 +209,22 @@ func (c *Client) Fetch(ctx context.Context, req *FetchRequest) (*FetchResponse,
 	return ret, nil
 }
 
+// lookupTopic resolves the name of a topic from its ID.
+func (c *Client) lookupTopic(ctx context.Context, addr net.Addr, id protocol.UUID) (string, error) {
+	r, err := c.Metadata(ctx, &MetadataRequest{Addr: addr})
+	if err != nil {
+		return "", err
+	}
+
+	for _, t := range r.Topics {
+		if !id.IsZero() && t.ID == id {
+			return t.Name, nil
+		}
+	}
+
+	return "", UnknownTopicID
+}
+
 func (req *FetchRequest) maxWait() time.Duration {
 	if req.MaxWait > 0 {
 		return req.MaxWait
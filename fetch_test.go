@@ -11,6 +11,9 @@ import (
 	"time"
 
 	"github.com/PerchSecurity/kafka-go/compress"
+	"github.com/PerchSecurity/kafka-go/kafkatest"
+	"github.com/PerchSecurity/kafka-go/protocol"
+	fetchAPI "github.com/PerchSecurity/kafka-go/protocol/fetch"
 )
 
 func produceRecords(t *testing.T, n int, addr net.Addr, topic string, compression compress.Codec) []Record {
@@ -73,6 +76,100 @@ func TestClientFetch(t *testing.T) {
 	})
 }
 
+func TestClientFetchByNameSendsNoMetadataRequest(t *testing.T) {
+	var requests []protocol.ApiKey
+
+	client := &Client{
+		Addr: TCP("localhost:9092"),
+		Transport: roundTripFunc(func(ctx context.Context, addr net.Addr, msg protocol.Message) (protocol.Message, error) {
+			requests = append(requests, msg.ApiKey())
+			return &fetchAPI.Response{
+				Topics: []fetchAPI.ResponseTopic{{
+					Topic:      "topic-1",
+					Partitions: []fetchAPI.ResponsePartition{{Partition: 0}},
+				}},
+			}, nil
+		}),
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.Fetch(context.Background(), &FetchRequest{Topic: "topic-1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if want := []protocol.ApiKey{protocol.Fetch, protocol.Fetch, protocol.Fetch}; !reflect.DeepEqual(requests, want) {
+		t.Errorf("requests mismatch: %v != %v", requests, want)
+	}
+}
+
+func TestClientFetchAfterLeaderMove(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cluster, err := kafkatest.NewCluster(kafkatest.Config{Brokers: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cluster.Close()
+
+	const topic = "moved"
+	if err := cluster.CreateTopic(topic, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	var versions []int16
+	transport := &Transport{
+		// The metadata must be refreshed by the errors reported in fetch
+		// responses, not by the cache expiring.
+		MetadataTTL: time.Hour,
+		Hooks: &TransportHooks{
+			AfterRoundTrip: func(ctx context.Context, info RoundTripInfo) {
+				if info.ApiKey == protocol.Fetch {
+					versions = append(versions, info.Version)
+				}
+			},
+		},
+	}
+	defer transport.CloseIdleConnections()
+
+	client := &Client{Addr: cluster.Addr(), Transport: transport}
+	fetch := func() error {
+		res, err := client.Fetch(ctx, &FetchRequest{Topic: topic, MaxBytes: 1e6})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.Error
+	}
+
+	if err := fetch(); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := client.Metadata(ctx, &MetadataRequest{Topics: []string{topic}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cluster.MoveLeader(topic, 0, 1-res.Topics[0].Partitions[0].Leader.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first fetch is routed to the previous leader, the error that it
+	// reports refreshes the metadata of the topic.
+	if err := fetch(); !errors.Is(err, NotLeaderForPartition) {
+		t.Fatalf("expected %v, got %v", NotLeaderForPartition, err)
+	}
+	if err := fetch(); err != nil {
+		t.Fatalf("the fetch request was not routed to the new leader: %v", err)
+	}
+
+	for _, v := range versions {
+		if v < 13 {
+			t.Fatalf("expected topics to be identified by ID in fetch requests, got v%d", v)
+		}
+	}
+}
+
 func TestClientFetchCompressed(t *testing.T) {
 	client, topic, shutdown := newLocalClientAndTopic()
 	defer shutdown()
@@ -15,6 +15,13 @@ type Topic struct {
 	// Name of the topic.
 	Name string
 
+	// Unique identifier of the topic, which changes when a topic is deleted
+	// and recreated with the same name.
+	//
+	// The ID is zero if the kafka broker does not support topic IDs (Metadata
+	// v10 and above).
+	ID protocol.UUID
+
 	// True if the topic is internal.
 	Internal bool
 
@@ -50,6 +57,13 @@ type Partition struct {
 	// Available only with metadata API level >= 6:
 	OfflineReplicas []Broker
 
+	// Epoch of the partition leader, incremented by kafka every time a new
+	// leader is elected for the partition.
+	//
+	// Available only with metadata API level >= 7, the value is -1 when the
+	// partition was read from a connection to an older broker.
+	LeaderEpoch int
+
 	// An error that may have occurred while attempting to read the partition
 	// metadata.
 	//
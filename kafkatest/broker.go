@@ -0,0 +1,143 @@
+package kafkatest
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/PerchSecurity/kafka-go/protocol"
+	_ "github.com/PerchSecurity/kafka-go/protocol/apiversions"
+)
+
+// Error codes of the kafka protocol used by the brokers, the values match the
+// constants of the kafka.Error type.
+const (
+	offsetOutOfRange          int16 = 1
+	corruptMessage            int16 = 2
+	unknownTopicOrPartition   int16 = 3
+	notLeaderForPartition     int16 = 6
+	notCoordinator            int16 = 16
+	invalidTopic              int16 = 17
+	illegalGeneration         int16 = 22
+	inconsistentGroupProtocol int16 = 23
+	invalidGroupID            int16 = 24
+	unknownMemberID           int16 = 25
+	invalidSessionTimeout     int16 = 26
+	rebalanceInProgress       int16 = 27
+	topicAlreadyExists        int16 = 36
+	invalidPartitions         int16 = 37
+	invalidReplicationFactor  int16 = 38
+	fencedLeaderEpoch         int16 = 74
+	unknownLeaderEpoch        int16 = 75
+	unknownTopicID            int16 = 100
+)
+
+// handlerFunc is the signature of functions handling requests received by
+// brokers. Handlers return a nil response when the request could not be
+// processed, which closes the client connection.
+type handlerFunc func(b *broker, version int16, clientID string, req protocol.Message) protocol.Message
+
+// handlers maps the API keys supported by the brokers to their handler,
+// ApiVersions requests are served by protocol.Server.
+var handlers = map[protocol.ApiKey]handlerFunc{
+	protocol.Metadata:             (*broker).metadata,
+	protocol.CreateTopics:         (*broker).createTopics,
+	protocol.DeleteTopics:         (*broker).deleteTopics,
+	protocol.Produce:              (*broker).produce,
+	protocol.Fetch:                (*broker).fetch,
+	protocol.ListOffsets:          (*broker).listOffsets,
+	protocol.OffsetForLeaderEpoch: (*broker).offsetForLeaderEpoch,
+	protocol.FindCoordinator:      (*broker).findCoordinator,
+	protocol.JoinGroup:            (*broker).joinGroup,
+	protocol.SyncGroup:            (*broker).syncGroup,
+	protocol.Heartbeat:            (*broker).heartbeat,
+	protocol.LeaveGroup:           (*broker).leaveGroup,
+	protocol.OffsetCommit:         (*broker).offsetCommit,
+	protocol.OffsetFetch:          (*broker).offsetFetch,
+}
+
+// apiVersions returns the versions of the APIs advertised by the brokers.
+func apiVersions() []protocol.ApiVersion {
+	versions := make([]protocol.ApiVersion, 0, len(handlers))
+	for apiKey := range handlers {
+		versions = append(versions, protocol.ApiVersion{
+			ApiKey:     apiKey,
+			MinVersion: apiKey.MinVersion(),
+			MaxVersion: apiKey.MaxVersion(),
+		})
+	}
+	return versions
+}
+
+var errBrokerClosed = errors.New("kafkatest: broker closed")
+
+type broker struct {
+	id      int32
+	host    string
+	port    int32
+	cluster *Cluster
+	server  protocol.Server
+}
+
+func newBroker(c *Cluster, id int32, l net.Listener) *broker {
+	addr := l.Addr().(*net.TCPAddr)
+	b := &broker{
+		id:      id,
+		host:    addr.IP.String(),
+		port:    int32(addr.Port),
+		cluster: c,
+	}
+	b.server.Handler = b
+	b.server.ApiVersions = apiVersions()
+	return b
+}
+
+func (b *broker) addr() string {
+	return net.JoinHostPort(b.host, strconv.Itoa(int(b.port)))
+}
+
+// ServeKafka satisfies the protocol.Handler interface.
+func (b *broker) ServeKafka(ctx context.Context, req *protocol.ServerRequest) (protocol.Message, error) {
+	apiKey := req.Message.ApiKey()
+
+	res := handlers[apiKey](b, req.Version, req.ClientID, req.Message)
+	if res == nil {
+		return nil, errBrokerClosed
+	}
+
+	b.cluster.mutex.Lock()
+	throttle := b.cluster.throttles[apiKey]
+	b.cluster.mutex.Unlock()
+
+	if throttle > 0 && setThrottleTime(res, throttle) && !apiKey.ClientSideThrottling(req.Version) {
+		// Older versions of the APIs expect brokers to delay responses by the
+		// throttle time.
+		timer := time.NewTimer(throttle)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return res, nil
+}
+
+// setThrottleTime sets the throttle time field of the response, returning
+// false if it has none.
+func setThrottleTime(res protocol.Message, throttle time.Duration) bool {
+	v := reflect.ValueOf(res).Elem()
+
+	for _, name := range [...]string{"ThrottleTimeMs", "ThrottleTimeMS", "ThrottleTime"} {
+		if f := v.FieldByName(name); f.IsValid() && f.Kind() == reflect.Int32 {
+			f.SetInt(int64(throttle / time.Millisecond))
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,414 @@
+// Package kafkatest implements in-memory kafka brokers to test programs using
+// kafka-go without running a kafka cluster.
+//
+// A Cluster listens on loopback addresses and speaks the kafka wire protocol,
+// it supports producing and fetching messages, listing offsets, consumer
+// groups, committing offsets, and creating or deleting topics. Tests can also
+// inject faults in the cluster: moving partition leaders, throttling clients,
+// and responding with error codes.
+//
+//	cluster, err := kafkatest.NewCluster(kafkatest.Config{Brokers: 3})
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer cluster.Close()
+//
+//	if err := cluster.CreateTopic("topic-A", 3); err != nil {
+//		t.Fatal(err)
+//	}
+//
+//	w := &kafka.Writer{
+//		Addr:  cluster.Addr(),
+//		Topic: "topic-A",
+//	}
+//
+// Records are kept in memory for the lifetime of the cluster, there is no
+// replication, retention, compaction, or support for transactions.
+package kafkatest
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PerchSecurity/kafka-go/protocol"
+)
+
+// Config carries the configuration of a Cluster.
+type Config struct {
+	// Number of brokers in the cluster, the brokers have IDs starting at zero.
+	//
+	// Default: 1
+	Brokers int
+
+	// ID of the cluster reported in metadata responses.
+	//
+	// Default: "kafkatest"
+	ClusterID string
+
+	// When true, topics are created when metadata requests allowing automatic
+	// topic creation reference them.
+	AutoCreateTopics bool
+
+	// Number of partitions of topics created automatically, or by requests
+	// which do not specify the number of partitions.
+	//
+	// Default: 1
+	DefaultPartitions int
+}
+
+// Cluster is an in-memory implementation of a kafka cluster.
+//
+// Cluster values are safe to use concurrently from multiple goroutines.
+type Cluster struct {
+	clusterID         string
+	autoCreateTopics  bool
+	defaultPartitions int
+	brokers           []*broker
+	done              chan struct{}
+	join              sync.WaitGroup
+
+	mutex     sync.Mutex
+	closed    bool
+	topics    map[string]*topic
+	groups    map[string]*group
+	faults    map[protocol.ApiKey]*fault
+	throttles map[protocol.ApiKey]time.Duration
+	// Closed and replaced when records are appended to partitions or leaders
+	// change, so long-polling fetch requests can wait for it.
+	changed chan struct{}
+}
+
+type fault struct {
+	errorCode int16
+	count     int
+}
+
+// NewCluster starts a cluster configured with config. The program must call
+// Close to release the resources held by the cluster when it does not need it
+// anymore.
+func NewCluster(config Config) (*Cluster, error) {
+	if config.Brokers <= 0 {
+		config.Brokers = 1
+	}
+	if config.ClusterID == "" {
+		config.ClusterID = "kafkatest"
+	}
+	if config.DefaultPartitions <= 0 {
+		config.DefaultPartitions = 1
+	}
+
+	c := &Cluster{
+		clusterID:         config.ClusterID,
+		autoCreateTopics:  config.AutoCreateTopics,
+		defaultPartitions: config.DefaultPartitions,
+		done:              make(chan struct{}),
+		topics:            make(map[string]*topic),
+		groups:            make(map[string]*group),
+		faults:            make(map[protocol.ApiKey]*fault),
+		throttles:         make(map[protocol.ApiKey]time.Duration),
+		changed:           make(chan struct{}),
+	}
+
+	listeners := make([]net.Listener, 0, config.Brokers)
+
+	for i := 0; i < config.Brokers; i++ {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, fmt.Errorf("kafkatest: starting broker %d: %w", i, err)
+		}
+		c.brokers = append(c.brokers, newBroker(c, int32(i), l))
+		listeners = append(listeners, l)
+	}
+
+	for i, b := range c.brokers {
+		c.join.Add(1)
+		go func(b *broker, l net.Listener) {
+			defer c.join.Done()
+			b.server.Serve(l)
+		}(b, listeners[i])
+	}
+
+	return c, nil
+}
+
+// Close stops the brokers of c and closes the connections of their clients.
+func (c *Cluster) Close() error {
+	c.mutex.Lock()
+	if c.closed {
+		c.mutex.Unlock()
+		return nil
+	}
+	c.closed = true
+	close(c.done)
+	c.mutex.Unlock()
+
+	for _, b := range c.brokers {
+		b.server.Close()
+	}
+
+	c.join.Wait()
+	return nil
+}
+
+// Addr returns the network address of the cluster, listing all of its brokers.
+// The value can be used as the address of kafka.Client or kafka.Writer values.
+func (c *Cluster) Addr() net.Addr {
+	return clusterAddr(c.Brokers())
+}
+
+// Brokers returns the addresses of the brokers of c, indexed by broker ID.
+// The value can be used as the list of brokers of kafka.Reader values.
+func (c *Cluster) Brokers() []string {
+	addrs := make([]string, len(c.brokers))
+	for i, b := range c.brokers {
+		addrs[i] = b.addr()
+	}
+	return addrs
+}
+
+// clusterAddr is a net.Addr listing multiple addresses in the comma-separated
+// format used by kafka.TCP.
+type clusterAddr []string
+
+func (a clusterAddr) Network() string {
+	networks := make([]string, len(a))
+	for i := range networks {
+		networks[i] = "tcp"
+	}
+	return strings.Join(networks, ",")
+}
+
+func (a clusterAddr) String() string { return strings.Join(a, ",") }
+
+// CreateTopic creates a topic with the given number of partitions. The leaders
+// of the partitions are spread across the brokers of the cluster.
+func (c *Cluster) CreateTopic(name string, partitions int) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, exists := c.topics[name]; exists {
+		return fmt.Errorf("kafkatest: topic %q already exists", name)
+	}
+	if partitions <= 0 {
+		return fmt.Errorf("kafkatest: invalid number of partitions for topic %q: %d", name, partitions)
+	}
+
+	c.createTopic(name, partitions)
+	return nil
+}
+
+// DeleteTopic deletes a topic and its records.
+func (c *Cluster) DeleteTopic(name string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, exists := c.topics[name]; !exists {
+		return fmt.Errorf("kafkatest: topic %q does not exist", name)
+	}
+
+	delete(c.topics, name)
+	c.notify()
+	return nil
+}
+
+// Topics returns the sorted list of topic names existing in the cluster.
+func (c *Cluster) Topics() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	names := make([]string, 0, len(c.topics))
+	for name := range c.topics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HighWatermark returns the offset of the next record produced to a topic
+// partition.
+func (c *Cluster) HighWatermark(topic string, partition int) (int64, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	p, err := c.partition(topic, partition)
+	if err != nil {
+		return -1, err
+	}
+	return p.highWatermark(), nil
+}
+
+// CommittedOffset returns the offset committed by a consumer group on a topic
+// partition, or -1 if the group did not commit any.
+func (c *Cluster) CommittedOffset(group, topic string, partition int) int64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if g := c.groups[group]; g != nil {
+		if o, ok := g.offsets[topicPartition{topic, int32(partition)}]; ok {
+			return o.offset
+		}
+	}
+	return -1
+}
+
+// MoveLeader elects the broker with the given ID as leader of a topic
+// partition and increments the leader epoch of the partition.
+//
+// The previous leader responds to produce, fetch, and list offsets requests
+// for the partition with NotLeaderForPartition errors, clients have to
+// refresh their metadata to find the new leader.
+func (c *Cluster) MoveLeader(topic string, partition int, brokerID int) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if brokerID < 0 || brokerID >= len(c.brokers) {
+		return fmt.Errorf("kafkatest: broker %d does not exist", brokerID)
+	}
+
+	p, err := c.partition(topic, partition)
+	if err != nil {
+		return err
+	}
+
+	p.leader = int32(brokerID)
+	p.epoch++
+	p.epochs = append(p.epochs, epochStart{epoch: p.epoch, offset: p.highWatermark()})
+	c.notify()
+	return nil
+}
+
+// Throttle makes the cluster throttle all responses to requests with the
+// given API key for the given duration, which simulates clients exceeding
+// their quotas. A zero duration stops throttling the requests.
+//
+// As brokers do, responses to recent versions of the APIs carry the throttle
+// time and are sent immediately, clients are expected to wait before sending
+// more requests (KIP-219). Responses to older versions are delayed by the
+// throttle time instead.
+func (c *Cluster) Throttle(apiKey protocol.ApiKey, throttle time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if throttle > 0 {
+		c.throttles[apiKey] = throttle
+	} else {
+		delete(c.throttles, apiKey)
+	}
+}
+
+// InjectError makes the cluster respond to the next count requests with the
+// given API key with an error code, instead of processing them. The error code
+// is set on all topics and partitions of the responses, or on the responses
+// themselves for APIs which have no topics.
+//
+// The error codes are defined by the kafka.Error type, for example:
+//
+//	cluster.InjectError(protocol.Produce, int16(kafka.NotEnoughReplicas), 2)
+//
+// A count of zero or less removes the error injected for the API key. The
+// brokers always respond to ApiVersions requests, errors cannot be injected in
+// their responses.
+func (c *Cluster) InjectError(apiKey protocol.ApiKey, errorCode int16, count int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if count > 0 {
+		c.faults[apiKey] = &fault{errorCode: errorCode, count: count}
+	} else {
+		delete(c.faults, apiKey)
+	}
+}
+
+// takeFault returns the error code injected for apiKey, if any. The method
+// must be called with the cluster mutex held.
+func (c *Cluster) takeFault(apiKey protocol.ApiKey) int16 {
+	f := c.faults[apiKey]
+	if f == nil {
+		return 0
+	}
+	if f.count--; f.count <= 0 {
+		delete(c.faults, apiKey)
+	}
+	return f.errorCode
+}
+
+// notify wakes up the requests waiting for changes in the cluster. The method
+// must be called with the cluster mutex held.
+func (c *Cluster) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// wait blocks until the cluster changes, the deadline is reached, or the
+// cluster is closed. The method must be called with the cluster mutex held,
+// which it releases while waiting.
+func (c *Cluster) wait(deadline time.Time) {
+	changed := c.changed
+	c.mutex.Unlock()
+	defer c.mutex.Lock()
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+
+	select {
+	case <-changed:
+	case <-timer.C:
+	case <-c.done:
+	}
+}
+
+var errUnknownTopicOrPartition = errors.New("kafkatest: unknown topic or partition")
+
+func (c *Cluster) partition(topic string, partition int) (*partition, error) {
+	t := c.topics[topic]
+	if t == nil || partition < 0 || partition >= len(t.partitions) {
+		return nil, fmt.Errorf("%w: %s/%d", errUnknownTopicOrPartition, topic, partition)
+	}
+	return t.partitions[partition], nil
+}
+
+func (c *Cluster) createTopic(name string, partitions int) *topic {
+	t := &topic{name: name, id: protocol.NewUUID(), partitions: make([]*partition, partitions)}
+	for i := range t.partitions {
+		replicas := make([]int32, len(c.brokers))
+		for j := range replicas {
+			replicas[j] = int32((i + j) % len(c.brokers))
+		}
+		t.partitions[i] = &partition{
+			leader:   replicas[0],
+			replicas: replicas,
+			epochs:   []epochStart{{epoch: 0, offset: 0}},
+		}
+	}
+	c.topics[name] = t
+	return t
+}
+
+func (c *Cluster) topicByID(id protocol.UUID) *topic {
+	if id.IsZero() {
+		return nil
+	}
+	for _, t := range c.topics {
+		if t.id == id {
+			return t
+		}
+	}
+	return nil
+}
+
+// coordinator returns the broker coordinating the given consumer group.
+func (c *Cluster) coordinator(group string) *broker {
+	h := uint32(2166136261)
+	for i := 0; i < len(group); i++ {
+		h = (h ^ uint32(group[i])) * 16777619
+	}
+	return c.brokers[h%uint32(len(c.brokers))]
+}
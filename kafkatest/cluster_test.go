@@ -0,0 +1,380 @@
+package kafkatest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/PerchSecurity/kafka-go"
+	"github.com/PerchSecurity/kafka-go/kafkatest"
+	"github.com/PerchSecurity/kafka-go/protocol"
+)
+
+func newCluster(t *testing.T, config kafkatest.Config) *kafkatest.Cluster {
+	t.Helper()
+
+	cluster, err := kafkatest.NewCluster(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cluster.Close() })
+	return cluster
+}
+
+func makeMessages(n int) []kafka.Message {
+	msgs := make([]kafka.Message, n)
+	for i := range msgs {
+		msgs[i] = kafka.Message{
+			Key:     []byte(fmt.Sprintf("key-%d", i)),
+			Value:   []byte(fmt.Sprintf("value-%d", i)),
+			Headers: []kafka.Header{{Key: "index", Value: []byte(fmt.Sprint(i))}},
+		}
+	}
+	return msgs
+}
+
+func TestClusterWriterReader(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cluster := newCluster(t, kafkatest.Config{Brokers: 3})
+	if err := cluster.CreateTopic("topic-A", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	w := &kafka.Writer{
+		Addr:         cluster.Addr(),
+		Topic:        "topic-A",
+		RequiredAcks: kafka.RequireOne,
+		BatchTimeout: time.Millisecond,
+	}
+	defer w.Close()
+
+	if err := w.WriteMessages(ctx, makeMessages(10)...); err != nil {
+		t.Fatal(err)
+	}
+
+	if offset, err := cluster.HighWatermark("topic-A", 0); err != nil {
+		t.Fatal(err)
+	} else if offset != 10 {
+		t.Fatalf("expected a high watermark of 10, got %d", offset)
+	}
+
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: cluster.Brokers(),
+		Topic:   "topic-A",
+		MaxWait: 100 * time.Millisecond,
+	})
+	defer r.Close()
+
+	if err := r.SetOffset(3); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 3; i < 10; i++ {
+		msg, err := r.ReadMessage(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg.Offset != int64(i) {
+			t.Errorf("expected offset %d, got %d", i, msg.Offset)
+		}
+		if value := fmt.Sprintf("value-%d", i); string(msg.Value) != value {
+			t.Errorf("expected value %q, got %q", value, msg.Value)
+		}
+		if len(msg.Headers) != 1 || string(msg.Headers[0].Value) != fmt.Sprint(i) {
+			t.Errorf("unexpected headers: %+v", msg.Headers)
+		}
+	}
+}
+
+func TestClusterConsumerGroup(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cluster := newCluster(t, kafkatest.Config{Brokers: 2})
+	if err := cluster.CreateTopic("topic-A", 2); err != nil {
+		t.Fatal(err)
+	}
+
+	w := &kafka.Writer{
+		Addr:         cluster.Addr(),
+		Topic:        "topic-A",
+		Balancer:     &kafka.RoundRobin{},
+		RequiredAcks: kafka.RequireOne,
+		BatchTimeout: time.Millisecond,
+	}
+	defer w.Close()
+
+	if err := w.WriteMessages(ctx, makeMessages(4)...); err != nil {
+		t.Fatal(err)
+	}
+
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: cluster.Brokers(),
+		Topic:   "topic-A",
+		GroupID: "group-A",
+		MaxWait: 100 * time.Millisecond,
+	})
+	defer r.Close()
+
+	for i := 0; i < 4; i++ {
+		if _, err := r.ReadMessage(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for partition := 0; partition < 2; partition++ {
+		if offset := cluster.CommittedOffset("group-A", "topic-A", partition); offset != 2 {
+			t.Errorf("partition %d: expected committed offset 2, got %d", partition, offset)
+		}
+	}
+}
+
+func TestClusterCreateTopics(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cluster := newCluster(t, kafkatest.Config{Brokers: 3})
+	client := &kafka.Client{Addr: cluster.Addr()}
+
+	res, err := client.CreateTopics(ctx, &kafka.CreateTopicsRequest{
+		Topics: []kafka.TopicConfig{
+			{Topic: "topic-A", NumPartitions: 3, ReplicationFactor: 3},
+			{Topic: "topic-B", NumPartitions: 1, ReplicationFactor: 4},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := res.Errors["topic-A"]; err != nil {
+		t.Error(err)
+	}
+	if err := res.Errors["topic-B"]; !errors.Is(err, kafka.InvalidReplicationFactor) {
+		t.Errorf("expected an invalid replication factor error, got %v", err)
+	}
+
+	meta, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: []string{"topic-A"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(meta.Topics) != 1 || len(meta.Topics[0].Partitions) != 3 {
+		t.Fatalf("unexpected metadata: %+v", meta.Topics)
+	}
+	for i, p := range meta.Topics[0].Partitions {
+		if p.Leader.ID != i {
+			t.Errorf("partition %d: expected leader %d, got %d", i, i, p.Leader.ID)
+		}
+	}
+}
+
+func TestClusterTopicIDs(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cluster := newCluster(t, kafkatest.Config{})
+	if err := cluster.CreateTopic("topic-A", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	w := &kafka.Writer{
+		Addr:         cluster.Addr(),
+		Topic:        "topic-A",
+		RequiredAcks: kafka.RequireOne,
+		BatchTimeout: time.Millisecond,
+	}
+	defer w.Close()
+
+	if err := w.WriteMessages(ctx, makeMessages(3)...); err != nil {
+		t.Fatal(err)
+	}
+
+	client := &kafka.Client{Addr: cluster.Addr()}
+
+	meta, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: []string{"topic-A"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	topicID := meta.Topics[0].ID
+	if topicID.IsZero() {
+		t.Fatal("expected the topic to have an ID")
+	}
+
+	res, err := client.Fetch(ctx, &kafka.FetchRequest{TopicID: topicID, MaxBytes: 1e6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Error != nil {
+		t.Fatal(res.Error)
+	}
+	if res.Topic != "topic-A" || res.TopicID != topicID {
+		t.Errorf("unexpected topic: %s (%s)", res.Topic, res.TopicID)
+	}
+	if res.HighWatermark != 3 {
+		t.Errorf("expected a high watermark of 3, got %d", res.HighWatermark)
+	}
+
+	del, err := client.DeleteTopics(ctx, &kafka.DeleteTopicsRequest{
+		TopicIDs: []protocol.UUID{topicID, protocol.NewUUID()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(del.TopicIDErrors) != 2 {
+		t.Fatalf("unexpected errors: %v", del.TopicIDErrors)
+	}
+	for id, err := range del.TopicIDErrors {
+		switch {
+		case id == topicID && err != nil:
+			t.Errorf("deleting %s: %v", id, err)
+		case id != topicID && !errors.Is(err, kafka.UnknownTopicID):
+			t.Errorf("expected an unknown topic id error, got %v", err)
+		}
+	}
+
+	// A topic recreated with the same name must not be mistaken for the
+	// deleted one.
+	if err := cluster.CreateTopic("topic-A", 1); err != nil {
+		t.Fatal(err)
+	}
+	res, err = client.Fetch(ctx, &kafka.FetchRequest{Topic: "topic-A", TopicID: topicID, MaxBytes: 1e6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(res.Error, kafka.UnknownTopicID) {
+		t.Errorf("expected an unknown topic id error, got %v", res.Error)
+	}
+}
+
+func TestClusterAutoCreateTopics(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cluster := newCluster(t, kafkatest.Config{AutoCreateTopics: true, DefaultPartitions: 4})
+
+	w := &kafka.Writer{
+		Addr:                   cluster.Addr(),
+		Topic:                  "topic-A",
+		RequiredAcks:           kafka.RequireOne,
+		BatchTimeout:           time.Millisecond,
+		AllowAutoTopicCreation: true,
+	}
+	defer w.Close()
+
+	if err := w.WriteMessages(ctx, makeMessages(1)...); err != nil {
+		t.Fatal(err)
+	}
+
+	if topics := cluster.Topics(); len(topics) != 1 || topics[0] != "topic-A" {
+		t.Errorf("unexpected topics: %q", topics)
+	}
+}
+
+func TestClusterMoveLeader(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cluster := newCluster(t, kafkatest.Config{Brokers: 2})
+	if err := cluster.CreateTopic("topic-A", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	w := &kafka.Writer{
+		Addr:         cluster.Addr(),
+		Topic:        "topic-A",
+		RequiredAcks: kafka.RequireOne,
+		BatchTimeout: time.Millisecond,
+		BatchSize:    1,
+		Transport:    &kafka.Transport{MetadataTTL: time.Hour},
+	}
+	defer w.Close()
+
+	if err := w.WriteMessages(ctx, makeMessages(1)...); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cluster.MoveLeader("topic-A", 0, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first attempt is sent to the previous leader, the writer retries
+	// after refreshing its metadata.
+	if err := w.WriteMessages(ctx, makeMessages(1)...); err != nil {
+		t.Fatal(err)
+	}
+
+	if offset, _ := cluster.HighWatermark("topic-A", 0); offset != 2 {
+		t.Errorf("expected a high watermark of 2, got %d", offset)
+	}
+	if stats := w.Stats(); stats.Retries == 0 {
+		t.Error("expected the writer to retry after the leader moved")
+	}
+}
+
+func TestClusterInjectError(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cluster := newCluster(t, kafkatest.Config{})
+	if err := cluster.CreateTopic("topic-A", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	w := &kafka.Writer{
+		Addr:         cluster.Addr(),
+		Topic:        "topic-A",
+		RequiredAcks: kafka.RequireOne,
+		BatchTimeout: time.Millisecond,
+		MaxAttempts:  1,
+	}
+	defer w.Close()
+
+	cluster.InjectError(protocol.Produce, int16(kafka.NotEnoughReplicas), 1)
+
+	var errs kafka.WriteErrors
+	if err := w.WriteMessages(ctx, makeMessages(1)...); !errors.As(err, &errs) || !errors.Is(errs[0], kafka.NotEnoughReplicas) {
+		t.Fatalf("expected a not enough replicas error, got %v", err)
+	}
+
+	if err := w.WriteMessages(ctx, makeMessages(1)...); err != nil {
+		t.Fatal(err)
+	}
+
+	if offset, _ := cluster.HighWatermark("topic-A", 0); offset != 1 {
+		t.Errorf("expected a high watermark of 1, got %d", offset)
+	}
+}
+
+func TestClusterThrottle(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cluster := newCluster(t, kafkatest.Config{})
+	if err := cluster.CreateTopic("topic-A", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	w := &kafka.Writer{
+		Addr:         cluster.Addr(),
+		Topic:        "topic-A",
+		RequiredAcks: kafka.RequireOne,
+		BatchTimeout: time.Millisecond,
+	}
+	defer w.Close()
+
+	cluster.Throttle(protocol.Produce, 50*time.Millisecond)
+
+	if err := w.WriteMessages(ctx, makeMessages(1)...); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := w.Stats()
+	if stats.Throttles != 1 {
+		t.Errorf("expected 1 throttle, got %d", stats.Throttles)
+	}
+	if stats.ThrottleTime.Max != 50*time.Millisecond {
+		t.Errorf("expected a throttle time of 50ms, got %s", stats.ThrottleTime.Max)
+	}
+}
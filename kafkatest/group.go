@@ -0,0 +1,652 @@
+package kafkatest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"time"
+
+	"github.com/PerchSecurity/kafka-go/protocol"
+	"github.com/PerchSecurity/kafka-go/protocol/findcoordinator"
+	"github.com/PerchSecurity/kafka-go/protocol/heartbeat"
+	"github.com/PerchSecurity/kafka-go/protocol/joingroup"
+	"github.com/PerchSecurity/kafka-go/protocol/leavegroup"
+	"github.com/PerchSecurity/kafka-go/protocol/offsetcommit"
+	"github.com/PerchSecurity/kafka-go/protocol/offsetfetch"
+	"github.com/PerchSecurity/kafka-go/protocol/syncgroup"
+)
+
+type groupState int
+
+const (
+	groupEmpty groupState = iota
+	groupPreparingRebalance
+	groupCompletingRebalance
+	groupStable
+)
+
+// group is the state of a consumer group, all fields are protected by the
+// cluster mutex.
+type group struct {
+	id           string
+	state        groupState
+	generation   int32
+	protocolType string
+	protocol     string
+	leader       string
+	members      map[string]*member
+	offsets      map[topicPartition]committedOffset
+
+	// Fired when the members which did not rejoin the group in time are
+	// removed from it, the counter detects timers which fired after being
+	// stopped.
+	rebalanceTimer *time.Timer
+	rebalances     int
+
+	// Closed when the leader sent the assignments of the generation, or
+	// when a rebalance started before that.
+	synced chan struct{}
+}
+
+type member struct {
+	id               string
+	sessionTimeout   time.Duration
+	rebalanceTimeout time.Duration
+	protocols        []joingroup.RequestProtocol
+	assignment       []byte
+
+	// Receives the response to the last JoinGroup request of the member,
+	// when the join phase of the rebalance completes. The channel is nil
+	// when the member is not waiting to join the group.
+	joined chan *joingroup.Response
+
+	sessionTimer *time.Timer
+	sessions     int
+}
+
+type topicPartition struct {
+	topic     string
+	partition int32
+}
+
+type committedOffset struct {
+	offset   int64
+	metadata string
+}
+
+func newMemberID(clientID string) string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return clientID + "-" + hex.EncodeToString(b)
+}
+
+// rebalance moves g to the preparing rebalance state, all members have to
+// rejoin the group before their rebalance timeout expires.
+func (c *Cluster) rebalance(g *group) {
+	if g.state == groupPreparingRebalance {
+		c.maybeCompleteJoin(g)
+		return
+	}
+
+	g.state = groupPreparingRebalance
+	g.rebalances++
+	if g.synced != nil {
+		close(g.synced)
+		g.synced = nil
+	}
+
+	timeout := time.Duration(0)
+	for _, m := range g.members {
+		if m.rebalanceTimeout > timeout {
+			timeout = m.rebalanceTimeout
+		}
+	}
+
+	rebalances := g.rebalances
+	g.rebalanceTimer = time.AfterFunc(timeout, func() {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+
+		if g.state == groupPreparingRebalance && g.rebalances == rebalances {
+			c.completeJoin(g)
+		}
+	})
+
+	c.maybeCompleteJoin(g)
+}
+
+func (c *Cluster) maybeCompleteJoin(g *group) {
+	for _, m := range g.members {
+		if m.joined == nil {
+			return
+		}
+	}
+	c.completeJoin(g)
+}
+
+// completeJoin ends the join phase of a rebalance, members which did not
+// rejoin are removed from the group, and the others receive their responses.
+func (c *Cluster) completeJoin(g *group) {
+	if g.rebalanceTimer != nil {
+		g.rebalanceTimer.Stop()
+		g.rebalanceTimer = nil
+	}
+
+	for _, m := range g.members {
+		if m.joined == nil {
+			c.removeMember(g, m)
+		}
+	}
+
+	g.generation++
+
+	if len(g.members) == 0 {
+		g.state = groupEmpty
+		g.protocolType = ""
+		g.protocol = ""
+		g.leader = ""
+		return
+	}
+
+	memberIDs := make([]string, 0, len(g.members))
+	for id := range g.members {
+		memberIDs = append(memberIDs, id)
+	}
+	sort.Strings(memberIDs)
+
+	if g.members[g.leader] == nil {
+		g.leader = memberIDs[0]
+	}
+
+	g.protocol = ""
+	for _, p := range g.members[g.leader].protocols {
+		if g.supportsProtocol(p.Name) {
+			g.protocol = p.Name
+			break
+		}
+	}
+
+	g.state = groupCompletingRebalance
+	g.synced = make(chan struct{})
+
+	leaderMembers := make([]joingroup.ResponseMember, 0, len(memberIDs))
+	for _, id := range memberIDs {
+		leaderMembers = append(leaderMembers, joingroup.ResponseMember{
+			MemberID: id,
+			Metadata: g.members[id].protocolMetadata(g.protocol),
+		})
+	}
+
+	for _, id := range memberIDs {
+		m := g.members[id]
+		res := &joingroup.Response{
+			GenerationID: g.generation,
+			ProtocolType: g.protocolType,
+			ProtocolName: g.protocol,
+			LeaderID:     g.leader,
+			MemberID:     id,
+			Members:      []joingroup.ResponseMember{},
+		}
+		if id == g.leader {
+			res.Members = leaderMembers
+		}
+		m.assignment = nil
+		m.joined <- res
+		m.joined = nil
+		c.heartbeat(g, m)
+	}
+}
+
+func (g *group) supportsProtocol(name string) bool {
+	for _, m := range g.members {
+		if m.protocolMetadata(name) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *member) protocolMetadata(name string) []byte {
+	for _, p := range m.protocols {
+		if p.Name == name {
+			if p.Metadata == nil {
+				return []byte{}
+			}
+			return p.Metadata
+		}
+	}
+	return nil
+}
+
+// heartbeat resets the session timer of m, the member is removed from the
+// group if it does not send another heartbeat before its session times out.
+func (c *Cluster) heartbeat(g *group, m *member) {
+	if m.sessionTimer != nil {
+		m.sessionTimer.Stop()
+	}
+
+	m.sessions++
+	sessions := m.sessions
+	m.sessionTimer = time.AfterFunc(m.sessionTimeout, func() {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+
+		if g.members[m.id] == m && m.sessions == sessions {
+			c.removeMember(g, m)
+			c.rebalance(g)
+		}
+	})
+}
+
+func (c *Cluster) removeMember(g *group, m *member) {
+	if m.sessionTimer != nil {
+		m.sessionTimer.Stop()
+		m.sessionTimer = nil
+	}
+	if m.joined != nil {
+		m.joined <- &joingroup.Response{ErrorCode: unknownMemberID, GenerationID: -1}
+		m.joined = nil
+	}
+	delete(g.members, m.id)
+}
+
+// waitGroup releases the cluster mutex while waiting for ch to be ready or the
+// cluster to be closed, returning false in the latter case.
+func (c *Cluster) waitGroup(ch <-chan struct{}) bool {
+	c.mutex.Unlock()
+	defer c.mutex.Lock()
+
+	select {
+	case <-ch:
+		return true
+	case <-c.done:
+		return false
+	}
+}
+
+func (b *broker) findCoordinator(version int16, clientID string, msg protocol.Message) protocol.Message {
+	req := msg.(*findcoordinator.Request)
+	c := b.cluster
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	res := &findcoordinator.Response{NodeID: -1, Port: -1}
+
+	if res.ErrorCode = c.takeFault(protocol.FindCoordinator); res.ErrorCode == 0 {
+		coordinator := c.coordinator(req.Key)
+		res.NodeID = coordinator.id
+		res.Host = coordinator.host
+		res.Port = coordinator.port
+	}
+
+	return res
+}
+
+// checkCoordinator returns the error code of group requests sent to b.
+func (b *broker) checkCoordinator(groupID string) int16 {
+	switch {
+	case groupID == "":
+		return invalidGroupID
+	case b.cluster.coordinator(groupID) != b:
+		return notCoordinator
+	default:
+		return 0
+	}
+}
+
+func (b *broker) joinGroup(version int16, clientID string, msg protocol.Message) protocol.Message {
+	req := msg.(*joingroup.Request)
+	c := b.cluster
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	res := &joingroup.Response{GenerationID: -1, MemberID: req.MemberID}
+
+	if res.ErrorCode = c.takeFault(protocol.JoinGroup); res.ErrorCode != 0 {
+		return res
+	}
+	if res.ErrorCode = b.checkCoordinator(req.GroupID); res.ErrorCode != 0 {
+		return res
+	}
+	if req.SessionTimeoutMS <= 0 {
+		res.ErrorCode = invalidSessionTimeout
+		return res
+	}
+
+	g := c.groups[req.GroupID]
+	if g == nil {
+		g = &group{
+			id:      req.GroupID,
+			members: make(map[string]*member),
+			offsets: make(map[topicPartition]committedOffset),
+		}
+		c.groups[req.GroupID] = g
+	}
+
+	m := g.members[req.MemberID]
+	switch {
+	case req.MemberID == "":
+		m = &member{id: newMemberID(clientID)}
+	case m == nil:
+		res.ErrorCode = unknownMemberID
+		return res
+	}
+
+	if len(g.members) != 0 && req.ProtocolType != g.protocolType {
+		res.ErrorCode = inconsistentGroupProtocol
+		return res
+	}
+
+	for _, other := range g.members {
+		if other == m {
+			continue
+		}
+		compatible := false
+		for _, p := range req.Protocols {
+			if other.protocolMetadata(p.Name) != nil {
+				compatible = true
+				break
+			}
+		}
+		if !compatible {
+			res.ErrorCode = inconsistentGroupProtocol
+			return res
+		}
+	}
+
+	m.sessionTimeout = time.Duration(req.SessionTimeoutMS) * time.Millisecond
+	m.rebalanceTimeout = m.sessionTimeout
+	if version >= 1 {
+		m.rebalanceTimeout = time.Duration(req.RebalanceTimeoutMS) * time.Millisecond
+	}
+	m.protocols = req.Protocols
+	if m.joined != nil {
+		// The member sent another JoinGroup request while it was waiting for
+		// the previous one, which will never be responded to.
+		m.joined <- &joingroup.Response{ErrorCode: unknownMemberID, GenerationID: -1}
+	}
+	joined := make(chan *joingroup.Response, 1)
+	m.joined = joined
+
+	g.members[m.id] = m
+	g.protocolType = req.ProtocolType
+	c.heartbeat(g, m)
+	c.rebalance(g)
+
+	c.mutex.Unlock()
+	defer c.mutex.Lock()
+
+	select {
+	case res := <-joined:
+		return res
+	case <-c.done:
+		return nil
+	}
+}
+
+func (b *broker) syncGroup(version int16, clientID string, msg protocol.Message) protocol.Message {
+	req := msg.(*syncgroup.Request)
+	c := b.cluster
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	res := &syncgroup.Response{Assignments: []byte{}}
+
+	if res.ErrorCode = c.takeFault(protocol.SyncGroup); res.ErrorCode != 0 {
+		return res
+	}
+	if res.ErrorCode = b.checkCoordinator(req.GroupID); res.ErrorCode != 0 {
+		return res
+	}
+
+	g := c.groups[req.GroupID]
+	if g == nil || g.members[req.MemberID] == nil {
+		res.ErrorCode = unknownMemberID
+		return res
+	}
+	m := g.members[req.MemberID]
+
+	for {
+		switch {
+		case req.GenerationID != g.generation:
+			res.ErrorCode = illegalGeneration
+			return res
+		case g.state == groupPreparingRebalance:
+			res.ErrorCode = rebalanceInProgress
+			return res
+		case g.state == groupStable:
+			c.heartbeat(g, m)
+			res.ProtocolType = g.protocolType
+			res.ProtocolName = g.protocol
+			res.Assignments = m.assignment
+			if res.Assignments == nil {
+				res.Assignments = []byte{}
+			}
+			return res
+		}
+
+		if req.MemberID == g.leader {
+			for _, a := range req.Assignments {
+				if other := g.members[a.MemberID]; other != nil {
+					other.assignment = a.Assignment
+				}
+			}
+			g.state = groupStable
+			close(g.synced)
+			g.synced = nil
+			continue
+		}
+
+		if !c.waitGroup(g.synced) {
+			return nil
+		}
+		if g.members[req.MemberID] != m {
+			res.ErrorCode = unknownMemberID
+			return res
+		}
+	}
+}
+
+func (b *broker) heartbeat(version int16, clientID string, msg protocol.Message) protocol.Message {
+	req := msg.(*heartbeat.Request)
+	c := b.cluster
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	res := &heartbeat.Response{}
+
+	if res.ErrorCode = c.takeFault(protocol.Heartbeat); res.ErrorCode != 0 {
+		return res
+	}
+	if res.ErrorCode = b.checkCoordinator(req.GroupID); res.ErrorCode != 0 {
+		return res
+	}
+
+	g := c.groups[req.GroupID]
+	switch {
+	case g == nil || g.members[req.MemberID] == nil:
+		res.ErrorCode = unknownMemberID
+	case req.GenerationID != g.generation:
+		res.ErrorCode = illegalGeneration
+	case g.state == groupPreparingRebalance:
+		res.ErrorCode = rebalanceInProgress
+	default:
+		c.heartbeat(g, g.members[req.MemberID])
+	}
+
+	return res
+}
+
+func (b *broker) leaveGroup(version int16, clientID string, msg protocol.Message) protocol.Message {
+	req := msg.(*leavegroup.Request)
+	c := b.cluster
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	res := &leavegroup.Response{}
+
+	if res.ErrorCode = c.takeFault(protocol.LeaveGroup); res.ErrorCode != 0 {
+		return res
+	}
+	if res.ErrorCode = b.checkCoordinator(req.GroupID); res.ErrorCode != 0 {
+		return res
+	}
+
+	memberIDs := []string{req.MemberID}
+	if version >= 3 {
+		memberIDs = make([]string, len(req.Members))
+		for i, m := range req.Members {
+			memberIDs[i] = m.MemberID
+		}
+		res.Members = make([]leavegroup.ResponseMember, len(req.Members))
+	}
+
+	g := c.groups[req.GroupID]
+	left := false
+
+	for i, id := range memberIDs {
+		errorCode := int16(0)
+		if g == nil || g.members[id] == nil {
+			errorCode = unknownMemberID
+		} else {
+			c.removeMember(g, g.members[id])
+			left = true
+		}
+
+		if version >= 3 {
+			res.Members[i] = leavegroup.ResponseMember{MemberID: id, ErrorCode: errorCode}
+		} else {
+			res.ErrorCode = errorCode
+		}
+	}
+
+	if left {
+		c.rebalance(g)
+	}
+	return res
+}
+
+func (b *broker) offsetCommit(version int16, clientID string, msg protocol.Message) protocol.Message {
+	req := msg.(*offsetcommit.Request)
+	c := b.cluster
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	errorCode := c.takeFault(protocol.OffsetCommit)
+	if errorCode == 0 {
+		errorCode = b.checkCoordinator(req.GroupID)
+	}
+
+	g := c.groups[req.GroupID]
+	if errorCode == 0 && version >= 1 && req.GenerationID >= 0 {
+		switch {
+		case g == nil || g.members[req.MemberID] == nil:
+			errorCode = unknownMemberID
+		case req.GenerationID != g.generation:
+			errorCode = illegalGeneration
+		case g.state == groupPreparingRebalance:
+			errorCode = rebalanceInProgress
+		default:
+			c.heartbeat(g, g.members[req.MemberID])
+		}
+	}
+
+	if errorCode == 0 && g == nil {
+		g = &group{
+			id:      req.GroupID,
+			members: make(map[string]*member),
+			offsets: make(map[topicPartition]committedOffset),
+		}
+		c.groups[req.GroupID] = g
+	}
+
+	res := &offsetcommit.Response{Topics: make([]offsetcommit.ResponseTopic, len(req.Topics))}
+
+	for i, t := range req.Topics {
+		rt := &res.Topics[i]
+		rt.Name = t.Name
+		rt.Partitions = make([]offsetcommit.ResponsePartition, len(t.Partitions))
+
+		for j, p := range t.Partitions {
+			rp := &rt.Partitions[j]
+			rp.PartitionIndex = p.PartitionIndex
+
+			if rp.ErrorCode = errorCode; errorCode != 0 {
+				continue
+			}
+			if _, err := c.partition(t.Name, int(p.PartitionIndex)); err != nil {
+				rp.ErrorCode = unknownTopicOrPartition
+				continue
+			}
+
+			g.offsets[topicPartition{t.Name, p.PartitionIndex}] = committedOffset{
+				offset:   p.CommittedOffset,
+				metadata: p.CommittedMetadata,
+			}
+		}
+	}
+
+	return res
+}
+
+func (b *broker) offsetFetch(version int16, clientID string, msg protocol.Message) protocol.Message {
+	req := msg.(*offsetfetch.Request)
+	c := b.cluster
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	errorCode := c.takeFault(protocol.OffsetFetch)
+	if errorCode == 0 {
+		errorCode = b.checkCoordinator(req.GroupID)
+	}
+
+	res := &offsetfetch.Response{}
+	if version >= 2 {
+		res.ErrorCode = errorCode
+	}
+
+	g := c.groups[req.GroupID]
+	topics := req.Topics
+
+	if topics == nil {
+		// A null list of topics requests the offsets of all partitions which
+		// the group committed offsets for.
+		partitions := make(map[string][]int32)
+		if g != nil && errorCode == 0 {
+			for tp := range g.offsets {
+				partitions[tp.topic] = append(partitions[tp.topic], tp.partition)
+			}
+		}
+		for name, indexes := range partitions {
+			sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+			topics = append(topics, offsetfetch.RequestTopic{Name: name, PartitionIndexes: indexes})
+		}
+		sort.Slice(topics, func(i, j int) bool { return topics[i].Name < topics[j].Name })
+	}
+
+	res.Topics = make([]offsetfetch.ResponseTopic, len(topics))
+
+	for i, t := range topics {
+		rt := &res.Topics[i]
+		rt.Name = t.Name
+		rt.Partitions = make([]offsetfetch.ResponsePartition, len(t.PartitionIndexes))
+
+		for j, index := range t.PartitionIndexes {
+			rp := &rt.Partitions[j]
+			rp.PartitionIndex = index
+			rp.CommittedOffset = -1
+			rp.ComittedLeaderEpoch = -1
+			rp.ErrorCode = errorCode
+
+			if errorCode != 0 || g == nil {
+				continue
+			}
+			if o, ok := g.offsets[topicPartition{t.Name, index}]; ok {
+				rp.CommittedOffset = o.offset
+				rp.Metadata = o.metadata
+			}
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,467 @@
+package kafkatest
+
+import (
+	"io"
+	"time"
+
+	"github.com/PerchSecurity/kafka-go/protocol"
+	"github.com/PerchSecurity/kafka-go/protocol/fetch"
+	"github.com/PerchSecurity/kafka-go/protocol/listoffsets"
+	"github.com/PerchSecurity/kafka-go/protocol/offsetforleaderepoch"
+	"github.com/PerchSecurity/kafka-go/protocol/produce"
+)
+
+type topic struct {
+	name       string
+	id         protocol.UUID
+	partitions []*partition
+}
+
+// partition is the log of a topic partition, records are never deleted so
+// their offsets are also their index in the records slice.
+type partition struct {
+	leader   int32
+	replicas []int32
+	epoch    int32
+	epochs   []epochStart
+	records  []record
+}
+
+type epochStart struct {
+	epoch  int32
+	offset int64
+}
+
+type record struct {
+	offset  int64
+	time    time.Time
+	key     []byte
+	value   []byte
+	headers []protocol.Header
+}
+
+// size estimates the number of bytes taken by r in a record set.
+func (r *record) size() int {
+	n := 32 + len(r.key) + len(r.value)
+	for _, h := range r.headers {
+		n += 8 + len(h.Key) + len(h.Value)
+	}
+	return n
+}
+
+func (r *record) protocolRecord() protocol.Record {
+	return protocol.Record{
+		Offset:  r.offset,
+		Time:    r.time,
+		Key:     protocol.NewBytes(r.key),
+		Value:   protocol.NewBytes(r.value),
+		Headers: r.headers,
+	}
+}
+
+func (p *partition) highWatermark() int64 {
+	return int64(len(p.records))
+}
+
+// checkLeader returns the error code of requests for p sent to the broker with
+// the given ID, with the leader epoch known by the client (or -1).
+func (p *partition) checkLeader(brokerID, leaderEpoch int32) int16 {
+	switch {
+	case p.leader != brokerID:
+		return notLeaderForPartition
+	case leaderEpoch < 0:
+		return 0
+	case leaderEpoch < p.epoch:
+		return fencedLeaderEpoch
+	case leaderEpoch > p.epoch:
+		return unknownLeaderEpoch
+	default:
+		return 0
+	}
+}
+
+// append reads the records from rs and appends them to p, returning the offset
+// of the first record.
+func (p *partition) append(rs protocol.RecordSet) (int64, error) {
+	baseOffset := p.highWatermark()
+	records := []record{}
+	now := time.Now()
+
+	if rs.Records != nil {
+		for {
+			r, err := rs.Records.ReadRecord()
+			if err != nil {
+				if err == io.EOF {
+					break
+				}
+				return -1, err
+			}
+
+			rec := record{
+				offset: baseOffset + int64(len(records)),
+				time:   r.Time,
+			}
+			if rec.time.IsZero() {
+				rec.time = now
+			}
+			if rec.key, err = readBytes(r.Key); err != nil {
+				return -1, err
+			}
+			if rec.value, err = readBytes(r.Value); err != nil {
+				return -1, err
+			}
+			for _, h := range r.Headers {
+				rec.headers = append(rec.headers, protocol.Header{
+					Key:   h.Key,
+					Value: append([]byte(nil), h.Value...),
+				})
+			}
+
+			records = append(records, rec)
+		}
+	}
+
+	p.records = append(p.records, records...)
+	return baseOffset, nil
+}
+
+// read returns the records starting at offset, up to maxBytes. The first
+// record is always returned when there is one, like kafka does to allow
+// consumers to make progress.
+func (p *partition) read(offset int64, maxBytes int) []record {
+	records := p.records[offset:]
+	size := 0
+
+	for i := range records {
+		if size += records[i].size(); size > maxBytes && i > 0 {
+			return records[:i]
+		}
+	}
+
+	return records
+}
+
+// offsetForTime returns the offset and time of the first record produced at or
+// after t, or -1 if there are none.
+func (p *partition) offsetForTime(t time.Time) (int64, int64) {
+	for i := range p.records {
+		if r := &p.records[i]; !r.time.Before(t) {
+			return r.offset, timestamp(r.time)
+		}
+	}
+	return -1, -1
+}
+
+// endOffsetForEpoch returns the largest epoch of p less or equal to epoch,
+// and the offset after the last record of this epoch.
+func (p *partition) endOffsetForEpoch(epoch int32) (int32, int64) {
+	if epoch > p.epoch {
+		return -1, -1
+	}
+	for i := len(p.epochs) - 1; i >= 0; i-- {
+		if p.epochs[i].epoch <= epoch {
+			if i == len(p.epochs)-1 {
+				return p.epochs[i].epoch, p.highWatermark()
+			}
+			return p.epochs[i].epoch, p.epochs[i+1].offset
+		}
+	}
+	return -1, -1
+}
+
+func readBytes(b protocol.Bytes) ([]byte, error) {
+	if b == nil {
+		return nil, nil
+	}
+	defer b.Close()
+	return protocol.ReadAll(b)
+}
+
+func timestamp(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func makeTime(t int64) time.Time {
+	return time.Unix(t/1000, (t%1000)*int64(time.Millisecond))
+}
+
+func (b *broker) produce(version int16, clientID string, msg protocol.Message) protocol.Message {
+	req := msg.(*produce.Request)
+	c := b.cluster
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	errorCode := c.takeFault(protocol.Produce)
+	res := &produce.Response{Topics: make([]produce.ResponseTopic, len(req.Topics))}
+	appended := false
+
+	for i, t := range req.Topics {
+		rt := &res.Topics[i]
+		rt.Topic = t.Topic
+		rt.Partitions = make([]produce.ResponsePartition, len(t.Partitions))
+
+		for j, p := range t.Partitions {
+			rp := &rt.Partitions[j]
+			rp.Partition = p.Partition
+			rp.BaseOffset = -1
+			rp.LogAppendTime = -1
+			rp.LogStartOffset = -1
+
+			if errorCode != 0 {
+				rp.ErrorCode = errorCode
+				continue
+			}
+
+			part, err := c.partition(t.Topic, int(p.Partition))
+			if err != nil {
+				rp.ErrorCode = unknownTopicOrPartition
+				continue
+			}
+			if rp.ErrorCode = part.checkLeader(b.id, -1); rp.ErrorCode != 0 {
+				continue
+			}
+
+			baseOffset, err := part.append(p.RecordSet)
+			if err != nil {
+				rp.ErrorCode = corruptMessage
+				continue
+			}
+
+			rp.BaseOffset = baseOffset
+			rp.LogStartOffset = 0
+			appended = true
+		}
+	}
+
+	if appended {
+		c.notify()
+	}
+	return res
+}
+
+func (b *broker) fetch(version int16, clientID string, msg protocol.Message) protocol.Message {
+	req := msg.(*fetch.Request)
+	c := b.cluster
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	errorCode := c.takeFault(protocol.Fetch)
+	deadline := time.Now().Add(time.Duration(req.MaxWaitTime) * time.Millisecond)
+
+	for {
+		res, size, failed := b.fetchRecords(version, req, errorCode)
+		if failed || size >= int(req.MinBytes) || !time.Now().Before(deadline) {
+			return res
+		}
+		if c.wait(deadline); c.closed {
+			return nil
+		}
+	}
+}
+
+// fetchRecords builds the response to a fetch request, returning the number
+// of bytes of records in the response, and whether any partition had errors.
+func (b *broker) fetchRecords(version int16, req *fetch.Request, errorCode int16) (res *fetch.Response, size int, failed bool) {
+	c := b.cluster
+	res = &fetch.Response{Topics: make([]fetch.ResponseTopic, len(req.Topics))}
+
+	recordVersion := int8(1)
+	if version >= 4 {
+		recordVersion = 2
+	}
+
+	maxBytes := int(req.MaxBytes)
+	if version < 3 {
+		maxBytes = int(^uint(0) >> 1)
+	}
+
+	for i, t := range req.Topics {
+		rt := &res.Topics[i]
+		rt.Topic = t.Topic
+		rt.Partitions = make([]fetch.ResponsePartition, len(t.Partitions))
+
+		// Topics are only identified by their ID in version 13 and above.
+		topicErrorCode := unknownTopicOrPartition
+		if version >= 13 {
+			rt.Topic, rt.TopicID, topicErrorCode = "", t.TopicID, unknownTopicID
+			if topic := c.topicByID(t.TopicID); topic != nil {
+				rt.Topic = topic.name
+			}
+		}
+
+		for j, p := range t.Partitions {
+			rp := &rt.Partitions[j]
+			rp.Partition = p.Partition
+			rp.HighWatermark = -1
+			rp.LastStableOffset = -1
+			rp.LogStartOffset = -1
+			rp.PreferredReadReplica = -1
+			rp.RecordSet = protocol.RecordSet{Version: 1, Records: protocol.NewRecordReader()}
+
+			if errorCode != 0 {
+				rp.ErrorCode, failed = errorCode, true
+				continue
+			}
+
+			part, err := c.partition(rt.Topic, int(p.Partition))
+			if err != nil {
+				rp.ErrorCode, failed = topicErrorCode, true
+				continue
+			}
+
+			leaderEpoch := int32(-1)
+			if version >= 9 {
+				leaderEpoch = p.CurrentLeaderEpoch
+			}
+			if rp.ErrorCode = part.checkLeader(b.id, leaderEpoch); rp.ErrorCode != 0 {
+				failed = true
+				continue
+			}
+
+			rp.HighWatermark = part.highWatermark()
+			rp.LastStableOffset = rp.HighWatermark
+			rp.LogStartOffset = 0
+
+			if p.FetchOffset < 0 || p.FetchOffset > rp.HighWatermark {
+				rp.ErrorCode, failed = offsetOutOfRange, true
+				continue
+			}
+
+			limit := int(p.PartitionMaxBytes)
+			if limit > maxBytes-size {
+				limit = maxBytes - size
+			}
+			if limit <= 0 && size > 0 {
+				continue
+			}
+
+			records := part.read(p.FetchOffset, limit)
+			if len(records) == 0 {
+				continue
+			}
+
+			batch := make([]protocol.Record, len(records))
+			for k := range records {
+				batch[k] = records[k].protocolRecord()
+				size += records[k].size()
+			}
+
+			rp.RecordSet.Version = recordVersion
+			if recordVersion == 2 {
+				rp.RecordSet.Records = &protocol.RecordBatch{
+					PartitionLeaderEpoch: part.epoch,
+					BaseOffset:           records[0].offset,
+					Records:              protocol.NewRecordReader(batch...),
+				}
+			} else {
+				rp.RecordSet.Records = &protocol.MessageSet{
+					BaseOffset: records[0].offset,
+					Records:    protocol.NewRecordReader(batch...),
+				}
+			}
+		}
+	}
+
+	return res, size, failed
+}
+
+func (b *broker) listOffsets(version int16, clientID string, msg protocol.Message) protocol.Message {
+	req := msg.(*listoffsets.Request)
+	c := b.cluster
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	errorCode := c.takeFault(protocol.ListOffsets)
+	res := &listoffsets.Response{Topics: make([]listoffsets.ResponseTopic, len(req.Topics))}
+
+	for i, t := range req.Topics {
+		rt := &res.Topics[i]
+		rt.Topic = t.Topic
+		rt.Partitions = make([]listoffsets.ResponsePartition, len(t.Partitions))
+
+		for j, p := range t.Partitions {
+			rp := &rt.Partitions[j]
+			rp.Partition = p.Partition
+			rp.Timestamp = -1
+			rp.Offset = -1
+			rp.LeaderEpoch = -1
+
+			if errorCode != 0 {
+				rp.ErrorCode = errorCode
+				continue
+			}
+
+			part, err := c.partition(t.Topic, int(p.Partition))
+			if err != nil {
+				rp.ErrorCode = unknownTopicOrPartition
+				continue
+			}
+
+			leaderEpoch := int32(-1)
+			if version >= 4 {
+				leaderEpoch = p.CurrentLeaderEpoch
+			}
+			if rp.ErrorCode = part.checkLeader(b.id, leaderEpoch); rp.ErrorCode != 0 {
+				continue
+			}
+
+			switch p.Timestamp {
+			case -1: // latest
+				rp.Offset = part.highWatermark()
+			case -2: // earliest
+				rp.Offset = 0
+			default:
+				rp.Offset, rp.Timestamp = part.offsetForTime(makeTime(p.Timestamp))
+			}
+			rp.LeaderEpoch = part.epoch
+		}
+	}
+
+	return res
+}
+
+func (b *broker) offsetForLeaderEpoch(version int16, clientID string, msg protocol.Message) protocol.Message {
+	req := msg.(*offsetforleaderepoch.Request)
+	c := b.cluster
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	errorCode := c.takeFault(protocol.OffsetForLeaderEpoch)
+	res := &offsetforleaderepoch.Response{Topics: make([]offsetforleaderepoch.ResponseTopic, len(req.Topics))}
+
+	for i, t := range req.Topics {
+		rt := &res.Topics[i]
+		rt.Topic = t.Topic
+		rt.Partitions = make([]offsetforleaderepoch.ResponsePartition, len(t.Partitions))
+
+		for j, p := range t.Partitions {
+			rp := &rt.Partitions[j]
+			rp.Partition = p.Partition
+			rp.LeaderEpoch = -1
+			rp.EndOffset = -1
+
+			if errorCode != 0 {
+				rp.ErrorCode = errorCode
+				continue
+			}
+
+			part, err := c.partition(t.Topic, int(p.Partition))
+			if err != nil {
+				rp.ErrorCode = unknownTopicOrPartition
+				continue
+			}
+
+			leaderEpoch := int32(-1)
+			if version >= 2 {
+				leaderEpoch = p.CurrentLeaderEpoch
+			}
+			if rp.ErrorCode = part.checkLeader(b.id, leaderEpoch); rp.ErrorCode != 0 {
+				continue
+			}
+
+			rp.LeaderEpoch, rp.EndOffset = part.endOffsetForEpoch(p.LeaderEpoch)
+		}
+	}
+
+	return res
+}
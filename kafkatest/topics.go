@@ -0,0 +1,215 @@
+package kafkatest
+
+import (
+	"sort"
+
+	"github.com/PerchSecurity/kafka-go/protocol"
+	"github.com/PerchSecurity/kafka-go/protocol/createtopics"
+	"github.com/PerchSecurity/kafka-go/protocol/deletetopics"
+	"github.com/PerchSecurity/kafka-go/protocol/metadata"
+)
+
+func (b *broker) metadata(version int16, clientID string, msg protocol.Message) protocol.Message {
+	req := msg.(*metadata.Request)
+	c := b.cluster
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	errorCode := c.takeFault(protocol.Metadata)
+	res := &metadata.Response{
+		Brokers:      make([]metadata.ResponseBroker, len(c.brokers)),
+		ClusterID:    c.clusterID,
+		ControllerID: 0,
+	}
+
+	for i, broker := range c.brokers {
+		res.Brokers[i] = metadata.ResponseBroker{
+			NodeID: broker.id,
+			Host:   broker.host,
+			Port:   broker.port,
+		}
+	}
+
+	// Topics are sent as a list of names before version 10.
+	topics := req.Topics
+	if version < 10 && req.TopicNames != nil {
+		topics = make([]metadata.RequestTopic, len(req.TopicNames))
+		for i, name := range req.TopicNames {
+			topics[i].Name = name
+		}
+	}
+
+	// A null list of topics requests all topics, and so does an empty list in
+	// version 0.
+	if topics == nil || (version == 0 && len(topics) == 0) {
+		names := make([]string, 0, len(c.topics))
+		for name := range c.topics {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		topics = make([]metadata.RequestTopic, len(names))
+		for i, name := range names {
+			topics[i].Name = name
+		}
+	} else if c.autoCreateTopics && (version < 4 || req.AllowAutoTopicCreation) {
+		for _, t := range topics {
+			if _, exists := c.topics[t.Name]; !exists && t.Name != "" {
+				c.createTopic(t.Name, c.defaultPartitions)
+			}
+		}
+	}
+
+	res.Topics = make([]metadata.ResponseTopic, len(topics))
+
+	for i, rq := range topics {
+		rt := &res.Topics[i]
+		rt.Name = rq.Name
+		rt.TopicID = rq.TopicID
+
+		t := c.topics[rq.Name]
+		if rq.Name == "" {
+			if t = c.topicByID(rq.TopicID); t != nil {
+				rt.Name = t.name
+			}
+		}
+
+		switch {
+		case errorCode != 0:
+			rt.ErrorCode = errorCode
+			continue
+		case rq.Name == "" && !rq.TopicID.IsZero():
+			if t == nil {
+				rt.ErrorCode = unknownTopicID
+				continue
+			}
+		case rq.Name == "":
+			rt.ErrorCode = invalidTopic
+			continue
+		case t == nil:
+			rt.ErrorCode = unknownTopicOrPartition
+			continue
+		}
+
+		rt.TopicID = t.id
+		rt.Partitions = make([]metadata.ResponsePartition, len(t.partitions))
+
+		for j, p := range t.partitions {
+			rt.Partitions[j] = metadata.ResponsePartition{
+				PartitionIndex:  int32(j),
+				LeaderID:        p.leader,
+				LeaderEpoch:     p.epoch,
+				ReplicaNodes:    p.replicas,
+				IsrNodes:        p.replicas,
+				OfflineReplicas: []int32{},
+			}
+		}
+	}
+
+	return res
+}
+
+func (b *broker) createTopics(version int16, clientID string, msg protocol.Message) protocol.Message {
+	req := msg.(*createtopics.Request)
+	c := b.cluster
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	errorCode := c.takeFault(protocol.CreateTopics)
+	res := &createtopics.Response{Topics: make([]createtopics.ResponseTopic, len(req.Topics))}
+
+	for i, t := range req.Topics {
+		rt := &res.Topics[i]
+		rt.Name = t.Name
+		rt.NumPartitions = -1
+		rt.ReplicationFactor = -1
+
+		numPartitions := int(t.NumPartitions)
+		if len(t.Assignments) != 0 {
+			numPartitions = len(t.Assignments)
+		} else if numPartitions == -1 {
+			numPartitions = c.defaultPartitions
+		}
+
+		replicationFactor := int(t.ReplicationFactor)
+		if len(t.Assignments) != 0 {
+			replicationFactor = len(t.Assignments[0].BrokerIDs)
+		} else if replicationFactor == -1 {
+			replicationFactor = 1
+		}
+
+		switch {
+		case errorCode != 0:
+			rt.ErrorCode = errorCode
+		case t.Name == "":
+			rt.ErrorCode = invalidTopic
+		case c.topics[t.Name] != nil:
+			rt.ErrorCode = topicAlreadyExists
+		case numPartitions <= 0:
+			rt.ErrorCode = invalidPartitions
+		case replicationFactor <= 0 || replicationFactor > len(c.brokers):
+			rt.ErrorCode = invalidReplicationFactor
+		default:
+			if !req.ValidateOnly {
+				c.createTopic(t.Name, numPartitions)
+			}
+			rt.NumPartitions = int32(numPartitions)
+			rt.ReplicationFactor = int16(replicationFactor)
+			rt.Configs = []createtopics.ResponseTopicConfig{}
+		}
+	}
+
+	return res
+}
+
+func (b *broker) deleteTopics(version int16, clientID string, msg protocol.Message) protocol.Message {
+	req := msg.(*deletetopics.Request)
+	c := b.cluster
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	errorCode := c.takeFault(protocol.DeleteTopics)
+
+	// Topics are sent as a list of names before version 6.
+	topics := req.Topics
+	if version < 6 {
+		topics = make([]deletetopics.RequestTopic, len(req.TopicNames))
+		for i, name := range req.TopicNames {
+			topics[i].Name = name
+		}
+	}
+
+	res := &deletetopics.Response{Responses: make([]deletetopics.ResponseTopic, len(topics))}
+	deleted := false
+
+	for i, rq := range topics {
+		rt := &res.Responses[i]
+		rt.Name = rq.Name
+		rt.TopicID = rq.TopicID
+
+		t := c.topics[rq.Name]
+		if rq.Name == "" {
+			if t = c.topicByID(rq.TopicID); t != nil {
+				rt.Name = t.name
+			}
+		}
+
+		switch {
+		case errorCode != 0:
+			rt.ErrorCode = errorCode
+		case t == nil && rq.Name == "":
+			rt.ErrorCode = unknownTopicID
+		case t == nil:
+			rt.ErrorCode = unknownTopicOrPartition
+		default:
+			rt.TopicID = t.id
+			delete(c.topics, t.name)
+			deleted = true
+		}
+	}
+
+	if deleted {
+		c.notify()
+	}
+	return res
+}
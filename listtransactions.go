@@ -0,0 +1,115 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/PerchSecurity/kafka-go/protocol/listtransactions"
+)
+
+// ListTransactionsRequest represents a request sent to a kafka cluster to list
+// the transactions known by the transaction coordinators.
+type ListTransactionsRequest struct {
+	// Address of the kafka cluster to send the request to.
+	Addr net.Addr
+
+	// Only list the transactions in these states (e.g. "Ongoing",
+	// "PrepareCommit", "PrepareAbort", "CompleteCommit", "CompleteAbort",
+	// "Empty", "Dead"). When empty, transactions in all states are listed.
+	States []string
+
+	// Only list the transactions of these producer IDs. When empty,
+	// transactions of all producers are listed.
+	ProducerIDs []int64
+
+	// Only list the transactions that have been running for longer than this
+	// duration.
+	//
+	// This field requires the kafka broker to support the ListTransactions
+	// API in version 1 or above (otherwise the value is ignored).
+	MinDuration time.Duration
+}
+
+// ListTransactionsResponse represents a response from a kafka cluster to a list
+// transactions request. The results of all brokers are merged into a single
+// response.
+type ListTransactionsResponse struct {
+	// The amount of time that the brokers throttled the request.
+	Throttle time.Duration
+
+	// An error that may have occurred while attempting to list transactions.
+	//
+	// The error contains the kafka error code. Programs may use the standard
+	// errors.Is function to test the error against kafka error codes.
+	Error error
+
+	// States of the request filters that the brokers did not recognize.
+	UnknownStates []string
+
+	// List of transactions, sorted by transactional ID.
+	Transactions []ListTransactionsResponseTransaction
+}
+
+// ListTransactionsResponseTransaction represents a transaction returned by a
+// transaction coordinator.
+type ListTransactionsResponseTransaction struct {
+	// ID of the broker coordinating the transaction.
+	Coordinator int
+
+	// Transactional ID of the producer.
+	TransactionalID string
+
+	// ID of the producer running the transaction.
+	ProducerID int64
+
+	// Current state of the transaction.
+	State string
+}
+
+// ListTransactions sends a list transactions request to every broker of a kafka
+// cluster and returns the merged response.
+//
+// This API requires kafka 3.0 or above.
+func (c *Client) ListTransactions(ctx context.Context, req *ListTransactionsRequest) (*ListTransactionsResponse, error) {
+	m, err := c.roundTrip(ctx, req.Addr, &listtransactions.Request{
+		StateFilters:      req.States,
+		ProducerIDFilters: req.ProducerIDs,
+		DurationFilter:    makeDurationFilter(req.MinDuration),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("kafka.(*Client).ListTransactions: %w", err)
+	}
+
+	res := m.(*listtransactions.Response)
+	ret := &ListTransactionsResponse{
+		Throttle:      makeDuration(res.ThrottleTimeMs),
+		Error:         makeError(res.ErrorCode, ""),
+		UnknownStates: res.UnknownStateFilters,
+		Transactions:  make([]ListTransactionsResponseTransaction, len(res.TransactionStates)),
+	}
+
+	for i, t := range res.TransactionStates {
+		ret.Transactions[i] = ListTransactionsResponseTransaction{
+			Coordinator:     int(t.BrokerID),
+			TransactionalID: t.TransactionalID,
+			ProducerID:      t.ProducerID,
+			State:           t.TransactionState,
+		}
+	}
+
+	sort.Slice(ret.Transactions, func(i, j int) bool {
+		return ret.Transactions[i].TransactionalID < ret.Transactions[j].TransactionalID
+	})
+
+	return ret, nil
+}
+
+func makeDurationFilter(d time.Duration) int64 {
+	if d <= 0 {
+		return -1
+	}
+	return int64(d / time.Millisecond)
+}
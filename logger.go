@@ -1,5 +1,12 @@
 package kafka
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
 // Logger interface API for log.Logger.
 type Logger interface {
 	Printf(string, ...interface{})
@@ -7,11 +14,152 @@ type Logger interface {
 
 // LoggerFunc is a bridge between Logger and any third party logger
 // Usage:
-//   l := NewLogger() // some logger
-//   r := kafka.NewReader(kafka.ReaderConfig{
-//     Logger:      kafka.LoggerFunc(l.Infof),
-//     ErrorLogger: kafka.LoggerFunc(l.Errorf),
-//   })
+//
+//	l := NewLogger() // some logger
+//	r := kafka.NewReader(kafka.ReaderConfig{
+//	  Logger:      kafka.LoggerFunc(l.Infof),
+//	  ErrorLogger: kafka.LoggerFunc(l.Errorf),
+//	})
 type LoggerFunc func(string, ...interface{})
 
 func (f LoggerFunc) Printf(msg string, args ...interface{}) { f(msg, args...) }
+
+// LogLevel represents the severity of events logged to a StructuredLogger.
+//
+// The values match those of the log/slog package, so levels can be converted
+// with slog.Level(level).
+//
+// Readers, writers and consumer groups use the levels consistently:
+//
+//   - LogLevelDebug for details about individual requests and batches,
+//   - LogLevelInfo for changes of state, like joining a consumer group,
+//   - LogLevelWarn for errors which are retried automatically,
+//   - LogLevelError for errors which are reported to the program.
+//
+// When no StructuredLogger is configured, debug and info events are logged to
+// Logger, and warn and error events to ErrorLogger.
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = -4
+	LogLevelInfo  LogLevel = 0
+	LogLevelWarn  LogLevel = 4
+	LogLevelError LogLevel = 8
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelDebug:
+		return "DEBUG"
+	case LogLevelInfo:
+		return "INFO"
+	case LogLevelWarn:
+		return "WARN"
+	case LogLevelError:
+		return "ERROR"
+	default:
+		return "LogLevel(" + strconv.Itoa(int(l)) + ")"
+	}
+}
+
+// LogAttr is a key-value pair attached to events logged to a
+// StructuredLogger.
+//
+// The following keys are used:
+//
+//   - "topic" (string)
+//   - "partition" (int)
+//   - "offset" (int64)
+//   - "group" (string), the consumer group ID
+//   - "generation" (int32), the consumer group generation ID
+//   - "member_id" (string), the consumer group member ID
+//   - "broker" (string), the address of a kafka broker
+//   - "error" (error)
+//   - "error_code" (int), when the error was a kafka Error
+type LogAttr struct {
+	Key   string
+	Value interface{}
+}
+
+// StructuredLogger is an interface implemented by types which log events
+// with attributes.
+//
+// On Go 1.21 and above, NewSlogLogger adapts a *slog.Logger to this interface.
+type StructuredLogger interface {
+	Log(ctx context.Context, level LogLevel, msg string, attrs ...LogAttr)
+}
+
+// StructuredLoggerFunc is an adapter to allow the use of ordinary functions as
+// a StructuredLogger.
+type StructuredLoggerFunc func(context.Context, LogLevel, string, ...LogAttr)
+
+// Log calls f(ctx, level, msg, attrs...).
+func (f StructuredLoggerFunc) Log(ctx context.Context, level LogLevel, msg string, attrs ...LogAttr) {
+	f(ctx, level, msg, attrs...)
+}
+
+func topicAttr(topic string) LogAttr          { return LogAttr{Key: "topic", Value: topic} }
+func partitionAttr(partition int) LogAttr     { return LogAttr{Key: "partition", Value: partition} }
+func offsetAttr(offset int64) LogAttr         { return LogAttr{Key: "offset", Value: offset} }
+func groupAttr(groupID string) LogAttr        { return LogAttr{Key: "group", Value: groupID} }
+func generationAttr(generation int32) LogAttr { return LogAttr{Key: "generation", Value: generation} }
+func memberIDAttr(memberID string) LogAttr    { return LogAttr{Key: "member_id", Value: memberID} }
+func brokerAttr(broker string) LogAttr        { return LogAttr{Key: "broker", Value: broker} }
+
+func errorAttrs(err error) []LogAttr {
+	attrs := []LogAttr{{Key: "error", Value: err}}
+	var kafkaError Error
+	if errors.As(err, &kafkaError) {
+		attrs = append(attrs, LogAttr{Key: "error_code", Value: int(kafkaError)})
+	}
+	return attrs
+}
+
+// logger dispatches the events logged by readers, writers and consumer groups
+// to their StructuredLogger, or to their Printf loggers when no structured
+// logger was configured.
+type logger struct {
+	structured  StructuredLogger
+	logger      Logger
+	errorLogger Logger
+	attrs       []LogAttr
+}
+
+// with returns a copy of l which attaches attrs to the events it logs.
+func (l logger) with(attrs ...LogAttr) logger {
+	if l.structured != nil {
+		l.attrs = append(l.attrs[:len(l.attrs):len(l.attrs)], attrs...)
+	}
+	return l
+}
+
+// withError returns a copy of l which attaches err and its error code to the
+// events it logs.
+func (l logger) withError(err error) logger {
+	if l.structured != nil {
+		l = l.with(errorAttrs(err)...)
+	}
+	return l
+}
+
+// enabled returns true if events logged to l are written anywhere, callers
+// use it to skip building costly log messages.
+func (l logger) enabled() bool {
+	return l.structured != nil || l.logger != nil || l.errorLogger != nil
+}
+
+func (l logger) logf(level LogLevel, format string, args ...interface{}) {
+	switch {
+	case l.structured != nil:
+		l.structured.Log(context.Background(), level, fmt.Sprintf(format, args...), l.attrs...)
+	case level >= LogLevelWarn && l.errorLogger != nil:
+		l.errorLogger.Printf(format, args...)
+	case l.logger != nil:
+		l.logger.Printf(format, args...)
+	}
+}
+
+func (l logger) debugf(format string, args ...interface{}) { l.logf(LogLevelDebug, format, args...) }
+func (l logger) infof(format string, args ...interface{})  { l.logf(LogLevelInfo, format, args...) }
+func (l logger) warnf(format string, args ...interface{})  { l.logf(LogLevelWarn, format, args...) }
+func (l logger) errorf(format string, args ...interface{}) { l.logf(LogLevelError, format, args...) }
@@ -0,0 +1,37 @@
+//go:build go1.21
+// +build go1.21
+
+package kafka
+
+import (
+	"context"
+	"log/slog"
+)
+
+// NewSlogLogger returns a StructuredLogger which logs events to l.
+//
+// Usage:
+//
+//	r := kafka.NewReader(kafka.ReaderConfig{
+//	  Brokers:          []string{"localhost:9092"},
+//	  Topic:            "topic-A",
+//	  StructuredLogger: kafka.NewSlogLogger(slog.Default()),
+//	})
+func NewSlogLogger(l *slog.Logger) StructuredLogger {
+	return slogLogger{logger: l}
+}
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+func (l slogLogger) Log(ctx context.Context, level LogLevel, msg string, attrs ...LogAttr) {
+	if !l.logger.Enabled(ctx, slog.Level(level)) {
+		return
+	}
+	slogAttrs := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		slogAttrs[i] = slog.Any(attr.Key, attr.Value)
+	}
+	l.logger.LogAttrs(ctx, slog.Level(level), msg, slogAttrs...)
+}
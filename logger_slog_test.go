@@ -0,0 +1,38 @@
+//go:build go1.21
+// +build go1.21
+
+package kafka
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogLogger(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	handler := slog.NewTextHandler(buffer, &slog.HandlerOptions{Level: slog.LevelInfo})
+
+	log := logger{structured: NewSlogLogger(slog.New(handler))}.with(topicAttr("topic-A"), partitionAttr(2))
+	log.debugf("not logged")
+	log.with(offsetAttr(42)).withError(OffsetOutOfRange).errorf("reading partition %d", 2)
+
+	output := buffer.String()
+	if strings.Contains(output, "not logged") {
+		t.Error("debug events must be filtered by the handler level")
+	}
+
+	for _, expected := range []string{
+		"level=ERROR",
+		`msg="reading partition 2"`,
+		"topic=topic-A",
+		"partition=2",
+		"offset=42",
+		"error_code=1",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("missing %s in output: %s", expected, output)
+		}
+	}
+}
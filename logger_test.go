@@ -0,0 +1,150 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type logEvent struct {
+	level LogLevel
+	msg   string
+	attrs map[string]interface{}
+}
+
+type testStructuredLogger struct {
+	mutex  sync.Mutex
+	events []logEvent
+}
+
+func (l *testStructuredLogger) Log(ctx context.Context, level LogLevel, msg string, attrs ...LogAttr) {
+	e := logEvent{level: level, msg: msg, attrs: make(map[string]interface{})}
+	for _, attr := range attrs {
+		e.attrs[attr.Key] = attr.Value
+	}
+	l.mutex.Lock()
+	l.events = append(l.events, e)
+	l.mutex.Unlock()
+}
+
+func (l *testStructuredLogger) logged() []logEvent {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return append([]logEvent(nil), l.events...)
+}
+
+func TestLoggerPrintf(t *testing.T) {
+	var infos, errs []string
+	info := LoggerFunc(func(msg string, args ...interface{}) { infos = append(infos, fmt.Sprintf(msg, args...)) })
+	errorf := LoggerFunc(func(msg string, args ...interface{}) { errs = append(errs, fmt.Sprintf(msg, args...)) })
+
+	log := logger{logger: info, errorLogger: errorf}.with(topicAttr("topic-A"))
+	log.debugf("debug %d", 1)
+	log.infof("info %d", 2)
+	log.warnf("warn %d", 3)
+	log.errorf("error %d", 4)
+
+	if !reflect.DeepEqual(infos, []string{"debug 1", "info 2"}) {
+		t.Errorf("unexpected events logged to Logger: %q", infos)
+	}
+	if !reflect.DeepEqual(errs, []string{"warn 3", "error 4"}) {
+		t.Errorf("unexpected events logged to ErrorLogger: %q", errs)
+	}
+
+	// Without ErrorLogger, errors are logged to Logger.
+	infos = nil
+	log = logger{logger: info}
+	log.errorf("error %d", 5)
+
+	if !reflect.DeepEqual(infos, []string{"error 5"}) {
+		t.Errorf("unexpected events logged to Logger: %q", infos)
+	}
+}
+
+func TestLoggerStructured(t *testing.T) {
+	structured := &testStructuredLogger{}
+	printf := LoggerFunc(func(string, ...interface{}) {
+		t.Error("events must not be logged to Logger when a StructuredLogger is configured")
+	})
+
+	base := logger{structured: structured, logger: printf}.with(topicAttr("topic-A"))
+	base.with(partitionAttr(1)).withError(fmt.Errorf("fetching: %w", NotLeaderForPartition)).warnf("failed to read from partition %d", 1)
+	base.infof("done")
+
+	expected := []logEvent{
+		{
+			level: LogLevelWarn,
+			msg:   "failed to read from partition 1",
+			attrs: map[string]interface{}{
+				"topic":      "topic-A",
+				"partition":  1,
+				"error":      fmt.Errorf("fetching: %w", NotLeaderForPartition),
+				"error_code": int(NotLeaderForPartition),
+			},
+		},
+		{
+			level: LogLevelInfo,
+			msg:   "done",
+			attrs: map[string]interface{}{
+				"topic": "topic-A",
+			},
+		},
+	}
+
+	if events := structured.logged(); !reflect.DeepEqual(events, expected) {
+		t.Errorf("unexpected events:\nwant: %+v\ngot:  %+v", expected, events)
+	}
+}
+
+func TestConsumerGroupStructuredLogger(t *testing.T) {
+	structured := &testStructuredLogger{}
+	groupID := makeGroupID()
+
+	mc := mockCoordinator{
+		findCoordinatorFunc: func(findCoordinatorRequestV0) (findCoordinatorResponseV0, error) {
+			return findCoordinatorResponseV0{}, errors.New("dial error")
+		},
+	}
+
+	group, err := NewConsumerGroup(ConsumerGroupConfig{
+		ID:               groupID,
+		Topics:           []string{"test"},
+		Brokers:          []string{"no-such-broker"},
+		JoinGroupBackoff: time.Second,
+		StructuredLogger: structured,
+		connect: func(*Dialer, ...string) (coordinator, error) {
+			return mc, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer group.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := group.Next(ctx); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	events := structured.logged()
+	if len(events) == 0 {
+		t.Fatal("no events were logged")
+	}
+
+	e := events[0]
+	if e.level != LogLevelError {
+		t.Errorf("expected an error event, got %s", e.level)
+	}
+	if group := e.attrs["group"]; group != groupID {
+		t.Errorf("expected the group attribute to be %q, got %v", groupID, group)
+	}
+	if _, ok := e.attrs["error"]; !ok {
+		t.Error("expected the event to carry the error attribute")
+	}
+}
@@ -28,6 +28,30 @@ type Message struct {
 	// If not set at the creation, Time will be automatically set when
 	// writing the message.
 	Time time.Time
+
+	// Lease on the pooled buffer that Key and Value alias, when the message
+	// was read with pooled buffers.
+	lease *messageLease
+}
+
+// Release returns the buffer that the message key and value alias to the pool
+// it was taken from, when the message was read with pooled buffers (see
+// ReaderConfig.PooledBuffers and ReadBatchConfig.PooledBuffers). Release sets
+// Key and Value to nil, the program must not retain references to their
+// content after calling it.
+//
+// Release is idempotent, calling it again on a message, or on a copy of a
+// message that was already released, does nothing but clear Key and Value.
+// Messages that are never released are not leaked, their buffers are garbage
+// collected instead of being reused. The method is a no-op on messages which
+// were not read with pooled buffers.
+func (msg *Message) Release() {
+	if msg.lease != nil {
+		msg.lease.release()
+		msg.lease = nil
+		msg.Key = nil
+		msg.Value = nil
+	}
 }
 
 func (msg Message) message(cw *crc32Writer) message {
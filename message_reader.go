@@ -23,6 +23,12 @@ type messageSetReader struct {
 	lengthRemain int
 
 	decompressed *bytes.Buffer
+	// When pooled is true, message keys and values are read into reference
+	// counted buffers instead of being allocated, and lease holds the
+	// reference on the buffer that compressed message sets are decompressed
+	// into.
+	pooled bool
+	lease  *bufferLease
 }
 
 type readerStack struct {
@@ -32,6 +38,11 @@ type readerStack struct {
 	parent *readerStack
 	count  int            // how many messages left in the current message set
 	header messagesHeader // the current header for a subset of messages within the set.
+	// When reading a decompressed message set with pooled buffers, data holds
+	// the decompressed bytes and lease the reference on the buffer they were
+	// decompressed into.
+	data  []byte
+	lease *bufferLease
 }
 
 // messagesHeader describes a set of records. there may be many messagesHeader's in a message set.
@@ -93,6 +104,16 @@ func newMessageSetReader(reader *bufio.Reader, remain int) (*messageSetReader, e
 	return res, err
 }
 
+// leaderEpoch returns the partition leader epoch of the record batch that the
+// reader is positioned on, or -1 if the messages use a format which predates
+// record batches (v0 and v1).
+func (r *messageSetReader) leaderEpoch() int32 {
+	if r.empty || r.readerStack == nil || r.header.magic != 2 {
+		return -1
+	}
+	return r.header.v2.leaderEpoch
+}
+
 func (r *messageSetReader) remaining() (remain int) {
 	if r.empty {
 		return 0
@@ -169,6 +190,7 @@ func (r *messageSetReader) readMessageV1(min int64, key readBytesFunc, val readB
 			}
 
 			// read and decompress the contained message set.
+			r.renewLease()
 			r.decompressed.Reset()
 			if err = r.readBytesWith(func(br *bufio.Reader, sz int, n int) (remain int, err error) {
 				// x4 as a guess that the average compression ratio is near 75%
@@ -206,6 +228,8 @@ func (r *messageSetReader) readMessageV1(min int64, key readBytesFunc, val readB
 				remain: r.decompressed.Len(),
 				base:   offset,
 				parent: r.readerStack,
+				data:   r.decompressed.Bytes(),
+				lease:  r.lease,
 			}
 			continue
 		}
@@ -269,6 +293,7 @@ func (r *messageSetReader) readMessageV2(_ int64, key readBytesFunc, val readByt
 				err = fmt.Errorf("batch remain < 0 (%d)", batchRemain)
 				return
 			}
+			r.renewLease()
 			r.decompressed.Reset()
 			// x4 as a guess that the average compression ratio is near 75%
 			r.decompressed.Grow(4 * batchRemain)
@@ -287,6 +312,8 @@ func (r *messageSetReader) readMessageV2(_ int64, key readBytesFunc, val readByt
 				parent: r.readerStack,
 				header: r.header,
 				count:  r.count,
+				data:   r.decompressed.Bytes(),
+				lease:  r.lease,
 			}
 			// all of the messages in this set are in the decompressed set just pushed onto the reader
 			// stack. here we set the parent count to 0 so that when the child set is exhausted, the
@@ -338,6 +365,69 @@ func (r *messageSetReader) readMessageV2(_ int64, key readBytesFunc, val readByt
 	return
 }
 
+// renewLease acquires a new buffer to decompress a message set into when the
+// reader uses pooled buffers. The buffer of the previous message set cannot be
+// reused because the messages read from it may still alias its content, the
+// reader only drops its own reference on it.
+func (r *messageSetReader) renewLease() {
+	if !r.pooled {
+		return
+	}
+	if r.lease != nil {
+		r.lease.unref()
+	} else {
+		releaseBuffer(r.decompressed)
+	}
+	r.lease = acquireBufferLease()
+	r.decompressed = r.lease.buffer
+}
+
+// releaseBuffers releases the buffers held by the reader, it is called when
+// the batch that the reader belongs to is closed.
+func (r *messageSetReader) releaseBuffers() {
+	if r.lease != nil {
+		r.lease.unref()
+		r.lease = nil
+	} else {
+		releaseBuffer(r.decompressed)
+	}
+	r.decompressed = nil
+}
+
+// readLeasedBytes reads n bytes of a message key or value from rd. When the
+// reader is positioned on a decompressed message set, the returned slice
+// aliases the decompressed buffer, otherwise the bytes are copied to a buffer
+// acquired from the pool. In both cases *lease is set to the lease retaining
+// the buffer, the same lease is used for the key and value of a message.
+func (r *messageSetReader) readLeasedBytes(lease **messageLease, rd *bufio.Reader, sz int, n int) ([]byte, int, error) {
+	if n <= 0 {
+		return nil, sz, nil
+	}
+	if n > sz {
+		sz, _ = discardN(rd, sz, sz)
+		return nil, sz, errShortRead
+	}
+
+	if s := r.readerStack; s.lease != nil && s.reader == rd {
+		if *lease == nil {
+			s.lease.ref()
+			*lease = &messageLease{buffer: s.lease}
+		}
+		i := len(s.data) - sz
+		b := s.data[i : i+n : i+n]
+		sz, err := discardN(rd, sz, n)
+		return b, sz, err
+	}
+
+	if *lease == nil {
+		*lease = &messageLease{buffer: acquireBufferLease()}
+	}
+	buf := (*lease).buffer.buffer
+	i := buf.Len()
+	c, err := io.CopyN(buf, rd, int64(n))
+	return buf.Bytes()[i : i+int(c) : i+int(c)], sz - int(c), err
+}
+
 func (r *messageSetReader) discardBytes() (err error) {
 	r.remain, err = discardBytes(r.reader, r.remain)
 	return
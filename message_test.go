@@ -535,6 +535,82 @@ func TestMessageSetReader(t *testing.T) {
 
 }
 
+func TestMessageSetReaderPooledBuffers(t *testing.T) {
+	msgs := make([]Message, 8)
+	for i := range msgs {
+		msgs[i] = Message{
+			Time:   time.Now(),
+			Offset: int64(i),
+			Key:    []byte(fmt.Sprintf("key-%d", i)),
+			Value:  bytes.Repeat([]byte{byte('a' + i)}, 100*(i+1)),
+		}
+	}
+	builder := fetchResponseBuilder{
+		header: fetchResponseHeader{
+			highWatermarkOffset: 8,
+			lastStableOffset:    8,
+			topic:               "test-topic",
+		},
+		msgSets: []messageSetBuilder{
+			v2MessageSetBuilder{
+				codec: new(snappy.Codec),
+				msgs:  []Message{msgs[0], msgs[1]},
+			},
+			v2MessageSetBuilder{
+				msgs: []Message{msgs[2], msgs[3]},
+			},
+			v1MessageSetBuilder{
+				codec: new(gzip.Codec),
+				msgs:  []Message{msgs[4]},
+			},
+			v2MessageSetBuilder{
+				codec: new(zstd.Codec),
+				msgs:  []Message{msgs[5], msgs[6], msgs[7]},
+			},
+		},
+	}
+
+	rh, err := newReaderHelper(t, builder.bytes())
+	require.NoError(t, err)
+	rh.pooled = true
+
+	// Messages are all read before being checked to verify that decoding the
+	// next message sets does not overwrite the buffers they alias.
+	read := make([]Message, len(msgs))
+	for i := range read {
+		read[i] = rh.readMessage()
+	}
+
+	for i, msg := range read {
+		require.Equal(t, msgs[i].Offset, msg.Offset)
+		require.Equal(t, string(msgs[i].Key), string(msg.Key))
+		require.Equal(t, string(msgs[i].Value), string(msg.Value))
+		require.NotNil(t, msg.lease)
+	}
+
+	// Messages of compressed message sets alias the decompressed buffer.
+	require.True(t, read[0].lease.buffer == read[1].lease.buffer)
+	require.True(t, read[5].lease.buffer == read[6].lease.buffer)
+	require.True(t, read[1].lease.buffer != read[5].lease.buffer)
+	// Messages of uncompressed message sets are copied to their own buffer.
+	require.True(t, read[2].lease.buffer != read[3].lease.buffer)
+
+	lease := read[5].lease.buffer
+	rh.releaseBuffers()
+	require.EqualValues(t, 3, lease.refc)
+
+	for i := range read {
+		copied := read[i]
+		read[i].Release()
+		require.Nil(t, read[i].Key)
+		require.Nil(t, read[i].Value)
+		read[i].Release() // no-op once released
+		copied.Release()  // copies share the released lease
+		require.Nil(t, copied.Value)
+	}
+	require.EqualValues(t, 0, lease.refc)
+}
+
 func TestMessageSetReaderEmpty(t *testing.T) {
 	m := messageSetReader{empty: true}
 
@@ -736,6 +812,16 @@ func (r *readerHelper) readMessageErr() (msg Message, err error) {
 		msg.Value, remain, err = readNewBytes(r, size, nbytes)
 		return
 	}
+	if r.pooled {
+		keyFunc = func(rd *bufio.Reader, size int, nbytes int) (remain int, err error) {
+			msg.Key, remain, err = r.readLeasedBytes(&msg.lease, rd, size, nbytes)
+			return
+		}
+		valueFunc = func(rd *bufio.Reader, size int, nbytes int) (remain int, err error) {
+			msg.Value, remain, err = r.readLeasedBytes(&msg.lease, rd, size, nbytes)
+			return
+		}
+	}
 	var timestamp int64
 	var headers []Header
 	r.offset, _, timestamp, headers, err = r.messageSetReader.readMessage(r.offset, keyFunc, valueFunc)
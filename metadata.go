@@ -77,6 +77,7 @@ func (c *Client) Metadata(ctx context.Context, req *MetadataRequest) (*MetadataR
 	for i, t := range res.Topics {
 		ret.Topics[i] = Topic{
 			Name:       t.Name,
+			ID:         t.TopicID,
 			Internal:   t.IsInternal,
 			Partitions: make([]Partition, len(t.Partitions)),
 			Error:      makeError(t.ErrorCode, ""),
@@ -84,12 +85,13 @@ func (c *Client) Metadata(ctx context.Context, req *MetadataRequest) (*MetadataR
 
 		for j, p := range t.Partitions {
 			partition := Partition{
-				Topic:    t.Name,
-				ID:       int(p.PartitionIndex),
-				Leader:   brokers[p.LeaderID],
-				Replicas: make([]Broker, len(p.ReplicaNodes)),
-				Isr:      make([]Broker, len(p.IsrNodes)),
-				Error:    makeError(p.ErrorCode, ""),
+				Topic:       t.Name,
+				ID:          int(p.PartitionIndex),
+				Leader:      brokers[p.LeaderID],
+				Replicas:    make([]Broker, len(p.ReplicaNodes)),
+				Isr:         make([]Broker, len(p.IsrNodes)),
+				LeaderEpoch: int(p.LeaderEpoch),
+				Error:       makeError(p.ErrorCode, ""),
 			}
 
 			for i, id := range p.ReplicaNodes {
@@ -285,3 +287,70 @@ func (p partitionMetadataV6) writeTo(wb *writeBuffer) {
 	wb.writeInt32Array(p.Isr)
 	wb.writeInt32Array(p.OfflineReplicas)
 }
+
+type metadataResponseV7 struct {
+	ThrottleTimeMs int32
+	Brokers        []brokerMetadataV1
+	ClusterId      string
+	ControllerID   int32
+	Topics         []topicMetadataV7
+}
+
+func (r metadataResponseV7) size() int32 {
+	n1 := sizeofArray(len(r.Brokers), func(i int) int32 { return r.Brokers[i].size() })
+	n2 := sizeofNullableString(&r.ClusterId)
+	n3 := sizeofArray(len(r.Topics), func(i int) int32 { return r.Topics[i].size() })
+	return 4 + 4 + n1 + n2 + n3
+}
+
+func (r metadataResponseV7) writeTo(wb *writeBuffer) {
+	wb.writeInt32(r.ThrottleTimeMs)
+	wb.writeArray(len(r.Brokers), func(i int) { r.Brokers[i].writeTo(wb) })
+	wb.writeString(r.ClusterId)
+	wb.writeInt32(r.ControllerID)
+	wb.writeArray(len(r.Topics), func(i int) { r.Topics[i].writeTo(wb) })
+}
+
+type topicMetadataV7 struct {
+	TopicErrorCode int16
+	TopicName      string
+	Internal       bool
+	Partitions     []partitionMetadataV7
+}
+
+func (t topicMetadataV7) size() int32 {
+	return 2 + 1 +
+		sizeofString(t.TopicName) +
+		sizeofArray(len(t.Partitions), func(i int) int32 { return t.Partitions[i].size() })
+}
+
+func (t topicMetadataV7) writeTo(wb *writeBuffer) {
+	wb.writeInt16(t.TopicErrorCode)
+	wb.writeString(t.TopicName)
+	wb.writeBool(t.Internal)
+	wb.writeArray(len(t.Partitions), func(i int) { t.Partitions[i].writeTo(wb) })
+}
+
+type partitionMetadataV7 struct {
+	PartitionErrorCode int16
+	PartitionID        int32
+	Leader             int32
+	LeaderEpoch        int32
+	Replicas           []int32
+	Isr                []int32
+	OfflineReplicas    []int32
+}
+
+func (p partitionMetadataV7) size() int32 {
+	return 2 + 4 + 4 + 4 + sizeofInt32Array(p.Replicas) + sizeofInt32Array(p.Isr) + sizeofInt32Array(p.OfflineReplicas)
+}
+
+func (p partitionMetadataV7) writeTo(wb *writeBuffer) {
+	wb.writeInt16(p.PartitionErrorCode)
+	wb.writeInt32(p.PartitionID)
+	wb.writeInt32(p.Leader)
+	wb.writeInt32(p.LeaderEpoch)
+	wb.writeInt32Array(p.Replicas)
+	wb.writeInt32Array(p.Isr)
+	wb.writeInt32Array(p.OfflineReplicas)
+}
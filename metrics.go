@@ -0,0 +1,328 @@
+package kafka
+
+import (
+	"strconv"
+	"time"
+)
+
+// MetricKind represents the type of values reported for a metric.
+type MetricKind int
+
+const (
+	// MetricCounter is the kind of monotonic counters. Values reported for
+	// counters are deltas that must be added to the current total.
+	MetricCounter MetricKind = iota
+
+	// MetricHistogram is the kind of metrics recording distributions of values,
+	// like request latencies or batch sizes.
+	MetricHistogram
+)
+
+func (k MetricKind) String() string {
+	switch k {
+	case MetricCounter:
+		return "counter"
+	case MetricHistogram:
+		return "histogram"
+	default:
+		return "MetricKind(" + strconv.Itoa(int(k)) + ")"
+	}
+}
+
+// Metric describes a metric emitted by kafka-go.
+//
+// Metric values are immutable descriptors, the package reports values for the
+// same metric with the same pointer, which recorders may use as a key to cache
+// the instruments that they create.
+type Metric struct {
+	// Name of the metric, as a dot-separated identifier (e.g.
+	// "kafka.writer.messages").
+	Name string
+
+	// Kind of the metric.
+	Kind MetricKind
+
+	// Unit of the values, following the UCUM conventions used by
+	// OpenTelemetry: "s" for durations in seconds, "By" for sizes in bytes,
+	// or annotations like "{message}" for plain counts.
+	Unit string
+
+	// Human-readable description of the metric.
+	Description string
+
+	// Names of the labels that values of the metric are reported with. Label
+	// values are passed to the recorder in the same order.
+	Labels []string
+}
+
+// MetricsRecorder is an interface implemented by types which export metrics
+// emitted by kafka-go to a monitoring system.
+//
+// The kafka-go/prometheus and kafka-go/otel packages provide implementations
+// of this interface for Prometheus and OpenTelemetry.
+//
+// MetricsRecorder implementations must be safe to use concurrently from
+// multiple goroutines. The methods are invoked synchronously on hot code
+// paths, they should avoid blocking.
+type MetricsRecorder interface {
+	// Add increments the counter m by value, value is never negative.
+	Add(m *Metric, value int64, labelValues ...string)
+
+	// Record reports value in the histogram m.
+	Record(m *Metric, value float64, labelValues ...string)
+}
+
+// Metrics returns the list of all metrics that kafka-go may report to a
+// MetricsRecorder. Programs may use it to pre-register the metrics with their
+// monitoring system.
+func Metrics() []*Metric {
+	return []*Metric{
+		writerWrites,
+		writerMessages,
+		writerBytes,
+		writerErrors,
+		writerRetries,
+		writerWriteDuration,
+		writerBatchSize,
+		writerBatchBytes,
+		writerThrottleDuration,
+		readerFetches,
+		readerMessages,
+		readerBytes,
+		readerErrors,
+		readerFetchDuration,
+		readerThrottleDuration,
+		consumerGroupGenerations,
+		consumerGroupErrors,
+		consumerGroupRebalanceDuration,
+		transportRequests,
+		transportErrors,
+		transportRequestDuration,
+		transportConnections,
+		transportConnectionErrors,
+		transportConnectDuration,
+	}
+}
+
+var (
+	topicPartitionLabels = []string{"topic", "partition"}
+	groupLabels          = []string{"group"}
+	brokerLabels         = []string{"broker"}
+	brokerApiKeyLabels   = []string{"broker", "api_key"}
+)
+
+var (
+	writerWrites = &Metric{
+		Name:        "kafka.writer.writes",
+		Kind:        MetricCounter,
+		Unit:        "{request}",
+		Description: "Number of produce requests sent by writers.",
+		Labels:      topicPartitionLabels,
+	}
+
+	writerMessages = &Metric{
+		Name:        "kafka.writer.messages",
+		Kind:        MetricCounter,
+		Unit:        "{message}",
+		Description: "Number of messages written by writers.",
+		Labels:      topicPartitionLabels,
+	}
+
+	writerBytes = &Metric{
+		Name:        "kafka.writer.bytes",
+		Kind:        MetricCounter,
+		Unit:        "By",
+		Description: "Number of bytes of message keys and values written by writers.",
+		Labels:      topicPartitionLabels,
+	}
+
+	writerErrors = &Metric{
+		Name:        "kafka.writer.errors",
+		Kind:        MetricCounter,
+		Unit:        "{error}",
+		Description: "Number of produce requests which failed.",
+		Labels:      topicPartitionLabels,
+	}
+
+	writerRetries = &Metric{
+		Name:        "kafka.writer.retries",
+		Kind:        MetricCounter,
+		Unit:        "{retry}",
+		Description: "Number of produce requests retried after an error.",
+		Labels:      topicPartitionLabels,
+	}
+
+	writerWriteDuration = &Metric{
+		Name:        "kafka.writer.write.duration",
+		Kind:        MetricHistogram,
+		Unit:        "s",
+		Description: "Latency of produce requests sent by writers.",
+		Labels:      topicPartitionLabels,
+	}
+
+	writerBatchSize = &Metric{
+		Name:        "kafka.writer.batch.size",
+		Kind:        MetricHistogram,
+		Unit:        "{message}",
+		Description: "Number of messages in batches written by writers.",
+		Labels:      topicPartitionLabels,
+	}
+
+	writerBatchBytes = &Metric{
+		Name:        "kafka.writer.batch.bytes",
+		Kind:        MetricHistogram,
+		Unit:        "By",
+		Description: "Size of batches written by writers.",
+		Labels:      topicPartitionLabels,
+	}
+
+	writerThrottleDuration = &Metric{
+		Name:        "kafka.writer.throttle.duration",
+		Kind:        MetricHistogram,
+		Unit:        "s",
+		Description: "Throttle time of produce responses throttled by the brokers.",
+		Labels:      topicPartitionLabels,
+	}
+
+	readerFetches = &Metric{
+		Name:        "kafka.reader.fetches",
+		Kind:        MetricCounter,
+		Unit:        "{request}",
+		Description: "Number of fetch requests sent by readers.",
+		Labels:      topicPartitionLabels,
+	}
+
+	readerMessages = &Metric{
+		Name:        "kafka.reader.messages",
+		Kind:        MetricCounter,
+		Unit:        "{message}",
+		Description: "Number of messages consumed by readers.",
+		Labels:      topicPartitionLabels,
+	}
+
+	readerBytes = &Metric{
+		Name:        "kafka.reader.bytes",
+		Kind:        MetricCounter,
+		Unit:        "By",
+		Description: "Number of bytes of message keys and values consumed by readers.",
+		Labels:      topicPartitionLabels,
+	}
+
+	readerErrors = &Metric{
+		Name:        "kafka.reader.errors",
+		Kind:        MetricCounter,
+		Unit:        "{error}",
+		Description: "Number of errors that occurred while reading from partitions.",
+		Labels:      topicPartitionLabels,
+	}
+
+	readerFetchDuration = &Metric{
+		Name:        "kafka.reader.fetch.duration",
+		Kind:        MetricHistogram,
+		Unit:        "s",
+		Description: "Time that readers waited for fetch responses.",
+		Labels:      topicPartitionLabels,
+	}
+
+	readerThrottleDuration = &Metric{
+		Name:        "kafka.reader.throttle.duration",
+		Kind:        MetricHistogram,
+		Unit:        "s",
+		Description: "Throttle time of fetch responses throttled by the brokers.",
+		Labels:      topicPartitionLabels,
+	}
+
+	consumerGroupGenerations = &Metric{
+		Name:        "kafka.consumergroup.generations",
+		Kind:        MetricCounter,
+		Unit:        "{generation}",
+		Description: "Number of generations joined by consumer group members.",
+		Labels:      groupLabels,
+	}
+
+	consumerGroupErrors = &Metric{
+		Name:        "kafka.consumergroup.errors",
+		Kind:        MetricCounter,
+		Unit:        "{error}",
+		Description: "Number of errors that occurred while joining consumer group generations.",
+		Labels:      groupLabels,
+	}
+
+	consumerGroupRebalanceDuration = &Metric{
+		Name:        "kafka.consumergroup.rebalance.duration",
+		Kind:        MetricHistogram,
+		Unit:        "s",
+		Description: "Time taken to join, sync and fetch the offsets of a consumer group generation.",
+		Labels:      groupLabels,
+	}
+
+	transportRequests = &Metric{
+		Name:        "kafka.transport.requests",
+		Kind:        MetricCounter,
+		Unit:        "{request}",
+		Description: "Number of requests sent by transports.",
+		Labels:      brokerApiKeyLabels,
+	}
+
+	transportErrors = &Metric{
+		Name:        "kafka.transport.errors",
+		Kind:        MetricCounter,
+		Unit:        "{error}",
+		Description: "Number of requests sent by transports which failed with a network or protocol error.",
+		Labels:      brokerApiKeyLabels,
+	}
+
+	transportRequestDuration = &Metric{
+		Name:        "kafka.transport.request.duration",
+		Kind:        MetricHistogram,
+		Unit:        "s",
+		Description: "Latency of requests sent by transports.",
+		Labels:      brokerApiKeyLabels,
+	}
+
+	transportConnections = &Metric{
+		Name:        "kafka.transport.connections",
+		Kind:        MetricCounter,
+		Unit:        "{connection}",
+		Description: "Number of connections established by transports.",
+		Labels:      brokerLabels,
+	}
+
+	transportConnectionErrors = &Metric{
+		Name:        "kafka.transport.connection.errors",
+		Kind:        MetricCounter,
+		Unit:        "{error}",
+		Description: "Number of connections that transports failed to establish.",
+		Labels:      brokerLabels,
+	}
+
+	transportConnectDuration = &Metric{
+		Name:        "kafka.transport.connect.duration",
+		Kind:        MetricHistogram,
+		Unit:        "s",
+		Description: "Time taken to establish connections, including TLS and SASL handshakes.",
+		Labels:      brokerLabels,
+	}
+)
+
+// metrics wraps a MetricsRecorder to make reporting metrics a no-op when no
+// recorder was configured.
+type metrics struct {
+	recorder MetricsRecorder
+}
+
+func (m metrics) add(metric *Metric, value int64, labelValues ...string) {
+	if m.recorder != nil {
+		m.recorder.Add(metric, value, labelValues...)
+	}
+}
+
+func (m metrics) record(metric *Metric, value float64, labelValues ...string) {
+	if m.recorder != nil {
+		m.recorder.Record(metric, value, labelValues...)
+	}
+}
+
+func (m metrics) recordDuration(metric *Metric, d time.Duration, labelValues ...string) {
+	m.record(metric, d.Seconds(), labelValues...)
+}
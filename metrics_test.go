@@ -0,0 +1,210 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PerchSecurity/kafka-go/kafkatest"
+	"github.com/PerchSecurity/kafka-go/protocol"
+	"github.com/PerchSecurity/kafka-go/protocol/apiversions"
+)
+
+// testMetricsRecorder is a MetricsRecorder which keeps the values it receives
+// in memory, indexed by metric name and label values.
+type testMetricsRecorder struct {
+	mutex      sync.Mutex
+	counters   map[string]int64
+	histograms map[string][]float64
+}
+
+func metricKey(m *Metric, labelValues []string) string {
+	return m.Name + "{" + strings.Join(labelValues, ",") + "}"
+}
+
+func (r *testMetricsRecorder) Add(m *Metric, value int64, labelValues ...string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.counters == nil {
+		r.counters = make(map[string]int64)
+	}
+	r.counters[metricKey(m, labelValues)] += value
+}
+
+func (r *testMetricsRecorder) Record(m *Metric, value float64, labelValues ...string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.histograms == nil {
+		r.histograms = make(map[string][]float64)
+	}
+	k := metricKey(m, labelValues)
+	r.histograms[k] = append(r.histograms[k], value)
+}
+
+func (r *testMetricsRecorder) counter(m *Metric, labelValues ...string) int64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.counters[metricKey(m, labelValues)]
+}
+
+func (r *testMetricsRecorder) histogram(m *Metric, labelValues ...string) []float64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.histograms[metricKey(m, labelValues)]
+}
+
+func TestMetricsLabels(t *testing.T) {
+	seen := make(map[string]bool)
+
+	for _, m := range Metrics() {
+		if seen[m.Name] {
+			t.Errorf("metric %s is declared more than once", m.Name)
+		}
+		seen[m.Name] = true
+
+		if len(m.Labels) == 0 {
+			t.Errorf("metric %s has no labels", m.Name)
+		}
+		if m.Kind == MetricHistogram && m.Unit == "" {
+			t.Errorf("histogram %s has no unit", m.Name)
+		}
+	}
+}
+
+func TestTransportMetrics(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, server := net.Pipe()
+	defer client.Close()
+
+	served := make(chan protocol.ApiKey, 10)
+	go serveSASLBroker(server, served, 0)
+
+	recorder := &testMetricsRecorder{}
+	reqs := make(chan connRequest)
+	c := &conn{
+		reqs: reqs,
+		group: &connGroup{
+			addr: &networkAddress{network: "tcp", address: "localhost:9092"},
+			pool: &connPool{
+				idleTimeout: time.Minute,
+				metrics:     metrics{recorder: recorder},
+			},
+		},
+	}
+	defer c.close()
+	go c.run(protocol.NewConn(client, "test"), reqs)
+
+	for i := 0; i < 2; i++ {
+		res := make(async, 1)
+		reqs <- connRequest{ctx: ctx, req: &apiversions.Request{}, res: res}
+		if _, err := res.await(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	labels := []string{"localhost:9092", protocol.ApiVersions.String()}
+
+	if n := recorder.counter(transportRequests, labels...); n != 2 {
+		t.Errorf("expected 2 requests to be counted, got %d", n)
+	}
+	if n := recorder.counter(transportErrors, labels...); n != 0 {
+		t.Errorf("expected no errors to be counted, got %d", n)
+	}
+	if values := recorder.histogram(transportRequestDuration, labels...); len(values) != 2 {
+		t.Errorf("expected 2 request latencies to be recorded, got %d", len(values))
+	}
+}
+
+func TestWriterMetricsRetries(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cluster, err := kafkatest.NewCluster(kafkatest.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cluster.Close()
+
+	const topic = "writer-metrics"
+	if err := cluster.CreateTopic(topic, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := &testMetricsRecorder{}
+	w := &Writer{
+		Addr:            cluster.Addr(),
+		Topic:           topic,
+		RequiredAcks:    RequireOne,
+		BatchTimeout:    time.Millisecond,
+		WriteBackoffMin: time.Millisecond,
+		WriteBackoffMax: time.Millisecond,
+		Metrics:         recorder,
+	}
+	defer w.Close()
+
+	// The first two attempts fail, the messages are only written once.
+	cluster.InjectError(protocol.Produce, int16(NotEnoughReplicas), 2)
+
+	msgs := makeTestSequence(5)
+	if err := w.WriteMessages(ctx, msgs...); err != nil {
+		t.Fatal(err)
+	}
+
+	labels := []string{topic, "0"}
+
+	if n := recorder.counter(writerWrites, labels...); n != 3 {
+		t.Errorf("expected 3 writes to be counted, got %d", n)
+	}
+	if n := recorder.counter(writerErrors, labels...); n != 2 {
+		t.Errorf("expected 2 errors to be counted, got %d", n)
+	}
+	if n := recorder.counter(writerMessages, labels...); n != int64(len(msgs)) {
+		t.Errorf("expected %d messages to be counted, got %d", len(msgs), n)
+	}
+}
+
+func TestConsumerGroupMetrics(t *testing.T) {
+	recorder := &testMetricsRecorder{}
+	groupID := makeGroupID()
+
+	mc := mockCoordinator{
+		findCoordinatorFunc: func(findCoordinatorRequestV0) (findCoordinatorResponseV0, error) {
+			return findCoordinatorResponseV0{}, errors.New("dial error")
+		},
+	}
+
+	group, err := NewConsumerGroup(ConsumerGroupConfig{
+		ID:               groupID,
+		Topics:           []string{"test"},
+		Brokers:          []string{"no-such-broker"},
+		JoinGroupBackoff: time.Second,
+		Metrics:          recorder,
+		connect: func(*Dialer, ...string) (coordinator, error) {
+			return mc, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer group.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := group.Next(ctx); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if n := recorder.counter(consumerGroupErrors, groupID); n != 1 {
+		t.Errorf("expected 1 error to be counted, got %d", n)
+	}
+	if n := recorder.counter(consumerGroupGenerations, groupID); n != 0 {
+		t.Errorf("expected no generations to be counted, got %d", n)
+	}
+}
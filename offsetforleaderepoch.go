@@ -0,0 +1,240 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/PerchSecurity/kafka-go/protocol/offsetforleaderepoch"
+)
+
+// OffsetForLeaderEpochRequest represents a request sent to a kafka broker to
+// retrieve the end offsets of partition leader epochs.
+type OffsetForLeaderEpochRequest struct {
+	// Address of the kafka broker to send the request to.
+	Addr net.Addr
+
+	// Set of topic partitions to retrieve the end offsets for.
+	Topics map[string][]OffsetForLeaderEpochPartition
+}
+
+// OffsetForLeaderEpochPartition represents the leader epoch of a partition to
+// retrieve the end offset for.
+type OffsetForLeaderEpochPartition struct {
+	// ID of the partition.
+	Partition int
+
+	// The current leader epoch known by the program, which the broker uses to
+	// fence requests sent with stale metadata. Set to -1 to disable fencing.
+	//
+	// This field requires the kafka broker to support the OffsetForLeaderEpoch
+	// API in version 2 or above (otherwise the value is ignored).
+	CurrentLeaderEpoch int
+
+	// The leader epoch to retrieve the end offset for.
+	LeaderEpoch int
+}
+
+// OffsetForLeaderEpochResponse represents a response from a kafka broker to an
+// offset for leader epoch request.
+type OffsetForLeaderEpochResponse struct {
+	// The amount of time that the broker throttled the request.
+	Throttle time.Duration
+
+	// Set of topic partitions that the kafka broker has returned end offsets
+	// for.
+	Topics map[string][]OffsetForLeaderEpochResponsePartition
+}
+
+// OffsetForLeaderEpochResponsePartition represents the end offset of a leader
+// epoch on a partition.
+type OffsetForLeaderEpochResponsePartition struct {
+	// ID of the partition.
+	Partition int
+
+	// The largest leader epoch of the partition which is smaller than or equal
+	// to the requested epoch, or -1 if unknown.
+	//
+	// This field requires the kafka broker to support the OffsetForLeaderEpoch
+	// API in version 1 or above (otherwise the value is zero).
+	LeaderEpoch int
+
+	// The end offset of LeaderEpoch, which is the start offset of the next
+	// leader epoch, or the log end offset if LeaderEpoch is the current leader
+	// epoch. The value is -1 if the epoch is unknown to the broker.
+	EndOffset int64
+
+	// An error that may have occurred while attempting to retrieve the end
+	// offset of the partition leader epoch.
+	//
+	// The error contains the kafka error code. Programs may use the standard
+	// errors.Is function to test the error against kafka error codes.
+	Error error
+}
+
+// OffsetForLeaderEpoch sends an offset for leader epoch request to the leaders
+// of the requested partitions and returns the merged response.
+//
+// Programs use this API to detect log truncation after an unclean leader
+// election: if the end offset of the epoch of the last consumed message is
+// lower than the next offset to consume, the messages in between were removed
+// from the partition log (see KIP-320).
+func (c *Client) OffsetForLeaderEpoch(ctx context.Context, req *OffsetForLeaderEpochRequest) (*OffsetForLeaderEpochResponse, error) {
+	topics := make([]offsetforleaderepoch.RequestTopic, 0, len(req.Topics))
+
+	for topicName, partitions := range req.Topics {
+		requestPartitions := make([]offsetforleaderepoch.RequestPartition, len(partitions))
+
+		for i, p := range partitions {
+			requestPartitions[i] = offsetforleaderepoch.RequestPartition{
+				Partition:          int32(p.Partition),
+				CurrentLeaderEpoch: int32(p.CurrentLeaderEpoch),
+				LeaderEpoch:        int32(p.LeaderEpoch),
+			}
+		}
+
+		topics = append(topics, offsetforleaderepoch.RequestTopic{
+			Topic:      topicName,
+			Partitions: requestPartitions,
+		})
+	}
+
+	m, err := c.roundTrip(ctx, req.Addr, &offsetforleaderepoch.Request{
+		ReplicaID: -1,
+		Topics:    topics,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("kafka.(*Client).OffsetForLeaderEpoch: %w", err)
+	}
+
+	res := m.(*offsetforleaderepoch.Response)
+	ret := &OffsetForLeaderEpochResponse{
+		Throttle: makeDuration(res.ThrottleTimeMs),
+		Topics:   make(map[string][]OffsetForLeaderEpochResponsePartition, len(res.Topics)),
+	}
+
+	for _, t := range res.Topics {
+		partitions := make([]OffsetForLeaderEpochResponsePartition, len(t.Partitions))
+
+		for i, p := range t.Partitions {
+			partitions[i] = OffsetForLeaderEpochResponsePartition{
+				Partition:   int(p.Partition),
+				LeaderEpoch: int(p.LeaderEpoch),
+				EndOffset:   p.EndOffset,
+				Error:       makeError(p.ErrorCode, ""),
+			}
+		}
+
+		ret.Topics[t.Topic] = partitions
+	}
+
+	return ret, nil
+}
+
+type offsetForLeaderEpochRequestV2 struct {
+	Topics []offsetForLeaderEpochRequestTopicV2
+}
+
+func (r offsetForLeaderEpochRequestV2) size() int32 {
+	return sizeofArray(len(r.Topics), func(i int) int32 { return r.Topics[i].size() })
+}
+
+func (r offsetForLeaderEpochRequestV2) writeTo(wb *writeBuffer) {
+	wb.writeArray(len(r.Topics), func(i int) { r.Topics[i].writeTo(wb) })
+}
+
+type offsetForLeaderEpochRequestTopicV2 struct {
+	Topic      string
+	Partitions []offsetForLeaderEpochRequestPartitionV2
+}
+
+func (t offsetForLeaderEpochRequestTopicV2) size() int32 {
+	return sizeofString(t.Topic) +
+		sizeofArray(len(t.Partitions), func(i int) int32 { return t.Partitions[i].size() })
+}
+
+func (t offsetForLeaderEpochRequestTopicV2) writeTo(wb *writeBuffer) {
+	wb.writeString(t.Topic)
+	wb.writeArray(len(t.Partitions), func(i int) { t.Partitions[i].writeTo(wb) })
+}
+
+type offsetForLeaderEpochRequestPartitionV2 struct {
+	Partition          int32
+	CurrentLeaderEpoch int32
+	LeaderEpoch        int32
+}
+
+func (p offsetForLeaderEpochRequestPartitionV2) size() int32 {
+	return 4 + 4 + 4
+}
+
+func (p offsetForLeaderEpochRequestPartitionV2) writeTo(wb *writeBuffer) {
+	wb.writeInt32(p.Partition)
+	wb.writeInt32(p.CurrentLeaderEpoch)
+	wb.writeInt32(p.LeaderEpoch)
+}
+
+type offsetForLeaderEpochResponseV2 struct {
+	ThrottleTimeMs int32
+	Topics         []offsetForLeaderEpochResponseTopicV2
+}
+
+type offsetForLeaderEpochResponseTopicV2 struct {
+	Topic      string
+	Partitions []offsetForLeaderEpochResponsePartitionV2
+}
+
+type offsetForLeaderEpochResponsePartitionV2 struct {
+	ErrorCode   int16
+	Partition   int32
+	LeaderEpoch int32
+	EndOffset   int64
+}
+
+// offsetForLeaderEpoch returns the end offset of a leader epoch on the topic
+// partition of the connection, using the leader epoch configured on the
+// connection to fence the request.
+//
+// The method requires the broker to support the OffsetForLeaderEpoch API in
+// version 2 or above (kafka 2.1).
+func (c *Conn) offsetForLeaderEpoch(leaderEpoch int32) (epoch int32, endOffset int64, err error) {
+	if _, err = c.negotiateVersion(offsetForLeaderEpoch, v2); err != nil {
+		return -1, -1, err
+	}
+
+	err = c.readOperation(
+		func(deadline time.Time, id int32) error {
+			return c.writeRequest(offsetForLeaderEpoch, v2, id, offsetForLeaderEpochRequestV2{
+				Topics: []offsetForLeaderEpochRequestTopicV2{{
+					Topic: c.topic,
+					Partitions: []offsetForLeaderEpochRequestPartitionV2{{
+						Partition:          c.partition,
+						CurrentLeaderEpoch: atomic.LoadInt32(&c.leaderEpoch),
+						LeaderEpoch:        leaderEpoch,
+					}},
+				}},
+			})
+		},
+		func(deadline time.Time, size int) error {
+			var res offsetForLeaderEpochResponseV2
+			if err := c.readResponse(size, &res); err != nil {
+				return err
+			}
+
+			for _, t := range res.Topics {
+				for _, p := range t.Partitions {
+					if p.ErrorCode != 0 {
+						return Error(p.ErrorCode)
+					}
+					epoch, endOffset = p.LeaderEpoch, p.EndOffset
+					return nil
+				}
+			}
+
+			return fmt.Errorf("1 kafka partition was expected in the offset for leader epoch response but the client received none")
+		},
+	)
+	return
+}
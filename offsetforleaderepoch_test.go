@@ -0,0 +1,49 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	ktesting "github.com/PerchSecurity/kafka-go/testing"
+)
+
+func TestClientOffsetForLeaderEpoch(t *testing.T) {
+	if !ktesting.KafkaIsAtLeast("2.1.0") {
+		return
+	}
+
+	ctx := context.Background()
+	client, shutdown := newLocalClient()
+	defer shutdown()
+
+	topic := makeTopic()
+	createTopic(t, topic, 1)
+	defer deleteTopic(t, topic)
+
+	produceRecords(t, 10, client.Addr, topic, nil)
+
+	res, err := client.OffsetForLeaderEpoch(ctx, &OffsetForLeaderEpochRequest{
+		Topics: map[string][]OffsetForLeaderEpochPartition{
+			topic: {{Partition: 0, CurrentLeaderEpoch: -1, LeaderEpoch: 0}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	partitions := res.Topics[topic]
+	if len(partitions) != 1 {
+		t.Fatalf("expected 1 partition in the response, got %d", len(partitions))
+	}
+
+	p := partitions[0]
+	if p.Error != nil {
+		t.Fatal(p.Error)
+	}
+	if p.LeaderEpoch != 0 {
+		t.Errorf("leader epoch mismatch: want=0 got=%d", p.LeaderEpoch)
+	}
+	if p.EndOffset != 10 {
+		t.Errorf("end offset mismatch: want=10 got=%d", p.EndOffset)
+	}
+}
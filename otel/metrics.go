@@ -0,0 +1,111 @@
+// Package otel integrates kafka-go with OpenTelemetry.
+//
+// Tracer creates spans for the operations of writers, readers and transports,
+// and propagates the trace context from producers to consumers in the message
+// headers:
+//
+//	tracer := otel.NewTracer(tracerProvider.Tracer("kafka-go"), nil)
+//
+//	w := &kafka.Writer{
+//		Addr:      kafka.TCP("localhost:9092"),
+//		Transport: &kafka.Transport{Tracer: tracer},
+//		Tracer:    tracer,
+//	}
+//
+// MetricsRecorder exports the metrics of kafka-go through an OpenTelemetry
+// meter:
+//
+//	recorder, err := otel.NewMetricsRecorder(meterProvider.Meter("kafka-go"))
+//	if err != nil {
+//		...
+//	}
+//
+//	r := kafka.NewReader(kafka.ReaderConfig{
+//		Brokers: []string{"localhost:9092"},
+//		Topic:   "topic-A",
+//		Metrics: recorder,
+//	})
+package otel
+
+import (
+	"context"
+
+	"github.com/PerchSecurity/kafka-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// MetricsRecorder is an implementation of kafka.MetricsRecorder which reports
+// the metrics to an OpenTelemetry meter.
+//
+// The instruments are named after the kafka-go metrics (e.g.
+// "kafka.writer.messages"), and the label values are reported as attributes.
+//
+// MetricsRecorder values are safe to use concurrently from multiple
+// goroutines.
+type MetricsRecorder struct {
+	counters   map[*kafka.Metric]metric.Int64Counter
+	histograms map[*kafka.Metric]metric.Float64Histogram
+}
+
+// NewMetricsRecorder creates the instruments for all the metrics returned by
+// kafka.Metrics on meter, and returns a MetricsRecorder which reports values
+// to them.
+func NewMetricsRecorder(meter metric.Meter) (*MetricsRecorder, error) {
+	r := &MetricsRecorder{
+		counters:   make(map[*kafka.Metric]metric.Int64Counter),
+		histograms: make(map[*kafka.Metric]metric.Float64Histogram),
+	}
+
+	for _, m := range kafka.Metrics() {
+		switch m.Kind {
+		case kafka.MetricCounter:
+			c, err := meter.Int64Counter(m.Name,
+				metric.WithDescription(m.Description),
+				metric.WithUnit(m.Unit),
+			)
+			if err != nil {
+				return nil, err
+			}
+			r.counters[m] = c
+
+		case kafka.MetricHistogram:
+			h, err := meter.Float64Histogram(m.Name,
+				metric.WithDescription(m.Description),
+				metric.WithUnit(m.Unit),
+			)
+			if err != nil {
+				return nil, err
+			}
+			r.histograms[m] = h
+		}
+	}
+
+	return r, nil
+}
+
+// Add satisfies the kafka.MetricsRecorder interface.
+func (r *MetricsRecorder) Add(m *kafka.Metric, value int64, labelValues ...string) {
+	if c := r.counters[m]; c != nil {
+		c.Add(context.Background(), value, metric.WithAttributes(attributes(m, labelValues)...))
+	}
+}
+
+// Record satisfies the kafka.MetricsRecorder interface.
+func (r *MetricsRecorder) Record(m *kafka.Metric, value float64, labelValues ...string) {
+	if h := r.histograms[m]; h != nil {
+		h.Record(context.Background(), value, metric.WithAttributes(attributes(m, labelValues)...))
+	}
+}
+
+func attributes(m *kafka.Metric, labelValues []string) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(labelValues))
+	for i, value := range labelValues {
+		if i < len(m.Labels) {
+			attrs = append(attrs, attribute.String(m.Labels[i], value))
+		}
+	}
+	return attrs
+}
+
+var _ kafka.MetricsRecorder = (*MetricsRecorder)(nil)
@@ -0,0 +1,68 @@
+package otel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/PerchSecurity/kafka-go"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestMetricsRecorder(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	recorder, err := NewMetricsRecorder(provider.Meter("kafka-go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metrics := make(map[string]*kafka.Metric)
+	for _, m := range kafka.Metrics() {
+		metrics[m.Name] = m
+	}
+
+	recorder.Add(metrics["kafka.reader.messages"], 3, "topic-A", "0")
+	recorder.Add(metrics["kafka.reader.messages"], 2, "topic-A", "0")
+	recorder.Record(metrics["kafka.transport.request.duration"], 0.25, "localhost:9092", "Fetch")
+
+	var data metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	found := make(map[string]metricdata.Metrics)
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			found[m.Name] = m
+		}
+	}
+
+	messages, ok := found["kafka.reader.messages"].Data.(metricdata.Sum[int64])
+	if !ok || len(messages.DataPoints) != 1 {
+		t.Fatalf("unexpected data for kafka.reader.messages: %#v", found["kafka.reader.messages"].Data)
+	}
+	if !messages.IsMonotonic {
+		t.Error("expected kafka.reader.messages to be monotonic")
+	}
+	point := messages.DataPoints[0]
+	if point.Value != 5 {
+		t.Errorf("expected kafka.reader.messages to be 5, got %d", point.Value)
+	}
+	if topic, _ := point.Attributes.Value(attribute.Key("topic")); topic.AsString() != "topic-A" {
+		t.Errorf("expected the topic attribute to be topic-A, got %q", topic.AsString())
+	}
+
+	duration, ok := found["kafka.transport.request.duration"].Data.(metricdata.Histogram[float64])
+	if !ok || len(duration.DataPoints) != 1 {
+		t.Fatalf("unexpected data for kafka.transport.request.duration: %#v", found["kafka.transport.request.duration"].Data)
+	}
+	if apiKey, _ := duration.DataPoints[0].Attributes.Value(attribute.Key("api_key")); apiKey.AsString() != "Fetch" {
+		t.Errorf("expected the api_key attribute to be Fetch, got %q", apiKey.AsString())
+	}
+	if unit := found["kafka.transport.request.duration"].Unit; unit != "s" {
+		t.Errorf("expected the unit to be s, got %q", unit)
+	}
+}
@@ -0,0 +1,189 @@
+package otel
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/PerchSecurity/kafka-go"
+	"github.com/PerchSecurity/kafka-go/protocol"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracer is an implementation of kafka.Tracer which exports spans to an
+// OpenTelemetry tracer:
+//
+//   - a "publish" span of kind producer for each call to
+//     Writer.WriteMessages, its context is injected in the headers of the
+//     messages (by default in the W3C traceparent header),
+//   - a "send <topic>" span of kind client for each produce request, linked
+//     to the publish spans of the messages in the batch,
+//   - a span of kind client named after the API key (e.g. "Fetch") for each
+//     request sent by a Transport to a broker,
+//   - a "receive <topic>" span of kind consumer for each message returned by
+//     a Reader, linked to the publish span of the message.
+//
+// Tracer values are safe to use concurrently from multiple goroutines.
+type Tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// NewTracer returns a Tracer which creates spans with tracer, and propagates
+// their context in message headers with propagator. If propagator is nil, the
+// W3C trace context format is used.
+func NewTracer(tracer trace.Tracer, propagator propagation.TextMapPropagator) *Tracer {
+	if propagator == nil {
+		propagator = propagation.TraceContext{}
+	}
+	return &Tracer{
+		tracer:     tracer,
+		propagator: propagator,
+	}
+}
+
+var systemKafka = attribute.String("messaging.system", "kafka")
+
+// StartWrite satisfies the kafka.Tracer interface.
+func (t *Tracer) StartWrite(ctx context.Context, msgs []kafka.Message) (context.Context, func(error)) {
+	ctx, span := t.tracer.Start(ctx, "publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			systemKafka,
+			attribute.String("messaging.operation.name", "publish"),
+			attribute.Int("messaging.batch.message_count", len(msgs)),
+		),
+	)
+
+	for i := range msgs {
+		t.propagator.Inject(ctx, headerCarrier{&msgs[i].Headers})
+	}
+
+	return ctx, func(err error) { end(span, err) }
+}
+
+// StartProduce satisfies the kafka.Tracer interface.
+func (t *Tracer) StartProduce(ctx context.Context, topic string, partition int, msgs []kafka.Message) (context.Context, func(error)) {
+	var links []trace.Link
+	seen := make(map[trace.SpanID]bool)
+
+	for i := range msgs {
+		sc := t.spanContext(msgs[i])
+		if sc.IsValid() && !seen[sc.SpanID()] {
+			seen[sc.SpanID()] = true
+			links = append(links, trace.Link{SpanContext: sc})
+		}
+	}
+
+	ctx, span := t.tracer.Start(ctx, "send "+topic,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithLinks(links...),
+		trace.WithAttributes(
+			systemKafka,
+			attribute.String("messaging.operation.name", "send"),
+			attribute.String("messaging.destination.name", topic),
+			attribute.String("messaging.destination.partition.id", strconv.Itoa(partition)),
+			attribute.Int("messaging.batch.message_count", len(msgs)),
+		),
+	)
+
+	return ctx, func(err error) { end(span, err) }
+}
+
+// StartRoundTrip satisfies the kafka.Tracer interface.
+func (t *Tracer) StartRoundTrip(ctx context.Context, broker string, req protocol.Message) (context.Context, func(error)) {
+	apiKey := req.ApiKey()
+
+	ctx, span := t.tracer.Start(ctx, apiKey.String(),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			systemKafka,
+			attribute.String("server.address", broker),
+			attribute.String("kafka.api_key", apiKey.String()),
+		),
+	)
+
+	return ctx, func(err error) { end(span, err) }
+}
+
+// Consume satisfies the kafka.Tracer interface.
+func (t *Tracer) Consume(ctx context.Context, msg kafka.Message) {
+	var links []trace.Link
+	if sc := t.spanContext(msg); sc.IsValid() {
+		links = append(links, trace.Link{SpanContext: sc})
+	}
+
+	_, span := t.tracer.Start(ctx, "receive "+msg.Topic,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithLinks(links...),
+		trace.WithAttributes(
+			systemKafka,
+			attribute.String("messaging.operation.name", "receive"),
+			attribute.String("messaging.destination.name", msg.Topic),
+			attribute.String("messaging.destination.partition.id", strconv.Itoa(msg.Partition)),
+			attribute.Int64("messaging.kafka.offset", msg.Offset),
+		),
+	)
+	span.End()
+}
+
+// Extract returns a copy of ctx carrying the span context propagated in the
+// headers of msg, programs use it to create spans for the processing of the
+// message which are children of the span that published it.
+func (t *Tracer) Extract(ctx context.Context, msg kafka.Message) context.Context {
+	return t.propagator.Extract(ctx, headerCarrier{&msg.Headers})
+}
+
+func (t *Tracer) spanContext(msg kafka.Message) trace.SpanContext {
+	return trace.SpanContextFromContext(t.Extract(context.Background(), msg))
+}
+
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// headerCarrier adapts message headers to the propagation.TextMapCarrier
+// interface.
+type headerCarrier struct {
+	headers *[]kafka.Header
+}
+
+func (c headerCarrier) Get(key string) string {
+	for _, h := range *c.headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func (c headerCarrier) Set(key, value string) {
+	// The headers may share their backing array with the messages passed to
+	// the writer, always allocate a new slice instead of modifying it.
+	headers := make([]kafka.Header, 0, len(*c.headers)+1)
+	for _, h := range *c.headers {
+		if h.Key != key {
+			headers = append(headers, h)
+		}
+	}
+	*c.headers = append(headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, len(*c.headers))
+	for i, h := range *c.headers {
+		keys[i] = h.Key
+	}
+	return keys
+}
+
+var (
+	_ kafka.Tracer               = (*Tracer)(nil)
+	_ propagation.TextMapCarrier = headerCarrier{}
+)
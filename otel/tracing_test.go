@@ -0,0 +1,153 @@
+package otel
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/PerchSecurity/kafka-go"
+	"github.com/PerchSecurity/kafka-go/protocol"
+	"github.com/PerchSecurity/kafka-go/protocol/metadata"
+	"github.com/PerchSecurity/kafka-go/protocol/produce"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type roundTripFunc func(context.Context, net.Addr, protocol.Message) (protocol.Message, error)
+
+func (f roundTripFunc) RoundTrip(ctx context.Context, addr net.Addr, msg protocol.Message) (protocol.Message, error) {
+	return f(ctx, addr, msg)
+}
+
+func newTestTracer() (*Tracer, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	return NewTracer(provider.Tracer("kafka-go"), nil), recorder
+}
+
+func findSpan(spans []sdktrace.ReadOnlySpan, name string) sdktrace.ReadOnlySpan {
+	for _, span := range spans {
+		if span.Name() == name {
+			return span
+		}
+	}
+	return nil
+}
+
+func TestTracerWriter(t *testing.T) {
+	tracer, recorder := newTestTracer()
+
+	transport := roundTripFunc(func(ctx context.Context, addr net.Addr, msg protocol.Message) (protocol.Message, error) {
+		switch req := msg.(type) {
+		case *metadata.Request:
+			return &metadata.Response{
+				Brokers: []metadata.ResponseBroker{{NodeID: 1, Host: "localhost", Port: 9092}},
+				Topics: []metadata.ResponseTopic{{
+					Name:       "topic-A",
+					Partitions: []metadata.ResponsePartition{{PartitionIndex: 0, LeaderID: 1}},
+				}},
+			}, nil
+		case *produce.Request:
+			return &produce.Response{
+				Topics: []produce.ResponseTopic{{
+					Topic:      req.Topics[0].Topic,
+					Partitions: []produce.ResponsePartition{{Partition: 0}},
+				}},
+			}, nil
+		default:
+			t.Fatalf("unexpected request: %T", msg)
+			return nil, nil
+		}
+	})
+
+	var written []kafka.Message
+	w := &kafka.Writer{
+		Addr:      kafka.TCP("localhost:9092"),
+		Topic:     "topic-A",
+		Transport: transport,
+		BatchSize: 2,
+		Tracer:    tracer,
+		Completion: func(messages []kafka.Message, err error) {
+			written = append(written, messages...)
+		},
+	}
+	defer w.Close()
+
+	msgs := []kafka.Message{{Value: []byte("hello")}, {Value: []byte("world")}}
+	if err := w.WriteMessages(context.Background(), msgs...); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, msg := range msgs {
+		if len(msg.Headers) != 0 {
+			t.Error("the messages passed to the writer must not be modified")
+		}
+	}
+
+	spans := recorder.Ended()
+	publish := findSpan(spans, "publish")
+	if publish == nil {
+		t.Fatal("no publish span was recorded")
+	}
+	if kind := publish.SpanKind(); kind != trace.SpanKindProducer {
+		t.Errorf("expected the publish span to be of kind producer, got %s", kind)
+	}
+
+	send := findSpan(spans, "send topic-A")
+	if send == nil {
+		t.Fatal("no send span was recorded")
+	}
+	if links := send.Links(); len(links) != 1 || links[0].SpanContext.SpanID() != publish.SpanContext().SpanID() {
+		t.Errorf("expected the send span to be linked to the publish span, got %+v", links)
+	}
+
+	if len(written) != 2 {
+		t.Fatalf("expected 2 messages to be written, got %d", len(written))
+	}
+	for _, msg := range written {
+		ctx := tracer.Extract(context.Background(), msg)
+		if sc := trace.SpanContextFromContext(ctx); sc.SpanID() != publish.SpanContext().SpanID() {
+			t.Errorf("expected the publish span context to be propagated in the message headers, got %+v", msg.Headers)
+		}
+	}
+}
+
+func TestTracerConsume(t *testing.T) {
+	tracer, recorder := newTestTracer()
+
+	ctx, finish := tracer.StartWrite(context.Background(), nil)
+	finish(nil)
+	producer := trace.SpanContextFromContext(ctx)
+
+	msgs := []kafka.Message{{Topic: "topic-A", Partition: 1, Offset: 42}}
+	tracer.StartWrite(ctx, msgs)
+	tracer.Consume(context.Background(), msgs[0])
+
+	receive := findSpan(recorder.Ended(), "receive topic-A")
+	if receive == nil {
+		t.Fatal("no receive span was recorded")
+	}
+	if kind := receive.SpanKind(); kind != trace.SpanKindConsumer {
+		t.Errorf("expected the receive span to be of kind consumer, got %s", kind)
+	}
+
+	links := receive.Links()
+	if len(links) != 1 {
+		t.Fatalf("expected the receive span to have 1 link, got %d", len(links))
+	}
+	if links[0].SpanContext.TraceID() != producer.TraceID() {
+		t.Error("expected the receive span to be linked to the trace of the producer")
+	}
+}
+
+func TestTracerRoundTrip(t *testing.T) {
+	tracer, recorder := newTestTracer()
+
+	_, finish := tracer.StartRoundTrip(context.Background(), "localhost:9092", &metadata.Request{})
+	finish(nil)
+
+	if span := findSpan(recorder.Ended(), "Metadata"); span == nil {
+		t.Fatal("no span was recorded for the metadata request")
+	}
+}
@@ -94,6 +94,12 @@ type ProduceRequest struct {
 	// An optional compression algorithm to apply to the batch of records sent
 	// to the kafka broker.
 	Compression Compression
+
+	// An optional codec used to compress the batch of records, which takes
+	// precedence over Compression. This allows the program to use a codec
+	// configured differently from the one installed in the compress.Codecs
+	// table, for example a zstd codec with a dictionary.
+	CompressionCodec CompressionCodec
 }
 
 // ProduceResponse represents a response from a kafka broker to a produce
@@ -144,6 +150,9 @@ type ProduceResponse struct {
 // the error will be nil on success.
 func (c *Client) Produce(ctx context.Context, req *ProduceRequest) (*ProduceResponse, error) {
 	attributes := protocol.Attributes(req.Compression) & 0x7
+	if req.CompressionCodec != nil {
+		attributes = protocol.Attributes(req.CompressionCodec.Code()) & 0x7
+	}
 
 	m, err := c.roundTrip(ctx, req.Addr, &produceAPI.Request{
 		TransactionalID: req.TransactionalID,
@@ -156,6 +165,7 @@ func (c *Client) Produce(ctx context.Context, req *ProduceRequest) (*ProduceResp
 				RecordSet: protocol.RecordSet{
 					Attributes: attributes,
 					Records:    req.Records,
+					Codec:      req.CompressionCodec,
 				},
 			}},
 		}},
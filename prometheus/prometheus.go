@@ -0,0 +1,166 @@
+// Package prometheus exports the metrics of kafka-go to Prometheus.
+//
+// A Recorder implements both kafka.MetricsRecorder and prometheus.Collector,
+// it is meant to be registered with a prometheus registry and configured on
+// the kafka-go values that emit metrics:
+//
+//	recorder := prometheus.NewRecorder(prometheus.Config{})
+//	registry.MustRegister(recorder)
+//
+//	w := &kafka.Writer{
+//		Addr:      kafka.TCP("localhost:9092"),
+//		Transport: &kafka.Transport{Metrics: recorder},
+//		Metrics:   recorder,
+//	}
+package prometheus
+
+import (
+	"strings"
+
+	"github.com/PerchSecurity/kafka-go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Config carries the configuration of a Recorder.
+type Config struct {
+	// Namespace prefixed to the names of all metrics, separated by an
+	// underscore.
+	Namespace string
+
+	// Labels added to all metrics reported by the recorder.
+	ConstLabels prometheus.Labels
+
+	// Buckets of the histograms which measure durations, in seconds.
+	//
+	// Defaults to prometheus.DefBuckets.
+	DurationBuckets []float64
+
+	// Buckets of the histograms which measure sizes, like the number of
+	// messages or bytes in a batch.
+	//
+	// Defaults to exponential buckets from 1 to ~4M.
+	SizeBuckets []float64
+}
+
+// Recorder is an implementation of kafka.MetricsRecorder which reports the
+// metrics to Prometheus.
+//
+// Metric names are derived from the names of the kafka-go metrics, with dots
+// replaced by underscores, a suffix for the base unit, and the "_total" suffix
+// for counters. For example, "kafka.writer.write.duration" is exported as
+// "kafka_writer_write_duration_seconds" and "kafka.writer.messages" as
+// "kafka_writer_messages_total".
+//
+// Recorder values are safe to use concurrently from multiple goroutines.
+type Recorder struct {
+	counters   map[*kafka.Metric]*prometheus.CounterVec
+	histograms map[*kafka.Metric]*prometheus.HistogramVec
+}
+
+var defaultSizeBuckets = prometheus.ExponentialBuckets(1, 4, 12)
+
+// NewRecorder constructs a Recorder for all the metrics returned by
+// kafka.Metrics.
+func NewRecorder(config Config) *Recorder {
+	durationBuckets := config.DurationBuckets
+	if durationBuckets == nil {
+		durationBuckets = prometheus.DefBuckets
+	}
+
+	sizeBuckets := config.SizeBuckets
+	if sizeBuckets == nil {
+		sizeBuckets = defaultSizeBuckets
+	}
+
+	r := &Recorder{
+		counters:   make(map[*kafka.Metric]*prometheus.CounterVec),
+		histograms: make(map[*kafka.Metric]*prometheus.HistogramVec),
+	}
+
+	for _, m := range kafka.Metrics() {
+		switch m.Kind {
+		case kafka.MetricCounter:
+			r.counters[m] = prometheus.NewCounterVec(prometheus.CounterOpts{
+				Namespace:   config.Namespace,
+				Name:        MetricName(m),
+				Help:        m.Description,
+				ConstLabels: config.ConstLabels,
+			}, m.Labels)
+
+		case kafka.MetricHistogram:
+			buckets := sizeBuckets
+			if m.Unit == "s" {
+				buckets = durationBuckets
+			}
+			r.histograms[m] = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+				Namespace:   config.Namespace,
+				Name:        MetricName(m),
+				Help:        m.Description,
+				ConstLabels: config.ConstLabels,
+				Buckets:     buckets,
+			}, m.Labels)
+		}
+	}
+
+	return r
+}
+
+// Add satisfies the kafka.MetricsRecorder interface.
+func (r *Recorder) Add(m *kafka.Metric, value int64, labelValues ...string) {
+	if c := r.counters[m]; c != nil {
+		c.WithLabelValues(labelValues...).Add(float64(value))
+	}
+}
+
+// Record satisfies the kafka.MetricsRecorder interface.
+func (r *Recorder) Record(m *kafka.Metric, value float64, labelValues ...string) {
+	if h := r.histograms[m]; h != nil {
+		h.WithLabelValues(labelValues...).Observe(value)
+	}
+}
+
+// Describe satisfies the prometheus.Collector interface.
+func (r *Recorder) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range r.counters {
+		c.Describe(ch)
+	}
+	for _, h := range r.histograms {
+		h.Describe(ch)
+	}
+}
+
+// Collect satisfies the prometheus.Collector interface.
+func (r *Recorder) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range r.counters {
+		c.Collect(ch)
+	}
+	for _, h := range r.histograms {
+		h.Collect(ch)
+	}
+}
+
+// MetricName returns the name that m is exported with, without namespace.
+func MetricName(m *kafka.Metric) string {
+	name := strings.ReplaceAll(m.Name, ".", "_")
+
+	var unit string
+	switch m.Unit {
+	case "s":
+		unit = "_seconds"
+	case "By":
+		unit = "_bytes"
+	}
+	if !strings.HasSuffix(name, unit) {
+		name += unit
+	}
+
+	if m.Kind == kafka.MetricCounter {
+		name += "_total"
+	}
+	return name
+}
+
+var (
+	_ kafka.MetricsRecorder = (*Recorder)(nil)
+	_ prometheus.Collector  = (*Recorder)(nil)
+)
@@ -0,0 +1,76 @@
+package prometheus
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PerchSecurity/kafka-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetricName(t *testing.T) {
+	tests := []struct {
+		metric kafka.Metric
+		name   string
+	}{
+		{
+			metric: kafka.Metric{Name: "kafka.writer.messages", Kind: kafka.MetricCounter, Unit: "{message}"},
+			name:   "kafka_writer_messages_total",
+		},
+		{
+			metric: kafka.Metric{Name: "kafka.writer.bytes", Kind: kafka.MetricCounter, Unit: "By"},
+			name:   "kafka_writer_bytes_total",
+		},
+		{
+			metric: kafka.Metric{Name: "kafka.transport.request.duration", Kind: kafka.MetricHistogram, Unit: "s"},
+			name:   "kafka_transport_request_duration_seconds",
+		},
+		{
+			metric: kafka.Metric{Name: "kafka.writer.batch.size", Kind: kafka.MetricHistogram, Unit: "{message}"},
+			name:   "kafka_writer_batch_size",
+		},
+	}
+
+	for _, test := range tests {
+		if name := MetricName(&test.metric); name != test.name {
+			t.Errorf("%s: expected %s, got %s", test.metric.Name, test.name, name)
+		}
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	recorder := NewRecorder(Config{Namespace: "test"})
+
+	registry := prometheus.NewPedanticRegistry()
+	if err := registry.Register(recorder); err != nil {
+		t.Fatal(err)
+	}
+
+	metrics := make(map[string]*kafka.Metric)
+	for _, m := range kafka.Metrics() {
+		metrics[m.Name] = m
+	}
+
+	messages := metrics["kafka.writer.messages"]
+	recorder.Add(messages, 3, "topic-A", "0")
+	recorder.Add(messages, 2, "topic-A", "0")
+	recorder.Add(messages, 1, "topic-A", "1")
+
+	duration := metrics["kafka.transport.request.duration"]
+	recorder.Record(duration, 0.25, "localhost:9092", "Produce")
+
+	expected := `
+# HELP test_kafka_writer_messages_total Number of messages written by writers.
+# TYPE test_kafka_writer_messages_total counter
+test_kafka_writer_messages_total{partition="0",topic="topic-A"} 5
+test_kafka_writer_messages_total{partition="1",topic="topic-A"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "test_kafka_writer_messages_total"); err != nil {
+		t.Error(err)
+	}
+
+	if n := testutil.CollectAndCount(recorder, "test_kafka_transport_request_duration_seconds"); n != 1 {
+		t.Errorf("expected 1 request duration series, got %d", n)
+	}
+}
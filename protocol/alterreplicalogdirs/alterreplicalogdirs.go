@@ -0,0 +1,77 @@
+package alterreplicalogdirs
+
+import (
+	"github.com/PerchSecurity/kafka-go/protocol"
+)
+
+func init() {
+	protocol.Register(&Request{}, &Response{})
+}
+
+// Detailed API definition: https://kafka.apache.org/protocol#The_Messages_AlterReplicaLogDirs
+type Request struct {
+	// We need at least one tagged field to indicate that v2+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v2,max=v2,tag"`
+
+	Dirs []RequestDir `kafka:"min=v0,max=v2"`
+
+	// BrokerID is the broker hosting the replicas that are moved between log
+	// directories, the request is sent to this broker.
+	BrokerID int32 `kafka:"-"`
+}
+
+type RequestDir struct {
+	// We need at least one tagged field to indicate that v2+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v2,max=v2,tag"`
+
+	Path   string         `kafka:"min=v0,max=v2"`
+	Topics []RequestTopic `kafka:"min=v0,max=v2"`
+}
+
+type RequestTopic struct {
+	// We need at least one tagged field to indicate that v2+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v2,max=v2,tag"`
+
+	Name       string  `kafka:"min=v0,max=v2"`
+	Partitions []int32 `kafka:"min=v0,max=v2"`
+}
+
+func (r *Request) ApiKey() protocol.ApiKey { return protocol.AlterReplicaLogDirs }
+
+func (r *Request) Broker(cluster protocol.Cluster) (protocol.Broker, error) {
+	return cluster.Brokers[r.BrokerID], nil
+}
+
+type Response struct {
+	// We need at least one tagged field to indicate that v2+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v2,max=v2,tag"`
+
+	ThrottleTimeMs int32            `kafka:"min=v0,max=v2"`
+	Results        []ResponseResult `kafka:"min=v0,max=v2"`
+}
+
+type ResponseResult struct {
+	// We need at least one tagged field to indicate that v2+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v2,max=v2,tag"`
+
+	TopicName  string              `kafka:"min=v0,max=v2"`
+	Partitions []ResponsePartition `kafka:"min=v0,max=v2"`
+}
+
+type ResponsePartition struct {
+	// We need at least one tagged field to indicate that v2+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v2,max=v2,tag"`
+
+	PartitionIndex int32 `kafka:"min=v0,max=v2"`
+	ErrorCode      int16 `kafka:"min=v0,max=v2"`
+}
+
+func (r *Response) ApiKey() protocol.ApiKey { return protocol.AlterReplicaLogDirs }
+
+var _ protocol.BrokerMessage = (*Request)(nil)
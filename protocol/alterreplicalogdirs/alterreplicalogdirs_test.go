@@ -0,0 +1,48 @@
+package alterreplicalogdirs_test
+
+import (
+	"testing"
+
+	"github.com/PerchSecurity/kafka-go/protocol/alterreplicalogdirs"
+	"github.com/PerchSecurity/kafka-go/protocol/prototest"
+)
+
+const (
+	v0 = 0
+	v2 = 2
+)
+
+func TestAlterReplicaLogDirsRequest(t *testing.T) {
+	for _, version := range []int16{v0, v2} {
+		prototest.TestRequest(t, version, &alterreplicalogdirs.Request{
+			Dirs: []alterreplicalogdirs.RequestDir{
+				{
+					Path: "/var/lib/kafka/data-2",
+					Topics: []alterreplicalogdirs.RequestTopic{
+						{
+							Name:       "foo",
+							Partitions: []int32{0, 1},
+						},
+					},
+				},
+			},
+		})
+	}
+}
+
+func TestAlterReplicaLogDirsResponse(t *testing.T) {
+	for _, version := range []int16{v0, v2} {
+		prototest.TestResponse(t, version, &alterreplicalogdirs.Response{
+			ThrottleTimeMs: 500,
+			Results: []alterreplicalogdirs.ResponseResult{
+				{
+					TopicName: "foo",
+					Partitions: []alterreplicalogdirs.ResponsePartition{
+						{PartitionIndex: 0, ErrorCode: 0},
+						{PartitionIndex: 1, ErrorCode: 57},
+					},
+				},
+			},
+		})
+	}
+}
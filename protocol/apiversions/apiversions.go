@@ -25,3 +25,19 @@ type ApiKeyResponse struct {
 	MinVersion int16 `kafka:"min=v0,max=v2"`
 	MaxVersion int16 `kafka:"min=v0,max=v2"`
 }
+
+// SetApiVersions satisfies the protocol.ApiVersionsResponse interface.
+func (r *Response) SetApiVersions(errorCode int16, apiVersions []protocol.ApiVersion) {
+	r.ErrorCode = errorCode
+	r.ApiKeys = make([]ApiKeyResponse, len(apiVersions))
+
+	for i, v := range apiVersions {
+		r.ApiKeys[i] = ApiKeyResponse{
+			ApiKey:     int16(v.ApiKey),
+			MinVersion: v.MinVersion,
+			MaxVersion: v.MaxVersion,
+		}
+	}
+}
+
+var _ protocol.ApiVersionsResponse = (*Response)(nil)
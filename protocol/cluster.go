@@ -34,6 +34,19 @@ func (c Cluster) TopicNames() []string {
 	return topicNames
 }
 
+// TopicByID returns the topic with the given ID. Topics have IDs when the
+// cluster metadata was obtained from brokers supporting topic IDs (kafka 2.8+).
+func (c Cluster) TopicByID(id UUID) (Topic, bool) {
+	if !id.IsZero() {
+		for _, t := range c.Topics {
+			if t.ID == id {
+				return t, true
+			}
+		}
+	}
+	return Topic{}, false
+}
+
 func (c Cluster) IsZero() bool {
 	return c.ClusterID == "" && c.Controller == 0 && len(c.Brokers) == 0 && len(c.Topics) == 0
 }
@@ -78,6 +78,13 @@ func (c *Conn) SetVersions(versions map[ApiKey]int16) {
 	c.versions.Store(connVersions)
 }
 
+// ApiVersion returns the version used to send messages of the given API key
+// on the connection.
+func (c *Conn) ApiVersion(key ApiKey) int16 {
+	versions, _ := c.versions.Load().(map[ApiKey]int16)
+	return versions[key]
+}
+
 func (c *Conn) RoundTrip(msg Message) (Message, error) {
 	correlationID := atomic.AddInt32(&c.idgen, +1)
 	versions, _ := c.versions.Load().(map[ApiKey]int16)
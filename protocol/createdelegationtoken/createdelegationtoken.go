@@ -0,0 +1,56 @@
+package createdelegationtoken
+
+import "github.com/PerchSecurity/kafka-go/protocol"
+
+func init() {
+	protocol.Register(&Request{}, &Response{})
+}
+
+// Detailed API definition: https://kafka.apache.org/protocol#The_Messages_CreateDelegationToken
+type Request struct {
+	// We need at least one tagged field to indicate that v2+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v2,max=v3,tag"`
+
+	OwnerPrincipalType string           `kafka:"min=v3,max=v3,nullable"`
+	OwnerPrincipalName string           `kafka:"min=v3,max=v3,nullable"`
+	Renewers           []RequestRenewer `kafka:"min=v0,max=v3"`
+	MaxLifetimeMs      int64            `kafka:"min=v0,max=v3"`
+}
+
+type RequestRenewer struct {
+	// We need at least one tagged field to indicate that v2+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v2,max=v3,tag"`
+
+	PrincipalType string `kafka:"min=v0,max=v3"`
+	PrincipalName string `kafka:"min=v0,max=v3"`
+}
+
+func (r *Request) ApiKey() protocol.ApiKey { return protocol.CreateDelegationToken }
+
+func (r *Request) Broker(cluster protocol.Cluster) (protocol.Broker, error) {
+	return cluster.Brokers[cluster.Controller], nil
+}
+
+type Response struct {
+	// We need at least one tagged field to indicate that v2+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v2,max=v3,tag"`
+
+	ErrorCode                   int16  `kafka:"min=v0,max=v3"`
+	PrincipalType               string `kafka:"min=v0,max=v3"`
+	PrincipalName               string `kafka:"min=v0,max=v3"`
+	TokenRequesterPrincipalType string `kafka:"min=v3,max=v3"`
+	TokenRequesterPrincipalName string `kafka:"min=v3,max=v3"`
+	IssueTimestampMs            int64  `kafka:"min=v0,max=v3"`
+	ExpiryTimestampMs           int64  `kafka:"min=v0,max=v3"`
+	MaxTimestampMs              int64  `kafka:"min=v0,max=v3"`
+	TokenID                     string `kafka:"min=v0,max=v3"`
+	HMAC                        []byte `kafka:"min=v0,max=v3"`
+	ThrottleTimeMs              int32  `kafka:"min=v0,max=v3"`
+}
+
+func (r *Response) ApiKey() protocol.ApiKey { return protocol.CreateDelegationToken }
+
+var _ protocol.BrokerMessage = (*Request)(nil)
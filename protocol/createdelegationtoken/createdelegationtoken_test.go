@@ -0,0 +1,62 @@
+package createdelegationtoken_test
+
+import (
+	"testing"
+
+	"github.com/PerchSecurity/kafka-go/protocol/createdelegationtoken"
+	"github.com/PerchSecurity/kafka-go/protocol/prototest"
+)
+
+const (
+	v0 = 0
+	v2 = 2
+	v3 = 3
+)
+
+func TestCreateDelegationTokenRequest(t *testing.T) {
+	for _, version := range []int16{v0, v2} {
+		prototest.TestRequest(t, version, &createdelegationtoken.Request{
+			Renewers: []createdelegationtoken.RequestRenewer{
+				{PrincipalType: "User", PrincipalName: "renewer"},
+			},
+			MaxLifetimeMs: 86400000,
+		})
+	}
+
+	prototest.TestRequest(t, v3, &createdelegationtoken.Request{
+		OwnerPrincipalType: "User",
+		OwnerPrincipalName: "owner",
+		Renewers: []createdelegationtoken.RequestRenewer{
+			{PrincipalType: "User", PrincipalName: "renewer"},
+		},
+		MaxLifetimeMs: -1,
+	})
+}
+
+func TestCreateDelegationTokenResponse(t *testing.T) {
+	for _, version := range []int16{v0, v2} {
+		prototest.TestResponse(t, version, &createdelegationtoken.Response{
+			PrincipalType:     "User",
+			PrincipalName:     "owner",
+			IssueTimestampMs:  1000,
+			ExpiryTimestampMs: 2000,
+			MaxTimestampMs:    3000,
+			TokenID:           "token-id",
+			HMAC:              []byte("hmac"),
+			ThrottleTimeMs:    500,
+		})
+	}
+
+	prototest.TestResponse(t, v3, &createdelegationtoken.Response{
+		PrincipalType:               "User",
+		PrincipalName:               "owner",
+		TokenRequesterPrincipalType: "User",
+		TokenRequesterPrincipalName: "requester",
+		IssueTimestampMs:            1000,
+		ExpiryTimestampMs:           2000,
+		MaxTimestampMs:              3000,
+		TokenID:                     "token-id",
+		HMAC:                        []byte("hmac"),
+		ThrottleTimeMs:              500,
+	})
+}
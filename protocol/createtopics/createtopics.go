@@ -51,6 +51,14 @@ type Response struct {
 
 func (r *Response) ApiKey() protocol.ApiKey { return protocol.CreateTopics }
 
+func (r *Response) PartitionErrors(fn func(topic string, partition int32, errorCode int16)) {
+	for _, t := range r.Topics {
+		if t.ErrorCode != 0 {
+			fn(t.Name, -1, t.ErrorCode)
+		}
+	}
+}
+
 type ResponseTopic struct {
 	Name              string `kafka:"min=v0,max=v5"`
 	ErrorCode         int16  `kafka:"min=v0,max=v5"`
@@ -70,5 +78,6 @@ type ResponseTopicConfig struct {
 }
 
 var (
-	_ protocol.BrokerMessage = (*Request)(nil)
+	_ protocol.BrokerMessage    = (*Request)(nil)
+	_ protocol.PartitionErrorer = (*Response)(nil)
 )
@@ -106,6 +106,14 @@ func (d *decoder) decodeCompactBytes(v value) {
 	v.setBytes(d.readCompactBytes())
 }
 
+func (d *decoder) decodeCompactRecordSet(v value) {
+	if d.err == nil {
+		if _, err := v.iface(reflect.PtrTo(recordSetType)).(*RecordSet).readFromCompact(d); err != nil {
+			d.setError(err)
+		}
+	}
+}
+
 func (d *decoder) decodeArray(v value, elemType reflect.Type, decodeElem decodeFunc) {
 	if n := d.readInt32(); n < 0 {
 		v.setArray(array{})
@@ -340,6 +348,10 @@ var (
 )
 
 func decodeFuncOf(typ reflect.Type, version int16, flexible bool, tag structTag) decodeFunc {
+	if flexible && typ == recordSetType {
+		// In flexible messages, record sets are prefixed with a compact size
+		return (*decoder).decodeCompactRecordSet
+	}
 	if reflect.PtrTo(typ).Implements(readerFrom) {
 		return readerDecodeFuncOf(typ)
 	}
@@ -0,0 +1,132 @@
+package deleterecords
+
+import (
+	"sort"
+
+	"github.com/PerchSecurity/kafka-go/protocol"
+)
+
+//go:generate go run ../internal/gen -api DeleteRecords
+
+func (r *Request) Broker(cluster protocol.Cluster) (protocol.Broker, error) {
+	// Expects r to be a request that was returned by Split, will likely panic
+	// or produce the wrong result if that's not the case.
+	partition := r.Topics[0].Partitions[0].PartitionIndex
+	topic := r.Topics[0].Name
+
+	if p, ok := cluster.Topics[topic].Partitions[partition]; ok {
+		return cluster.Brokers[p.Leader], nil
+	}
+
+	return protocol.Broker{ID: -1}, nil
+}
+
+func (r *Request) Split(cluster protocol.Cluster) ([]protocol.Message, protocol.Merger, error) {
+	// DeleteRecords requests must be sent to the leaders of the partitions, we
+	// group the partitions by leader and send one request to each broker.
+	leaders := make(map[int32]int)
+	requests := make([]Request, 0, len(cluster.Brokers))
+
+	for _, t := range r.Topics {
+		for _, p := range t.Partitions {
+			leader := int32(-1)
+			if partition, ok := cluster.Topics[t.Name].Partitions[p.PartitionIndex]; ok {
+				leader = partition.Leader
+			}
+
+			i, ok := leaders[leader]
+			if !ok {
+				i = len(requests)
+				leaders[leader] = i
+				requests = append(requests, Request{TimeoutMs: r.TimeoutMs})
+			}
+
+			req := &requests[i]
+			if n := len(req.Topics); n == 0 || req.Topics[n-1].Name != t.Name {
+				req.Topics = append(req.Topics, RequestTopic{Name: t.Name})
+			}
+			topic := &req.Topics[len(req.Topics)-1]
+			topic.Partitions = append(topic.Partitions, p)
+		}
+	}
+
+	messages := make([]protocol.Message, len(requests))
+
+	for i := range requests {
+		messages[i] = &requests[i]
+	}
+
+	return messages, new(Response), nil
+}
+
+func (r *Response) PartitionErrors(fn func(topic string, partition int32, errorCode int16)) {
+	for _, t := range r.Topics {
+		for _, p := range t.Partitions {
+			if p.ErrorCode != 0 {
+				fn(t.Name, p.PartitionIndex, p.ErrorCode)
+			}
+		}
+	}
+}
+
+func (r *Response) Merge(requests []protocol.Message, results []interface{}) (protocol.Message, error) {
+	topics := make(map[string][]ResponsePartitionResult)
+	errors := 0
+
+	for i, res := range results {
+		m, err := protocol.Result(res)
+		if err != nil {
+			for _, t := range requests[i].(*Request).Topics {
+				for _, p := range t.Partitions {
+					topics[t.Name] = append(topics[t.Name], ResponsePartitionResult{
+						PartitionIndex: p.PartitionIndex,
+						LowWatermark:   -1,
+						ErrorCode:      -1, // UNKNOWN, can we do better?
+					})
+				}
+			}
+			errors++
+			continue
+		}
+
+		response := m.(*Response)
+
+		if r.ThrottleTimeMs < response.ThrottleTimeMs {
+			r.ThrottleTimeMs = response.ThrottleTimeMs
+		}
+
+		for _, t := range response.Topics {
+			topics[t.Name] = append(topics[t.Name], t.Partitions...)
+		}
+	}
+
+	if errors > 0 && errors == len(results) {
+		_, err := protocol.Result(results[0])
+		return nil, err
+	}
+
+	r.Topics = make([]ResponseTopicResult, 0, len(topics))
+
+	for topicName, partitions := range topics {
+		sort.Slice(partitions, func(i, j int) bool {
+			return partitions[i].PartitionIndex < partitions[j].PartitionIndex
+		})
+		r.Topics = append(r.Topics, ResponseTopicResult{
+			Name:       topicName,
+			Partitions: partitions,
+		})
+	}
+
+	sort.Slice(r.Topics, func(i, j int) bool {
+		return r.Topics[i].Name < r.Topics[j].Name
+	})
+
+	return r, nil
+}
+
+var (
+	_ protocol.BrokerMessage    = (*Request)(nil)
+	_ protocol.Splitter         = (*Request)(nil)
+	_ protocol.Merger           = (*Response)(nil)
+	_ protocol.PartitionErrorer = (*Response)(nil)
+)
@@ -0,0 +1,60 @@
+// Code generated by protocol/internal/gen from DeleteRecordsRequest.json and DeleteRecordsResponse.json. DO NOT EDIT.
+
+package deleterecords_test
+
+import (
+	"testing"
+
+	"github.com/PerchSecurity/kafka-go/protocol/deleterecords"
+	"github.com/PerchSecurity/kafka-go/protocol/prototest"
+)
+
+func TestRequestRoundTrip(t *testing.T) {
+	prototest.TestRequest(t, 0, &deleterecords.Request{
+		Topics: []deleterecords.RequestTopic{{
+			Name: "name-2",
+			Partitions: []deleterecords.RequestPartition{{
+				PartitionIndex: 4,
+				Offset:         5,
+			}},
+		}},
+		TimeoutMs: 6,
+	})
+
+	prototest.TestRequest(t, 2, &deleterecords.Request{
+		Topics: []deleterecords.RequestTopic{{
+			Name: "name-2",
+			Partitions: []deleterecords.RequestPartition{{
+				PartitionIndex: 4,
+				Offset:         5,
+			}},
+		}},
+		TimeoutMs: 6,
+	})
+}
+
+func TestResponseRoundTrip(t *testing.T) {
+	prototest.TestResponse(t, 0, &deleterecords.Response{
+		ThrottleTimeMs: 1,
+		Topics: []deleterecords.ResponseTopicResult{{
+			Name: "name-3",
+			Partitions: []deleterecords.ResponsePartitionResult{{
+				PartitionIndex: 5,
+				LowWatermark:   6,
+				ErrorCode:      7,
+			}},
+		}},
+	})
+
+	prototest.TestResponse(t, 2, &deleterecords.Response{
+		ThrottleTimeMs: 1,
+		Topics: []deleterecords.ResponseTopicResult{{
+			Name: "name-3",
+			Partitions: []deleterecords.ResponsePartitionResult{{
+				PartitionIndex: 5,
+				LowWatermark:   6,
+				ErrorCode:      7,
+			}},
+		}},
+	})
+}
@@ -7,8 +7,25 @@ func init() {
 }
 
 type Request struct {
-	TopicNames []string `kafka:"min=v0,max=v3"`
-	TimeoutMs  int32    `kafka:"min=v0,max=v3"`
+	// We need at least one tagged field to indicate that v4+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v4,max=v6,tag"`
+
+	// Names of the topics to delete.
+	//
+	// In v6+, the names are sent as Topics entries, see Prepare.
+	TopicNames []string `kafka:"min=v0,max=v5"`
+
+	// Topics to delete in v6+. Topics are deleted by ID when their name is
+	// empty.
+	Topics []RequestTopic `kafka:"min=v6,max=v6"`
+
+	TimeoutMs int32 `kafka:"min=v0,max=v6"`
+}
+
+type RequestTopic struct {
+	Name    string        `kafka:"min=v6,max=v6,nullable"`
+	TopicID protocol.UUID `kafka:"min=v6,max=v6"`
 }
 
 func (r *Request) ApiKey() protocol.ApiKey { return protocol.DeleteTopics }
@@ -17,18 +34,76 @@ func (r *Request) Broker(cluster protocol.Cluster) (protocol.Broker, error) {
 	return cluster.Brokers[cluster.Controller], nil
 }
 
+// Prepare moves the names of TopicNames to Topics for versions which use the
+// latter, and the other way around, so programs can set either field. Topics
+// which only have an ID cannot be deleted in versions before v6.
+func (r *Request) Prepare(apiVersion int16) {
+	if apiVersion >= 6 {
+		for _, name := range r.TopicNames {
+			if !r.hasTopic(name) {
+				r.Topics = append(r.Topics, RequestTopic{Name: name})
+			}
+		}
+	} else {
+		for _, t := range r.Topics {
+			if t.Name != "" && !r.hasTopicName(t.Name) {
+				r.TopicNames = append(r.TopicNames, t.Name)
+			}
+		}
+	}
+}
+
+func (r *Request) hasTopic(name string) bool {
+	for _, t := range r.Topics {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Request) hasTopicName(name string) bool {
+	for _, n := range r.TopicNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
 type Response struct {
-	ThrottleTimeMs int32           `kafka:"min=v1,max=v3"`
-	Responses      []ResponseTopic `kafka:"min=v0,max=v3"`
+	// We need at least one tagged field to indicate that v4+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v4,max=v6,tag"`
+
+	ThrottleTimeMs int32           `kafka:"min=v1,max=v6"`
+	Responses      []ResponseTopic `kafka:"min=v0,max=v6"`
 }
 
 func (r *Response) ApiKey() protocol.ApiKey { return protocol.DeleteTopics }
 
+func (r *Response) PartitionErrors(fn func(topic string, partition int32, errorCode int16)) {
+	for _, t := range r.Responses {
+		if t.ErrorCode != 0 {
+			name := t.Name
+			if name == "" && !t.TopicID.IsZero() {
+				// Topics deleted by ID have no name in v6+ responses.
+				name = t.TopicID.String()
+			}
+			fn(name, -1, t.ErrorCode)
+		}
+	}
+}
+
 type ResponseTopic struct {
-	Name      string `kafka:"min=v0,max=v3"`
-	ErrorCode int16  `kafka:"min=v0,max=v3"`
+	Name         string        `kafka:"min=v0,max=v5|min=v6,max=v6,nullable"`
+	TopicID      protocol.UUID `kafka:"min=v6,max=v6"`
+	ErrorCode    int16         `kafka:"min=v0,max=v6"`
+	ErrorMessage string        `kafka:"min=v5,max=v6,nullable"`
 }
 
 var (
-	_ protocol.BrokerMessage = (*Request)(nil)
+	_ protocol.BrokerMessage    = (*Request)(nil)
+	_ protocol.PreparedMessage  = (*Request)(nil)
+	_ protocol.PartitionErrorer = (*Response)(nil)
 )
@@ -1,8 +1,10 @@
 package deletetopics_test
 
 import (
+	"reflect"
 	"testing"
 
+	"github.com/PerchSecurity/kafka-go/protocol"
 	"github.com/PerchSecurity/kafka-go/protocol/deletetopics"
 	"github.com/PerchSecurity/kafka-go/protocol/prototest"
 )
@@ -11,6 +13,8 @@ const (
 	v0 = 0
 	v1 = 1
 	v3 = 3
+	v4 = 4
+	v6 = 6
 )
 
 func TestDeleteTopicsRequest(t *testing.T) {
@@ -72,3 +76,51 @@ func TestDeleteTopicsResponse(t *testing.T) {
 		},
 	})
 }
+
+func TestDeleteTopicsTopicIDs(t *testing.T) {
+	topicID := protocol.NewUUID()
+
+	prototest.TestRequest(t, v4, &deletetopics.Request{
+		TopicNames: []string{"foo", "bar"},
+		TimeoutMs:  500,
+	})
+
+	prototest.TestRequest(t, v6, &deletetopics.Request{
+		Topics: []deletetopics.RequestTopic{
+			{Name: "foo"},
+			{TopicID: topicID},
+		},
+		TimeoutMs: 500,
+	})
+
+	prototest.TestResponse(t, v6, &deletetopics.Response{
+		ThrottleTimeMs: 500,
+		Responses: []deletetopics.ResponseTopic{
+			{
+				Name:    "foo",
+				TopicID: topicID,
+			},
+			{
+				TopicID:      protocol.NewUUID(),
+				ErrorCode:    100,
+				ErrorMessage: "unknown topic id",
+			},
+		},
+	})
+}
+
+func TestDeleteTopicsRequestPrepare(t *testing.T) {
+	topicID := protocol.NewUUID()
+
+	req := &deletetopics.Request{
+		TopicNames: []string{"foo"},
+		Topics:     []deletetopics.RequestTopic{{TopicID: topicID}},
+	}
+	req.Prepare(v6)
+	req.Prepare(v6) // must be idempotent when requests are retried
+
+	want := []deletetopics.RequestTopic{{TopicID: topicID}, {Name: "foo"}}
+	if !reflect.DeepEqual(req.Topics, want) {
+		t.Errorf("topics mismatch: %+v != %+v", req.Topics, want)
+	}
+}
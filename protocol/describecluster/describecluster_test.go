@@ -0,0 +1,50 @@
+package describecluster_test
+
+import (
+	"testing"
+
+	"github.com/PerchSecurity/kafka-go/protocol/describecluster"
+	"github.com/PerchSecurity/kafka-go/protocol/prototest"
+)
+
+const (
+	v0 = 0
+	v1 = 1
+)
+
+func TestDescribeClusterRequest(t *testing.T) {
+	prototest.TestRequest(t, v0, &describecluster.Request{
+		IncludeClusterAuthorizedOperations: true,
+	})
+
+	prototest.TestRequest(t, v1, &describecluster.Request{
+		IncludeClusterAuthorizedOperations: true,
+		EndpointType:                       1,
+	})
+}
+
+func TestDescribeClusterResponse(t *testing.T) {
+	brokers := []describecluster.ResponseBroker{
+		{BrokerID: 1, Host: "kafka-1", Port: 9092, Rack: "us-east-1a"},
+		{BrokerID: 2, Host: "kafka-2", Port: 9092},
+	}
+
+	prototest.TestResponse(t, v0, &describecluster.Response{
+		ThrottleTimeMs:              500,
+		ClusterID:                   "cluster-id",
+		ControllerID:                1,
+		Brokers:                     brokers,
+		ClusterAuthorizedOperations: 1 << 8,
+	})
+
+	prototest.TestResponse(t, v1, &describecluster.Response{
+		ThrottleTimeMs:              500,
+		ErrorCode:                   29,
+		ErrorMessage:                "cluster authorization failed",
+		EndpointType:                1,
+		ClusterID:                   "cluster-id",
+		ControllerID:                1,
+		Brokers:                     brokers,
+		ClusterAuthorizedOperations: -2147483648,
+	})
+}
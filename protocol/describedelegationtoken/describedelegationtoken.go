@@ -0,0 +1,71 @@
+package describedelegationtoken
+
+import "github.com/PerchSecurity/kafka-go/protocol"
+
+func init() {
+	protocol.Register(&Request{}, &Response{})
+}
+
+// Detailed API definition: https://kafka.apache.org/protocol#The_Messages_DescribeDelegationToken
+type Request struct {
+	// We need at least one tagged field to indicate that v2+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v2,max=v3,tag"`
+
+	Owners []RequestOwner `kafka:"min=v0,max=v3,nullable"`
+}
+
+type RequestOwner struct {
+	// We need at least one tagged field to indicate that v2+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v2,max=v3,tag"`
+
+	PrincipalType string `kafka:"min=v0,max=v3"`
+	PrincipalName string `kafka:"min=v0,max=v3"`
+}
+
+func (r *Request) ApiKey() protocol.ApiKey { return protocol.DescribeDelegationToken }
+
+func (r *Request) Broker(cluster protocol.Cluster) (protocol.Broker, error) {
+	return cluster.Brokers[cluster.Controller], nil
+}
+
+type Response struct {
+	// We need at least one tagged field to indicate that v2+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v2,max=v3,tag"`
+
+	ErrorCode      int16           `kafka:"min=v0,max=v3"`
+	Tokens         []ResponseToken `kafka:"min=v0,max=v3"`
+	ThrottleTimeMs int32           `kafka:"min=v0,max=v3"`
+}
+
+type ResponseToken struct {
+	// We need at least one tagged field to indicate that v2+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v2,max=v3,tag"`
+
+	PrincipalType               string            `kafka:"min=v0,max=v3"`
+	PrincipalName               string            `kafka:"min=v0,max=v3"`
+	TokenRequesterPrincipalType string            `kafka:"min=v3,max=v3"`
+	TokenRequesterPrincipalName string            `kafka:"min=v3,max=v3"`
+	IssueTimestampMs            int64             `kafka:"min=v0,max=v3"`
+	ExpiryTimestampMs           int64             `kafka:"min=v0,max=v3"`
+	MaxTimestampMs              int64             `kafka:"min=v0,max=v3"`
+	TokenID                     string            `kafka:"min=v0,max=v3"`
+	HMAC                        []byte            `kafka:"min=v0,max=v3"`
+	Renewers                    []ResponseRenewer `kafka:"min=v0,max=v3"`
+}
+
+type ResponseRenewer struct {
+	// We need at least one tagged field to indicate that v2+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v2,max=v3,tag"`
+
+	PrincipalType string `kafka:"min=v0,max=v3"`
+	PrincipalName string `kafka:"min=v0,max=v3"`
+}
+
+func (r *Response) ApiKey() protocol.ApiKey { return protocol.DescribeDelegationToken }
+
+var _ protocol.BrokerMessage = (*Request)(nil)
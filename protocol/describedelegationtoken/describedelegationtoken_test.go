@@ -0,0 +1,66 @@
+package describedelegationtoken_test
+
+import (
+	"testing"
+
+	"github.com/PerchSecurity/kafka-go/protocol/describedelegationtoken"
+	"github.com/PerchSecurity/kafka-go/protocol/prototest"
+)
+
+const (
+	v0 = 0
+	v2 = 2
+	v3 = 3
+)
+
+func TestDescribeDelegationTokenRequest(t *testing.T) {
+	for _, version := range []int16{v0, v2, v3} {
+		prototest.TestRequest(t, version, &describedelegationtoken.Request{
+			Owners: []describedelegationtoken.RequestOwner{
+				{PrincipalType: "User", PrincipalName: "owner"},
+			},
+		})
+	}
+}
+
+func TestDescribeDelegationTokenResponse(t *testing.T) {
+	for _, version := range []int16{v0, v2} {
+		prototest.TestResponse(t, version, &describedelegationtoken.Response{
+			Tokens: []describedelegationtoken.ResponseToken{
+				{
+					PrincipalType:     "User",
+					PrincipalName:     "owner",
+					IssueTimestampMs:  1000,
+					ExpiryTimestampMs: 2000,
+					MaxTimestampMs:    3000,
+					TokenID:           "token-id",
+					HMAC:              []byte("hmac"),
+					Renewers: []describedelegationtoken.ResponseRenewer{
+						{PrincipalType: "User", PrincipalName: "renewer"},
+					},
+				},
+			},
+			ThrottleTimeMs: 500,
+		})
+	}
+
+	prototest.TestResponse(t, v3, &describedelegationtoken.Response{
+		Tokens: []describedelegationtoken.ResponseToken{
+			{
+				PrincipalType:               "User",
+				PrincipalName:               "owner",
+				TokenRequesterPrincipalType: "User",
+				TokenRequesterPrincipalName: "requester",
+				IssueTimestampMs:            1000,
+				ExpiryTimestampMs:           2000,
+				MaxTimestampMs:              3000,
+				TokenID:                     "token-id",
+				HMAC:                        []byte("hmac"),
+				Renewers: []describedelegationtoken.ResponseRenewer{
+					{PrincipalType: "User", PrincipalName: "renewer"},
+				},
+			},
+		},
+		ThrottleTimeMs: 500,
+	})
+}
@@ -0,0 +1,142 @@
+package describelogdirs
+
+import (
+	"github.com/PerchSecurity/kafka-go/protocol"
+)
+
+func init() {
+	protocol.Register(&Request{}, &Response{})
+}
+
+// Detailed API definition: https://kafka.apache.org/protocol#The_Messages_DescribeLogDirs
+type Request struct {
+	// We need at least one tagged field to indicate that v2+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v2,max=v4,tag"`
+
+	Topics []RequestTopic `kafka:"min=v0,max=v4,nullable"`
+
+	// BrokerID is the broker that the request is sent to. It is set on each
+	// of the requests returned by Split.
+	BrokerID int32 `kafka:"-"`
+}
+
+type RequestTopic struct {
+	// We need at least one tagged field to indicate that v2+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v2,max=v4,tag"`
+
+	Topic      string  `kafka:"min=v0,max=v4"`
+	Partitions []int32 `kafka:"min=v0,max=v4"`
+}
+
+func (r *Request) ApiKey() protocol.ApiKey { return protocol.DescribeLogDirs }
+
+func (r *Request) Broker(cluster protocol.Cluster) (protocol.Broker, error) {
+	return cluster.Brokers[r.BrokerID], nil
+}
+
+func (r *Request) Split(cluster protocol.Cluster) (
+	[]protocol.Message,
+	protocol.Merger,
+	error,
+) {
+	// Log directories are local to each broker, so the request is sent to all
+	// of them and the results are merged back together.
+	brokerIDs := cluster.BrokerIDs()
+	messages := make([]protocol.Message, len(brokerIDs))
+
+	for i, id := range brokerIDs {
+		messages[i] = &Request{
+			Topics:   r.Topics,
+			BrokerID: id,
+		}
+	}
+
+	return messages, new(Response), nil
+}
+
+type Response struct {
+	// We need at least one tagged field to indicate that v2+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v2,max=v4,tag"`
+
+	ThrottleTimeMs int32            `kafka:"min=v0,max=v4"`
+	ErrorCode      int16            `kafka:"min=v3,max=v4"`
+	Results        []ResponseResult `kafka:"min=v0,max=v4"`
+}
+
+type ResponseResult struct {
+	// We need at least one tagged field to indicate that v2+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v2,max=v4,tag"`
+
+	ErrorCode   int16           `kafka:"min=v0,max=v4"`
+	LogDir      string          `kafka:"min=v0,max=v4"`
+	Topics      []ResponseTopic `kafka:"min=v0,max=v4"`
+	TotalBytes  int64           `kafka:"min=v4,max=v4"`
+	UsableBytes int64           `kafka:"min=v4,max=v4"`
+
+	// Use this to store which broker returned the response.
+	BrokerID int32 `kafka:"-"`
+}
+
+type ResponseTopic struct {
+	// We need at least one tagged field to indicate that v2+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v2,max=v4,tag"`
+
+	Name       string              `kafka:"min=v0,max=v4"`
+	Partitions []ResponsePartition `kafka:"min=v0,max=v4"`
+}
+
+type ResponsePartition struct {
+	// We need at least one tagged field to indicate that v2+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v2,max=v4,tag"`
+
+	PartitionIndex int32 `kafka:"min=v0,max=v4"`
+	PartitionSize  int64 `kafka:"min=v0,max=v4"`
+	OffsetLag      int64 `kafka:"min=v0,max=v4"`
+	IsFutureKey    bool  `kafka:"min=v0,max=v4"`
+}
+
+func (r *Response) ApiKey() protocol.ApiKey { return protocol.DescribeLogDirs }
+
+func (r *Response) Merge(requests []protocol.Message, results []interface{}) (
+	protocol.Message,
+	error,
+) {
+	response := &Response{}
+
+	for i, result := range results {
+		m, err := protocol.Result(result)
+		if err != nil {
+			return nil, err
+		}
+
+		brokerResp := m.(*Response)
+		brokerID := requests[i].(*Request).BrokerID
+
+		if brokerResp.ThrottleTimeMs > response.ThrottleTimeMs {
+			response.ThrottleTimeMs = brokerResp.ThrottleTimeMs
+		}
+
+		if response.ErrorCode == 0 {
+			response.ErrorCode = brokerResp.ErrorCode
+		}
+
+		for _, res := range brokerResp.Results {
+			res.BrokerID = brokerID
+			response.Results = append(response.Results, res)
+		}
+	}
+
+	return response, nil
+}
+
+var (
+	_ protocol.BrokerMessage = (*Request)(nil)
+	_ protocol.Splitter      = (*Request)(nil)
+	_ protocol.Merger        = (*Response)(nil)
+)
@@ -0,0 +1,78 @@
+package describelogdirs_test
+
+import (
+	"testing"
+
+	"github.com/PerchSecurity/kafka-go/protocol/describelogdirs"
+	"github.com/PerchSecurity/kafka-go/protocol/prototest"
+)
+
+const (
+	v0 = 0
+	v2 = 2
+	v3 = 3
+	v4 = 4
+)
+
+func TestDescribeLogDirsRequest(t *testing.T) {
+	for _, version := range []int16{v0, v2, v4} {
+		prototest.TestRequest(t, version, &describelogdirs.Request{
+			Topics: []describelogdirs.RequestTopic{
+				{
+					Topic:      "foo",
+					Partitions: []int32{0, 1, 2},
+				},
+			},
+		})
+	}
+}
+
+func TestDescribeLogDirsResponse(t *testing.T) {
+	prototest.TestResponse(t, v0, &describelogdirs.Response{
+		ThrottleTimeMs: 500,
+		Results: []describelogdirs.ResponseResult{
+			{
+				LogDir: "/var/lib/kafka/data",
+				Topics: []describelogdirs.ResponseTopic{
+					{
+						Name: "foo",
+						Partitions: []describelogdirs.ResponsePartition{
+							{PartitionIndex: 0, PartitionSize: 1024, OffsetLag: 0},
+							{PartitionIndex: 1, PartitionSize: 2048, OffsetLag: 10, IsFutureKey: true},
+						},
+					},
+				},
+			},
+		},
+	})
+
+	prototest.TestResponse(t, v3, &describelogdirs.Response{
+		ThrottleTimeMs: 500,
+		ErrorCode:      1,
+		Results: []describelogdirs.ResponseResult{
+			{
+				ErrorCode: 57,
+				LogDir:    "/var/lib/kafka/data",
+			},
+		},
+	})
+
+	prototest.TestResponse(t, v4, &describelogdirs.Response{
+		ThrottleTimeMs: 500,
+		Results: []describelogdirs.ResponseResult{
+			{
+				LogDir: "/var/lib/kafka/data",
+				Topics: []describelogdirs.ResponseTopic{
+					{
+						Name: "foo",
+						Partitions: []describelogdirs.ResponsePartition{
+							{PartitionIndex: 0, PartitionSize: 1024, OffsetLag: 0},
+						},
+					},
+				},
+				TotalBytes:  1 << 40,
+				UsableBytes: 1 << 39,
+			},
+		},
+	})
+}
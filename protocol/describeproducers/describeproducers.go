@@ -0,0 +1,194 @@
+package describeproducers
+
+import (
+	"sort"
+
+	"github.com/PerchSecurity/kafka-go/protocol"
+)
+
+func init() {
+	protocol.Register(&Request{}, &Response{})
+}
+
+// Detailed API definition: https://kafka.apache.org/protocol#The_Messages_DescribeProducers
+type Request struct {
+	// We need at least one tagged field to indicate that v0+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v0,max=v0,tag"`
+
+	Topics []RequestTopic `kafka:"min=v0,max=v0"`
+}
+
+type RequestTopic struct {
+	// We need at least one tagged field to indicate that v0+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v0,max=v0,tag"`
+
+	Name             string  `kafka:"min=v0,max=v0"`
+	PartitionIndexes []int32 `kafka:"min=v0,max=v0"`
+}
+
+func (r *Request) ApiKey() protocol.ApiKey { return protocol.DescribeProducers }
+
+func (r *Request) Broker(cluster protocol.Cluster) (protocol.Broker, error) {
+	// Expects r to be a request that was returned by Split, all partitions of
+	// the request are then led by the same broker.
+	for _, t := range r.Topics {
+		topic, ok := cluster.Topics[t.Name]
+		if !ok {
+			return protocol.Broker{}, protocol.NewErrNoTopic(t.Name)
+		}
+
+		for _, p := range t.PartitionIndexes {
+			partition, ok := topic.Partitions[p]
+			if !ok {
+				return protocol.Broker{}, protocol.NewErrNoPartition(t.Name, p)
+			}
+
+			broker, ok := cluster.Brokers[partition.Leader]
+			if !ok {
+				return protocol.Broker{}, protocol.NewErrNoLeader(t.Name, p)
+			}
+
+			return broker, nil
+		}
+	}
+
+	return protocol.Broker{ID: -1}, nil
+}
+
+func (r *Request) Split(cluster protocol.Cluster) ([]protocol.Message, protocol.Merger, error) {
+	// Producer states are only known by the partition leaders, the partitions
+	// are grouped by leader so a single request is sent to each of the
+	// brokers.
+	requests := make(map[int32]*Request)
+	leaders := make([]int32, 0, 8)
+
+	for _, t := range r.Topics {
+		topic, ok := cluster.Topics[t.Name]
+		if !ok {
+			return nil, nil, protocol.NewErrNoTopic(t.Name)
+		}
+
+		for _, p := range t.PartitionIndexes {
+			partition, ok := topic.Partitions[p]
+			if !ok {
+				return nil, nil, protocol.NewErrNoPartition(t.Name, p)
+			}
+
+			req := requests[partition.Leader]
+			if req == nil {
+				req = &Request{}
+				requests[partition.Leader] = req
+				leaders = append(leaders, partition.Leader)
+			}
+
+			if n := len(req.Topics); n == 0 || req.Topics[n-1].Name != t.Name {
+				req.Topics = append(req.Topics, RequestTopic{Name: t.Name})
+			}
+
+			last := &req.Topics[len(req.Topics)-1]
+			last.PartitionIndexes = append(last.PartitionIndexes, p)
+		}
+	}
+
+	sort.Slice(leaders, func(i, j int) bool { return leaders[i] < leaders[j] })
+	messages := make([]protocol.Message, len(leaders))
+
+	for i, leader := range leaders {
+		messages[i] = requests[leader]
+	}
+
+	return messages, new(Response), nil
+}
+
+type Response struct {
+	// We need at least one tagged field to indicate that v0+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v0,max=v0,tag"`
+
+	ThrottleTimeMs int32           `kafka:"min=v0,max=v0"`
+	Topics         []ResponseTopic `kafka:"min=v0,max=v0"`
+}
+
+type ResponseTopic struct {
+	// We need at least one tagged field to indicate that v0+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v0,max=v0,tag"`
+
+	Name       string              `kafka:"min=v0,max=v0"`
+	Partitions []ResponsePartition `kafka:"min=v0,max=v0"`
+}
+
+type ResponsePartition struct {
+	// We need at least one tagged field to indicate that v0+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v0,max=v0,tag"`
+
+	PartitionIndex  int32           `kafka:"min=v0,max=v0"`
+	ErrorCode       int16           `kafka:"min=v0,max=v0"`
+	ErrorMessage    string          `kafka:"min=v0,max=v0,nullable"`
+	ActiveProducers []ProducerState `kafka:"min=v0,max=v0"`
+}
+
+type ProducerState struct {
+	// We need at least one tagged field to indicate that v0+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v0,max=v0,tag"`
+
+	ProducerID            int64 `kafka:"min=v0,max=v0"`
+	ProducerEpoch         int32 `kafka:"min=v0,max=v0"`
+	LastSequence          int32 `kafka:"min=v0,max=v0"`
+	LastTimestamp         int64 `kafka:"min=v0,max=v0"`
+	CoordinatorEpoch      int32 `kafka:"min=v0,max=v0"`
+	CurrentTxnStartOffset int64 `kafka:"min=v0,max=v0"`
+}
+
+func (r *Response) ApiKey() protocol.ApiKey { return protocol.DescribeProducers }
+
+func (r *Response) PartitionErrors(fn func(topic string, partition int32, errorCode int16)) {
+	for _, t := range r.Topics {
+		for _, p := range t.Partitions {
+			if p.ErrorCode != 0 {
+				fn(t.Name, p.PartitionIndex, p.ErrorCode)
+			}
+		}
+	}
+}
+
+func (r *Response) Merge(requests []protocol.Message, results []interface{}) (protocol.Message, error) {
+	response := &Response{}
+	topics := make(map[string]int)
+
+	for _, result := range results {
+		m, err := protocol.Result(result)
+		if err != nil {
+			return nil, err
+		}
+
+		res := m.(*Response)
+
+		if res.ThrottleTimeMs > response.ThrottleTimeMs {
+			response.ThrottleTimeMs = res.ThrottleTimeMs
+		}
+
+		for _, t := range res.Topics {
+			i, ok := topics[t.Name]
+			if !ok {
+				i = len(response.Topics)
+				topics[t.Name] = i
+				response.Topics = append(response.Topics, ResponseTopic{Name: t.Name})
+			}
+			response.Topics[i].Partitions = append(response.Topics[i].Partitions, t.Partitions...)
+		}
+	}
+
+	return response, nil
+}
+
+var (
+	_ protocol.BrokerMessage    = (*Request)(nil)
+	_ protocol.Splitter         = (*Request)(nil)
+	_ protocol.Merger           = (*Response)(nil)
+	_ protocol.PartitionErrorer = (*Response)(nil)
+)
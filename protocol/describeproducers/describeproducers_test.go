@@ -0,0 +1,51 @@
+package describeproducers_test
+
+import (
+	"testing"
+
+	"github.com/PerchSecurity/kafka-go/protocol/describeproducers"
+	"github.com/PerchSecurity/kafka-go/protocol/prototest"
+)
+
+const (
+	v0 = 0
+)
+
+func TestDescribeProducersRequest(t *testing.T) {
+	prototest.TestRequest(t, v0, &describeproducers.Request{
+		Topics: []describeproducers.RequestTopic{
+			{Name: "foo", PartitionIndexes: []int32{0, 1}},
+		},
+	})
+}
+
+func TestDescribeProducersResponse(t *testing.T) {
+	prototest.TestResponse(t, v0, &describeproducers.Response{
+		ThrottleTimeMs: 500,
+		Topics: []describeproducers.ResponseTopic{
+			{
+				Name: "foo",
+				Partitions: []describeproducers.ResponsePartition{
+					{
+						PartitionIndex: 0,
+						ActiveProducers: []describeproducers.ProducerState{
+							{
+								ProducerID:            1,
+								ProducerEpoch:         2,
+								LastSequence:          3,
+								LastTimestamp:         4,
+								CoordinatorEpoch:      5,
+								CurrentTxnStartOffset: 6,
+							},
+						},
+					},
+					{
+						PartitionIndex: 1,
+						ErrorCode:      6,
+						ErrorMessage:   "not leader",
+					},
+				},
+			},
+		},
+	})
+}
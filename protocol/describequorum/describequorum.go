@@ -0,0 +1,6 @@
+// Package describequorum implements the DescribeQuorum API, which reports the
+// state of the raft quorum of the controllers in KRaft clusters. Requests can
+// be sent to any broker, which forward them to the active controller.
+package describequorum
+
+//go:generate go run ../internal/gen -api DescribeQuorum
@@ -0,0 +1,71 @@
+// Code generated by protocol/internal/gen from DescribeQuorumRequest.json and DescribeQuorumResponse.json. DO NOT EDIT.
+
+package describequorum_test
+
+import (
+	"testing"
+
+	"github.com/PerchSecurity/kafka-go/protocol/describequorum"
+	"github.com/PerchSecurity/kafka-go/protocol/prototest"
+)
+
+func TestRequestRoundTrip(t *testing.T) {
+	prototest.TestRequest(t, 0, &describequorum.Request{
+		Topics: []describequorum.RequestTopicData{{
+			TopicName: "topicname-2",
+			Partitions: []describequorum.RequestPartitionData{{
+				PartitionIndex: 4,
+			}},
+		}},
+	})
+}
+
+func TestResponseRoundTrip(t *testing.T) {
+	prototest.TestResponse(t, 0, &describequorum.Response{
+		ErrorCode: 1,
+		Topics: []describequorum.ResponseTopicData{{
+			TopicName: "topicname-3",
+			Partitions: []describequorum.ResponsePartitionData{{
+				PartitionIndex: 5,
+				ErrorCode:      6,
+				LeaderID:       7,
+				LeaderEpoch:    8,
+				HighWatermark:  9,
+				CurrentVoters: []describequorum.ResponseReplicaState{{
+					ReplicaID:    11,
+					LogEndOffset: 12,
+				}},
+				Observers: []describequorum.ResponseReplicaState{{
+					ReplicaID:    14,
+					LogEndOffset: 15,
+				}},
+			}},
+		}},
+	})
+
+	prototest.TestResponse(t, 1, &describequorum.Response{
+		ErrorCode: 1,
+		Topics: []describequorum.ResponseTopicData{{
+			TopicName: "topicname-3",
+			Partitions: []describequorum.ResponsePartitionData{{
+				PartitionIndex: 5,
+				ErrorCode:      6,
+				LeaderID:       7,
+				LeaderEpoch:    8,
+				HighWatermark:  9,
+				CurrentVoters: []describequorum.ResponseReplicaState{{
+					ReplicaID:             11,
+					LogEndOffset:          12,
+					LastFetchTimestamp:    13,
+					LastCaughtUpTimestamp: 14,
+				}},
+				Observers: []describequorum.ResponseReplicaState{{
+					ReplicaID:             16,
+					LogEndOffset:          17,
+					LastFetchTimestamp:    18,
+					LastCaughtUpTimestamp: 19,
+				}},
+			}},
+		}},
+	})
+}
@@ -0,0 +1,110 @@
+package describetransactions
+
+import "github.com/PerchSecurity/kafka-go/protocol"
+
+func init() {
+	protocol.Register(&Request{}, &Response{})
+}
+
+// Detailed API definition: https://kafka.apache.org/protocol#The_Messages_DescribeTransactions
+type Request struct {
+	// We need at least one tagged field to indicate that v0+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v0,max=v0,tag"`
+
+	TransactionalIDs []string `kafka:"min=v0,max=v0"`
+}
+
+func (r *Request) ApiKey() protocol.ApiKey { return protocol.DescribeTransactions }
+
+func (r *Request) Transaction() string {
+	// Expects r to be a request that was returned by Split, which only carry
+	// a single transactional ID.
+	if len(r.TransactionalIDs) == 0 {
+		return ""
+	}
+	return r.TransactionalIDs[0]
+}
+
+func (r *Request) Split(cluster protocol.Cluster) (
+	[]protocol.Message,
+	protocol.Merger,
+	error,
+) {
+	// Transactions may be managed by different coordinators, one request is
+	// sent for each transactional ID so it gets routed to its coordinator.
+	messages := make([]protocol.Message, len(r.TransactionalIDs))
+
+	for i, id := range r.TransactionalIDs {
+		messages[i] = &Request{
+			TransactionalIDs: []string{id},
+		}
+	}
+
+	return messages, new(Response), nil
+}
+
+type Response struct {
+	// We need at least one tagged field to indicate that v0+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v0,max=v0,tag"`
+
+	ThrottleTimeMs    int32                      `kafka:"min=v0,max=v0"`
+	TransactionStates []ResponseTransactionState `kafka:"min=v0,max=v0"`
+}
+
+type ResponseTransactionState struct {
+	// We need at least one tagged field to indicate that v0+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v0,max=v0,tag"`
+
+	ErrorCode              int16           `kafka:"min=v0,max=v0"`
+	TransactionalID        string          `kafka:"min=v0,max=v0"`
+	TransactionState       string          `kafka:"min=v0,max=v0"`
+	TransactionTimeoutMs   int32           `kafka:"min=v0,max=v0"`
+	TransactionStartTimeMs int64           `kafka:"min=v0,max=v0"`
+	ProducerID             int64           `kafka:"min=v0,max=v0"`
+	ProducerEpoch          int16           `kafka:"min=v0,max=v0"`
+	Topics                 []ResponseTopic `kafka:"min=v0,max=v0"`
+}
+
+type ResponseTopic struct {
+	// We need at least one tagged field to indicate that v0+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v0,max=v0,tag"`
+
+	Topic      string  `kafka:"min=v0,max=v0"`
+	Partitions []int32 `kafka:"min=v0,max=v0"`
+}
+
+func (r *Response) ApiKey() protocol.ApiKey { return protocol.DescribeTransactions }
+
+func (r *Response) Merge(requests []protocol.Message, results []interface{}) (
+	protocol.Message,
+	error,
+) {
+	response := &Response{}
+
+	for _, result := range results {
+		m, err := protocol.Result(result)
+		if err != nil {
+			return nil, err
+		}
+
+		res := m.(*Response)
+
+		if res.ThrottleTimeMs > response.ThrottleTimeMs {
+			response.ThrottleTimeMs = res.ThrottleTimeMs
+		}
+
+		response.TransactionStates = append(response.TransactionStates, res.TransactionStates...)
+	}
+
+	return response, nil
+}
+
+var (
+	_ protocol.TransactionalMessage = (*Request)(nil)
+	_ protocol.Splitter             = (*Request)(nil)
+	_ protocol.Merger               = (*Response)(nil)
+)
@@ -0,0 +1,41 @@
+package describetransactions_test
+
+import (
+	"testing"
+
+	"github.com/PerchSecurity/kafka-go/protocol/describetransactions"
+	"github.com/PerchSecurity/kafka-go/protocol/prototest"
+)
+
+const (
+	v0 = 0
+)
+
+func TestDescribeTransactionsRequest(t *testing.T) {
+	prototest.TestRequest(t, v0, &describetransactions.Request{
+		TransactionalIDs: []string{"txn-1", "txn-2"},
+	})
+}
+
+func TestDescribeTransactionsResponse(t *testing.T) {
+	prototest.TestResponse(t, v0, &describetransactions.Response{
+		ThrottleTimeMs: 500,
+		TransactionStates: []describetransactions.ResponseTransactionState{
+			{
+				TransactionalID:        "txn-1",
+				TransactionState:       "Ongoing",
+				TransactionTimeoutMs:   60000,
+				TransactionStartTimeMs: 1000,
+				ProducerID:             1,
+				ProducerEpoch:          2,
+				Topics: []describetransactions.ResponseTopic{
+					{Topic: "foo", Partitions: []int32{0, 1}},
+				},
+			},
+			{
+				ErrorCode:       51,
+				TransactionalID: "txn-2",
+			},
+		},
+	})
+}
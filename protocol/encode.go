@@ -166,6 +166,12 @@ func (e *encoder) encodeCompactNullBytes(v value) {
 	e.writeCompactNullBytes(v.bytes())
 }
 
+func (e *encoder) encodeCompactRecordSet(v value) {
+	if err := v.iface(reflect.PtrTo(recordSetType)).(*RecordSet).writeToCompact(e); err != nil {
+		e.err = err
+	}
+}
+
 func (e *encoder) encodeArray(v value, elemType reflect.Type, encodeElem encodeFunc) {
 	a := v.array(elemType)
 	n := a.length()
@@ -370,10 +376,15 @@ var (
 	_ io.ByteWriter   = (*encoder)(nil)
 	_ io.StringWriter = (*encoder)(nil)
 
-	writerTo = reflect.TypeOf((*io.WriterTo)(nil)).Elem()
+	writerTo      = reflect.TypeOf((*io.WriterTo)(nil)).Elem()
+	recordSetType = reflect.TypeOf(RecordSet{})
 )
 
 func encodeFuncOf(typ reflect.Type, version int16, flexible bool, tag structTag) encodeFunc {
+	if flexible && typ == recordSetType {
+		// In flexible messages, record sets are prefixed with a compact size
+		return (*encoder).encodeCompactRecordSet
+	}
 	if reflect.PtrTo(typ).Implements(writerTo) {
 		return writerEncodeFuncOf(typ)
 	}
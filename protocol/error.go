@@ -39,6 +39,21 @@ const (
 	ErrNoReset Error = "record sequence does not support reset"
 )
 
+// UnsupportedVersionError is returned by ReadRequest when it receives a request
+// for an API, or a version of an API, which is not registered in the package.
+type UnsupportedVersionError struct {
+	ApiKey  ApiKey
+	Version int16
+}
+
+func (e *UnsupportedVersionError) Error() string {
+	t := e.ApiKey.apiType()
+	if len(t.requests) == 0 {
+		return fmt.Sprintf("unsupported api: %s", e.ApiKey)
+	}
+	return fmt.Sprintf("unsupported %s version: v%d not in range v%d-v%d", e.ApiKey, e.Version, t.minVersion(), t.maxVersion())
+}
+
 type TopicError struct {
 	Topic string
 	Err   error
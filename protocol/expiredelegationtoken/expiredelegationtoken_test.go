@@ -0,0 +1,32 @@
+package expiredelegationtoken_test
+
+import (
+	"testing"
+
+	"github.com/PerchSecurity/kafka-go/protocol/expiredelegationtoken"
+	"github.com/PerchSecurity/kafka-go/protocol/prototest"
+)
+
+const (
+	v0 = 0
+	v2 = 2
+)
+
+func TestExpireDelegationTokenRequest(t *testing.T) {
+	for _, version := range []int16{v0, v2} {
+		prototest.TestRequest(t, version, &expiredelegationtoken.Request{
+			HMAC:               []byte("hmac"),
+			ExpiryTimePeriodMs: 3600000,
+		})
+	}
+}
+
+func TestExpireDelegationTokenResponse(t *testing.T) {
+	for _, version := range []int16{v0, v2} {
+		prototest.TestResponse(t, version, &expiredelegationtoken.Response{
+			ErrorCode:         0,
+			ExpiryTimestampMs: 1000,
+			ThrottleTimeMs:    500,
+		})
+	}
+}
@@ -11,16 +11,20 @@ func init() {
 }
 
 type Request struct {
-	ReplicaID       int32                   `kafka:"min=v0,max=v11"`
-	MaxWaitTime     int32                   `kafka:"min=v0,max=v11"`
-	MinBytes        int32                   `kafka:"min=v0,max=v11"`
-	MaxBytes        int32                   `kafka:"min=v3,max=v11"`
-	IsolationLevel  int8                    `kafka:"min=v4,max=v11"`
-	SessionID       int32                   `kafka:"min=v7,max=v11"`
-	SessionEpoch    int32                   `kafka:"min=v7,max=v11"`
-	Topics          []RequestTopic          `kafka:"min=v0,max=v11"`
-	ForgottenTopics []RequestForgottenTopic `kafka:"min=v7,max=v11"`
-	RackID          string                  `kafka:"min=v11,max=v11"`
+	// We need at least one tagged field to indicate that v12+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v12,max=v13,tag"`
+
+	ReplicaID       int32                   `kafka:"min=v0,max=v13"`
+	MaxWaitTime     int32                   `kafka:"min=v0,max=v13"`
+	MinBytes        int32                   `kafka:"min=v0,max=v13"`
+	MaxBytes        int32                   `kafka:"min=v3,max=v13"`
+	IsolationLevel  int8                    `kafka:"min=v4,max=v13"`
+	SessionID       int32                   `kafka:"min=v7,max=v13"`
+	SessionEpoch    int32                   `kafka:"min=v7,max=v13"`
+	Topics          []RequestTopic          `kafka:"min=v0,max=v13"`
+	ForgottenTopics []RequestForgottenTopic `kafka:"min=v7,max=v13"`
+	RackID          string                  `kafka:"min=v11,max=v13"`
 }
 
 func (r *Request) ApiKey() protocol.ApiKey { return protocol.Fetch }
@@ -31,9 +35,9 @@ func (r *Request) Broker(cluster protocol.Cluster) (protocol.Broker, error) {
 	for i := range r.Topics {
 		t := &r.Topics[i]
 
-		topic, ok := cluster.Topics[t.Topic]
+		topic, ok := t.lookup(cluster)
 		if !ok {
-			return broker, NewError(protocol.NewErrNoTopic(t.Topic))
+			return broker, NewError(protocol.NewErrNoTopic(t.name()))
 		}
 
 		for j := range t.Partitions {
@@ -41,11 +45,11 @@ func (r *Request) Broker(cluster protocol.Cluster) (protocol.Broker, error) {
 
 			partition, ok := topic.Partitions[p.Partition]
 			if !ok {
-				return broker, NewError(protocol.NewErrNoPartition(t.Topic, p.Partition))
+				return broker, NewError(protocol.NewErrNoPartition(topic.Name, p.Partition))
 			}
 
 			if b, ok := cluster.Brokers[partition.Leader]; !ok {
-				return broker, NewError(protocol.NewErrNoLeader(t.Topic, p.Partition))
+				return broker, NewError(protocol.NewErrNoLeader(topic.Name, p.Partition))
 			} else if broker.ID < 0 {
 				broker = b
 			} else if b.ID != broker.ID {
@@ -57,56 +61,132 @@ func (r *Request) Broker(cluster protocol.Cluster) (protocol.Broker, error) {
 	return broker, nil
 }
 
+func (r *Request) Split(cluster protocol.Cluster) ([]protocol.Message, protocol.Merger, error) {
+	// Topics may be identified by name or by ID, the other field is resolved
+	// from the cluster layout so the request can be encoded in the version
+	// negotiated with the broker. The fields are resolved in a copy of the
+	// request, so a retry after the topic was recreated does not send the ID
+	// that the topic had on the previous attempt.
+	req := *r
+	req.Topics = make([]RequestTopic, len(r.Topics))
+
+	for i, t := range r.Topics {
+		if topic, ok := t.lookup(cluster); ok {
+			if t.Topic == "" {
+				t.Topic = topic.Name
+			}
+			if t.TopicID.IsZero() {
+				t.TopicID = topic.ID
+			}
+		}
+		req.Topics[i] = t
+	}
+
+	return []protocol.Message{&req}, new(Response), nil
+}
+
+// RequestTopic identifies a topic by name up to v12, and by ID in v13+.
+// Programs which do not know the version negotiated with the broker may set
+// both fields.
 type RequestTopic struct {
-	Topic      string             `kafka:"min=v0,max=v11"`
-	Partitions []RequestPartition `kafka:"min=v0,max=v11"`
+	Topic      string             `kafka:"min=v0,max=v12"`
+	TopicID    protocol.UUID      `kafka:"min=v13,max=v13"`
+	Partitions []RequestPartition `kafka:"min=v0,max=v13"`
+}
+
+func (t *RequestTopic) name() string {
+	if t.Topic == "" && !t.TopicID.IsZero() {
+		return t.TopicID.String()
+	}
+	return t.Topic
+}
+
+func (t *RequestTopic) lookup(cluster protocol.Cluster) (protocol.Topic, bool) {
+	if t.Topic == "" {
+		return cluster.TopicByID(t.TopicID)
+	}
+	topic, ok := cluster.Topics[t.Topic]
+	return topic, ok
 }
 
 type RequestPartition struct {
-	Partition          int32 `kafka:"min=v0,max=v11"`
-	CurrentLeaderEpoch int32 `kafka:"min=v9,max=v11"`
-	FetchOffset        int64 `kafka:"min=v0,max=v11"`
-	LogStartOffset     int64 `kafka:"min=v5,max=v11"`
-	PartitionMaxBytes  int32 `kafka:"min=v0,max=v11"`
+	Partition          int32 `kafka:"min=v0,max=v13"`
+	CurrentLeaderEpoch int32 `kafka:"min=v9,max=v13"`
+	FetchOffset        int64 `kafka:"min=v0,max=v13"`
+	// The epoch of the last fetched record, or -1 if unknown.
+	LastFetchedEpoch  int32 `kafka:"min=v12,max=v13"`
+	LogStartOffset    int64 `kafka:"min=v5,max=v13"`
+	PartitionMaxBytes int32 `kafka:"min=v0,max=v13"`
 }
 
 type RequestForgottenTopic struct {
-	Topic      string  `kafka:"min=v7,max=v11"`
-	Partitions []int32 `kafka:"min=v7,max=v11"`
+	Topic      string        `kafka:"min=v7,max=v12"`
+	TopicID    protocol.UUID `kafka:"min=v13,max=v13"`
+	Partitions []int32       `kafka:"min=v7,max=v13"`
 }
 
 type Response struct {
-	ThrottleTimeMs int32           `kafka:"min=v1,max=v11"`
-	ErrorCode      int16           `kafka:"min=v7,max=v11"`
-	SessionID      int32           `kafka:"min=v7,max=v11"`
-	Topics         []ResponseTopic `kafka:"min=v0,max=v11"`
+	// We need at least one tagged field to indicate that v12+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v12,max=v13,tag"`
+
+	ThrottleTimeMs int32           `kafka:"min=v1,max=v13"`
+	ErrorCode      int16           `kafka:"min=v7,max=v13"`
+	SessionID      int32           `kafka:"min=v7,max=v13"`
+	Topics         []ResponseTopic `kafka:"min=v0,max=v13"`
 }
 
 func (r *Response) ApiKey() protocol.ApiKey { return protocol.Fetch }
 
+// PartitionErrors reports topics of v13+ responses, which are only identified
+// by ID, with the string representation of their ID.
+func (r *Response) PartitionErrors(fn func(topic string, partition int32, errorCode int16)) {
+	for _, t := range r.Topics {
+		for _, p := range t.Partitions {
+			if p.ErrorCode != 0 {
+				fn(t.name(), p.Partition, p.ErrorCode)
+			}
+		}
+	}
+}
+
+func (r *Response) Merge(requests []protocol.Message, results []interface{}) (protocol.Message, error) {
+	return protocol.Result(results[0])
+}
+
+// ResponseTopic identifies a topic by name up to v12, and by ID in v13+.
 type ResponseTopic struct {
-	Topic      string              `kafka:"min=v0,max=v11"`
-	Partitions []ResponsePartition `kafka:"min=v0,max=v11"`
+	Topic      string              `kafka:"min=v0,max=v12"`
+	TopicID    protocol.UUID       `kafka:"min=v13,max=v13"`
+	Partitions []ResponsePartition `kafka:"min=v0,max=v13"`
+}
+
+func (t *ResponseTopic) name() string {
+	if t.Topic == "" && !t.TopicID.IsZero() {
+		return t.TopicID.String()
+	}
+	return t.Topic
 }
 
 type ResponsePartition struct {
-	Partition            int32                 `kafka:"min=v0,max=v11"`
-	ErrorCode            int16                 `kafka:"min=v0,max=v11"`
-	HighWatermark        int64                 `kafka:"min=v0,max=v11"`
-	LastStableOffset     int64                 `kafka:"min=v4,max=v11"`
-	LogStartOffset       int64                 `kafka:"min=v5,max=v11"`
-	AbortedTransactions  []ResponseTransaction `kafka:"min=v4,max=v11"`
-	PreferredReadReplica int32                 `kafka:"min=v11,max=v11"`
-	RecordSet            protocol.RecordSet    `kafka:"min=v0,max=v11"`
+	Partition            int32                 `kafka:"min=v0,max=v13"`
+	ErrorCode            int16                 `kafka:"min=v0,max=v13"`
+	HighWatermark        int64                 `kafka:"min=v0,max=v13"`
+	LastStableOffset     int64                 `kafka:"min=v4,max=v13"`
+	LogStartOffset       int64                 `kafka:"min=v5,max=v13"`
+	AbortedTransactions  []ResponseTransaction `kafka:"min=v4,max=v11|min=v12,max=v13,nullable"`
+	PreferredReadReplica int32                 `kafka:"min=v11,max=v13"`
+	RecordSet            protocol.RecordSet    `kafka:"min=v0,max=v13"`
 }
 
 type ResponseTransaction struct {
-	ProducerID  int64 `kafka:"min=v4,max=v11"`
-	FirstOffset int64 `kafka:"min=v4,max=v11"`
+	ProducerID  int64 `kafka:"min=v4,max=v13"`
+	FirstOffset int64 `kafka:"min=v4,max=v13"`
 }
 
 var (
-	_ protocol.BrokerMessage = (*Request)(nil)
+	_ protocol.BrokerMessage    = (*Request)(nil)
+	_ protocol.PartitionErrorer = (*Response)(nil)
 )
 
 type Error struct {
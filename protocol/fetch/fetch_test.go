@@ -12,6 +12,8 @@ import (
 const (
 	v0  = 0
 	v11 = 11
+	v12 = 12
+	v13 = 13
 )
 
 func TestFetchRequest(t *testing.T) {
@@ -34,6 +36,114 @@ func TestFetchRequest(t *testing.T) {
 	})
 }
 
+func TestFetchRequestTopicIDs(t *testing.T) {
+	topicID := protocol.NewUUID()
+
+	prototest.TestRequest(t, v12, &fetch.Request{
+		ReplicaID:   -1,
+		MaxWaitTime: 500,
+		MinBytes:    1024,
+		MaxBytes:    4096,
+		Topics: []fetch.RequestTopic{
+			{
+				Topic: "topic-1",
+				Partitions: []fetch.RequestPartition{
+					{
+						Partition:          1,
+						CurrentLeaderEpoch: 3,
+						FetchOffset:        2,
+						LastFetchedEpoch:   -1,
+						LogStartOffset:     -1,
+						PartitionMaxBytes:  1024,
+					},
+				},
+			},
+		},
+		RackID: "rack-1",
+	})
+
+	prototest.TestRequest(t, v13, &fetch.Request{
+		ReplicaID:   -1,
+		MaxWaitTime: 500,
+		MinBytes:    1024,
+		MaxBytes:    4096,
+		Topics: []fetch.RequestTopic{
+			{
+				TopicID: topicID,
+				Partitions: []fetch.RequestPartition{
+					{
+						Partition:         1,
+						FetchOffset:       2,
+						LastFetchedEpoch:  -1,
+						LogStartOffset:    -1,
+						PartitionMaxBytes: 1024,
+					},
+				},
+			},
+		},
+		ForgottenTopics: []fetch.RequestForgottenTopic{
+			{
+				TopicID:    topicID,
+				Partitions: []int32{0},
+			},
+		},
+	})
+}
+
+func TestFetchRequestSplitResolvesTopics(t *testing.T) {
+	topicID := protocol.NewUUID()
+
+	cluster := protocol.Cluster{
+		Brokers: map[int32]protocol.Broker{1: {ID: 1}},
+		Topics: map[string]protocol.Topic{
+			"topic-1": {
+				Name:       "topic-1",
+				ID:         topicID,
+				Partitions: map[int32]protocol.Partition{0: {ID: 0, Leader: 1}},
+			},
+		},
+	}
+
+	split := func(req *fetch.Request) fetch.RequestTopic {
+		t.Helper()
+		msgs, _, err := req.Split(cluster)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(msgs) != 1 {
+			t.Fatalf("expected a single request, got %d", len(msgs))
+		}
+		if b, err := msgs[0].(*fetch.Request).Broker(cluster); err != nil || b.ID != 1 {
+			t.Errorf("unexpected broker: %+v (err=%v)", b, err)
+		}
+		return msgs[0].(*fetch.Request).Topics[0]
+	}
+
+	byName := &fetch.Request{Topics: []fetch.RequestTopic{{
+		Topic:      "topic-1",
+		Partitions: []fetch.RequestPartition{{Partition: 0}},
+	}}}
+	if id := split(byName).TopicID; id != topicID {
+		t.Errorf("topic ID mismatch: %v != %v", id, topicID)
+	}
+	// The ID is resolved in a copy, so it is looked up again if the topic is
+	// recreated before the request is retried.
+	if id := byName.Topics[0].TopicID; !id.IsZero() {
+		t.Errorf("the topic ID was written to the request: %v", id)
+	}
+
+	byID := &fetch.Request{Topics: []fetch.RequestTopic{{
+		TopicID:    topicID,
+		Partitions: []fetch.RequestPartition{{Partition: 0}},
+	}}}
+	if name := split(byID).Topic; name != "topic-1" {
+		t.Errorf("topic name mismatch: %q != %q", name, "topic-1")
+	}
+	if name := byID.Topics[0].Topic; name != "" {
+		t.Errorf("the topic name was written to the request: %q", name)
+	}
+}
+
 func TestFetchResponse(t *testing.T) {
 	t0 := time.Now().Truncate(time.Millisecond)
 	t1 := t0.Add(1 * time.Millisecond)
@@ -90,6 +200,54 @@ func TestFetchResponse(t *testing.T) {
 	})
 }
 
+func TestFetchResponseTopicIDs(t *testing.T) {
+	t0 := time.Now().Truncate(time.Millisecond)
+	t1 := t0.Add(1 * time.Millisecond)
+
+	prototest.TestResponse(t, v12, &fetch.Response{
+		Topics: []fetch.ResponseTopic{
+			{
+				Topic: "topic-1",
+				Partitions: []fetch.ResponsePartition{
+					{
+						Partition:            1,
+						HighWatermark:        1000,
+						PreferredReadReplica: -1,
+						RecordSet: protocol.RecordSet{
+							Version: 2,
+							Records: protocol.NewRecordReader(
+								protocol.Record{Offset: 0, Time: t0, Key: nil, Value: prototest.String("msg-0")},
+								protocol.Record{Offset: 1, Time: t1, Key: prototest.Bytes([]byte{1}), Value: prototest.String("msg-1")},
+							),
+						},
+					},
+				},
+			},
+		},
+	})
+
+	prototest.TestResponse(t, v13, &fetch.Response{
+		Topics: []fetch.ResponseTopic{
+			{
+				TopicID: protocol.NewUUID(),
+				Partitions: []fetch.ResponsePartition{
+					{
+						Partition:            1,
+						HighWatermark:        1000,
+						PreferredReadReplica: -1,
+						RecordSet: protocol.RecordSet{
+							Version: 2,
+							Records: protocol.NewRecordReader(
+								protocol.Record{Offset: 0, Time: t0, Key: nil, Value: prototest.String("msg-0")},
+							),
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
 func BenchmarkFetchResponse(b *testing.B) {
 	t0 := time.Now().Truncate(time.Millisecond)
 	t1 := t0.Add(1 * time.Millisecond)
@@ -0,0 +1,582 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const protocolImport = "github.com/PerchSecurity/kafka-go/protocol"
+
+// api is the model of a kafka API built from the specs of its request and
+// response messages.
+type api struct {
+	name      string // e.g. DeleteRecords
+	pkg       string // e.g. deleterecords
+	request   *message
+	response  *message
+	structs   []*goStruct // in the order they are emitted
+	usesProto bool        // whether the structs reference the protocol package
+}
+
+type message struct {
+	spec     *messageSpec
+	file     string
+	valid    versionRange
+	flexible versionRange
+	top      *goStruct
+}
+
+type goStruct struct {
+	name   string
+	fields []*goField
+}
+
+type goField struct {
+	name     string
+	about    string
+	kafka    string    // kafka type of the field or its elements, e.g. int32
+	array    bool      // whether the field is an array
+	elem     *goStruct // non-nil when the field is a struct or array of structs
+	segments []segment
+}
+
+// segment is a range of versions in which a field is encoded the same way.
+type segment struct {
+	versions versionRange
+	nullable bool
+	tag      int // -1 when the field is not tagged
+}
+
+func (f *goField) present(v int16) bool {
+	for _, s := range f.segments {
+		if s.versions.contains(v) {
+			return true
+		}
+	}
+	return false
+}
+
+var primitiveTypes = map[string]string{
+	"bool":    "bool",
+	"int8":    "int8",
+	"int16":   "int16",
+	"int32":   "int32",
+	"int64":   "int64",
+	"float64": "float64",
+	"string":  "string",
+	"bytes":   "[]byte",
+	"uuid":    "protocol.UUID",
+	"records": "protocol.RecordSet",
+}
+
+// loadAPI reads the request and response specs of the named API from dir.
+func loadAPI(dir, name, pkg string) (*api, error) {
+	a := &api{name: name, pkg: pkg}
+
+	for _, m := range []struct {
+		msg  **message
+		kind string
+	}{
+		{msg: &a.request, kind: "Request"},
+		{msg: &a.response, kind: "Response"},
+	} {
+		file := name + m.kind + ".json"
+		spec, err := readMessageSpec(filepath.Join(dir, file))
+		if err != nil {
+			return nil, err
+		}
+		if spec.Name != name+m.kind {
+			return nil, fmt.Errorf("%s: unexpected message name: %q", file, spec.Name)
+		}
+		msg, err := a.newMessage(file, spec, m.kind)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		*m.msg = msg
+	}
+
+	if a.request.spec.ApiKey != a.response.spec.ApiKey {
+		return nil, fmt.Errorf("%s: request and response API keys mismatch: %d != %d",
+			name, a.request.spec.ApiKey, a.response.spec.ApiKey)
+	}
+	return a, nil
+}
+
+func (a *api) newMessage(file string, spec *messageSpec, kind string) (*message, error) {
+	valid, err := parseVersions(spec.ValidVersions)
+	if err != nil {
+		return nil, err
+	}
+	if valid.empty() || valid.max == maxVersion {
+		return nil, fmt.Errorf("invalid version range: %q", spec.ValidVersions)
+	}
+	flexible, err := parseVersions(spec.FlexibleVersions)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &message{
+		spec:     spec,
+		file:     file,
+		valid:    valid,
+		flexible: flexible.intersect(valid),
+	}
+
+	b := &structBuilder{
+		api:    a,
+		msg:    m,
+		prefix: kind,
+		common: make(map[string]structSpec, len(spec.CommonStructs)),
+		built:  make(map[string]*goStruct),
+	}
+	for _, s := range spec.CommonStructs {
+		b.common[s.Name] = s
+	}
+
+	m.top, err = b.build(kind, spec.Fields)
+	return m, err
+}
+
+const maxVersion = 1<<15 - 1
+
+type structBuilder struct {
+	api    *api
+	msg    *message
+	prefix string
+	common map[string]structSpec
+	built  map[string]*goStruct
+}
+
+func (b *structBuilder) build(name string, fields []fieldSpec) (*goStruct, error) {
+	s := &goStruct{name: name}
+	b.built[name] = s
+	b.api.structs = append(b.api.structs, s)
+
+	for _, f := range fields {
+		field, err := b.field(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", name, f.Name, err)
+		}
+		if field != nil {
+			s.fields = append(s.fields, field)
+		}
+	}
+	return s, nil
+}
+
+func (b *structBuilder) field(f fieldSpec) (*goField, error) {
+	versions, err := parseVersions(f.Versions)
+	if err != nil {
+		return nil, err
+	}
+	if versions = versions.intersect(b.msg.valid); versions.empty() {
+		return nil, nil // the field was removed before the oldest version
+	}
+	nullable, err := parseVersions(f.NullableVersions)
+	if err != nil {
+		return nil, err
+	}
+	tagged, err := parseVersions(f.TaggedVersions)
+	if err != nil {
+		return nil, err
+	}
+	if !tagged.empty() && f.Tag == nil {
+		return nil, fmt.Errorf("missing tag of field with tagged versions %q", f.TaggedVersions)
+	}
+
+	field := &goField{
+		name:  goName(f.Name),
+		about: f.About,
+		kafka: strings.TrimPrefix(f.Type, "[]"),
+		array: strings.HasPrefix(f.Type, "[]"),
+	}
+
+	if _, ok := primitiveTypes[field.kafka]; ok {
+		if len(f.Fields) != 0 {
+			return nil, fmt.Errorf("field of type %q cannot have fields", f.Type)
+		}
+		if field.kafka == "uuid" || field.kafka == "records" {
+			b.api.usesProto = true
+		}
+	} else if field.elem, err = b.struct_(field.kafka, f.Fields); err != nil {
+		return nil, err
+	}
+
+	for v := versions.min; v <= versions.max; v++ {
+		seg := segment{
+			versions: versionRange{min: v, max: v},
+			nullable: nullable.contains(v),
+			tag:      -1,
+		}
+		if tagged.contains(v) {
+			seg.tag = *f.Tag
+		}
+		if n := len(field.segments); n != 0 {
+			if last := &field.segments[n-1]; last.nullable == seg.nullable && last.tag == seg.tag {
+				last.versions.max = v
+				continue
+			}
+		}
+		field.segments = append(field.segments, seg)
+	}
+
+	return field, nil
+}
+
+// struct_ returns the go struct for the kafka struct type of the given name,
+// which is either declared inline by a field or in the common structs.
+func (b *structBuilder) struct_(kafkaName string, fields []fieldSpec) (*goStruct, error) {
+	name := b.prefix + structName(b.api.name, kafkaName)
+	if s, ok := b.built[name]; ok {
+		if len(fields) != 0 {
+			return nil, fmt.Errorf("duplicate declaration of struct %q", kafkaName)
+		}
+		return s, nil
+	}
+	if len(fields) == 0 {
+		common, ok := b.common[kafkaName]
+		if !ok {
+			return nil, fmt.Errorf("unknown type: %q", kafkaName)
+		}
+		fields = common.Fields
+	}
+	return b.build(name, fields)
+}
+
+// structName returns the name of nested structs, without the name of the API
+// which would stutter with the package name. The name is prefixed with either
+// Request or Response to prevent conflicts between the two messages.
+func structName(apiName, kafkaName string) string {
+	if s := strings.TrimPrefix(kafkaName, apiName); s != "" {
+		return s
+	}
+	return kafkaName
+}
+
+// goName converts the name of a field to a go identifier, following the go
+// conventions for initialisms (e.g. TopicId becomes TopicID).
+func goName(name string) string {
+	r := []rune(name)
+	r[0] = unicode.ToUpper(r[0])
+	s := string(r)
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if strings.HasPrefix(s[i:], "Id") {
+			if rest := strings.TrimPrefix(s[i+2:], "s"); rest == "" || unicode.IsUpper(rune(rest[0])) {
+				b.WriteString("ID")
+				i++
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func (s segment) structTag() string {
+	tag := fmt.Sprintf("min=v%d,max=v%d", s.versions.min, s.versions.max)
+	if s.nullable {
+		tag += ",nullable"
+	}
+	if s.tag >= 0 {
+		tag += ",tag=" + strconv.Itoa(s.tag)
+	}
+	return tag
+}
+
+func (f *goField) goType() string {
+	t := primitiveTypes[f.kafka]
+	if f.elem != nil {
+		t = f.elem.name
+	}
+	if f.array {
+		t = "[]" + t
+	}
+	return t
+}
+
+func (f *goField) structTag() string {
+	tags := make([]string, len(f.segments))
+	for i, s := range f.segments {
+		tags[i] = s.structTag()
+	}
+	return `kafka:"` + strings.Join(tags, "|") + `"`
+}
+
+// generateCode returns the source of the structs of the API.
+func generateCode(a *api) ([]byte, error) {
+	w := new(codeWriter)
+	w.header(a)
+	w.printf("package %s\n\n", a.pkg)
+	w.printf("import %q\n\n", protocolImport)
+	w.printf("func init() {\n\tprotocol.Register(&Request{}, &Response{})\n}\n\n")
+
+	for _, s := range a.structs {
+		var msg *message
+		switch s {
+		case a.request.top:
+			msg = a.request
+		case a.response.top:
+			msg = a.response
+		}
+
+		w.printf("type %s struct {\n", s.name)
+		first := msg == nil || !w.versionMarker(msg)
+		for _, f := range s.fields {
+			if f.about != "" {
+				if !first {
+					w.printf("\n")
+				}
+				w.comment("\t", f.about)
+			}
+			first = false
+			w.printf("\t%s %s `%s`\n", f.name, f.goType(), f.structTag())
+		}
+		w.printf("}\n\n")
+
+		if msg != nil {
+			w.printf("func (r *%s) ApiKey() protocol.ApiKey { return protocol.%s }\n\n", s.name, a.name)
+		}
+	}
+
+	return w.format()
+}
+
+// versionMarker writes the blank fields declaring the versions of a message
+// which are not implied by its fields: the message types are flexible in the
+// versions where they have tagged fields, and the first and last versions are
+// those of the fields. The method returns whether it wrote anything.
+func (w *codeWriter) versionMarker(m *message) bool {
+	covered := noVersions
+	for _, f := range m.top.fields {
+		for _, s := range f.segments {
+			if covered.empty() || s.versions.min < covered.min {
+				covered.min = s.versions.min
+			}
+			if covered.empty() || s.versions.max > covered.max {
+				covered.max = s.versions.max
+			}
+		}
+	}
+
+	var tags []string
+	if m.flexible.empty() {
+		if covered != m.valid {
+			tags = append(tags, segment{versions: m.valid, tag: -1}.structTag())
+		}
+	} else {
+		if covered.empty() || covered.min > m.valid.min {
+			if r := (versionRange{min: m.valid.min, max: m.flexible.min - 1}); !r.empty() {
+				tags = append(tags, segment{versions: r, tag: -1}.structTag())
+			}
+		}
+		tags = append(tags, segment{versions: m.flexible, tag: -1}.structTag()+",tag")
+	}
+
+	if len(tags) == 0 {
+		return false
+	}
+	if !m.flexible.empty() {
+		w.printf("\t// We need at least one tagged field to indicate that v%d+ uses \"flexible\"\n", m.flexible.min)
+		w.printf("\t// messages.\n")
+	}
+	w.printf("\t_ struct{} `kafka:\"%s\"`\n", strings.Join(tags, "|"))
+	return true
+}
+
+// generateTest returns the source of the round-trip tests of the API, testing
+// each version where the encoding of the messages changes.
+func generateTest(a *api) ([]byte, error) {
+	w := new(codeWriter)
+	w.header(a)
+	w.printf("package %s_test\n\n", a.pkg)
+	w.printf("import (\n\t\"testing\"\n\n")
+	if a.usesProto {
+		w.printf("\t%q\n", protocolImport)
+	}
+	w.printf("\t%q\n", protocolImport+"/"+a.pkg)
+	w.printf("\t%q\n)\n\n", protocolImport+"/prototest")
+
+	for _, m := range []struct {
+		msg  *message
+		kind string
+	}{
+		{msg: a.request, kind: "Request"},
+		{msg: a.response, kind: "Response"},
+	} {
+		w.printf("func Test%sRoundTrip(t *testing.T) {\n", m.kind)
+		for i, v := range changeVersions(m.msg) {
+			if i != 0 {
+				w.printf("\n")
+			}
+			w.printf("\tprototest.Test%s(t, %d, &%s.%s", m.kind, v, a.pkg, m.msg.top.name)
+			if err := w.value(a, m.msg.top, v, new(int)); err != nil {
+				return nil, err
+			}
+			w.printf(")\n")
+		}
+		w.printf("}\n\n")
+	}
+
+	return w.format()
+}
+
+// changeVersions returns the versions of m in which the encoding of at least
+// one field changes.
+func changeVersions(m *message) []int16 {
+	set := map[int16]bool{m.valid.min: true}
+	if !m.flexible.empty() {
+		set[m.flexible.min] = true
+	}
+
+	seen := map[*goStruct]bool{}
+	var walk func(*goStruct)
+	walk = func(s *goStruct) {
+		if seen[s] {
+			return
+		}
+		seen[s] = true
+		for _, f := range s.fields {
+			for _, seg := range f.segments {
+				set[seg.versions.min] = true
+				set[seg.versions.max+1] = true
+			}
+			if f.elem != nil {
+				walk(f.elem)
+			}
+		}
+	}
+	walk(m.top)
+
+	versions := make([]int16, 0, len(set))
+	for v := range set {
+		if m.valid.contains(v) {
+			versions = append(versions, v)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	return versions
+}
+
+// value writes the body of a composite literal of s with sample values in the
+// fields that exist in version v, n is incremented to generate distinct values.
+func (w *codeWriter) value(a *api, s *goStruct, v int16, n *int) error {
+	w.printf("{\n")
+	for _, f := range s.fields {
+		if !f.present(v) {
+			continue
+		}
+		w.printf("%s: ", f.name)
+		if f.elem != nil && !f.array {
+			w.printf("%s.%s", a.pkg, f.elem.name)
+		}
+		if f.array {
+			w.printf("[]")
+			if f.elem != nil {
+				w.printf("%s.%s", a.pkg, f.elem.name)
+			} else {
+				w.printf("%s", primitiveTypes[f.kafka])
+			}
+			w.printf("{")
+		}
+		for i, count := 0, f.count(); i < count; i++ {
+			if i != 0 {
+				w.printf(", ")
+			}
+			*n++
+			if f.elem != nil {
+				if err := w.value(a, f.elem, v, n); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := w.sample(f, *n); err != nil {
+				return err
+			}
+		}
+		if f.array {
+			w.printf("}")
+		}
+		w.printf(",\n")
+	}
+	w.printf("}")
+	return nil
+}
+
+func (f *goField) count() int {
+	if f.array && f.elem == nil {
+		return 2
+	}
+	return 1
+}
+
+func (w *codeWriter) sample(f *goField, n int) error {
+	name := strings.ToLower(f.name)
+	switch f.kafka {
+	case "bool":
+		w.printf("true")
+	case "int8":
+		w.printf("%d", n%128)
+	case "int16", "int32", "int64":
+		w.printf("%d", n)
+	case "float64":
+		w.printf("%d.5", n)
+	case "string":
+		w.printf("%q", fmt.Sprintf("%s-%d", name, n))
+	case "bytes":
+		w.printf("[]byte(%q)", fmt.Sprintf("%s-%d", name, n))
+	case "uuid":
+		w.printf("protocol.UUID{%d}", n%256)
+	default:
+		return fmt.Errorf("%s: no sample values for fields of type %q", f.name, f.kafka)
+	}
+	return nil
+}
+
+type codeWriter struct {
+	bytes.Buffer
+}
+
+func (w *codeWriter) printf(format string, args ...interface{}) {
+	fmt.Fprintf(w, format, args...)
+}
+
+func (w *codeWriter) header(a *api) {
+	w.printf("// Code generated by protocol/internal/gen from %s and %s. DO NOT EDIT.\n\n",
+		a.request.file, a.response.file)
+}
+
+// comment writes text as a line comment wrapped at 80 columns, assuming that
+// tabs are 4 columns wide.
+func (w *codeWriter) comment(indent, text string) {
+	width := 80 - 4*strings.Count(indent, "\t") - len("// ")
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len(line)+1+len(word) > width {
+			w.printf("%s// %s\n", indent, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		w.printf("%s// %s\n", indent, line)
+	}
+}
+
+func (w *codeWriter) format() ([]byte, error) {
+	b, err := format.Source(w.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w\n%s", err, w.Bytes())
+	}
+	return b, nil
+}
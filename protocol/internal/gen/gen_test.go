@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParseVersions(t *testing.T) {
+	tests := []struct {
+		in  string
+		out versionRange
+	}{
+		{in: "none", out: noVersions},
+		{in: "", out: noVersions},
+		{in: "3", out: versionRange{min: 3, max: 3}},
+		{in: "1-4", out: versionRange{min: 1, max: 4}},
+		{in: "2+", out: versionRange{min: 2, max: maxVersion}},
+	}
+
+	for _, test := range tests {
+		r, err := parseVersions(test.in)
+		if err != nil {
+			t.Errorf("%q: %v", test.in, err)
+		} else if r != test.out {
+			t.Errorf("%q: %+v != %+v", test.in, r, test.out)
+		}
+	}
+
+	if _, err := parseVersions("v1+"); err == nil {
+		t.Error("expected an error parsing a malformed version")
+	}
+}
+
+func TestGoName(t *testing.T) {
+	for in, out := range map[string]string{
+		"timeoutMs":      "TimeoutMs",
+		"TopicId":        "TopicID",
+		"TopicIds":       "TopicIDs",
+		"ProducerIdBase": "ProducerIDBase",
+		"Idempotent":     "Idempotent",
+		"IsolationLevel": "IsolationLevel",
+	} {
+		if s := goName(in); s != out {
+			t.Errorf("%q: %q != %q", in, s, out)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	files, err := generate("Example", "example", "testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := string(files["generated.go"])
+
+	for _, s := range []string{
+		"_ struct{} `kafka:\"min=v3,max=v4,tag\"`",
+		"TopicIDs []protocol.UUID `kafka:\"min=v2,max=v4\"`",
+		"Rack string `kafka:\"min=v1,max=v1|min=v2,max=v4,nullable\"`",
+		"Tagged int64 `kafka:\"min=v3,max=v4,tag=0\"`",
+		"Entries []RequestEntry `kafka:\"min=v1,max=v4\"`",
+		"Entry RequestShared `kafka:\"min=v1,max=v4\"`",
+		"ProducerID int64 `kafka:\"min=v1,max=v4\"`",
+		"_ struct{} `kafka:\"min=v0,max=v2\"`",
+		"ThrottleTimeMs int32 `kafka:\"min=v1,max=v2\"`",
+	} {
+		if !strings.Contains(strings.Join(strings.Fields(code), " "), s) {
+			t.Errorf("missing declaration in generated code: %s", s)
+		}
+	}
+	if strings.Contains(code, "Removed") {
+		t.Error("fields removed before the oldest version must not be generated")
+	}
+
+	test := string(files["generated_test.go"])
+	for _, v := range []string{"1", "2", "3"} {
+		if !strings.Contains(test, "prototest.TestRequest(t, "+v+",") {
+			t.Errorf("missing test of version %s", v)
+		}
+	}
+	if strings.Contains(test, "prototest.TestRequest(t, 4,") {
+		t.Error("versions where the encoding does not change must not be tested")
+	}
+}
+
+// TestGeneratedFilesUpToDate verifies that the files of the protocol packages
+// match the output of the generator, run go generate ./... in the protocol
+// directory to update them.
+func TestGeneratedFilesUpToDate(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("..", "..", "*", "generated.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no generated files found")
+	}
+
+	header := regexp.MustCompile(`from (\w+)Request\.json`)
+
+	for _, path := range paths {
+		dir := filepath.Dir(path)
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := header.FindSubmatch(b)
+		if m == nil {
+			t.Errorf("%s: missing header", path)
+			continue
+		}
+
+		files, err := generate(string(m[1]), filepath.Base(dir), "messages")
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+			continue
+		}
+
+		for name, content := range files {
+			b, err := ioutil.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				t.Error(err)
+			} else if !bytes.Equal(b, content) {
+				t.Errorf("%s is out of date", filepath.Join(dir, name))
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Command gen generates the request and response types of the protocol
+// packages from the JSON specs of the kafka messages.
+//
+// The program is intended to be used with go generate from the directory of
+// a protocol package, for example:
+//
+//	//go:generate go run ../internal/gen -api DeleteRecords
+//
+// It reads the DeleteRecordsRequest.json and DeleteRecordsResponse.json specs
+// from the messages directory (copied from clients/src/main/resources/common/message
+// in the kafka repository), and writes the message types to generated.go and
+// the prototest round-trip tests of each version to generated_test.go.
+//
+// Only the encoding of the messages is generated, the methods which depend on
+// the semantics of the APIs (e.g. Broker, Split, or Merge) are implemented in
+// other files of the packages.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	var (
+		apiName string
+		pkg     string
+		specDir string
+		outDir  string
+	)
+	flag.StringVar(&apiName, "api", "", "Name of the API to generate, e.g. DeleteRecords")
+	flag.StringVar(&pkg, "package", "", "Name of the generated package, defaults to the lowercase name of the API")
+	flag.StringVar(&specDir, "spec", filepath.Join("..", "internal", "gen", "messages"), "Directory of the message specs")
+	flag.StringVar(&outDir, "out", ".", "Directory where the files are generated")
+	flag.Parse()
+
+	if apiName == "" {
+		fmt.Fprintln(os.Stderr, "gen: missing -api flag")
+		flag.Usage()
+		os.Exit(2)
+	}
+	if pkg == "" {
+		pkg = strings.ToLower(apiName)
+	}
+
+	if err := run(apiName, pkg, specDir, outDir); err != nil {
+		fmt.Fprintf(os.Stderr, "gen: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(apiName, pkg, specDir, outDir string) error {
+	files, err := generate(apiName, pkg, specDir)
+	if err != nil {
+		return err
+	}
+	for name, b := range files {
+		if err := ioutil.WriteFile(filepath.Join(outDir, name), b, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generate returns the content of the generated files of an API, indexed by
+// file name.
+func generate(apiName, pkg, specDir string) (map[string][]byte, error) {
+	a, err := loadAPI(specDir, apiName, pkg)
+	if err != nil {
+		return nil, err
+	}
+	code, err := generateCode(a)
+	if err != nil {
+		return nil, err
+	}
+	test, err := generateTest(a)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{
+		"generated.go":      code,
+		"generated_test.go": test,
+	}, nil
+}
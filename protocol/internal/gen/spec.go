@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// messageSpec is the representation of the JSON files describing the kafka
+// messages, see clients/src/main/resources/common/message/README.md in the
+// kafka repository for details.
+type messageSpec struct {
+	ApiKey           int          `json:"apiKey"`
+	Type             string       `json:"type"`
+	Name             string       `json:"name"`
+	ValidVersions    string       `json:"validVersions"`
+	FlexibleVersions string       `json:"flexibleVersions"`
+	Fields           []fieldSpec  `json:"fields"`
+	CommonStructs    []structSpec `json:"commonStructs"`
+}
+
+type structSpec struct {
+	Name     string      `json:"name"`
+	Versions string      `json:"versions"`
+	Fields   []fieldSpec `json:"fields"`
+}
+
+type fieldSpec struct {
+	Name             string      `json:"name"`
+	Type             string      `json:"type"`
+	Versions         string      `json:"versions"`
+	NullableVersions string      `json:"nullableVersions"`
+	TaggedVersions   string      `json:"taggedVersions"`
+	Tag              *int        `json:"tag"`
+	About            string      `json:"about"`
+	Fields           []fieldSpec `json:"fields"`
+}
+
+// readMessageSpec reads the message spec in the file at path.
+//
+// The files contain comments, which are not valid JSON, kafka only uses
+// comments on their own lines so we strip those before decoding the content.
+func readMessageSpec(path string) (*messageSpec, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for s.Scan() {
+		if line := s.Text(); !strings.HasPrefix(strings.TrimSpace(line), "//") {
+			buf.WriteString(line)
+			buf.WriteByte('\n')
+		}
+	}
+
+	spec := new(messageSpec)
+	if err := json.Unmarshal(buf.Bytes(), spec); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return spec, nil
+}
+
+// versionRange is a range of message versions, the range is empty when min is
+// greater than max.
+type versionRange struct {
+	min int16
+	max int16
+}
+
+var noVersions = versionRange{min: 0, max: -1}
+
+// parseVersions parses the version ranges of message specs, which are either
+// "none", a single version, an open range "N+", or a closed range "N-M".
+func parseVersions(s string) (versionRange, error) {
+	switch {
+	case s == "" || s == "none":
+		return noVersions, nil
+	case strings.HasSuffix(s, "+"):
+		min, err := parseVersion(s[:len(s)-1])
+		return versionRange{min: min, max: math.MaxInt16}, err
+	case strings.Contains(s, "-"):
+		i := strings.IndexByte(s, '-')
+		min, err := parseVersion(s[:i])
+		if err != nil {
+			return noVersions, err
+		}
+		max, err := parseVersion(s[i+1:])
+		return versionRange{min: min, max: max}, err
+	default:
+		v, err := parseVersion(s)
+		return versionRange{min: v, max: v}, err
+	}
+}
+
+func parseVersion(s string) (int16, error) {
+	v, err := strconv.ParseInt(s, 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf("malformed version: %q", s)
+	}
+	return int16(v), nil
+}
+
+func (r versionRange) empty() bool { return r.min > r.max }
+
+func (r versionRange) contains(v int16) bool { return r.min <= v && v <= r.max }
+
+func (r versionRange) intersect(other versionRange) versionRange {
+	if other.min > r.min {
+		r.min = other.min
+	}
+	if other.max < r.max {
+		r.max = other.max
+	}
+	return r
+}
@@ -111,6 +111,16 @@ type ResponsePartition struct {
 
 func (r *Response) ApiKey() protocol.ApiKey { return protocol.ListOffsets }
 
+func (r *Response) PartitionErrors(fn func(topic string, partition int32, errorCode int16)) {
+	for _, t := range r.Topics {
+		for _, p := range t.Partitions {
+			if p.ErrorCode != 0 {
+				fn(t.Topic, p.Partition, p.ErrorCode)
+			}
+		}
+	}
+}
+
 func (r *Response) Merge(requests []protocol.Message, results []interface{}) (protocol.Message, error) {
 	type topicPartition struct {
 		topic     string
@@ -224,7 +234,8 @@ func (r *Response) Merge(requests []protocol.Message, results []interface{}) (pr
 }
 
 var (
-	_ protocol.BrokerMessage = (*Request)(nil)
-	_ protocol.Splitter      = (*Request)(nil)
-	_ protocol.Merger        = (*Response)(nil)
+	_ protocol.BrokerMessage    = (*Request)(nil)
+	_ protocol.Splitter         = (*Request)(nil)
+	_ protocol.Merger           = (*Response)(nil)
+	_ protocol.PartitionErrorer = (*Response)(nil)
 )
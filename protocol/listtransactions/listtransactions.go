@@ -0,0 +1,124 @@
+package listtransactions
+
+import "github.com/PerchSecurity/kafka-go/protocol"
+
+func init() {
+	protocol.Register(&Request{}, &Response{})
+}
+
+// Detailed API definition: https://kafka.apache.org/protocol#The_Messages_ListTransactions
+type Request struct {
+	// We need at least one tagged field to indicate that v0+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v0,max=v1,tag"`
+
+	StateFilters      []string `kafka:"min=v0,max=v1"`
+	ProducerIDFilters []int64  `kafka:"min=v0,max=v1"`
+	DurationFilter    int64    `kafka:"min=v1,max=v1"`
+
+	// BrokerID is the broker that the request is sent to. It is set on each
+	// of the requests returned by Split.
+	BrokerID int32 `kafka:"-"`
+}
+
+func (r *Request) ApiKey() protocol.ApiKey { return protocol.ListTransactions }
+
+func (r *Request) Broker(cluster protocol.Cluster) (protocol.Broker, error) {
+	return cluster.Brokers[r.BrokerID], nil
+}
+
+func (r *Request) Split(cluster protocol.Cluster) (
+	[]protocol.Message,
+	protocol.Merger,
+	error,
+) {
+	// Each broker only reports the transactions that it is the coordinator
+	// of, so the request is sent to all of them and the results are merged
+	// back together.
+	brokerIDs := cluster.BrokerIDs()
+	messages := make([]protocol.Message, len(brokerIDs))
+
+	for i, id := range brokerIDs {
+		messages[i] = &Request{
+			StateFilters:      r.StateFilters,
+			ProducerIDFilters: r.ProducerIDFilters,
+			DurationFilter:    r.DurationFilter,
+			BrokerID:          id,
+		}
+	}
+
+	return messages, new(Response), nil
+}
+
+type Response struct {
+	// We need at least one tagged field to indicate that v0+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v0,max=v1,tag"`
+
+	ThrottleTimeMs      int32                      `kafka:"min=v0,max=v1"`
+	ErrorCode           int16                      `kafka:"min=v0,max=v1"`
+	UnknownStateFilters []string                   `kafka:"min=v0,max=v1"`
+	TransactionStates   []ResponseTransactionState `kafka:"min=v0,max=v1"`
+}
+
+type ResponseTransactionState struct {
+	// We need at least one tagged field to indicate that v0+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v0,max=v1,tag"`
+
+	TransactionalID  string `kafka:"min=v0,max=v1"`
+	ProducerID       int64  `kafka:"min=v0,max=v1"`
+	TransactionState string `kafka:"min=v0,max=v1"`
+
+	// BrokerID is the coordinator of the transaction. It is set by Merge on
+	// the results of each of the brokers.
+	BrokerID int32 `kafka:"-"`
+}
+
+func (r *Response) ApiKey() protocol.ApiKey { return protocol.ListTransactions }
+
+func (r *Response) Merge(requests []protocol.Message, results []interface{}) (
+	protocol.Message,
+	error,
+) {
+	response := &Response{}
+	unknownStateFilters := make(map[string]struct{})
+
+	for i, result := range results {
+		m, err := protocol.Result(result)
+		if err != nil {
+			return nil, err
+		}
+
+		res := m.(*Response)
+		brokerID := requests[i].(*Request).BrokerID
+
+		if res.ThrottleTimeMs > response.ThrottleTimeMs {
+			response.ThrottleTimeMs = res.ThrottleTimeMs
+		}
+
+		if res.ErrorCode != 0 && response.ErrorCode == 0 {
+			response.ErrorCode = res.ErrorCode
+		}
+
+		for _, state := range res.UnknownStateFilters {
+			if _, ok := unknownStateFilters[state]; !ok {
+				unknownStateFilters[state] = struct{}{}
+				response.UnknownStateFilters = append(response.UnknownStateFilters, state)
+			}
+		}
+
+		for _, txn := range res.TransactionStates {
+			txn.BrokerID = brokerID
+			response.TransactionStates = append(response.TransactionStates, txn)
+		}
+	}
+
+	return response, nil
+}
+
+var (
+	_ protocol.BrokerMessage = (*Request)(nil)
+	_ protocol.Splitter      = (*Request)(nil)
+	_ protocol.Merger        = (*Response)(nil)
+)
@@ -0,0 +1,42 @@
+package listtransactions_test
+
+import (
+	"testing"
+
+	"github.com/PerchSecurity/kafka-go/protocol/listtransactions"
+	"github.com/PerchSecurity/kafka-go/protocol/prototest"
+)
+
+const (
+	v0 = 0
+	v1 = 1
+)
+
+func TestListTransactionsRequest(t *testing.T) {
+	prototest.TestRequest(t, v0, &listtransactions.Request{
+		StateFilters:      []string{"Ongoing"},
+		ProducerIDFilters: []int64{1, 2},
+	})
+
+	prototest.TestRequest(t, v1, &listtransactions.Request{
+		StateFilters:      []string{"Ongoing"},
+		ProducerIDFilters: []int64{1, 2},
+		DurationFilter:    60000,
+	})
+}
+
+func TestListTransactionsResponse(t *testing.T) {
+	for _, version := range []int16{v0, v1} {
+		prototest.TestResponse(t, version, &listtransactions.Response{
+			ThrottleTimeMs:      500,
+			UnknownStateFilters: []string{"Unknown"},
+			TransactionStates: []listtransactions.ResponseTransactionState{
+				{
+					TransactionalID:  "txn-1",
+					ProducerID:       1,
+					TransactionState: "Ongoing",
+				},
+			},
+		})
+	}
+}
@@ -7,46 +7,114 @@ func init() {
 }
 
 type Request struct {
-	TopicNames                         []string `kafka:"min=v0,max=v8,nullable"`
-	AllowAutoTopicCreation             bool     `kafka:"min=v4,max=v8"`
-	IncludeClusterAuthorizedOperations bool     `kafka:"min=v8,max=v8"`
-	IncludeTopicAuthorizedOperations   bool     `kafka:"min=v8,max=v8"`
+	// We need at least one tagged field to indicate that v9+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v9,max=v12,tag"`
+
+	// Names of the topics to retrieve metadata for, nil means all topics.
+	//
+	// In v10+, the names are sent as Topics entries, see Prepare.
+	TopicNames []string `kafka:"min=v0,max=v9,nullable"`
+
+	// Topics to retrieve metadata for in v10+, nil means all topics. Topics
+	// are looked up by ID when their name is empty, which requires v12+.
+	Topics []RequestTopic `kafka:"min=v10,max=v12,nullable"`
+
+	AllowAutoTopicCreation             bool `kafka:"min=v4,max=v12"`
+	IncludeClusterAuthorizedOperations bool `kafka:"min=v8,max=v10"`
+	IncludeTopicAuthorizedOperations   bool `kafka:"min=v8,max=v12"`
+}
+
+type RequestTopic struct {
+	TopicID protocol.UUID `kafka:"min=v10,max=v12"`
+	Name    string        `kafka:"min=v10,max=v12,nullable"`
 }
 
 func (r *Request) ApiKey() protocol.ApiKey { return protocol.Metadata }
 
+// Prepare moves the names of TopicNames to Topics for versions which use the
+// latter, and the other way around, so programs can set either field.
+func (r *Request) Prepare(apiVersion int16) {
+	if apiVersion >= 10 {
+		if r.TopicNames != nil && r.Topics == nil {
+			r.Topics = make([]RequestTopic, 0, len(r.TopicNames))
+		}
+		for _, name := range r.TopicNames {
+			if !r.hasTopic(name) {
+				r.Topics = append(r.Topics, RequestTopic{Name: name})
+			}
+		}
+	} else {
+		if r.Topics != nil && r.TopicNames == nil {
+			r.TopicNames = make([]string, 0, len(r.Topics))
+		}
+		for _, t := range r.Topics {
+			if t.Name != "" && !r.hasTopicName(t.Name) {
+				r.TopicNames = append(r.TopicNames, t.Name)
+			}
+		}
+	}
+}
+
+func (r *Request) hasTopic(name string) bool {
+	for _, t := range r.Topics {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Request) hasTopicName(name string) bool {
+	for _, n := range r.TopicNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
 type Response struct {
-	ThrottleTimeMs              int32            `kafka:"min=v3,max=v8"`
-	Brokers                     []ResponseBroker `kafka:"min=v0,max=v8"`
-	ClusterID                   string           `kafka:"min=v2,max=v8,nullable"`
-	ControllerID                int32            `kafka:"min=v1,max=v8"`
-	Topics                      []ResponseTopic  `kafka:"min=v0,max=v8"`
-	ClusterAuthorizedOperations int32            `kafka:"min=v8,max=v8"`
+	// We need at least one tagged field to indicate that v9+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v9,max=v12,tag"`
+
+	ThrottleTimeMs              int32            `kafka:"min=v3,max=v12"`
+	Brokers                     []ResponseBroker `kafka:"min=v0,max=v12"`
+	ClusterID                   string           `kafka:"min=v2,max=v12,nullable"`
+	ControllerID                int32            `kafka:"min=v1,max=v12"`
+	Topics                      []ResponseTopic  `kafka:"min=v0,max=v12"`
+	ClusterAuthorizedOperations int32            `kafka:"min=v8,max=v10"`
 }
 
 func (r *Response) ApiKey() protocol.ApiKey { return protocol.Metadata }
 
 type ResponseBroker struct {
-	NodeID int32  `kafka:"min=v0,max=v8"`
-	Host   string `kafka:"min=v0,max=v8"`
-	Port   int32  `kafka:"min=v0,max=v8"`
-	Rack   string `kafka:"min=v1,max=v8,nullable"`
+	NodeID int32  `kafka:"min=v0,max=v12"`
+	Host   string `kafka:"min=v0,max=v12"`
+	Port   int32  `kafka:"min=v0,max=v12"`
+	Rack   string `kafka:"min=v1,max=v12,nullable"`
 }
 
 type ResponseTopic struct {
-	ErrorCode                 int16               `kafka:"min=v0,max=v8"`
-	Name                      string              `kafka:"min=v0,max=v8"`
-	IsInternal                bool                `kafka:"min=v1,max=v8"`
-	Partitions                []ResponsePartition `kafka:"min=v0,max=v8"`
-	TopicAuthorizedOperations int32               `kafka:"min=v8,max=v8"`
+	ErrorCode                 int16               `kafka:"min=v0,max=v12"`
+	Name                      string              `kafka:"min=v0,max=v11|min=v12,max=v12,nullable"`
+	TopicID                   protocol.UUID       `kafka:"min=v10,max=v12"`
+	IsInternal                bool                `kafka:"min=v1,max=v12"`
+	Partitions                []ResponsePartition `kafka:"min=v0,max=v12"`
+	TopicAuthorizedOperations int32               `kafka:"min=v8,max=v12"`
 }
 
 type ResponsePartition struct {
-	ErrorCode       int16   `kafka:"min=v0,max=v8"`
-	PartitionIndex  int32   `kafka:"min=v0,max=v8"`
-	LeaderID        int32   `kafka:"min=v0,max=v8"`
-	LeaderEpoch     int32   `kafka:"min=v7,max=v8"`
-	ReplicaNodes    []int32 `kafka:"min=v0,max=v8"`
-	IsrNodes        []int32 `kafka:"min=v0,max=v8"`
-	OfflineReplicas []int32 `kafka:"min=v5,max=v8"`
+	ErrorCode       int16   `kafka:"min=v0,max=v12"`
+	PartitionIndex  int32   `kafka:"min=v0,max=v12"`
+	LeaderID        int32   `kafka:"min=v0,max=v12"`
+	LeaderEpoch     int32   `kafka:"min=v7,max=v12"`
+	ReplicaNodes    []int32 `kafka:"min=v0,max=v12"`
+	IsrNodes        []int32 `kafka:"min=v0,max=v12"`
+	OfflineReplicas []int32 `kafka:"min=v5,max=v12"`
 }
+
+var (
+	_ protocol.PreparedMessage = (*Request)(nil)
+)
@@ -1,17 +1,21 @@
 package metadata_test
 
 import (
+	"reflect"
 	"testing"
 
+	"github.com/PerchSecurity/kafka-go/protocol"
 	"github.com/PerchSecurity/kafka-go/protocol/metadata"
 	"github.com/PerchSecurity/kafka-go/protocol/prototest"
 )
 
 const (
-	v0 = 0
-	v1 = 1
-	v4 = 4
-	v8 = 8
+	v0  = 0
+	v1  = 1
+	v4  = 4
+	v8  = 8
+	v9  = 9
+	v12 = 12
 )
 
 func TestMetadataRequest(t *testing.T) {
@@ -143,6 +147,76 @@ func TestMetadataResponse(t *testing.T) {
 	})
 }
 
+func TestMetadataTopicIDs(t *testing.T) {
+	topicID := protocol.NewUUID()
+
+	prototest.TestRequest(t, v9, &metadata.Request{
+		TopicNames:             []string{"hello", "world"},
+		AllowAutoTopicCreation: true,
+	})
+
+	prototest.TestRequest(t, v12, &metadata.Request{
+		Topics: []metadata.RequestTopic{
+			{Name: "hello"},
+			{TopicID: topicID},
+		},
+		IncludeTopicAuthorizedOperations: true,
+	})
+
+	prototest.TestResponse(t, v12, &metadata.Response{
+		ThrottleTimeMs: 123,
+		ClusterID:      "test",
+		ControllerID:   1,
+		Brokers: []metadata.ResponseBroker{
+			{
+				NodeID: 0,
+				Host:   "127.0.0.1",
+				Port:   9092,
+				Rack:   "rack-1",
+			},
+		},
+		Topics: []metadata.ResponseTopic{
+			{
+				Name:    "topic-1",
+				TopicID: topicID,
+				Partitions: []metadata.ResponsePartition{
+					{
+						PartitionIndex:  0,
+						LeaderID:        0,
+						LeaderEpoch:     1,
+						ReplicaNodes:    []int32{0},
+						IsrNodes:        []int32{0},
+						OfflineReplicas: []int32{},
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestMetadataRequestPrepare(t *testing.T) {
+	topicID := protocol.NewUUID()
+
+	req := &metadata.Request{TopicNames: []string{"hello"}}
+	req.Prepare(v12)
+	if want := []metadata.RequestTopic{{Name: "hello"}}; !reflect.DeepEqual(req.Topics, want) {
+		t.Errorf("topics mismatch: %+v != %+v", req.Topics, want)
+	}
+
+	req = &metadata.Request{Topics: []metadata.RequestTopic{{Name: "hello"}, {TopicID: topicID}}}
+	req.Prepare(v8)
+	if want := []string{"hello"}; !reflect.DeepEqual(req.TopicNames, want) {
+		t.Errorf("topic names mismatch: %q != %q", req.TopicNames, want)
+	}
+
+	// An empty list of topics must not become a request for all topics.
+	req = &metadata.Request{TopicNames: []string{}}
+	req.Prepare(v12)
+	if req.Topics == nil {
+		t.Error("expected an empty list of topics")
+	}
+}
+
 func BenchmarkMetadataRequest(b *testing.B) {
 	prototest.BenchmarkRequest(b, v8, &metadata.Request{
 		TopicNames:                         []string{"hello", "world"},
@@ -32,6 +32,18 @@ type Response struct {
 
 func (r *Response) ApiKey() protocol.ApiKey { return protocol.OffsetFetch }
 
+func (r *Response) PartitionErrors(fn func(topic string, partition int32, errorCode int16)) {
+	for _, t := range r.Topics {
+		for _, p := range t.Partitions {
+			if p.ErrorCode != 0 {
+				fn(t.Name, p.PartitionIndex, p.ErrorCode)
+			}
+		}
+	}
+}
+
+var _ protocol.PartitionErrorer = (*Response)(nil)
+
 type ResponseTopic struct {
 	Name       string              `kafka:"min=v0,max=v5"`
 	Partitions []ResponsePartition `kafka:"min=v0,max=v5"`
@@ -0,0 +1,192 @@
+package offsetforleaderepoch
+
+import (
+	"sort"
+
+	"github.com/PerchSecurity/kafka-go/protocol"
+)
+
+func init() {
+	protocol.Register(&Request{}, &Response{})
+}
+
+// Detailed API definition: https://kafka.apache.org/protocol#The_Messages_OffsetForLeaderEpoch
+type Request struct {
+	// We need at least one tagged field to indicate that v4+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v4,max=v4,tag"`
+
+	ReplicaID int32          `kafka:"min=v3,max=v4"`
+	Topics    []RequestTopic `kafka:"min=v0,max=v4"`
+}
+
+type RequestTopic struct {
+	// We need at least one tagged field to indicate that v4+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v4,max=v4,tag"`
+
+	Topic      string             `kafka:"min=v0,max=v4"`
+	Partitions []RequestPartition `kafka:"min=v0,max=v4"`
+}
+
+type RequestPartition struct {
+	// We need at least one tagged field to indicate that v4+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v4,max=v4,tag"`
+
+	Partition          int32 `kafka:"min=v0,max=v4"`
+	CurrentLeaderEpoch int32 `kafka:"min=v2,max=v4"`
+	LeaderEpoch        int32 `kafka:"min=v0,max=v4"`
+}
+
+func (r *Request) ApiKey() protocol.ApiKey { return protocol.OffsetForLeaderEpoch }
+
+func (r *Request) Broker(cluster protocol.Cluster) (protocol.Broker, error) {
+	// Expects r to be a request that was returned by Split, all partitions of
+	// the request are then led by the same broker.
+	for _, t := range r.Topics {
+		topic, ok := cluster.Topics[t.Topic]
+		if !ok {
+			return protocol.Broker{}, protocol.NewErrNoTopic(t.Topic)
+		}
+
+		for _, p := range t.Partitions {
+			partition, ok := topic.Partitions[p.Partition]
+			if !ok {
+				return protocol.Broker{}, protocol.NewErrNoPartition(t.Topic, p.Partition)
+			}
+
+			broker, ok := cluster.Brokers[partition.Leader]
+			if !ok {
+				return protocol.Broker{}, protocol.NewErrNoLeader(t.Topic, p.Partition)
+			}
+
+			return broker, nil
+		}
+	}
+
+	return protocol.Broker{ID: -1}, nil
+}
+
+func (r *Request) Split(cluster protocol.Cluster) ([]protocol.Message, protocol.Merger, error) {
+	// OffsetForLeaderEpoch requests must be sent to the partition leaders, the
+	// partitions are grouped by leader so a single request is sent to each of
+	// the brokers.
+	requests := make(map[int32]*Request)
+	leaders := make([]int32, 0, 8)
+
+	for _, t := range r.Topics {
+		topic, ok := cluster.Topics[t.Topic]
+		if !ok {
+			return nil, nil, protocol.NewErrNoTopic(t.Topic)
+		}
+
+		for _, p := range t.Partitions {
+			partition, ok := topic.Partitions[p.Partition]
+			if !ok {
+				return nil, nil, protocol.NewErrNoPartition(t.Topic, p.Partition)
+			}
+
+			req := requests[partition.Leader]
+			if req == nil {
+				req = &Request{ReplicaID: r.ReplicaID}
+				requests[partition.Leader] = req
+				leaders = append(leaders, partition.Leader)
+			}
+
+			if n := len(req.Topics); n == 0 || req.Topics[n-1].Topic != t.Topic {
+				req.Topics = append(req.Topics, RequestTopic{Topic: t.Topic})
+			}
+
+			last := &req.Topics[len(req.Topics)-1]
+			last.Partitions = append(last.Partitions, p)
+		}
+	}
+
+	sort.Slice(leaders, func(i, j int) bool { return leaders[i] < leaders[j] })
+	messages := make([]protocol.Message, len(leaders))
+
+	for i, leader := range leaders {
+		messages[i] = requests[leader]
+	}
+
+	return messages, new(Response), nil
+}
+
+type Response struct {
+	// We need at least one tagged field to indicate that v4+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v4,max=v4,tag"`
+
+	ThrottleTimeMs int32           `kafka:"min=v2,max=v4"`
+	Topics         []ResponseTopic `kafka:"min=v0,max=v4"`
+}
+
+type ResponseTopic struct {
+	// We need at least one tagged field to indicate that v4+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v4,max=v4,tag"`
+
+	Topic      string              `kafka:"min=v0,max=v4"`
+	Partitions []ResponsePartition `kafka:"min=v0,max=v4"`
+}
+
+type ResponsePartition struct {
+	// We need at least one tagged field to indicate that v4+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v4,max=v4,tag"`
+
+	ErrorCode   int16 `kafka:"min=v0,max=v4"`
+	Partition   int32 `kafka:"min=v0,max=v4"`
+	LeaderEpoch int32 `kafka:"min=v1,max=v4"`
+	EndOffset   int64 `kafka:"min=v0,max=v4"`
+}
+
+func (r *Response) ApiKey() protocol.ApiKey { return protocol.OffsetForLeaderEpoch }
+
+func (r *Response) PartitionErrors(fn func(topic string, partition int32, errorCode int16)) {
+	for _, t := range r.Topics {
+		for _, p := range t.Partitions {
+			if p.ErrorCode != 0 {
+				fn(t.Topic, p.Partition, p.ErrorCode)
+			}
+		}
+	}
+}
+
+func (r *Response) Merge(requests []protocol.Message, results []interface{}) (protocol.Message, error) {
+	response := &Response{}
+	topics := make(map[string]int)
+
+	for _, result := range results {
+		m, err := protocol.Result(result)
+		if err != nil {
+			return nil, err
+		}
+
+		res := m.(*Response)
+
+		if res.ThrottleTimeMs > response.ThrottleTimeMs {
+			response.ThrottleTimeMs = res.ThrottleTimeMs
+		}
+
+		for _, t := range res.Topics {
+			i, ok := topics[t.Topic]
+			if !ok {
+				i = len(response.Topics)
+				topics[t.Topic] = i
+				response.Topics = append(response.Topics, ResponseTopic{Topic: t.Topic})
+			}
+			response.Topics[i].Partitions = append(response.Topics[i].Partitions, t.Partitions...)
+		}
+	}
+
+	return response, nil
+}
+
+var (
+	_ protocol.BrokerMessage    = (*Request)(nil)
+	_ protocol.Splitter         = (*Request)(nil)
+	_ protocol.Merger           = (*Response)(nil)
+	_ protocol.PartitionErrorer = (*Response)(nil)
+)
@@ -105,6 +105,16 @@ type Response struct {
 
 func (r *Response) ApiKey() protocol.ApiKey { return protocol.Produce }
 
+func (r *Response) PartitionErrors(fn func(topic string, partition int32, errorCode int16)) {
+	for _, t := range r.Topics {
+		for _, p := range t.Partitions {
+			if p.ErrorCode != 0 {
+				fn(t.Topic, p.Partition, p.ErrorCode)
+			}
+		}
+	}
+}
+
 type ResponseTopic struct {
 	Topic      string              `kafka:"min=v0,max=v8"`
 	Partitions []ResponsePartition `kafka:"min=v0,max=v8"`
@@ -126,8 +136,9 @@ type ResponseError struct {
 }
 
 var (
-	_ protocol.BrokerMessage   = (*Request)(nil)
-	_ protocol.PreparedMessage = (*Request)(nil)
+	_ protocol.BrokerMessage    = (*Request)(nil)
+	_ protocol.PreparedMessage  = (*Request)(nil)
+	_ protocol.PartitionErrorer = (*Response)(nil)
 )
 
 type Error struct {
@@ -105,8 +105,23 @@ const (
 	AlterClientQuotas            ApiKey = 49
 	DescribeUserScramCredentials ApiKey = 50
 	AlterUserScramCredentials    ApiKey = 51
-
-	numApis = 52
+	Vote                         ApiKey = 52
+	BeginQuorumEpoch             ApiKey = 53
+	EndQuorumEpoch               ApiKey = 54
+	DescribeQuorum               ApiKey = 55
+	AlterPartition               ApiKey = 56
+	UpdateFeatures               ApiKey = 57
+	Envelope                     ApiKey = 58
+	FetchSnapshot                ApiKey = 59
+	DescribeCluster              ApiKey = 60
+	DescribeProducers            ApiKey = 61
+	BrokerRegistration           ApiKey = 62
+	BrokerHeartbeat              ApiKey = 63
+	UnregisterBroker             ApiKey = 64
+	DescribeTransactions         ApiKey = 65
+	ListTransactions             ApiKey = 66
+
+	numApis = 67
 )
 
 var apiNames = [numApis]string{
@@ -162,6 +177,21 @@ var apiNames = [numApis]string{
 	AlterClientQuotas:            "AlterClientQuotas",
 	DescribeUserScramCredentials: "DescribeUserScramCredentials",
 	AlterUserScramCredentials:    "AlterUserScramCredentials",
+	Vote:                         "Vote",
+	BeginQuorumEpoch:             "BeginQuorumEpoch",
+	EndQuorumEpoch:               "EndQuorumEpoch",
+	DescribeQuorum:               "DescribeQuorum",
+	AlterPartition:               "AlterPartition",
+	UpdateFeatures:               "UpdateFeatures",
+	Envelope:                     "Envelope",
+	FetchSnapshot:                "FetchSnapshot",
+	DescribeCluster:              "DescribeCluster",
+	DescribeProducers:            "DescribeProducers",
+	BrokerRegistration:           "BrokerRegistration",
+	BrokerHeartbeat:              "BrokerHeartbeat",
+	UnregisterBroker:             "UnregisterBroker",
+	DescribeTransactions:         "DescribeTransactions",
+	ListTransactions:             "ListTransactions",
 }
 
 type messageType struct {
@@ -445,6 +475,7 @@ func itoa(i int32) string {
 }
 
 type Topic struct {
+	ID         UUID
 	Name       string
 	Error      int16
 	Partitions map[int32]Partition
@@ -527,6 +558,18 @@ type Merger interface {
 	Merge(messages []Message, results []interface{}) (Message, error)
 }
 
+// PartitionErrorer is an extension of the Message interface implemented by
+// response types which report errors on topic partitions. Programs use it to
+// inspect the error codes of responses without knowing their concrete type.
+//
+// Responses which report errors on whole topics (e.g. CreateTopics) call fn
+// with a partition of -1.
+type PartitionErrorer interface {
+	// Calls fn for each topic partition of the response which has a non-zero
+	// error code.
+	PartitionErrors(fn func(topic string, partition int32, errorCode int16))
+}
+
 // Result converts r to a Message or an error, or panics if r could not be
 // converted to these types.
 func Result(r interface{}) (Message, error) {
@@ -58,6 +58,8 @@ func deepEqualValue(v1, v2 reflect.Value) bool {
 		return deepEqualPtr(v1, v2)
 	case reflect.Slice:
 		return deepEqualSlice(v1, v2)
+	case reflect.Array:
+		return v1.Interface() == v2.Interface()
 	default:
 		panic("comparing values of unsupported type: " + v1.Type().String())
 	}
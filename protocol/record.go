@@ -110,6 +110,26 @@ type RecordSet struct {
 	// that compose the stream, it may use type assertions to access the
 	// underlying types of each batch.
 	Records RecordReader
+
+	// The codec used to compress the records when writing the record set, it
+	// overrides the codec installed in the compress.Codecs table for the
+	// compression set in the attributes, which must match the codec's code.
+	//
+	// The field is ignored when reading record sets.
+	Codec compress.Codec
+}
+
+// codec returns the codec used to compress the records when writing the
+// record set, or nil if it is not compressed.
+func (rs *RecordSet) codec() compress.Codec {
+	compression := rs.Attributes.Compression()
+	if compression == 0 {
+		return nil
+	}
+	if rs.Codec != nil {
+		return rs.Codec
+	}
+	return compression.Codec()
 }
 
 // bufferedReader is an interface implemented by types like bufio.Reader, which
@@ -155,12 +175,38 @@ func (rs *RecordSet) ReadFrom(r io.Reader) (int64, error) {
 		return 4, nil
 	}
 
+	return rs.readRecords(d, limit, 4, int(size))
+}
+
+// readFromCompact reads a record set prefixed with its size in the compact
+// format of flexible messages.
+func (rs *RecordSet) readFromCompact(d *decoder) (int64, error) {
+	*rs = RecordSet{}
+	limit := d.remain
+	size := d.readUnsignedVarInt()
+	prefix := limit - d.remain
+
+	if d.err != nil {
+		return int64(prefix), d.err
+	}
+
+	if size <= 1 { // null or empty
+		return int64(prefix), nil
+	}
+
+	return rs.readRecords(d, limit, prefix, int(size-1))
+}
+
+// readRecords reads the size bytes of record batches following the size
+// prefix of a record set. limit is the number of bytes that remained in the
+// decoder before reading the prefix.
+func (rs *RecordSet) readRecords(d *decoder, limit, prefix, size int) (int64, error) {
 	stream := &RecordStream{
 		Records: make([]RecordReader, 0, 4),
 	}
 
 	var err error
-	d.remain = int(size)
+	d.remain = size
 
 	for d.remain > 0 && err == nil {
 		var version byte
@@ -169,7 +215,7 @@ func (rs *RecordSet) ReadFrom(r io.Reader) (int64, error) {
 			if len(stream.Records) != 0 {
 				break
 			}
-			return 4, fmt.Errorf("impossible record set shorter than %d bytes", magicByteOffset+1)
+			return int64(prefix), fmt.Errorf("impossible record set shorter than %d bytes", magicByteOffset+1)
 		}
 
 		switch r := d.reader.(type) {
@@ -177,7 +223,7 @@ func (rs *RecordSet) ReadFrom(r io.Reader) (int64, error) {
 			b, err := r.Peek(magicByteOffset + 1)
 			if err != nil {
 				n, _ := r.Discard(len(b))
-				return 4 + int64(n), dontExpectEOF(err)
+				return int64(prefix + n), dontExpectEOF(err)
 			}
 			version = b[magicByteOffset]
 		case bytesBuffer:
@@ -185,7 +231,7 @@ func (rs *RecordSet) ReadFrom(r io.Reader) (int64, error) {
 		default:
 			b := make([]byte, magicByteOffset+1)
 			if n, err := io.ReadFull(d.reader, b); err != nil {
-				return 4 + int64(n), dontExpectEOF(err)
+				return int64(prefix + n), dontExpectEOF(err)
 			}
 			version = b[magicByteOffset]
 			// Reconstruct the prefix that we had to read to determine the version
@@ -227,7 +273,7 @@ func (rs *RecordSet) ReadFrom(r io.Reader) (int64, error) {
 	}
 
 	d.discardAll()
-	rn := 4 + (int(size) - d.remain)
+	rn := prefix + (size - d.remain)
 	d.remain = limit - rn
 	return int64(rn), err
 }
@@ -237,6 +283,11 @@ func (rs *RecordSet) ReadFrom(r io.Reader) (int64, error) {
 //
 // The error will be ErrNoRecord if rs contained no records.
 //
+// Record offsets are ignored, records are written with consecutive offsets
+// starting at zero, or at the value returned by the Offset method of
+// rs.Records if it has one (like *RecordBatch and *MessageSet values do).
+// This allows brokers to write fetch responses with record offsets.
+//
 // Note: since this package is only compatible with kafka 0.10 and above, the
 // method never produces messages in version 0. If rs.Version is zero, the
 // method defaults to producing messages in version 1.
@@ -292,6 +343,22 @@ func (rs *RecordSet) WriteTo(w io.Writer) (int64, error) {
 	return n, nil
 }
 
+// writeToCompact writes the representation of rs into e, prefixed with its
+// size in the compact format of flexible messages.
+func (rs *RecordSet) writeToCompact(e *encoder) error {
+	buffer := newPageBuffer()
+	defer buffer.unref()
+
+	if _, err := rs.WriteTo(buffer); err != nil {
+		return err
+	}
+
+	buffer.Discard(4) // int32 size written by WriteTo
+	e.writeUnsignedVarInt(uint64(buffer.Len()) + 1)
+	_, err := buffer.WriteTo(e)
+	return err
+}
+
 // RawRecordSet represents a record set for a RawProduce request. The record set is
 // represented as a raw sequence of pre-encoded record set bytes.
 type RawRecordSet struct {
@@ -343,6 +410,20 @@ func timestamp(t time.Time) int64 {
 	return t.UnixNano() / int64(time.Millisecond)
 }
 
+// offsetRecordReader is implemented by record readers which know the offset
+// of their first record.
+type offsetRecordReader interface {
+	RecordReader
+	Offset() int64
+}
+
+func baseOffsetOf(r RecordReader) int64 {
+	if o, ok := r.(offsetRecordReader); ok {
+		return o.Offset()
+	}
+	return 0
+}
+
 func packUint32(u uint32) (b [4]byte) {
 	binary.BigEndian.PutUint32(b[:], u)
 	return
@@ -154,7 +154,7 @@ func (rs *RecordSet) writeToVersion1(buffer *pageBuffer, bufferOffset int64) err
 	records := rs.Records
 
 	if compression := attributes.Compression(); compression != 0 {
-		if codec := compression.Codec(); codec != nil {
+		if codec := rs.codec(); codec != nil {
 			// In the message format version 1, compression is achieved by
 			// compressing the value of a message which recursively contains
 			// the representation of the compressed message set.
@@ -195,6 +195,7 @@ func (rs *RecordSet) writeToVersion1(buffer *pageBuffer, bufferOffset int64) err
 
 	e := encoder{writer: buffer}
 	currentTimestamp := timestamp(time.Now())
+	baseOffset := baseOffsetOf(records)
 
 	return forEachRecord(records, func(i int, r *Record) error {
 		t := timestamp(r.Time)
@@ -203,7 +204,7 @@ func (rs *RecordSet) writeToVersion1(buffer *pageBuffer, bufferOffset int64) err
 		}
 
 		messageOffset := buffer.Size()
-		e.writeInt64(int64(i))
+		e.writeInt64(baseOffset + int64(i))
 		e.writeInt32(0) // message size placeholder
 		e.writeInt32(0) // crc32 placeholder
 		e.setCRC(crc32.IEEETable)
@@ -191,9 +191,10 @@ func (rs *RecordSet) readFromVersion2(d *decoder) error {
 func (rs *RecordSet) writeToVersion2(buffer *pageBuffer, bufferOffset int64) error {
 	records := rs.Records
 	numRecords := int32(0)
+	baseOffset := baseOffsetOf(records)
 
 	e := &encoder{writer: buffer}
-	e.writeInt64(0)                    // base offset                         |  0 +8
+	e.writeInt64(baseOffset)           // base offset                         |  0 +8
 	e.writeInt32(0)                    // placeholder for record batch length |  8 +4
 	e.writeInt32(-1)                   // partition leader epoch              | 12 +3
 	e.writeInt8(2)                     // magic byte                          | 16 +1
@@ -209,7 +210,7 @@ func (rs *RecordSet) writeToVersion2(buffer *pageBuffer, bufferOffset int64) err
 
 	var compressor io.WriteCloser
 	if compression := rs.Attributes.Compression(); compression != 0 {
-		if codec := compression.Codec(); codec != nil {
+		if codec := rs.codec(); codec != nil {
 			compressor = codec.NewWriter(buffer)
 			e.writer = compressor
 		}
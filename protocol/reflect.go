@@ -51,7 +51,16 @@ func (v value) string() string { return v.val.String() }
 
 func (v value) bytes() []byte { return v.val.Bytes() }
 
-func (v value) iface(t reflect.Type) interface{} { return v.val.Addr().Interface() }
+func (v value) iface(t reflect.Type) interface{} {
+	if !v.val.CanAddr() {
+		// Values passed to Marshal are not addressable, pointer methods are
+		// called on a copy instead.
+		p := reflect.New(v.val.Type())
+		p.Elem().Set(v.val)
+		return p.Interface()
+	}
+	return v.val.Addr().Interface()
+}
 
 func (v value) array(t reflect.Type) array { return array(v) }
 
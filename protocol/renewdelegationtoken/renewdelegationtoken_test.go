@@ -0,0 +1,32 @@
+package renewdelegationtoken_test
+
+import (
+	"testing"
+
+	"github.com/PerchSecurity/kafka-go/protocol/prototest"
+	"github.com/PerchSecurity/kafka-go/protocol/renewdelegationtoken"
+)
+
+const (
+	v0 = 0
+	v2 = 2
+)
+
+func TestRenewDelegationTokenRequest(t *testing.T) {
+	for _, version := range []int16{v0, v2} {
+		prototest.TestRequest(t, version, &renewdelegationtoken.Request{
+			HMAC:          []byte("hmac"),
+			RenewPeriodMs: 3600000,
+		})
+	}
+}
+
+func TestRenewDelegationTokenResponse(t *testing.T) {
+	for _, version := range []int16{v0, v2} {
+		prototest.TestResponse(t, version, &renewdelegationtoken.Response{
+			ErrorCode:         0,
+			ExpiryTimestampMs: 1000,
+			ThrottleTimeMs:    500,
+		})
+	}
+}
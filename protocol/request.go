@@ -5,6 +5,15 @@ import (
 	"io"
 )
 
+// ReadRequest reads a request from r, returning its version, correlation ID,
+// the ID of the client which sent it, and the decoded message. It is the
+// server-side counterpart of WriteRequest.
+//
+// When the request is for an API, or a version of an API, which is not
+// registered in the package, the body of the request is discarded and the
+// error is an *UnsupportedVersionError. The version and correlation ID are
+// still returned so the program can respond with an error and continue
+// reading requests from r.
 func ReadRequest(r io.Reader) (apiVersion int16, correlationID int32, clientID string, msg Message, err error) {
 	d := &decoder{reader: r, remain: 4}
 	size := d.readInt32()
@@ -20,27 +29,22 @@ func ReadRequest(r io.Reader) (apiVersion int16, correlationID int32, clientID s
 	correlationID = d.readInt32()
 	clientID = d.readString()
 
-	if i := int(apiKey); i < 0 || i >= len(apiTypes) {
-		err = fmt.Errorf("unsupported api key: %d", i)
-		return
-	}
-
 	if err = d.err; err != nil {
 		err = dontExpectEOF(err)
 		return
 	}
 
-	t := &apiTypes[apiKey]
-	if t == nil {
-		err = fmt.Errorf("unsupported api: %s", apiNames[apiKey])
-		return
-	}
-
+	t := apiKey.apiType()
 	minVersion := t.minVersion()
 	maxVersion := t.maxVersion()
 
-	if apiVersion < minVersion || apiVersion > maxVersion {
-		err = fmt.Errorf("unsupported %s version: v%d not in range v%d-v%d", apiKey, apiVersion, minVersion, maxVersion)
+	if len(t.requests) == 0 || apiVersion < minVersion || apiVersion > maxVersion {
+		d.discardAll()
+		if err = d.err; err != nil {
+			err = dontExpectEOF(err)
+		} else {
+			err = &UnsupportedVersionError{ApiKey: apiKey, Version: apiVersion}
+		}
 		return
 	}
 
@@ -83,6 +83,9 @@ func ReadResponse(r io.Reader, apiKey ApiKey, apiVersion int16) (correlationID i
 	return
 }
 
+// WriteResponse writes msg to w as the response to the request with the given
+// version and correlation ID. It is the server-side counterpart of
+// ReadResponse.
 func WriteResponse(w io.Writer, apiVersion int16, correlationID int32, msg Message) error {
 	apiKey := msg.ApiKey()
 
@@ -91,7 +94,7 @@ func WriteResponse(w io.Writer, apiVersion int16, correlationID int32, msg Messa
 	}
 
 	t := &apiTypes[apiKey]
-	if t == nil {
+	if len(t.responses) == 0 {
 		return fmt.Errorf("unsupported api: %s", apiNames[apiKey])
 	}
 
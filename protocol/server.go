@@ -0,0 +1,422 @@
+package protocol
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sort"
+	"sync"
+)
+
+// ErrServerClosed is returned by the Serve method of Server after the server
+// was closed.
+var ErrServerClosed = errors.New("kafka: server closed")
+
+// Handler is the interface implemented by types which serve the requests
+// received by a Server.
+type Handler interface {
+	// ServeKafka returns the response to req, or an error which closes the
+	// client connection.
+	//
+	// The response is discarded for requests which do not expect one, like
+	// produce requests with no acknowledgements; the handler may return nil.
+	//
+	// The context is canceled when the server is closed, handlers which block
+	// (e.g. to implement long-polling fetch requests) must watch it.
+	ServeKafka(ctx context.Context, req *ServerRequest) (Message, error)
+}
+
+// HandlerFunc is an adapter to allow the use of ordinary functions as kafka
+// request handlers.
+type HandlerFunc func(context.Context, *ServerRequest) (Message, error)
+
+// ServeKafka calls f(ctx, req).
+func (f HandlerFunc) ServeKafka(ctx context.Context, req *ServerRequest) (Message, error) {
+	return f(ctx, req)
+}
+
+// ServeMux is a request multiplexer, it dispatches each request to the
+// handler registered for its API key.
+//
+// Requests for API keys with no registered handler fail with an
+// *UnsupportedVersionError. When a Server has a ServeMux handler and no
+// ApiVersions configured, it only advertises the APIs registered in the mux.
+//
+// The zero value is an empty mux ready to use.
+type ServeMux struct {
+	mutex    sync.RWMutex
+	handlers map[ApiKey]Handler
+}
+
+// Handle registers the handler for requests of the given API key. Handle
+// panics if a handler is already registered for the key.
+func (m *ServeMux) Handle(apiKey ApiKey, handler Handler) {
+	if handler == nil {
+		panic("kafka: nil handler registered for " + apiKey.String())
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, ok := m.handlers[apiKey]; ok {
+		panic("kafka: multiple handlers registered for " + apiKey.String())
+	}
+	if m.handlers == nil {
+		m.handlers = make(map[ApiKey]Handler)
+	}
+	m.handlers[apiKey] = handler
+}
+
+// HandleFunc registers the handler function for requests of the given API
+// key.
+func (m *ServeMux) HandleFunc(apiKey ApiKey, handler func(context.Context, *ServerRequest) (Message, error)) {
+	m.Handle(apiKey, HandlerFunc(handler))
+}
+
+// ServeKafka dispatches req to the handler registered for its API key.
+func (m *ServeMux) ServeKafka(ctx context.Context, req *ServerRequest) (Message, error) {
+	apiKey := req.Message.ApiKey()
+
+	m.mutex.RLock()
+	handler := m.handlers[apiKey]
+	m.mutex.RUnlock()
+
+	if handler == nil {
+		return nil, &UnsupportedVersionError{ApiKey: apiKey, Version: req.Version}
+	}
+	return handler.ServeKafka(ctx, req)
+}
+
+func (m *ServeMux) handles(apiKey ApiKey) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	_, ok := m.handlers[apiKey]
+	return ok
+}
+
+// ServerRequest carries a request received by a Server.
+type ServerRequest struct {
+	// Version of the API used to encode the request, the response is encoded
+	// with the same version.
+	Version int16
+
+	// Correlation ID of the request, the server sets it on the response.
+	CorrelationID int32
+
+	// ID of the client which sent the request.
+	ClientID string
+
+	// Addresses of the connection that the request was received on.
+	LocalAddr  net.Addr
+	RemoteAddr net.Addr
+
+	// The request message, the ApiKey method of the message tells which API
+	// it is for.
+	Message Message
+}
+
+// ApiVersion represents the range of versions of an API supported by a
+// server.
+type ApiVersion struct {
+	ApiKey     ApiKey
+	MinVersion int16
+	MaxVersion int16
+}
+
+// ApiVersionsResponse is an extension of the Message interface implemented by
+// the response type of the ApiVersions API. Servers use it to advertise the
+// versions of the APIs that they support.
+type ApiVersionsResponse interface {
+	Message
+	// Sets the error code and list of API versions of the response.
+	SetApiVersions(errorCode int16, apiVersions []ApiVersion)
+}
+
+// Server serves kafka requests received on network connections.
+//
+// The server reads requests from connections and passes them to its handler,
+// requests received on the same connection are served one after the other
+// and their responses are written in the same order, as kafka brokers do.
+//
+// ApiVersions requests are served by the server itself, it advertises the
+// versions of APIs configured in its ApiVersions field. Clients sending
+// requests for other APIs or versions have their connection closed.
+//
+// The packages of the APIs served by the program must be imported so their
+// message types are registered, including the protocol/apiversions package.
+type Server struct {
+	// Handler serving the requests received by the server.
+	Handler Handler
+
+	// List of API versions advertised to clients in ApiVersions responses,
+	// the server only accepts requests within these versions.
+	//
+	// Default: all the versions of the APIs registered in the package, or
+	// only of the APIs registered in the mux when Handler is a *ServeMux
+	ApiVersions []ApiVersion
+
+	// Optional function called when a client connection is closed because
+	// of an error, other than the client closing the connection.
+	ErrorLog func(conn net.Conn, err error)
+
+	mutex     sync.Mutex
+	closed    bool
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]context.CancelFunc
+	join      sync.WaitGroup
+}
+
+// Serve accepts connections on l and serves requests received on them, until
+// the server is closed or l returns an error.
+//
+// Serve always returns a non-nil error, which is ErrServerClosed after Close
+// was called.
+func (s *Server) Serve(l net.Listener) error {
+	if !s.trackListener(l) {
+		return ErrServerClosed
+	}
+	defer s.untrackListener(l)
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if s.isClosed() {
+				return ErrServerClosed
+			}
+			return err
+		}
+
+		go s.ServeConn(conn)
+	}
+}
+
+// ServeConn serves the requests received on conn until the client or the
+// server closes it, or an error occurs. The connection is closed when the
+// method returns.
+func (s *Server) ServeConn(conn net.Conn) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if !s.trackConn(conn, cancel) {
+		conn.Close()
+		return
+	}
+	defer s.untrackConn(conn)
+
+	if err := s.serveConn(ctx, conn); err != nil && s.ErrorLog != nil && !s.isClosed() {
+		s.ErrorLog(conn, err)
+	}
+}
+
+func (s *Server) serveConn(ctx context.Context, conn net.Conn) error {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	versions := s.apiVersions()
+
+	for {
+		if _, err := r.Peek(1); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		apiVersion, correlationID, clientID, msg, err := ReadRequest(r)
+		if err == nil {
+			apiKey := msg.ApiKey()
+			if v, ok := versions[apiKey]; !ok || apiVersion < v.MinVersion || apiVersion > v.MaxVersion {
+				err = &UnsupportedVersionError{ApiKey: apiKey, Version: apiVersion}
+			}
+		}
+		if err != nil {
+			var unsupported *UnsupportedVersionError
+			if errors.As(err, &unsupported) && unsupported.ApiKey == ApiVersions {
+				// Clients start with the latest version of ApiVersions that
+				// they know of, brokers respond in v0 with an error to let
+				// them retry with a version that the broker supports
+				// (KIP-511).
+				if err := s.writeApiVersions(w, 0, correlationID, unsupportedVersion, versions); err != nil {
+					return err
+				}
+				continue
+			}
+			return err
+		}
+
+		apiKey := msg.ApiKey()
+
+		if apiKey == ApiVersions {
+			if err := s.writeApiVersions(w, apiVersion, correlationID, 0, versions); err != nil {
+				return err
+			}
+			continue
+		}
+
+		res, err := s.Handler.ServeKafka(ctx, &ServerRequest{
+			Version:       apiVersion,
+			CorrelationID: correlationID,
+			ClientID:      clientID,
+			LocalAddr:     conn.LocalAddr(),
+			RemoteAddr:    conn.RemoteAddr(),
+			Message:       msg,
+		})
+		if err != nil {
+			return err
+		}
+
+		if !hasResponse(msg) {
+			continue
+		}
+
+		if res == nil {
+			return Errorf("no response to %s request", apiKey)
+		}
+
+		if err := WriteResponse(w, apiVersion, correlationID, res); err != nil {
+			return err
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+}
+
+// unsupportedVersion is the UNSUPPORTED_VERSION error code of the kafka
+// protocol.
+const unsupportedVersion = 35
+
+func (s *Server) writeApiVersions(w *bufio.Writer, apiVersion int16, correlationID int32, errorCode int16, versions map[ApiKey]ApiVersion) error {
+	t := ApiVersions.apiType()
+	if len(t.responses) == 0 {
+		return Errorf("%s API is not registered", ApiVersions)
+	}
+
+	msg := t.responses[0].new()
+	res, ok := msg.(ApiVersionsResponse)
+	if !ok {
+		return Errorf("%T does not implement ApiVersionsResponse", msg)
+	}
+
+	apiVersions := make([]ApiVersion, 0, len(versions))
+	for _, v := range versions {
+		apiVersions = append(apiVersions, v)
+	}
+	sort.Slice(apiVersions, func(i, j int) bool {
+		return apiVersions[i].ApiKey < apiVersions[j].ApiKey
+	})
+	res.SetApiVersions(errorCode, apiVersions)
+
+	if err := WriteResponse(w, apiVersion, correlationID, res); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+func (s *Server) apiVersions() map[ApiKey]ApiVersion {
+	versions := make(map[ApiKey]ApiVersion)
+
+	if s.ApiVersions != nil {
+		for _, v := range s.ApiVersions {
+			versions[v.ApiKey] = v
+		}
+	} else {
+		mux, _ := s.Handler.(*ServeMux)
+
+		for i := range apiTypes {
+			if mux != nil && !mux.handles(ApiKey(i)) {
+				continue
+			}
+			if t := &apiTypes[i]; len(t.requests) != 0 {
+				versions[ApiKey(i)] = ApiVersion{
+					ApiKey:     ApiKey(i),
+					MinVersion: t.minVersion(),
+					MaxVersion: t.maxVersion(),
+				}
+			}
+		}
+	}
+
+	// The server must always accept ApiVersions requests, or clients would
+	// be unable to negotiate versions.
+	if _, ok := versions[ApiVersions]; !ok {
+		versions[ApiVersions] = ApiVersion{
+			ApiKey:     ApiVersions,
+			MinVersion: ApiVersions.MinVersion(),
+			MaxVersion: ApiVersions.MaxVersion(),
+		}
+	}
+
+	return versions
+}
+
+// Close closes the listeners and connections of the server, and cancels the
+// contexts passed to the handler. The method waits for connections to be
+// closed before returning.
+func (s *Server) Close() error {
+	s.mutex.Lock()
+	s.closed = true
+
+	for l := range s.listeners {
+		l.Close()
+	}
+	for conn, cancel := range s.conns {
+		conn.Close()
+		cancel()
+	}
+	s.mutex.Unlock()
+
+	s.join.Wait()
+	return nil
+}
+
+func (s *Server) isClosed() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.closed
+}
+
+func (s *Server) trackListener(l net.Listener) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return false
+	}
+	if s.listeners == nil {
+		s.listeners = make(map[net.Listener]struct{})
+	}
+	s.listeners[l] = struct{}{}
+	return true
+}
+
+func (s *Server) untrackListener(l net.Listener) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.listeners, l)
+}
+
+func (s *Server) trackConn(conn net.Conn, cancel context.CancelFunc) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return false
+	}
+	if s.conns == nil {
+		s.conns = make(map[net.Conn]context.CancelFunc)
+	}
+	s.conns[conn] = cancel
+	s.join.Add(1)
+	return true
+}
+
+func (s *Server) untrackConn(conn net.Conn) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.conns, conn)
+	s.join.Done()
+}
@@ -0,0 +1,212 @@
+package protocol_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/PerchSecurity/kafka-go/protocol"
+	"github.com/PerchSecurity/kafka-go/protocol/apiversions"
+	"github.com/PerchSecurity/kafka-go/protocol/metadata"
+	"github.com/PerchSecurity/kafka-go/protocol/produce"
+)
+
+func startServer(t *testing.T, s *protocol.Server) net.Conn {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	served := make(chan error, 1)
+	go func() { served <- s.Serve(l) }()
+
+	t.Cleanup(func() {
+		s.Close()
+		if err := <-served; !errors.Is(err, protocol.ErrServerClosed) {
+			t.Errorf("expected the server to be closed, got %v", err)
+		}
+	})
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestServer(t *testing.T) {
+	produced := make(chan *produce.Request, 1)
+
+	conn := startServer(t, &protocol.Server{
+		ApiVersions: []protocol.ApiVersion{
+			{ApiKey: protocol.Metadata, MinVersion: 1, MaxVersion: 4},
+			{ApiKey: protocol.Produce, MinVersion: 3, MaxVersion: 3},
+		},
+		Handler: protocol.HandlerFunc(func(ctx context.Context, req *protocol.ServerRequest) (protocol.Message, error) {
+			switch msg := req.Message.(type) {
+			case *metadata.Request:
+				return &metadata.Response{
+					Brokers: []metadata.ResponseBroker{{NodeID: 1, Host: "localhost", Port: 9092}},
+					Topics:  []metadata.ResponseTopic{{Name: req.ClientID}},
+				}, nil
+			case *produce.Request:
+				produced <- msg
+				return nil, nil
+			default:
+				return nil, errors.New("unexpected request")
+			}
+		}),
+	})
+
+	r := bufio.NewReader(conn)
+
+	if err := protocol.WriteRequest(conn, 1, 1, "test", &apiversions.Request{}); err != nil {
+		t.Fatal(err)
+	}
+	_, msg, err := protocol.ReadResponse(r, protocol.ApiVersions, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions := msg.(*apiversions.Response).ApiKeys
+	if len(versions) != 3 {
+		t.Fatalf("expected 3 api versions, got %+v", versions)
+	}
+	if v := versions[1]; v.ApiKey != int16(protocol.Metadata) || v.MinVersion != 1 || v.MaxVersion != 4 {
+		t.Errorf("unexpected metadata versions: %+v", v)
+	}
+
+	// Produce requests with no acknowledgements have no responses, the next
+	// response read from the connection must be the one of the metadata
+	// request.
+	if err := protocol.WriteRequest(conn, 3, 2, "test", &produce.Request{Acks: 0}); err != nil {
+		t.Fatal(err)
+	}
+	if err := protocol.WriteRequest(conn, 4, 3, "test", &metadata.Request{}); err != nil {
+		t.Fatal(err)
+	}
+	id, msg, err := protocol.ReadResponse(r, protocol.Metadata, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 3 {
+		t.Errorf("expected correlation id 3, got %d", id)
+	}
+	if res := msg.(*metadata.Response); len(res.Topics) != 1 || res.Topics[0].Name != "test" {
+		t.Errorf("unexpected metadata response: %+v", res)
+	}
+	if req := <-produced; req.Acks != 0 {
+		t.Errorf("unexpected produce request: %+v", req)
+	}
+
+	// Requests for versions which were not advertised close the connection.
+	if err := protocol.WriteRequest(conn, 5, 4, "test", &metadata.Request{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := protocol.ReadResponse(r, protocol.Metadata, 5); err == nil {
+		t.Error("expected the connection to be closed")
+	}
+}
+
+func TestServerUnsupportedApiVersions(t *testing.T) {
+	conn := startServer(t, &protocol.Server{
+		Handler: protocol.HandlerFunc(func(context.Context, *protocol.ServerRequest) (protocol.Message, error) {
+			return nil, errors.New("unexpected request")
+		}),
+	})
+
+	// ApiVersions v3 request, which is not supported by the package, with a
+	// client ID and tagged fields in its body.
+	req := []byte{
+		0, 0, 0, 0, // size
+		0, 18, // api key
+		0, 3, // api version
+		0, 0, 0, 42, // correlation id
+		0, 4, 't', 'e', 's', 't', // client id
+		0,                // header tagged fields
+		5, 'k', 'a', 'f', // client software name
+		3, 'g', 'o', // client software version
+		0, // tagged fields
+	}
+	binary.BigEndian.PutUint32(req, uint32(len(req)-4))
+
+	if _, err := conn.Write(req); err != nil {
+		t.Fatal(err)
+	}
+
+	r := bufio.NewReader(conn)
+	id, msg, err := protocol.ReadResponse(r, protocol.ApiVersions, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 42 {
+		t.Errorf("expected correlation id 42, got %d", id)
+	}
+	res := msg.(*apiversions.Response)
+	if res.ErrorCode != 35 {
+		t.Errorf("expected an unsupported version error, got %d", res.ErrorCode)
+	}
+	if len(res.ApiKeys) == 0 {
+		t.Error("expected the response to list api versions")
+	}
+
+	// The connection remains usable to retry with a supported version.
+	if err := protocol.WriteRequest(conn, 0, 43, "test", &apiversions.Request{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, msg, err := protocol.ReadResponse(r, protocol.ApiVersions, 0); err != nil {
+		t.Fatal(err)
+	} else if res := msg.(*apiversions.Response); res.ErrorCode != 0 {
+		t.Errorf("unexpected error code: %d", res.ErrorCode)
+	}
+}
+
+func TestServeMux(t *testing.T) {
+	mux := new(protocol.ServeMux)
+	mux.HandleFunc(protocol.Metadata, func(ctx context.Context, req *protocol.ServerRequest) (protocol.Message, error) {
+		return &metadata.Response{
+			Topics: []metadata.ResponseTopic{{Name: req.ClientID}},
+		}, nil
+	})
+
+	conn := startServer(t, &protocol.Server{Handler: mux})
+	r := bufio.NewReader(conn)
+
+	// Only the APIs registered in the mux are advertised.
+	if err := protocol.WriteRequest(conn, 1, 1, "test", &apiversions.Request{}); err != nil {
+		t.Fatal(err)
+	}
+	_, msg, err := protocol.ReadResponse(r, protocol.ApiVersions, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions := msg.(*apiversions.Response).ApiKeys
+	if len(versions) != 2 || versions[0].ApiKey != int16(protocol.Metadata) || versions[1].ApiKey != int16(protocol.ApiVersions) {
+		t.Fatalf("unexpected api versions: %+v", versions)
+	}
+
+	if err := protocol.WriteRequest(conn, 4, 2, "test", &metadata.Request{}); err != nil {
+		t.Fatal(err)
+	}
+	_, msg, err = protocol.ReadResponse(r, protocol.Metadata, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := msg.(*metadata.Response); len(res.Topics) != 1 || res.Topics[0].Name != "test" {
+		t.Errorf("unexpected metadata response: %+v", res)
+	}
+
+	_, err = mux.ServeKafka(context.Background(), &protocol.ServerRequest{
+		Version: 3,
+		Message: &produce.Request{},
+	})
+	var unsupported *protocol.UnsupportedVersionError
+	if !errors.As(err, &unsupported) || unsupported.ApiKey != protocol.Produce || unsupported.Version != 3 {
+		t.Errorf("expected an unsupported version error for produce requests, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package protocol
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// clientSideThrottlingVersions maps API keys to the first version of their
+// responses which expects clients to throttle themselves (KIP-219).
+//
+// With earlier versions, brokers delay sending throttled responses instead.
+// APIs which are not in this table were introduced after KIP-219 and always
+// use client-side throttling.
+var clientSideThrottlingVersions = map[ApiKey]int16{
+	Produce:                 6,
+	Fetch:                   8,
+	ListOffsets:             3,
+	Metadata:                6,
+	OffsetCommit:            4,
+	OffsetFetch:             4,
+	FindCoordinator:         2,
+	JoinGroup:               3,
+	Heartbeat:               2,
+	LeaveGroup:              2,
+	SyncGroup:               2,
+	DescribeGroups:          2,
+	ListGroups:              2,
+	SaslHandshake:           -1,
+	ApiVersions:             2,
+	CreateTopics:            3,
+	DeleteTopics:            2,
+	DeleteRecords:           1,
+	InitProducerId:          1,
+	OffsetForLeaderEpoch:    2,
+	AddPartitionsToTxn:      1,
+	AddOffsetsToTxn:         1,
+	EndTxn:                  1,
+	TxnOffsetCommit:         1,
+	DescribeAcls:            1,
+	CreateAcls:              1,
+	DeleteAcls:              1,
+	DescribeConfigs:         2,
+	AlterConfigs:            1,
+	AlterReplicaLogDirs:     1,
+	DescribeLogDirs:         1,
+	SaslAuthenticate:        -1,
+	CreatePartitions:        1,
+	CreateDelegationToken:   1,
+	RenewDelegationToken:    1,
+	ExpireDelegationToken:   1,
+	DescribeDelegationToken: 1,
+	DeleteGroups:            1,
+}
+
+// ClientSideThrottling returns true if the given version of responses to k
+// expect the client to stop sending requests to the broker for the throttle
+// time that they carry, instead of having been delayed by the broker.
+func (k ApiKey) ClientSideThrottling(version int16) bool {
+	minVersion, ok := clientSideThrottlingVersions[k]
+	if !ok {
+		return true
+	}
+	return minVersion >= 0 && version >= minVersion
+}
+
+// throttleTimeFields caches the index of the throttle time field of response
+// types, or -1 if they have none.
+var throttleTimeFields sync.Map // map[reflect.Type]int
+
+// ThrottleTime returns the throttle time carried by msg, or zero if the message
+// has no such field.
+func ThrottleTime(msg Message) time.Duration {
+	v := reflect.ValueOf(msg)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return 0
+	}
+	v = v.Elem()
+
+	i, ok := throttleTimeFields.Load(v.Type())
+	if !ok {
+		i = throttleTimeField(v.Type())
+		throttleTimeFields.Store(v.Type(), i)
+	}
+	if i.(int) < 0 {
+		return 0
+	}
+	return time.Duration(v.Field(i.(int)).Int()) * time.Millisecond
+}
+
+func throttleTimeField(t reflect.Type) int {
+	for i := 0; i < t.NumField(); i++ {
+		switch f := t.Field(i); f.Name {
+		case "ThrottleTimeMs", "ThrottleTimeMS", "ThrottleTime":
+			if f.Type.Kind() == reflect.Int32 {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,60 @@
+package protocol
+
+import (
+	"testing"
+	"time"
+)
+
+type testThrottledResponse struct {
+	ThrottleTimeMs int32 `kafka:"min=v0,max=v1"`
+	ErrorCode      int16 `kafka:"min=v0,max=v1"`
+}
+
+func (r *testThrottledResponse) ApiKey() ApiKey { return Produce }
+
+type testUnthrottledResponse struct {
+	ErrorCode int16 `kafka:"min=v0,max=v1"`
+}
+
+func (r *testUnthrottledResponse) ApiKey() ApiKey { return SaslHandshake }
+
+func TestThrottleTime(t *testing.T) {
+	tests := []struct {
+		msg      Message
+		throttle time.Duration
+	}{
+		{msg: &testThrottledResponse{ThrottleTimeMs: 250}, throttle: 250 * time.Millisecond},
+		{msg: &testThrottledResponse{}, throttle: 0},
+		{msg: &testUnthrottledResponse{}, throttle: 0},
+		{msg: (*testThrottledResponse)(nil), throttle: 0},
+	}
+
+	for _, test := range tests {
+		if throttle := ThrottleTime(test.msg); throttle != test.throttle {
+			t.Errorf("%T: expected a throttle time of %s, got %s", test.msg, test.throttle, throttle)
+		}
+	}
+}
+
+func TestClientSideThrottling(t *testing.T) {
+	tests := []struct {
+		apiKey  ApiKey
+		version int16
+		client  bool
+	}{
+		{apiKey: Produce, version: 5, client: false},
+		{apiKey: Produce, version: 6, client: true},
+		{apiKey: Fetch, version: 5, client: false},
+		{apiKey: Fetch, version: 10, client: true},
+		{apiKey: OffsetForLeaderEpoch, version: 1, client: false},
+		{apiKey: OffsetForLeaderEpoch, version: 2, client: true},
+		{apiKey: SaslAuthenticate, version: 2, client: false},
+		{apiKey: DescribeClientQuotas, version: 0, client: true},
+	}
+
+	for _, test := range tests {
+		if client := test.apiKey.ClientSideThrottling(test.version); client != test.client {
+			t.Errorf("%s v%d: expected client-side throttling to be %t, got %t", test.apiKey, test.version, test.client, client)
+		}
+	}
+}
@@ -75,3 +75,15 @@ type ResponsePartition struct {
 }
 
 func (r *Response) ApiKey() protocol.ApiKey { return protocol.TxnOffsetCommit }
+
+func (r *Response) PartitionErrors(fn func(topic string, partition int32, errorCode int16)) {
+	for _, t := range r.Topics {
+		for _, p := range t.Partitions {
+			if p.ErrorCode != 0 {
+				fn(t.Name, p.Partition, p.ErrorCode)
+			}
+		}
+	}
+}
+
+var _ protocol.PartitionErrorer = (*Response)(nil)
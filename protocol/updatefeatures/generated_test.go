@@ -0,0 +1,44 @@
+// Code generated by protocol/internal/gen from UpdateFeaturesRequest.json and UpdateFeaturesResponse.json. DO NOT EDIT.
+
+package updatefeatures_test
+
+import (
+	"testing"
+
+	"github.com/PerchSecurity/kafka-go/protocol/prototest"
+	"github.com/PerchSecurity/kafka-go/protocol/updatefeatures"
+)
+
+func TestRequestRoundTrip(t *testing.T) {
+	prototest.TestRequest(t, 0, &updatefeatures.Request{
+		TimeoutMs: 1,
+		FeatureUpdates: []updatefeatures.RequestFeatureUpdateKey{{
+			Feature:         "feature-3",
+			MaxVersionLevel: 4,
+			AllowDowngrade:  true,
+		}},
+	})
+
+	prototest.TestRequest(t, 1, &updatefeatures.Request{
+		TimeoutMs: 1,
+		FeatureUpdates: []updatefeatures.RequestFeatureUpdateKey{{
+			Feature:         "feature-3",
+			MaxVersionLevel: 4,
+			UpgradeType:     5,
+		}},
+		ValidateOnly: true,
+	})
+}
+
+func TestResponseRoundTrip(t *testing.T) {
+	prototest.TestResponse(t, 0, &updatefeatures.Response{
+		ThrottleTimeMs: 1,
+		ErrorCode:      2,
+		ErrorMessage:   "errormessage-3",
+		Results: []updatefeatures.ResponseUpdatableFeatureResult{{
+			Feature:      "feature-5",
+			ErrorCode:    6,
+			ErrorMessage: "errormessage-7",
+		}},
+	})
+}
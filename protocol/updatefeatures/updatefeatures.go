@@ -0,0 +1,13 @@
+package updatefeatures
+
+import "github.com/PerchSecurity/kafka-go/protocol"
+
+//go:generate go run ../internal/gen -api UpdateFeatures
+
+func (r *Request) Broker(cluster protocol.Cluster) (protocol.Broker, error) {
+	return cluster.Brokers[cluster.Controller], nil
+}
+
+var (
+	_ protocol.BrokerMessage = (*Request)(nil)
+)
@@ -0,0 +1,75 @@
+package protocol
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+)
+
+// UUID is the representation of the uuid type of the kafka protocol, used for
+// example to identify topics (KIP-516).
+//
+// The zero value is the null UUID, which kafka uses to indicate that no UUID
+// was set.
+type UUID [16]byte
+
+// NewUUID returns a new randomly generated UUID.
+func NewUUID() UUID {
+	var u UUID
+	if _, err := io.ReadFull(rand.Reader, u[:]); err != nil {
+		panic(err)
+	}
+	// Set the version (4) and variant (RFC 4122) bits, like the UUIDs
+	// generated by kafka brokers.
+	u[6] = (u[6] & 0x0f) | 0x40
+	u[8] = (u[8] & 0x3f) | 0x80
+	return u
+}
+
+// ParseUUID parses the string representation of a UUID, as returned by the
+// String method.
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return u, fmt.Errorf("malformed uuid %q: %w", s, err)
+	}
+	if len(b) != len(u) {
+		return u, fmt.Errorf("malformed uuid %q: expected %d bytes but got %d", s, len(u), len(b))
+	}
+	copy(u[:], b)
+	return u, nil
+}
+
+// IsZero returns true if u is the null UUID.
+func (u UUID) IsZero() bool { return u == UUID{} }
+
+// String returns the representation of u in URL-safe base64 without padding,
+// which is the format used by kafka tools (e.g. kafka-topics.sh).
+func (u UUID) String() string { return base64.RawURLEncoding.EncodeToString(u[:]) }
+
+// MarshalText satisfies the encoding.TextMarshaler interface.
+func (u UUID) MarshalText() ([]byte, error) { return []byte(u.String()), nil }
+
+// UnmarshalText satisfies the encoding.TextUnmarshaler interface.
+func (u *UUID) UnmarshalText(b []byte) error {
+	v, err := ParseUUID(string(b))
+	if err != nil {
+		return err
+	}
+	*u = v
+	return nil
+}
+
+// ReadFrom reads the wire representation of a UUID from r.
+func (u *UUID) ReadFrom(r io.Reader) (int64, error) {
+	n, err := io.ReadFull(r, u[:])
+	return int64(n), dontExpectEOF(err)
+}
+
+// WriteTo writes the wire representation of u to w.
+func (u *UUID) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(u[:])
+	return int64(n), err
+}
@@ -0,0 +1,67 @@
+package protocol
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestUUID(t *testing.T) {
+	u := NewUUID()
+	if u.IsZero() {
+		t.Fatal("expected a non-zero uuid")
+	}
+	if u == NewUUID() {
+		t.Error("expected uuids to be unique")
+	}
+
+	s := u.String()
+	if len(s) != 22 {
+		t.Errorf("expected a 22 characters string, got %q", s)
+	}
+	v, err := ParseUUID(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != u {
+		t.Errorf("uuid mismatch: %s != %s", v, u)
+	}
+
+	// The representation of the uuid of kafka's __cluster_metadata topic.
+	if s := (UUID{15: 1}).String(); s != "AAAAAAAAAAAAAAAAAAAAAQ" {
+		t.Errorf("unexpected representation: %q", s)
+	}
+
+	for _, s := range []string{"", "AAAA", "not a uuid!!!!!!!!!!!!"} {
+		if _, err := ParseUUID(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+func TestUUIDEncoding(t *testing.T) {
+	type message struct {
+		ID  UUID   `kafka:"min=v0,max=v1"`
+		IDs []UUID `kafka:"min=v1,max=v1"`
+	}
+
+	m := message{ID: NewUUID(), IDs: []UUID{NewUUID(), {}}}
+
+	b, err := Marshal(1, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != 16+4+2*16 {
+		t.Fatalf("unexpected size: %d", len(b))
+	}
+	if !bytes.Equal(b[:16], m.ID[:]) {
+		t.Errorf("unexpected encoding: % x", b[:16])
+	}
+
+	var v message
+	if err := Unmarshal(b, 1, &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.ID != m.ID || len(v.IDs) != 2 || v.IDs[0] != m.IDs[0] || !v.IDs[1].IsZero() {
+		t.Errorf("message mismatch: %+v != %+v", v, m)
+	}
+}
@@ -0,0 +1,122 @@
+package writetxnmarkers
+
+import "github.com/PerchSecurity/kafka-go/protocol"
+
+func init() {
+	protocol.Register(&Request{}, &Response{})
+}
+
+// Detailed API definition: https://kafka.apache.org/protocol#The_Messages_WriteTxnMarkers
+type Request struct {
+	// We need at least one tagged field to indicate that v1+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v1,max=v1,tag"`
+
+	Markers []RequestMarker `kafka:"min=v0,max=v1"`
+}
+
+type RequestMarker struct {
+	// We need at least one tagged field to indicate that v1+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v1,max=v1,tag"`
+
+	ProducerID        int64          `kafka:"min=v0,max=v1"`
+	ProducerEpoch     int16          `kafka:"min=v0,max=v1"`
+	TransactionResult bool           `kafka:"min=v0,max=v1"`
+	Topics            []RequestTopic `kafka:"min=v0,max=v1"`
+	CoordinatorEpoch  int32          `kafka:"min=v0,max=v1"`
+}
+
+type RequestTopic struct {
+	// We need at least one tagged field to indicate that v1+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v1,max=v1,tag"`
+
+	Name             string  `kafka:"min=v0,max=v1"`
+	PartitionIndexes []int32 `kafka:"min=v0,max=v1"`
+}
+
+func (r *Request) ApiKey() protocol.ApiKey { return protocol.WriteTxnMarkers }
+
+func (r *Request) Broker(cluster protocol.Cluster) (protocol.Broker, error) {
+	// Transaction markers must be written by the partition leaders, the
+	// request is expected to only contain partitions of the same leader.
+	for _, m := range r.Markers {
+		for _, t := range m.Topics {
+			topic, ok := cluster.Topics[t.Name]
+			if !ok {
+				return protocol.Broker{}, protocol.NewErrNoTopic(t.Name)
+			}
+
+			for _, p := range t.PartitionIndexes {
+				partition, ok := topic.Partitions[p]
+				if !ok {
+					return protocol.Broker{}, protocol.NewErrNoPartition(t.Name, p)
+				}
+
+				broker, ok := cluster.Brokers[partition.Leader]
+				if !ok {
+					return protocol.Broker{}, protocol.NewErrNoLeader(t.Name, p)
+				}
+
+				return broker, nil
+			}
+		}
+	}
+
+	return protocol.Broker{ID: -1}, nil
+}
+
+type Response struct {
+	// We need at least one tagged field to indicate that v1+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v1,max=v1,tag"`
+
+	Markers []ResponseMarker `kafka:"min=v0,max=v1"`
+}
+
+type ResponseMarker struct {
+	// We need at least one tagged field to indicate that v1+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v1,max=v1,tag"`
+
+	ProducerID int64           `kafka:"min=v0,max=v1"`
+	Topics     []ResponseTopic `kafka:"min=v0,max=v1"`
+}
+
+type ResponseTopic struct {
+	// We need at least one tagged field to indicate that v1+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v1,max=v1,tag"`
+
+	Name       string              `kafka:"min=v0,max=v1"`
+	Partitions []ResponsePartition `kafka:"min=v0,max=v1"`
+}
+
+type ResponsePartition struct {
+	// We need at least one tagged field to indicate that v1+ uses "flexible"
+	// messages.
+	_ struct{} `kafka:"min=v1,max=v1,tag"`
+
+	PartitionIndex int32 `kafka:"min=v0,max=v1"`
+	ErrorCode      int16 `kafka:"min=v0,max=v1"`
+}
+
+func (r *Response) ApiKey() protocol.ApiKey { return protocol.WriteTxnMarkers }
+
+func (r *Response) PartitionErrors(fn func(topic string, partition int32, errorCode int16)) {
+	for _, m := range r.Markers {
+		for _, t := range m.Topics {
+			for _, p := range t.Partitions {
+				if p.ErrorCode != 0 {
+					fn(t.Name, p.PartitionIndex, p.ErrorCode)
+				}
+			}
+		}
+	}
+}
+
+var (
+	_ protocol.BrokerMessage    = (*Request)(nil)
+	_ protocol.PartitionErrorer = (*Response)(nil)
+)
@@ -0,0 +1,51 @@
+package writetxnmarkers_test
+
+import (
+	"testing"
+
+	"github.com/PerchSecurity/kafka-go/protocol/prototest"
+	"github.com/PerchSecurity/kafka-go/protocol/writetxnmarkers"
+)
+
+const (
+	v0 = 0
+	v1 = 1
+)
+
+func TestWriteTxnMarkersRequest(t *testing.T) {
+	for _, version := range []int16{v0, v1} {
+		prototest.TestRequest(t, version, &writetxnmarkers.Request{
+			Markers: []writetxnmarkers.RequestMarker{
+				{
+					ProducerID:        1,
+					ProducerEpoch:     2,
+					TransactionResult: false,
+					Topics: []writetxnmarkers.RequestTopic{
+						{Name: "foo", PartitionIndexes: []int32{0}},
+					},
+					CoordinatorEpoch: 3,
+				},
+			},
+		})
+	}
+}
+
+func TestWriteTxnMarkersResponse(t *testing.T) {
+	for _, version := range []int16{v0, v1} {
+		prototest.TestResponse(t, version, &writetxnmarkers.Response{
+			Markers: []writetxnmarkers.ResponseMarker{
+				{
+					ProducerID: 1,
+					Topics: []writetxnmarkers.ResponseTopic{
+						{
+							Name: "foo",
+							Partitions: []writetxnmarkers.ResponsePartition{
+								{PartitionIndex: 0, ErrorCode: 0},
+							},
+						},
+					},
+				},
+			},
+		})
+	}
+}
@@ -137,9 +137,7 @@ func (r *Reader) subscribe(allAssignments map[string][]PartitionAssignment) {
 	r.start(offsets)
 	r.mutex.Unlock()
 
-	r.withLogger(func(l Logger) {
-		l.Printf("subscribed to topics and partitions: %+v", offsets)
-	})
+	r.log().infof("subscribed to topics and partitions: %+v", offsets)
 }
 
 // commitOffsetsWithRetry attempts to commit the specified offsets and retries
@@ -238,7 +236,7 @@ func (r *Reader) commitLoopInterval(ctx context.Context, gen *Generation) {
 
 	commit := func() {
 		if err := r.commitOffsetsWithRetry(gen, offsets, defaultCommitRetries); err != nil {
-			r.withErrorLogger(func(l Logger) { l.Printf("%v", err) })
+			r.log().withError(err).warnf("%v", err)
 		} else {
 			offsets.reset()
 		}
@@ -270,12 +268,8 @@ func (r *Reader) commitLoopInterval(ctx context.Context, gen *Generation) {
 
 // commitLoop processes commits off the commit chan.
 func (r *Reader) commitLoop(ctx context.Context, gen *Generation) {
-	r.withLogger(func(l Logger) {
-		l.Printf("started commit for group %s\n", r.config.GroupID)
-	})
-	defer r.withLogger(func(l Logger) {
-		l.Printf("stopped commit for group %s\n", r.config.GroupID)
-	})
+	r.log().debugf("started commit for group %s\n", r.config.GroupID)
+	defer r.log().debugf("stopped commit for group %s\n", r.config.GroupID)
 
 	if r.useSyncCommits() {
 		r.commitLoopImmediate(ctx, gen)
@@ -292,9 +286,7 @@ func (r *Reader) run(cg *ConsumerGroup) {
 	defer close(r.done)
 	defer cg.Close()
 
-	r.withLogger(func(l Logger) {
-		l.Printf("entering loop for consumer group, %v\n", r.config.GroupID)
-	})
+	r.log().infof("entering loop for consumer group, %v\n", r.config.GroupID)
 
 	for {
 		// Limit the number of attempts at waiting for the next
@@ -310,9 +302,7 @@ func (r *Reader) run(cg *ConsumerGroup) {
 				return
 			}
 			r.stats.errors.observe(1)
-			r.withErrorLogger(func(l Logger) {
-				l.Printf("%v", err)
-			})
+			r.log().withError(err).warnf("%v", err)
 			// Continue with next attempt...
 		}
 		if err != nil {
@@ -507,6 +497,11 @@ type ReaderConfig struct {
 	// back to using Logger instead.
 	ErrorLogger Logger
 
+	// If not nil, specifies a logger used to report events with attributes
+	// (topic, partition, offset, group, etc...). When set, Logger and
+	// ErrorLogger are not used.
+	StructuredLogger StructuredLogger
+
 	// IsolationLevel controls the visibility of transactional records.
 	// ReadUncommitted makes all records visible. With ReadCommitted only
 	// non-transactional and committed records are visible.
@@ -517,11 +512,36 @@ type ReaderConfig struct {
 	// The default is to try 3 times.
 	MaxAttempts int
 
+	// If not nil, specifies a recorder used to report metrics about the fetch
+	// requests sent by the reader, labeled by topic and partition. When
+	// GroupID is set, the recorder also receives the metrics of the consumer
+	// group.
+	Metrics MetricsRecorder
+
+	// If not nil, specifies a tracer which is notified of the messages
+	// returned by the reader.
+	Tracer Tracer
+
 	// OffsetOutOfRangeError indicates that the reader should return an error in
 	// the event of an OffsetOutOfRange error, rather than retrying indefinitely.
 	// This flag is being added to retain backwards-compatibility, so it will be
 	// removed in a future version of kafka-go.
 	OffsetOutOfRangeError bool
+
+	// PooledBuffers enables decoding the fetched messages into pooled buffers
+	// that the keys and values of the messages returned by ReadMessage and
+	// FetchMessage alias, instead of allocating them for each message.
+	//
+	// The program must call Release on each message once it is done with its
+	// key and value, and must not retain them after that. Messages that are
+	// not released are garbage collected as usual, but their buffers are not
+	// reused.
+	//
+	// The option only applies to the Reader and to Conn batches. Records
+	// returned by Client.Fetch are always decoded into the reference counted
+	// pages of the protocol package, which are recycled when the record set
+	// is closed.
+	PooledBuffers bool
 }
 
 // Validate method validates ReaderConfig properties.
@@ -586,6 +606,12 @@ type ReaderStats struct {
 	FetchSize  SummaryStats  `metric:"kafka.reader.fetch.size"`
 	FetchBytes SummaryStats  `metric:"kafka.reader.fetch.bytes"`
 
+	// Throttles counts the fetch responses throttled by the brokers because the
+	// reader exceeded a quota, and ThrottleTime reports the throttle times of
+	// these responses.
+	Throttles    int64         `metric:"kafka.reader.throttle.count" type:"counter"`
+	ThrottleTime DurationStats `metric:"kafka.reader.throttle.seconds"`
+
 	Offset        int64         `metric:"kafka.reader.offset"          type:"gauge"`
 	Lag           int64         `metric:"kafka.reader.lag"             type:"gauge"`
 	MinBytes      int64         `metric:"kafka.reader.fetch_bytes.min" type:"gauge"`
@@ -607,21 +633,23 @@ type ReaderStats struct {
 
 // readerStats is a struct that contains statistics on a reader.
 type readerStats struct {
-	dials      counter
-	fetches    counter
-	messages   counter
-	bytes      counter
-	rebalances counter
-	timeouts   counter
-	errors     counter
-	dialTime   summary
-	readTime   summary
-	waitTime   summary
-	fetchSize  summary
-	fetchBytes summary
-	offset     gauge
-	lag        gauge
-	partition  string
+	dials        counter
+	fetches      counter
+	messages     counter
+	bytes        counter
+	rebalances   counter
+	timeouts     counter
+	errors       counter
+	dialTime     summary
+	readTime     summary
+	waitTime     summary
+	fetchSize    summary
+	fetchBytes   summary
+	throttles    counter
+	throttleTime summary
+	offset       gauge
+	lag          gauge
+	partition    string
 }
 
 // NewReader creates and returns a new Reader configured with config.
@@ -707,11 +735,12 @@ func NewReader(config ReaderConfig) *Reader {
 		offset:  FirstOffset,
 		stctx:   stctx,
 		stats: &readerStats{
-			dialTime:   makeSummary(),
-			readTime:   makeSummary(),
-			waitTime:   makeSummary(),
-			fetchSize:  makeSummary(),
-			fetchBytes: makeSummary(),
+			dialTime:     makeSummary(),
+			readTime:     makeSummary(),
+			waitTime:     makeSummary(),
+			fetchSize:    makeSummary(),
+			fetchBytes:   makeSummary(),
+			throttleTime: makeSummary(),
 			// Generate the string representation of the partition number only
 			// once when the reader is created.
 			partition: strconv.Itoa(readerStatsPartition),
@@ -737,6 +766,8 @@ func NewReader(config ReaderConfig) *Reader {
 			StartOffset:            r.config.StartOffset,
 			Logger:                 r.config.Logger,
 			ErrorLogger:            r.config.ErrorLogger,
+			StructuredLogger:       r.config.StructuredLogger,
+			Metrics:                r.config.Metrics,
 		})
 		if err != nil {
 			panic(err)
@@ -857,8 +888,16 @@ func (r *Reader) FetchMessage(ctx context.Context) (Message, error) {
 					m.error = io.ErrUnexpectedEOF
 				}
 
+				if m.error == nil && r.config.Tracer != nil {
+					r.config.Tracer.Consume(ctx, m.message)
+				}
+
 				return m.message, m.error
 			}
+
+			// The message was fetched before the reader was repositioned,
+			// it will never be returned to the program.
+			m.message.Release()
 		}
 	}
 }
@@ -995,9 +1034,7 @@ func (r *Reader) Offset() int64 {
 	r.mutex.Lock()
 	offset := r.offset
 	r.mutex.Unlock()
-	r.withLogger(func(log Logger) {
-		log.Printf("looking up offset of kafka reader for partition %d of %s: %s", r.config.Partition, r.config.Topic, toHumanOffset(offset))
-	})
+	r.log().debugf("looking up offset of kafka reader for partition %d of %s: %s", r.config.Partition, r.config.Topic, toHumanOffset(offset))
 	return offset
 }
 
@@ -1033,10 +1070,8 @@ func (r *Reader) SetOffset(offset int64) error {
 	if r.closed {
 		err = io.ErrClosedPipe
 	} else if offset != r.offset {
-		r.withLogger(func(log Logger) {
-			log.Printf("setting the offset of the kafka reader for partition %d of %s from %s to %s",
-				r.config.Partition, r.config.Topic, toHumanOffset(r.offset), toHumanOffset(offset))
-		})
+		r.log().with(offsetAttr(offset)).infof("setting the offset of the kafka reader for partition %d of %s from %s to %s",
+			r.config.Partition, r.config.Topic, toHumanOffset(r.offset), toHumanOffset(offset))
 		r.offset = offset
 
 		if r.version != 0 {
@@ -1107,6 +1142,8 @@ func (r *Reader) Stats() ReaderStats {
 		WaitTime:      r.stats.waitTime.snapshotDuration(),
 		FetchSize:     r.stats.fetchSize.snapshot(),
 		FetchBytes:    r.stats.fetchBytes.snapshot(),
+		Throttles:     r.stats.throttles.snapshot(),
+		ThrottleTime:  r.stats.throttleTime.snapshotDuration(),
 		Offset:        r.stats.offset.snapshot(),
 		Lag:           r.stats.lag.snapshot(),
 		MinBytes:      int64(r.config.MinBytes),
@@ -1128,18 +1165,26 @@ func (r *Reader) getTopicPartitionOffset() map[topicPartition]int64 {
 	return map[topicPartition]int64{key: r.offset}
 }
 
-func (r *Reader) withLogger(do func(Logger)) {
-	if r.config.Logger != nil {
-		do(r.config.Logger)
+func (r *Reader) log() logger {
+	log := logger{
+		structured:  r.config.StructuredLogger,
+		logger:      r.config.Logger,
+		errorLogger: r.config.ErrorLogger,
 	}
+	if r.useConsumerGroup() {
+		return log.with(groupAttr(r.config.GroupID))
+	}
+	return log.with(topicAttr(r.config.Topic), partitionAttr(r.config.Partition))
 }
 
-func (r *Reader) withErrorLogger(do func(Logger)) {
-	if r.config.ErrorLogger != nil {
-		do(r.config.ErrorLogger)
-	} else {
-		r.withLogger(do)
+// partitionLog returns the logger used by the inner reader of the partition
+// represented by key.
+func (r *Reader) partitionLog(key topicPartition) logger {
+	log := r.log()
+	if r.useConsumerGroup() {
+		log = log.with(topicAttr(key.topic), partitionAttr(int(key.partition)))
 	}
+	return log
 }
 
 func (r *Reader) activateReadLag() {
@@ -1163,9 +1208,7 @@ func (r *Reader) readLag(ctx context.Context) {
 
 		if err != nil {
 			r.stats.errors.observe(1)
-			r.withErrorLogger(func(log Logger) {
-				log.Printf("kafka reader failed to read lag of partition %d of %s: %s", r.config.Partition, r.config.Topic, err)
-			})
+			r.log().withError(err).warnf("kafka reader failed to read lag of partition %d of %s: %s", r.config.Partition, r.config.Topic, err)
 		} else {
 			r.stats.lag.observe(lag)
 		}
@@ -1197,8 +1240,7 @@ func (r *Reader) start(offsetsByPartition map[topicPartition]int64) {
 
 			(&reader{
 				dialer:           r.config.Dialer,
-				logger:           r.config.Logger,
-				errorLogger:      r.config.ErrorLogger,
+				log:              r.partitionLog(key),
 				brokers:          r.config.Brokers,
 				topic:            key.topic,
 				partition:        int(key.partition),
@@ -1211,8 +1253,12 @@ func (r *Reader) start(offsetsByPartition map[topicPartition]int64) {
 				version:          r.version,
 				msgs:             r.msgs,
 				stats:            r.stats,
+				metrics:          metrics{recorder: r.config.Metrics},
+				metricLabels:     []string{key.topic, strconv.Itoa(int(key.partition))},
 				isolationLevel:   r.config.IsolationLevel,
+				pooledBuffers:    r.config.PooledBuffers,
 				maxAttempts:      r.config.MaxAttempts,
+				lastEpoch:        -1,
 
 				// backwards-compatibility flags
 				offsetOutOfRangeError: r.config.OffsetOutOfRangeError,
@@ -1226,8 +1272,7 @@ func (r *Reader) start(offsetsByPartition map[topicPartition]int64) {
 // them using the high level reader API.
 type reader struct {
 	dialer           *Dialer
-	logger           Logger
-	errorLogger      Logger
+	log              logger
 	brokers          []string
 	topic            string
 	partition        int
@@ -1240,9 +1285,17 @@ type reader struct {
 	version          int64
 	msgs             chan<- readerMessage
 	stats            *readerStats
+	metrics          metrics
+	metricLabels     []string // topic and partition
 	isolationLevel   IsolationLevel
+	pooledBuffers    bool
 	maxAttempts      int
 
+	// Leader epoch of the record batch that the last message was consumed
+	// from, or -1 if unknown. This is used to validate the position of the
+	// reader after a change of partition leader.
+	lastEpoch int32
+
 	offsetOutOfRangeError bool
 }
 
@@ -1270,11 +1323,9 @@ func (r *reader) run(ctx context.Context, offset int64) {
 			}
 		}
 
-		r.withLogger(func(log Logger) {
-			log.Printf("initializing kafka reader for partition %d of %s starting at offset %d", r.partition, r.topic, toHumanOffset(offset))
-		})
+		r.log.with(offsetAttr(offset)).infof("initializing kafka reader for partition %d of %s starting at offset %d", r.partition, r.topic, toHumanOffset(offset))
 
-		conn, start, err := r.initialize(ctx, offset)
+		conn, start, truncated, err := r.initialize(ctx, offset)
 		if err != nil {
 			if errors.Is(err, OffsetOutOfRange) {
 				if r.offsetOutOfRangeError {
@@ -1285,9 +1336,7 @@ func (r *reader) run(ctx context.Context, offset int64) {
 				// This would happen if the requested offset is passed the last
 				// offset on the partition leader. In that case we're just going
 				// to retry later hoping that enough data has been produced.
-				r.withErrorLogger(func(log Logger) {
-					log.Printf("error initializing the kafka reader for partition %d of %s: %s", r.partition, r.topic, err)
-				})
+				r.log.withError(err).warnf("error initializing the kafka reader for partition %d of %s: %s", r.partition, r.topic, err)
 
 				continue
 			}
@@ -1300,9 +1349,8 @@ func (r *reader) run(ctx context.Context, offset int64) {
 				r.sendError(ctx, err)
 			} else {
 				r.stats.errors.observe(1)
-				r.withErrorLogger(func(log Logger) {
-					log.Printf("error initializing the kafka reader for partition %d of %s: %s", r.partition, r.topic, err)
-				})
+				r.metrics.add(readerErrors, 1, r.metricLabels...)
+				r.log.withError(err).warnf("error initializing the kafka reader for partition %d of %s: %s", r.partition, r.topic, err)
 			}
 			continue
 		}
@@ -1316,6 +1364,13 @@ func (r *reader) run(ctx context.Context, offset int64) {
 		// to the connection we know we'll want to restart from this offset.
 		offset = start
 
+		log := r.log.with(brokerAttr(conn.RemoteAddr().String()))
+
+		if truncated != nil {
+			log.with(offsetAttr(truncated.DivergentOffset)).withError(truncated).errorf("the kafka reader detected a log truncation for partition %d of %s, resuming from offset %d (%d messages were truncated)", r.partition, r.topic, truncated.DivergentOffset, truncated.Offset-truncated.DivergentOffset)
+			r.sendError(ctx, truncated)
+		}
+
 		errcount := 0
 	readLoop:
 		for {
@@ -1347,9 +1402,7 @@ func (r *reader) run(ctx context.Context, offset int64) {
 				break readLoop
 
 			case errors.Is(err, UnknownTopicOrPartition):
-				r.withErrorLogger(func(log Logger) {
-					log.Printf("failed to read from current broker %v for partition %d of %s at offset %d: %v", r.brokers, r.partition, r.topic, toHumanOffset(offset), err)
-				})
+				log.with(offsetAttr(offset)).withError(err).warnf("failed to read from current broker %v for partition %d of %s at offset %d: %v", r.brokers, r.partition, r.topic, toHumanOffset(offset), err)
 
 				conn.Close()
 
@@ -1359,9 +1412,7 @@ func (r *reader) run(ctx context.Context, offset int64) {
 				break readLoop
 
 			case errors.Is(err, NotLeaderForPartition):
-				r.withErrorLogger(func(log Logger) {
-					log.Printf("failed to read from current broker for partition %d of %s at offset %d: %v", r.partition, r.topic, toHumanOffset(offset), err)
-				})
+				log.with(offsetAttr(offset)).withError(err).warnf("failed to read from current broker for partition %d of %s at offset %d: %v", r.partition, r.topic, toHumanOffset(offset), err)
 
 				conn.Close()
 
@@ -1370,30 +1421,37 @@ func (r *reader) run(ctx context.Context, offset int64) {
 				r.stats.rebalances.observe(1)
 				break readLoop
 
+			case errors.Is(err, FencedLeaderEpoch), errors.Is(err, UnknownLeaderEpoch):
+				// The leader epoch known by the reader does not match the one of
+				// the broker, either the reader's metadata is stale or the broker
+				// has not caught up with the leader election yet.
+				log.with(offsetAttr(offset)).withError(err).warnf("failed to read from current broker for partition %d of %s at offset %d: %v", r.partition, r.topic, toHumanOffset(offset), err)
+
+				conn.Close()
+
+				// The next call to .initialize will refresh the leader epoch and
+				// validate the position of the reader against the new leader.
+				r.stats.rebalances.observe(1)
+				break readLoop
+
 			case errors.Is(err, RequestTimedOut):
 				// Timeout on the kafka side, this can be safely retried.
 				errcount = 0
-				r.withLogger(func(log Logger) {
-					log.Printf("no messages received from kafka within the allocated time for partition %d of %s at offset %d: %v", r.partition, r.topic, toHumanOffset(offset), err)
-				})
+				log.with(offsetAttr(offset)).debugf("no messages received from kafka within the allocated time for partition %d of %s at offset %d: %v", r.partition, r.topic, toHumanOffset(offset), err)
 				r.stats.timeouts.observe(1)
 				continue
 
 			case errors.Is(err, OffsetOutOfRange):
 				first, last, err := r.readOffsets(conn)
 				if err != nil {
-					r.withErrorLogger(func(log Logger) {
-						log.Printf("the kafka reader got an error while attempting to determine whether it was reading before the first offset or after the last offset of partition %d of %s: %s", r.partition, r.topic, err)
-					})
+					log.withError(err).warnf("the kafka reader got an error while attempting to determine whether it was reading before the first offset or after the last offset of partition %d of %s: %s", r.partition, r.topic, err)
 					conn.Close()
 					break readLoop
 				}
 
 				switch {
 				case offset < first:
-					r.withErrorLogger(func(log Logger) {
-						log.Printf("the kafka reader is reading before the first offset for partition %d of %s, skipping from offset %d to %d (%d messages)", r.partition, r.topic, toHumanOffset(offset), first, first-offset)
-					})
+					log.with(offsetAttr(offset)).warnf("the kafka reader is reading before the first offset for partition %d of %s, skipping from offset %d to %d (%d messages)", r.partition, r.topic, toHumanOffset(offset), first, first-offset)
 					offset, errcount = first, 0
 					continue // retry immediately so we don't keep falling behind due to the backoff
 
@@ -1403,9 +1461,7 @@ func (r *reader) run(ctx context.Context, offset int64) {
 
 				default:
 					// We may be reading past the last offset, will retry later.
-					r.withErrorLogger(func(log Logger) {
-						log.Printf("the kafka reader is reading passed the last offset for partition %d of %s at offset %d", r.partition, r.topic, toHumanOffset(offset))
-					})
+					log.with(offsetAttr(offset)).warnf("the kafka reader is reading passed the last offset for partition %d of %s at offset %d", r.partition, r.topic, toHumanOffset(offset))
 				}
 
 			case errors.Is(err, context.Canceled):
@@ -1425,10 +1481,9 @@ func (r *reader) run(ctx context.Context, offset int64) {
 				if errors.As(err, &kafkaError) {
 					r.sendError(ctx, err)
 				} else {
-					r.withErrorLogger(func(log Logger) {
-						log.Printf("the kafka reader got an unknown error reading partition %d of %s at offset %d: %s", r.partition, r.topic, toHumanOffset(offset), err)
-					})
+					log.with(offsetAttr(offset)).withError(err).warnf("the kafka reader got an unknown error reading partition %d of %s at offset %d: %s", r.partition, r.topic, toHumanOffset(offset), err)
 					r.stats.errors.observe(1)
+					r.metrics.add(readerErrors, 1, r.metricLabels...)
 					conn.Close()
 					break readLoop
 				}
@@ -1439,13 +1494,21 @@ func (r *reader) run(ctx context.Context, offset int64) {
 	}
 }
 
-func (r *reader) initialize(ctx context.Context, offset int64) (conn *Conn, start int64, err error) {
+// initialize connects to the leader of the partition and positions the
+// connection at offset. If the log was truncated below offset, the connection
+// is positioned where the log diverges and the truncation is returned.
+func (r *reader) initialize(ctx context.Context, offset int64) (conn *Conn, start int64, truncated *LogTruncationError, err error) {
 	for i := 0; i != len(r.brokers) && conn == nil; i++ {
 		broker := r.brokers[i]
 		var first, last int64
 
+		var partition Partition
+
 		t0 := time.Now()
-		conn, err = r.dialer.DialLeader(ctx, "tcp", broker, r.topic, r.partition)
+		partition, err = r.dialer.LookupPartition(ctx, "tcp", broker, r.topic, r.partition)
+		if err == nil {
+			conn, err = r.dialer.DialPartition(ctx, "tcp", broker, partition)
+		}
 		t1 := time.Now()
 		r.stats.dials.observe(1)
 		r.stats.dialTime.observeDuration(t1.Sub(t0))
@@ -1454,6 +1517,10 @@ func (r *reader) initialize(ctx context.Context, offset int64) (conn *Conn, star
 			continue
 		}
 
+		// Fetch requests carry the leader epoch so the broker can reject them
+		// if the reader is talking to a stale leader.
+		conn.setLeaderEpoch(int32(partition.LeaderEpoch))
+
 		if first, last, err = r.readOffsets(conn); err != nil {
 			conn.Close()
 			conn = nil
@@ -1471,9 +1538,16 @@ func (r *reader) initialize(ctx context.Context, offset int64) (conn *Conn, star
 			offset = first
 		}
 
-		r.withLogger(func(log Logger) {
-			log.Printf("the kafka reader for partition %d of %s is seeking to offset %d", r.partition, r.topic, toHumanOffset(offset))
-		})
+		// The partition leader may have changed since the last messages were
+		// consumed, make sure that the log was not truncated below the offset
+		// before seeking to it.
+		if offset, err = r.validate(conn, offset); err != nil && !errors.As(err, &truncated) {
+			conn.Close()
+			conn = nil
+			break
+		}
+
+		r.log.with(offsetAttr(offset)).debugf("the kafka reader for partition %d of %s is seeking to offset %d", r.partition, r.topic, toHumanOffset(offset))
 
 		if start, err = conn.Seek(offset, SeekAbsolute); err != nil {
 			conn.Close()
@@ -1487,9 +1561,50 @@ func (r *reader) initialize(ctx context.Context, offset int64) (conn *Conn, star
 	return
 }
 
+// validate checks that the log of the partition was not truncated below offset
+// (e.g. after an unclean leader election), by comparing the end offset of the
+// leader epoch of the last consumed message with the position of the reader.
+//
+// If a truncation is detected, the offset where the log diverges is returned
+// with a *LogTruncationError.
+func (r *reader) validate(conn *Conn, offset int64) (int64, error) {
+	if r.lastEpoch < 0 {
+		return offset, nil // nothing was consumed yet
+	}
+
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	defer conn.SetDeadline(time.Time{})
+
+	epoch, endOffset, err := conn.offsetForLeaderEpoch(r.lastEpoch)
+	if err != nil {
+		if errors.Is(err, UnsupportedVersion) {
+			// The broker does not support the API (kafka < 2.1), we can't
+			// detect truncations.
+			return offset, nil
+		}
+		return offset, err
+	}
+
+	if endOffset < 0 || endOffset >= offset {
+		return offset, nil
+	}
+
+	truncated := &LogTruncationError{
+		Topic:           r.topic,
+		Partition:       r.partition,
+		Offset:          offset,
+		DivergentOffset: endOffset,
+		LeaderEpoch:     int(r.lastEpoch),
+	}
+
+	r.lastEpoch = epoch
+	return endOffset, truncated
+}
+
 func (r *reader) read(ctx context.Context, offset int64, conn *Conn) (int64, error) {
 	r.stats.fetches.observe(1)
 	r.stats.offset.observe(offset)
+	r.metrics.add(readerFetches, 1, r.metricLabels...)
 
 	t0 := time.Now()
 	conn.SetReadDeadline(t0.Add(r.maxWait))
@@ -1498,11 +1613,14 @@ func (r *reader) read(ctx context.Context, offset int64, conn *Conn) (int64, err
 		MinBytes:       r.minBytes,
 		MaxBytes:       r.maxBytes,
 		IsolationLevel: r.isolationLevel,
+		PooledBuffers:  r.pooledBuffers,
 	})
 	highWaterMark := batch.HighWaterMark()
+	throttle := batch.Throttle()
 
 	t1 := time.Now()
 	r.stats.waitTime.observeDuration(t1.Sub(t0))
+	r.metrics.recordDuration(readerFetchDuration, t1.Sub(t0), r.metricLabels...)
 
 	var msg Message
 	var err error
@@ -1522,11 +1640,13 @@ func (r *reader) read(ctx context.Context, offset int64, conn *Conn) (int64, err
 		r.stats.bytes.observe(n)
 
 		if err = r.sendMessage(ctx, msg, highWaterMark); err != nil {
+			msg.Release()
 			batch.Close()
 			break
 		}
 
 		offset = msg.Offset + 1
+		r.lastEpoch = batch.leaderEpoch
 		r.stats.offset.observe(offset)
 		r.stats.lag.observe(highWaterMark - offset)
 
@@ -1540,6 +1660,25 @@ func (r *reader) read(ctx context.Context, offset int64, conn *Conn) (int64, err
 	r.stats.readTime.observeDuration(t2.Sub(t1))
 	r.stats.fetchSize.observe(size)
 	r.stats.fetchBytes.observe(bytes)
+	r.metrics.add(readerMessages, size, r.metricLabels...)
+	r.metrics.add(readerBytes, bytes, r.metricLabels...)
+
+	if throttle > 0 {
+		r.stats.throttles.observe(1)
+		r.stats.throttleTime.observeDuration(throttle)
+		r.metrics.recordDuration(readerThrottleDuration, throttle, r.metricLabels...)
+
+		// Brokers supporting KIP-219 respond immediately to throttled fetch
+		// requests and expect the client to wait for the throttle time before
+		// sending the next one.
+		if batch.clientThrottle {
+			r.log.debugf("fetch request throttled by the broker for %s", throttle)
+			if !sleep(ctx, throttle) {
+				err = ctx.Err()
+			}
+		}
+	}
+
 	return offset, err
 }
 
@@ -1566,20 +1705,6 @@ func (r *reader) sendError(ctx context.Context, err error) error {
 	}
 }
 
-func (r *reader) withLogger(do func(Logger)) {
-	if r.logger != nil {
-		do(r.logger)
-	}
-}
-
-func (r *reader) withErrorLogger(do func(Logger)) {
-	if r.errorLogger != nil {
-		do(r.errorLogger)
-	} else {
-		r.withLogger(do)
-	}
-}
-
 // extractTopics returns the unique list of topics represented by the set of
 // provided members.
 func extractTopics(members []GroupMember) []string {
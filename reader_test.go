@@ -15,6 +15,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/PerchSecurity/kafka-go/kafkatest"
 	"github.com/stretchr/testify/require"
 )
 
@@ -1282,10 +1283,8 @@ func TestCommitLoopImmediateFlushOnGenerationEnd(t *testing.T) {
 				return offsetCommitResponseV2{}, nil
 			},
 		},
-		done:     make(chan struct{}),
-		log:      func(func(Logger)) {},
-		logError: func(func(Logger)) {},
-		joined:   make(chan struct{}),
+		done:   make(chan struct{}),
+		joined: make(chan struct{}),
 	}
 
 	// initialize commits so that the commitLoopImmediate select statement blocks
@@ -1353,9 +1352,7 @@ func TestCommitOffsetsWithRetry(t *testing.T) {
 						return offsetCommitResponseV2{}, nil
 					},
 				},
-				done:     make(chan struct{}),
-				log:      func(func(Logger)) {},
-				logError: func(func(Logger)) {},
+				done: make(chan struct{}),
 			}
 
 			r := &Reader{stctx: context.Background()}
@@ -1987,3 +1984,125 @@ func testReaderTopicRecreated(t *testing.T, ctx context.Context, r *Reader) {
 	_, err = r.ReadMessage(ctx)
 	require.ErrorIs(t, err, OffsetOutOfRange)
 }
+
+func TestReaderPooledBuffers(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cluster, err := kafkatest.NewCluster(kafkatest.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cluster.Close()
+
+	const topic = "pooled"
+	if err := cluster.CreateTopic(topic, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	w := &Writer{
+		Addr:         cluster.Addr(),
+		Topic:        topic,
+		RequiredAcks: RequireOne,
+		BatchTimeout: time.Millisecond,
+	}
+	defer w.Close()
+
+	want := makeTestSequence(20)
+	if err := w.WriteMessages(ctx, want...); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewReader(ReaderConfig{
+		Brokers:       cluster.Brokers(),
+		Topic:         topic,
+		MaxWait:       10 * time.Millisecond,
+		PooledBuffers: true,
+	})
+	defer r.Close()
+
+	// Messages are all fetched before being checked to verify that reading
+	// the next messages does not overwrite the buffers they alias.
+	got := make([]Message, len(want))
+	for i := range got {
+		if got[i], err = r.FetchMessage(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if got[i].lease == nil {
+			t.Fatalf("message %d was not read into a pooled buffer", i)
+		}
+	}
+
+	for i := range got {
+		m := &got[i]
+		if m.Offset != int64(i) || !bytes.Equal(m.Value, want[i].Value) {
+			t.Errorf("message %d mismatch: got %d=%q, want %d=%q", i, m.Offset, m.Value, i, want[i].Value)
+		}
+		m.Release()
+		if m.lease != nil || m.Value != nil {
+			t.Errorf("message %d was not released", i)
+		}
+	}
+}
+
+func TestReaderLogTruncation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cluster, err := kafkatest.NewCluster(kafkatest.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cluster.Close()
+
+	const topic = "truncated"
+	if err := cluster.CreateTopic(topic, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	w := &Writer{
+		Addr:         cluster.Addr(),
+		Topic:        topic,
+		RequiredAcks: RequireOne,
+		BatchTimeout: time.Millisecond,
+	}
+	defer w.Close()
+
+	// The first leader epoch ends at offset 10, the reader pretends to have
+	// consumed messages of this epoch up to offset 12, which were lost by the
+	// new leader.
+	if err := w.WriteMessages(ctx, makeTestSequence(10)...); err != nil {
+		t.Fatal(err)
+	}
+	if err := cluster.MoveLeader(topic, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteMessages(ctx, makeTestSequence(5)...); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &reader{
+		dialer:    DefaultDialer,
+		brokers:   cluster.Brokers(),
+		topic:     topic,
+		partition: 0,
+		stats:     &readerStats{},
+		lastEpoch: 0,
+	}
+
+	conn, start, truncated, err := r.initialize(ctx, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if truncated == nil {
+		t.Fatal("expected the log truncation to be detected")
+	}
+	if truncated.Offset != 12 || truncated.DivergentOffset != 10 || truncated.LeaderEpoch != 0 {
+		t.Errorf("unexpected log truncation: %+v", truncated)
+	}
+	if start != 10 {
+		t.Errorf("expected the reader to resume from offset 10, got %d", start)
+	}
+}
@@ -0,0 +1,48 @@
+package kafka
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/PerchSecurity/kafka-go/protocol"
+	"github.com/PerchSecurity/kafka-go/protocol/renewdelegationtoken"
+)
+
+func TestClientRenewDelegationToken(t *testing.T) {
+	var sent []*renewdelegationtoken.Request
+
+	client := &Client{
+		Addr: TCP("localhost:9092"),
+		Transport: roundTripFunc(func(ctx context.Context, addr net.Addr, msg protocol.Message) (protocol.Message, error) {
+			sent = append(sent, msg.(*renewdelegationtoken.Request))
+			return &renewdelegationtoken.Response{ExpiryTimestampMs: 2000}, nil
+		}),
+	}
+
+	res, err := client.RenewDelegationToken(context.Background(), &RenewDelegationTokenRequest{
+		HMAC:        []byte("hmac"),
+		RenewPeriod: time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Error != nil || !res.ExpiryTime.Equal(makeTime(2000)) {
+		t.Errorf("unexpected response: %+v", res)
+	}
+
+	// A zero period is sent as -1 to let the broker pick the default.
+	if _, err := client.RenewDelegationToken(context.Background(), &RenewDelegationTokenRequest{HMAC: []byte("hmac")}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*renewdelegationtoken.Request{
+		{HMAC: []byte("hmac"), RenewPeriodMs: 60000},
+		{HMAC: []byte("hmac"), RenewPeriodMs: -1},
+	}
+	if !reflect.DeepEqual(sent, want) {
+		t.Errorf("requests mismatch:\n got: %+v\nwant: %+v", sent, want)
+	}
+}
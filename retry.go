@@ -0,0 +1,210 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/PerchSecurity/kafka-go/protocol"
+)
+
+// RetryPolicy is an interface implemented by types which decide whether the
+// requests sent by a Client should be retried after a failed attempt.
+//
+// Implementations must be safe to use concurrently from multiple goroutines.
+type RetryPolicy interface {
+	// Retry is called after attempt number attempt (starting at 1) to send
+	// req failed with err. It returns how long the client should wait before
+	// the next attempt, and whether the request should be retried at all.
+	//
+	// The error is either the error returned by the transport, or a
+	// *ResponseError when the kafka broker returned a response carrying error
+	// codes on some of the topic partitions of the request.
+	Retry(req protocol.Message, attempt int, err error) (time.Duration, bool)
+}
+
+// DefaultRetryPolicy is a retry policy using exponential backoff with the
+// default configuration of ExponentialBackoff.
+var DefaultRetryPolicy RetryPolicy = &ExponentialBackoff{}
+
+// ExponentialBackoff is an implementation of RetryPolicy which retries
+// requests that failed with retriable errors, doubling the delay between each
+// attempt.
+//
+// Kafka errors are retried when they are temporary (see Error.Temporary).
+// Network errors and timeouts are only retried for requests that can safely
+// be sent more than once, since the broker may have processed the request
+// before the error occurred. For the same reason, responses carrying temporary
+// errors on some of their topics or partitions are only retried for these
+// requests, the other topics and partitions may have been modified already.
+// Produce requests are never retried, the Writer implements its own retry
+// logic.
+type ExponentialBackoff struct {
+	// Maximum number of attempts made to send a request, including the first
+	// one.
+	//
+	// Defaults to 5.
+	MaxAttempts int
+
+	// Bounds of the backoff applied between attempts. The actual delays are
+	// randomized to avoid synchronizing retries of concurrent requests.
+	//
+	// Default to 100ms and 5s.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// Retry satisfies the RetryPolicy interface.
+func (b *ExponentialBackoff) Retry(req protocol.Message, attempt int, err error) (time.Duration, bool) {
+	if attempt >= b.maxAttempts() || !isRetriableRequestError(req, err) {
+		return 0, false
+	}
+
+	backoff := b.minBackoff()
+
+	for i := 1; i < attempt && backoff < b.maxBackoff(); i++ {
+		backoff *= 2
+	}
+
+	if backoff > b.maxBackoff() {
+		backoff = b.maxBackoff()
+	}
+
+	// Randomize the delay between half and the full backoff.
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1)), true
+}
+
+func (b *ExponentialBackoff) maxAttempts() int {
+	if b.MaxAttempts > 0 {
+		return b.MaxAttempts
+	}
+	return 5
+}
+
+func (b *ExponentialBackoff) minBackoff() time.Duration {
+	if b.MinBackoff > 0 {
+		return b.MinBackoff
+	}
+	return 100 * time.Millisecond
+}
+
+func (b *ExponentialBackoff) maxBackoff() time.Duration {
+	if b.MaxBackoff > 0 {
+		return b.MaxBackoff
+	}
+	return 5 * time.Second
+}
+
+type clientRetriesKey struct{}
+
+// withClientRetries returns a copy of ctx indicating that the requests sent
+// with it are retried by a Client, transports do not retry them so the
+// attempts of both layers don't multiply.
+func withClientRetries(ctx context.Context) context.Context {
+	return context.WithValue(ctx, clientRetriesKey{}, true)
+}
+
+func retriedByClient(ctx context.Context) bool {
+	retried, _ := ctx.Value(clientRetriesKey{}).(bool)
+	return retried
+}
+
+// isRetriableRequestError returns true if req may succeed if it was sent again
+// after failing with err. It is the classifier shared by the retries of the
+// Client and of the Transport.
+func isRetriableRequestError(req protocol.Message, err error) bool {
+	if req.ApiKey() == protocol.Produce {
+		return false
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var kafkaError Error
+	var responseError *ResponseError
+	var protocolError protocol.Error
+
+	switch {
+	case errors.As(err, &responseError):
+		// The broker processed the request, it can only be sent again if it
+		// has no side effects on the topics or partitions which succeeded.
+		return canResend(req) && responseError.Temporary()
+	case errors.As(err, &kafkaError):
+		// The broker rejected the request, it is safe to send it again.
+		return kafkaError.Temporary()
+	case errors.As(err, &protocolError):
+		// The request could not be routed to a broker (e.g. because the
+		// partition had no leader), it was never sent.
+		return protocolError == protocol.ErrNoLeader
+	case isTimeout(err), isTemporary(err), isTransientNetworkError(err), errors.Is(err, io.EOF):
+		// The request may have reached the broker, it can only be sent again
+		// if it has no side effects.
+		return canResend(req)
+	default:
+		return false
+	}
+}
+
+// canResend returns true if req can be sent again after the broker may have
+// processed it. Offset commits have side effects, but committing the same
+// offsets twice has the same result.
+func canResend(req protocol.Message) bool {
+	return isIdempotent(req) || req.ApiKey() == protocol.OffsetCommit
+}
+
+// ResponseError is the error passed to RetryPolicy implementations when a
+// kafka broker returned a response carrying error codes on some of the topics
+// or topic partitions of the request. Errors on whole topics are reported with
+// a partition of -1.
+type ResponseError struct {
+	// The response returned by the kafka broker.
+	Response protocol.Message
+
+	// The errors reported on the topic partitions of the response. The errors
+	// wrap values of type Error.
+	Errors []*protocol.TopicPartitionError
+}
+
+// Error satisfies the error interface.
+func (e *ResponseError) Error() string {
+	s := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		s[i] = err.Error()
+	}
+	return fmt.Sprintf("kafka %s response contained %d partition error(s): %s", e.Response.ApiKey(), len(e.Errors), strings.Join(s, ", "))
+}
+
+// Temporary returns true if any of the partition errors is temporary, in which
+// case sending the request again may succeed on some of the partitions.
+func (e *ResponseError) Temporary() bool {
+	for _, err := range e.Errors {
+		if isTemporary(err) {
+			return true
+		}
+	}
+	return false
+}
+
+func makeResponseError(res protocol.Message) *ResponseError {
+	r, ok := res.(protocol.PartitionErrorer)
+	if !ok {
+		return nil
+	}
+
+	var errs []*protocol.TopicPartitionError
+
+	r.PartitionErrors(func(topic string, partition int32, errorCode int16) {
+		errs = append(errs, protocol.NewTopicPartitionError(topic, partition, Error(errorCode)))
+	})
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return &ResponseError{Response: res, Errors: errs}
+}
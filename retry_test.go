@@ -0,0 +1,279 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/PerchSecurity/kafka-go/kafkatest"
+	"github.com/PerchSecurity/kafka-go/protocol"
+	"github.com/PerchSecurity/kafka-go/protocol/createtopics"
+	"github.com/PerchSecurity/kafka-go/protocol/listoffsets"
+	produceAPI "github.com/PerchSecurity/kafka-go/protocol/produce"
+)
+
+type roundTripFunc func(context.Context, net.Addr, protocol.Message) (protocol.Message, error)
+
+func (f roundTripFunc) RoundTrip(ctx context.Context, addr net.Addr, msg protocol.Message) (protocol.Message, error) {
+	return f(ctx, addr, msg)
+}
+
+func TestClientRetryPolicy(t *testing.T) {
+	listOffsetsResponse := func(errorCode Error) *listoffsets.Response {
+		return &listoffsets.Response{
+			Topics: []listoffsets.ResponseTopic{{
+				Topic: "test",
+				Partitions: []listoffsets.ResponsePartition{{
+					Partition: 0,
+					ErrorCode: int16(errorCode),
+					Offset:    42,
+				}},
+			}},
+		}
+	}
+
+	newClient := func(policy RetryPolicy, results ...func() (protocol.Message, error)) (*Client, *int) {
+		attempts := new(int)
+		return &Client{
+			Addr:        TCP("localhost:9092"),
+			RetryPolicy: policy,
+			Transport: roundTripFunc(func(context.Context, net.Addr, protocol.Message) (protocol.Message, error) {
+				i := *attempts
+				*attempts++
+				if i >= len(results) {
+					i = len(results) - 1
+				}
+				return results[i]()
+			}),
+		}, attempts
+	}
+
+	policy := &ExponentialBackoff{MinBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+
+	t.Run("no retry policy makes a single attempt", func(t *testing.T) {
+		client, attempts := newClient(nil,
+			func() (protocol.Message, error) { return nil, io.ErrUnexpectedEOF },
+		)
+
+		_, err := client.roundTrip(context.Background(), nil, &listoffsets.Request{})
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if *attempts != 1 {
+			t.Fatalf("expected 1 attempt, got %d", *attempts)
+		}
+	})
+
+	t.Run("temporary partition errors are retried", func(t *testing.T) {
+		client, attempts := newClient(policy,
+			func() (protocol.Message, error) { return listOffsetsResponse(NotLeaderForPartition), nil },
+			func() (protocol.Message, error) { return listOffsetsResponse(LeaderNotAvailable), nil },
+			func() (protocol.Message, error) { return listOffsetsResponse(0), nil },
+		)
+
+		res, err := client.roundTrip(context.Background(), nil, &listoffsets.Request{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *attempts != 3 {
+			t.Fatalf("expected 3 attempts, got %d", *attempts)
+		}
+		if code := res.(*listoffsets.Response).Topics[0].Partitions[0].ErrorCode; code != 0 {
+			t.Fatalf("unexpected error code in the response: %v", Error(code))
+		}
+	})
+
+	t.Run("the last response is returned when attempts are exhausted", func(t *testing.T) {
+		client, attempts := newClient(&ExponentialBackoff{MaxAttempts: 2, MinBackoff: time.Millisecond},
+			func() (protocol.Message, error) { return listOffsetsResponse(NotLeaderForPartition), nil },
+		)
+
+		res, err := client.roundTrip(context.Background(), nil, &listoffsets.Request{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *attempts != 2 {
+			t.Fatalf("expected 2 attempts, got %d", *attempts)
+		}
+		if code := res.(*listoffsets.Response).Topics[0].Partitions[0].ErrorCode; Error(code) != NotLeaderForPartition {
+			t.Fatalf("unexpected error code in the response: %v", Error(code))
+		}
+	})
+
+	t.Run("permanent errors are not retried", func(t *testing.T) {
+		client, attempts := newClient(policy,
+			func() (protocol.Message, error) { return listOffsetsResponse(TopicAuthorizationFailed), nil },
+		)
+
+		if _, err := client.roundTrip(context.Background(), nil, &listoffsets.Request{}); err != nil {
+			t.Fatal(err)
+		}
+		if *attempts != 1 {
+			t.Fatalf("expected 1 attempt, got %d", *attempts)
+		}
+	})
+
+	t.Run("temporary errors of requests with side effects are not retried", func(t *testing.T) {
+		// A CreateTopics response with a temporary error on one topic, the
+		// other topic was created and would fail with TopicAlreadyExists if
+		// the request was sent again.
+		client, attempts := newClient(policy,
+			func() (protocol.Message, error) {
+				return &createtopics.Response{
+					Topics: []createtopics.ResponseTopic{
+						{Name: "created"},
+						{Name: "throttled", ErrorCode: int16(ThrottlingQuotaExceeded)},
+					},
+				}, nil
+			},
+		)
+
+		res, err := client.roundTrip(context.Background(), nil, &createtopics.Request{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *attempts != 1 {
+			t.Fatalf("expected 1 attempt, got %d", *attempts)
+		}
+		if topics := res.(*createtopics.Response).Topics; topics[0].ErrorCode != 0 {
+			t.Errorf("unexpected error on the created topic: %v", Error(topics[0].ErrorCode))
+		}
+	})
+
+	t.Run("responses with some temporary partition errors are retried", func(t *testing.T) {
+		res := listOffsetsResponse(TopicAuthorizationFailed)
+		res.Topics[0].Partitions = append(res.Topics[0].Partitions, listoffsets.ResponsePartition{
+			Partition: 1,
+			ErrorCode: int16(NotLeaderForPartition),
+		})
+
+		client, attempts := newClient(&ExponentialBackoff{MaxAttempts: 2, MinBackoff: time.Millisecond},
+			func() (protocol.Message, error) { return res, nil },
+		)
+
+		if _, err := client.roundTrip(context.Background(), nil, &listoffsets.Request{}); err != nil {
+			t.Fatal(err)
+		}
+		if *attempts != 2 {
+			t.Fatalf("expected 2 attempts, got %d", *attempts)
+		}
+	})
+
+	t.Run("network errors are only retried on idempotent requests", func(t *testing.T) {
+		client, attempts := newClient(policy,
+			func() (protocol.Message, error) { return nil, io.EOF },
+			func() (protocol.Message, error) { return &listoffsets.Response{}, nil },
+		)
+
+		if _, err := client.roundTrip(context.Background(), nil, &listoffsets.Request{}); err != nil {
+			t.Fatal(err)
+		}
+		if *attempts != 2 {
+			t.Fatalf("expected 2 attempts, got %d", *attempts)
+		}
+
+		*attempts = 0
+		if _, err := client.roundTrip(context.Background(), nil, &createtopics.Request{}); !errors.Is(err, io.EOF) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if *attempts != 1 {
+			t.Fatalf("expected 1 attempt, got %d", *attempts)
+		}
+	})
+
+	t.Run("produce requests are not retried", func(t *testing.T) {
+		client, attempts := newClient(policy,
+			func() (protocol.Message, error) { return nil, RequestTimedOut },
+		)
+
+		if _, err := client.roundTrip(context.Background(), nil, &produceAPI.Request{}); !errors.Is(err, RequestTimedOut) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if *attempts != 1 {
+			t.Fatalf("expected 1 attempt, got %d", *attempts)
+		}
+	})
+
+	t.Run("retries do not exceed the client timeout", func(t *testing.T) {
+		client, attempts := newClient(&ExponentialBackoff{MaxAttempts: 100, MinBackoff: 20 * time.Millisecond, MaxBackoff: 20 * time.Millisecond},
+			func() (protocol.Message, error) { return nil, LeaderNotAvailable },
+		)
+		client.Timeout = 50 * time.Millisecond
+
+		start := time.Now()
+		_, err := client.roundTrip(context.Background(), nil, &listoffsets.Request{})
+		if !errors.Is(err, LeaderNotAvailable) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Fatalf("retries took too long: %s", elapsed)
+		}
+		if *attempts < 2 || *attempts > 5 {
+			t.Fatalf("unexpected number of attempts: %d", *attempts)
+		}
+	})
+}
+
+func TestClientRetryPolicyDisablesTransportRetries(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cluster, err := kafkatest.NewCluster(kafkatest.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cluster.Close()
+
+	const topic = "retries"
+	if err := cluster.CreateTopic(topic, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// Count the ListOffsets requests actually sent to the cluster.
+	var requests int32
+	transport := &Transport{
+		Retry: &TransportRetry{MaxAttempts: 3, MinBackoff: time.Millisecond, Budget: 1},
+		Hooks: &TransportHooks{
+			AfterRoundTrip: func(ctx context.Context, info RoundTripInfo) {
+				if info.ApiKey == protocol.ListOffsets {
+					atomic.AddInt32(&requests, 1)
+				}
+			},
+		},
+	}
+	defer transport.CloseIdleConnections()
+
+	listOffsets := func(client *Client) {
+		cluster.InjectError(protocol.ListOffsets, int16(NotLeaderForPartition), 100)
+		defer cluster.InjectError(protocol.ListOffsets, 0, 0)
+		atomic.StoreInt32(&requests, 0)
+
+		res, err := client.ListOffsets(ctx, &ListOffsetsRequest{
+			Topics: map[string][]OffsetRequest{topic: {FirstOffsetOf(0)}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := res.Topics[topic][0].Error; !errors.Is(err, NotLeaderForPartition) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	listOffsets(&Client{Addr: cluster.Addr(), Transport: transport})
+	if n := atomic.LoadInt32(&requests); n != 3 {
+		t.Errorf("expected the transport to make 3 attempts, got %d", n)
+	}
+
+	listOffsets(&Client{
+		Addr:        cluster.Addr(),
+		Transport:   transport,
+		RetryPolicy: &ExponentialBackoff{MaxAttempts: 2, MinBackoff: time.Millisecond},
+	})
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("expected the client to make 2 attempts, got %d", n)
+	}
+}
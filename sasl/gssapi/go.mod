@@ -0,0 +1,10 @@
+module github.com/PerchSecurity/kafka-go/sasl/gssapi
+
+go 1.15
+
+require (
+	github.com/PerchSecurity/kafka-go v0.4.34
+	github.com/jcmturner/gokrb5/v8 v8.4.4
+)
+
+replace github.com/PerchSecurity/kafka-go => ../..
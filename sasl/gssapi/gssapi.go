@@ -0,0 +1,232 @@
+// Package gssapi implements the GSSAPI SASL mechanism (RFC 4752), which
+// authenticates with kafka brokers using Kerberos V5.
+//
+// The package uses the pure-Go gokrb5 library, it does not require cgo nor
+// the MIT or Heimdal Kerberos libraries to be installed.
+package gssapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/PerchSecurity/kafka-go/sasl"
+	"github.com/jcmturner/gokrb5/v8/client"
+	"github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/credentials"
+	"github.com/jcmturner/gokrb5/v8/gssapi"
+	"github.com/jcmturner/gokrb5/v8/iana/keyusage"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+	"github.com/jcmturner/gokrb5/v8/types"
+)
+
+const (
+	// DefaultServiceName is the default name of the kafka service principal,
+	// it matches the default value of sasl.kerberos.service.name.
+	DefaultServiceName = "kafka"
+
+	// The security layers negotiated at the end of the exchange (RFC 4752
+	// section 3.3), kafka only supports authentication without a security
+	// layer.
+	securityLayerNone = 0x01
+)
+
+// Mechanism implements sasl.Mechanism for the GSSAPI mechanism.
+type Mechanism struct {
+	// The Kerberos client used to obtain service tickets for the kafka
+	// brokers. Required.
+	//
+	// Keytab and CCache create mechanisms with clients that log in with a
+	// keytab file or a credential cache.
+	Client *client.Client
+
+	// The name of the kafka service principal, the brokers are authenticated
+	// as ServiceName/host. Optional, defaults to DefaultServiceName.
+	ServiceName string
+
+	// The realm of the kafka service principal. Optional, when empty the realm
+	// is resolved from the domain_realm section of the Kerberos configuration,
+	// and defaults to the realm of the client.
+	ServiceRealm string
+
+	// The host name used in the service principal. Optional, defaults to the
+	// host of the broker the authentication is performed on.
+	Host string
+
+	// The authorization identity sent to the broker. Optional, the identity
+	// of the client principal is used if empty.
+	AuthzID string
+}
+
+// Keytab returns a Mechanism which logs in as username@realm with the keys
+// stored in the keytab file at keytabPath. The Kerberos configuration is
+// loaded from the krb5.conf file at configPath.
+func Keytab(configPath, username, realm, keytabPath string, settings ...func(*client.Settings)) (*Mechanism, error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("gssapi: loading kerberos configuration: %w", err)
+	}
+
+	kt, err := keytab.Load(keytabPath)
+	if err != nil {
+		return nil, fmt.Errorf("gssapi: loading keytab: %w", err)
+	}
+
+	return &Mechanism{Client: client.NewWithKeytab(username, realm, kt, cfg, settings...)}, nil
+}
+
+// CCache returns a Mechanism which uses the tickets of the credential cache at
+// ccachePath (e.g. obtained by running kinit). The Kerberos configuration is
+// loaded from the krb5.conf file at configPath.
+func CCache(configPath, ccachePath string, settings ...func(*client.Settings)) (*Mechanism, error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("gssapi: loading kerberos configuration: %w", err)
+	}
+
+	cc, err := credentials.LoadCCache(ccachePath)
+	if err != nil {
+		return nil, fmt.Errorf("gssapi: loading credential cache: %w", err)
+	}
+
+	cl, err := client.NewFromCCache(cc, cfg, settings...)
+	if err != nil {
+		return nil, fmt.Errorf("gssapi: creating kerberos client: %w", err)
+	}
+
+	return &Mechanism{Client: cl}, nil
+}
+
+// Name satisfies the sasl.Mechanism interface.
+func (*Mechanism) Name() string {
+	return "GSSAPI"
+}
+
+// Start satisfies the sasl.Mechanism interface. It obtains a service ticket
+// for the broker and sends it in a Kerberos AP-REQ message.
+func (m *Mechanism) Start(ctx context.Context) (sasl.StateMachine, []byte, error) {
+	if m.Client == nil {
+		return nil, nil, errors.New("gssapi: no kerberos client configured")
+	}
+
+	host := m.Host
+	if host == "" {
+		if meta := sasl.MetadataFromContext(ctx); meta != nil {
+			host = meta.Host
+		}
+	}
+	if host == "" {
+		return nil, nil, errors.New("gssapi: missing host of the service principal")
+	}
+
+	serviceName := m.ServiceName
+	if serviceName == "" {
+		serviceName = DefaultServiceName
+	}
+
+	cl := m.Client
+	if m.ServiceRealm != "" {
+		cl = withServiceRealm(cl, host, m.ServiceRealm)
+	}
+
+	if err := cl.AffirmLogin(); err != nil {
+		return nil, nil, fmt.Errorf("gssapi: kerberos login: %w", err)
+	}
+
+	spn := serviceName + "/" + host
+
+	ticket, key, err := cl.GetServiceTicket(spn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("gssapi: obtaining service ticket for %s: %w", spn, err)
+	}
+
+	token, err := spnego.NewKRB5TokenAPREQ(cl, ticket, key,
+		[]int{gssapi.ContextFlagInteg, gssapi.ContextFlagConf},
+		[]int{},
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("gssapi: creating AP-REQ: %w", err)
+	}
+
+	ir, err := token.Marshal()
+	if err != nil {
+		return nil, nil, fmt.Errorf("gssapi: marshaling AP-REQ: %w", err)
+	}
+
+	return &session{key: key, authzID: m.AuthzID}, ir, nil
+}
+
+// withServiceRealm returns a copy of cl which resolves the realm of the
+// service principals of host to realm. The gokrb5 client resolves realms from
+// its configuration only, the copy has its own configuration with a
+// domain_realm entry for host, and shares the sessions and ticket cache of cl.
+func withServiceRealm(cl *client.Client, host, realm string) *client.Client {
+	cfg := *cl.Config
+	cfg.DomainRealm = make(config.DomainRealm, len(cl.Config.DomainRealm)+1)
+	for domain, r := range cl.Config.DomainRealm {
+		cfg.DomainRealm[domain] = r
+	}
+	cfg.DomainRealm[host] = realm
+
+	c := *cl
+	c.Config = &cfg
+	return &c
+}
+
+type session struct {
+	key     types.EncryptionKey
+	authzID string
+	done    bool
+}
+
+func (s *session) Next(ctx context.Context, challenge []byte) (bool, []byte, error) {
+	if s.done {
+		// The broker sends an empty message once it accepted the security
+		// layer selected by the client.
+		return true, nil, nil
+	}
+
+	if len(challenge) == 0 || challenge[0] == 0x60 {
+		// The broker completed the security context establishment, possibly
+		// with an AP-REP token if it performed mutual authentication, and
+		// waits for an empty response to send the security layers it
+		// supports.
+		return false, []byte{}, nil
+	}
+
+	token := &gssapi.WrapToken{}
+
+	if err := token.Unmarshal(challenge, true); err != nil {
+		return false, nil, fmt.Errorf("gssapi: invalid security layer message: %w", err)
+	}
+
+	if _, err := token.Verify(s.key, keyusage.GSSAPI_ACCEPTOR_SEAL); err != nil {
+		return false, nil, fmt.Errorf("gssapi: verifying security layer message: %w", err)
+	}
+
+	if len(token.Payload) != 4 {
+		return false, nil, fmt.Errorf("gssapi: invalid security layer message of length %d", len(token.Payload))
+	}
+
+	if token.Payload[0]&securityLayerNone == 0 {
+		return false, nil, errors.New("gssapi: the broker requires a security layer")
+	}
+
+	// Select no security layer and a maximum message size of zero, followed
+	// by the optional authorization identity.
+	payload := append([]byte{securityLayerNone, 0, 0, 0}, s.authzID...)
+
+	reply, err := gssapi.NewInitiatorWrapToken(payload, s.key)
+	if err != nil {
+		return false, nil, fmt.Errorf("gssapi: creating security layer message: %w", err)
+	}
+
+	b, err := reply.Marshal()
+	if err != nil {
+		return false, nil, fmt.Errorf("gssapi: marshaling security layer message: %w", err)
+	}
+
+	s.done = true
+	return false, b, nil
+}
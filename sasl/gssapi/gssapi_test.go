@@ -0,0 +1,185 @@
+package gssapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/PerchSecurity/kafka-go/sasl"
+	"github.com/jcmturner/gokrb5/v8/client"
+	"github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/crypto"
+	"github.com/jcmturner/gokrb5/v8/gssapi"
+	"github.com/jcmturner/gokrb5/v8/iana/keyusage"
+	"github.com/jcmturner/gokrb5/v8/service"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+	"github.com/jcmturner/gokrb5/v8/types"
+)
+
+func TestMechanism(t *testing.T) {
+	k := newKDC(t, map[string]string{
+		"alice":           "alice-password",
+		"kafka/localhost": "kafka-password",
+	})
+	defer k.close()
+
+	// The keytab of the KDC holds the keys of all principals, it is used both
+	// by the client and the broker.
+	mechanism := &Mechanism{
+		Client:  client.NewWithKeytab("alice", testRealm, k.keytab, k.config(t), client.DisablePAFXFAST(true)),
+		AuthzID: "alice",
+	}
+
+	ctx := sasl.WithMetadata(context.Background(), &sasl.Metadata{Host: "localhost", Port: 9092})
+
+	sess, ir, err := mechanism.Start(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The broker verifies the AP-REQ with the keys of the service principal.
+	var token spnego.KRB5Token
+	if err := token.Unmarshal(ir); err != nil {
+		t.Fatal(err)
+	}
+	if !token.IsAPReq() {
+		t.Fatal("the initial response is not an AP-REQ")
+	}
+
+	ok, creds, err := service.VerifyAPREQ(&token.APReq, service.NewSettings(k.keytab))
+	if err != nil || !ok {
+		t.Fatalf("the broker rejected the AP-REQ: %v", err)
+	}
+	if creds.UserName() != "alice" || creds.Domain() != testRealm {
+		t.Fatalf("unexpected client principal: %s@%s", creds.UserName(), creds.Domain())
+	}
+
+	sessionKey := token.APReq.Ticket.DecryptedEncPart.Key
+
+	// The security context is established, the broker sends an empty message
+	// and then the security layers it supports.
+	done, res, err := sess.Next(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done || len(res) != 0 {
+		t.Fatalf("expected an empty response, got done=%t response=%q", done, res)
+	}
+
+	done, res, err = sess.Next(ctx, acceptorWrapToken(t, sessionKey, []byte{0x07, 0x00, 0x10, 0x00}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done {
+		t.Fatal("the exchange completed before the client selected a security layer")
+	}
+
+	var reply gssapi.WrapToken
+	if err := reply.Unmarshal(res, false); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := reply.Verify(sessionKey, keyusage.GSSAPI_INITIATOR_SEAL); !ok {
+		t.Fatalf("invalid security layer reply: %v", err)
+	}
+	if string(reply.Payload) != "\x01\x00\x00\x00alice" {
+		t.Fatalf("unexpected security layer reply: %q", reply.Payload)
+	}
+
+	done, _, err = sess.Next(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !done {
+		t.Fatal("the exchange did not complete")
+	}
+}
+
+func TestMechanismServiceRealm(t *testing.T) {
+	k := newKDC(t, map[string]string{
+		"alice":           "alice-password",
+		"kafka/localhost": "kafka-password",
+	})
+	defer k.close()
+
+	// The configuration maps the broker host to a realm that has no KDC,
+	// ServiceRealm takes precedence over it.
+	cfg := k.config(t)
+	cfg.DomainRealm = config.DomainRealm{"localhost": "OTHER.COM"}
+
+	ctx := sasl.WithMetadata(context.Background(), &sasl.Metadata{Host: "localhost", Port: 9092})
+
+	mechanism := &Mechanism{
+		Client: client.NewWithKeytab("alice", testRealm, k.keytab, cfg, client.DisablePAFXFAST(true)),
+	}
+	if _, _, err := mechanism.Start(ctx); err == nil {
+		t.Fatal("expected the service ticket to be requested in the realm of the configuration")
+	}
+
+	mechanism.ServiceRealm = testRealm
+
+	_, ir, err := mechanism.Start(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var token spnego.KRB5Token
+	if err := token.Unmarshal(ir); err != nil {
+		t.Fatal(err)
+	}
+	if ticket := token.APReq.Ticket; ticket.Realm != testRealm || ticket.SName.PrincipalNameString() != "kafka/localhost" {
+		t.Errorf("unexpected service ticket: %s@%s", ticket.SName.PrincipalNameString(), ticket.Realm)
+	}
+
+	// The configuration of the client is left unchanged.
+	if realm := cfg.DomainRealm["localhost"]; realm != "OTHER.COM" {
+		t.Errorf("the configuration of the client was modified: %q", realm)
+	}
+}
+
+func TestMechanismRejectsInvalidSecurityLayerMessage(t *testing.T) {
+	k := newKDC(t, map[string]string{
+		"alice":            "alice-password",
+		"broker/kafka-0.x": "kafka-password",
+	})
+	defer k.close()
+
+	mechanism := &Mechanism{
+		Client:      client.NewWithKeytab("alice", testRealm, k.keytab, k.config(t), client.DisablePAFXFAST(true)),
+		ServiceName: "broker",
+		Host:        "kafka-0.x",
+	}
+
+	sess, _, err := mechanism.Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A token signed with a different key must be rejected.
+	etype, err := crypto.GetEtype(testEType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := types.GenerateEncryptionKey(etype)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := sess.Next(context.Background(), acceptorWrapToken(t, otherKey, []byte{0x01, 0x00, 0x10, 0x00})); err == nil {
+		t.Fatal("expected an error verifying a security layer message signed with the wrong key")
+	}
+}
+
+func acceptorWrapToken(t *testing.T, key types.EncryptionKey, payload []byte) []byte {
+	token := gssapi.WrapToken{
+		Flags:   0x01, // sent by the acceptor
+		EC:      12,
+		Payload: payload,
+	}
+	if err := token.SetCheckSum(key, keyusage.GSSAPI_ACCEPTOR_SEAL); err != nil {
+		t.Fatal(err)
+	}
+	b, err := token.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
@@ -0,0 +1,226 @@
+package gssapi
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/crypto"
+	"github.com/jcmturner/gokrb5/v8/iana/etypeID"
+	"github.com/jcmturner/gokrb5/v8/iana/keyusage"
+	"github.com/jcmturner/gokrb5/v8/iana/msgtype"
+	"github.com/jcmturner/gokrb5/v8/iana/patype"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/messages"
+	"github.com/jcmturner/gokrb5/v8/types"
+)
+
+const (
+	testRealm = "EXAMPLE.COM"
+	testEType = etypeID.AES256_CTS_HMAC_SHA1_96
+	testKVNO  = 1
+)
+
+// kdc is a minimal in-process stand-in for a Kerberos key distribution center,
+// it answers AS and TGS requests over TCP for the principals of its keytab,
+// without supporting pre-authentication nor any of the optional features of
+// the protocol.
+type kdc struct {
+	keytab   *keytab.Keytab
+	listener net.Listener
+}
+
+func newKDC(t *testing.T, principals map[string]string) *kdc {
+	kt := keytab.New()
+	principals["krbtgt/"+testRealm] = "krbtgt-password"
+
+	for principal, password := range principals {
+		if err := kt.AddEntry(principal, testRealm, password, time.Now(), testKVNO, testEType); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	k := &kdc{keytab: kt, listener: l}
+	go k.serve(t)
+	return k
+}
+
+func (k *kdc) close() {
+	k.listener.Close()
+}
+
+// config returns a kerberos configuration pointing clients at the KDC.
+func (k *kdc) config(t *testing.T) *config.Config {
+	cfg, err := config.NewFromString(fmt.Sprintf(`[libdefaults]
+  default_realm = %[1]s
+  udp_preference_limit = 1
+  default_tkt_enctypes = aes256-cts-hmac-sha1-96
+  default_tgs_enctypes = aes256-cts-hmac-sha1-96
+  permitted_enctypes = aes256-cts-hmac-sha1-96
+
+[realms]
+  %[1]s = {
+    kdc = %[2]s
+  }
+`, testRealm, k.listener.Addr()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func (k *kdc) serve(t *testing.T) {
+	for {
+		conn, err := k.listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			if err := k.handle(conn); err != nil {
+				t.Log("kdc:", err)
+			}
+		}()
+	}
+}
+
+func (k *kdc) handle(conn net.Conn) error {
+	var size [4]byte
+	if _, err := io.ReadFull(conn, size[:]); err != nil {
+		return err
+	}
+
+	req := make([]byte, binary.BigEndian.Uint32(size[:]))
+	if _, err := io.ReadFull(conn, req); err != nil {
+		return err
+	}
+
+	var res []byte
+	var err error
+
+	var asReq messages.ASReq
+	if asReq.Unmarshal(req) == nil {
+		res, err = k.handleAS(asReq)
+	} else {
+		var tgsReq messages.TGSReq
+		if err = tgsReq.Unmarshal(req); err == nil {
+			res, err = k.handleTGS(tgsReq)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	binary.BigEndian.PutUint32(size[:], uint32(len(res)))
+	_, err = conn.Write(append(size[:], res...))
+	return err
+}
+
+func (k *kdc) handleAS(req messages.ASReq) ([]byte, error) {
+	clientKey, _, err := k.keytab.GetEncryptionKey(req.ReqBody.CName, testRealm, testKVNO, testEType)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, encPart, err := k.issueTicket(req.ReqBody, req.ReqBody.CName)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := encPart.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	ed, err := crypto.GetEncryptedData(b, clientKey, keyusage.AS_REP_ENCPART, testKVNO)
+	if err != nil {
+		return nil, err
+	}
+
+	rep := messages.ASRep{KDCRepFields: messages.KDCRepFields{
+		PVNO:    5,
+		MsgType: msgtype.KRB_AS_REP,
+		CRealm:  testRealm,
+		CName:   req.ReqBody.CName,
+		Ticket:  ticket,
+		EncPart: ed,
+	}}
+	return rep.Marshal()
+}
+
+func (k *kdc) handleTGS(req messages.TGSReq) ([]byte, error) {
+	var apReq messages.APReq
+
+	for _, pa := range req.PAData {
+		if pa.PADataType == patype.PA_TGS_REQ {
+			if err := apReq.Unmarshal(pa.PADataValue); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := apReq.Ticket.DecryptEncPart(k.keytab, nil); err != nil {
+		return nil, err
+	}
+
+	tgt := apReq.Ticket.DecryptedEncPart
+
+	ticket, encPart, err := k.issueTicket(req.ReqBody, tgt.CName)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := encPart.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	ed, err := crypto.GetEncryptedData(b, tgt.Key, keyusage.TGS_REP_ENCPART_SESSION_KEY, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	rep := messages.TGSRep{KDCRepFields: messages.KDCRepFields{
+		PVNO:    5,
+		MsgType: msgtype.KRB_TGS_REP,
+		CRealm:  testRealm,
+		CName:   tgt.CName,
+		Ticket:  ticket,
+		EncPart: ed,
+	}}
+	return rep.Marshal()
+}
+
+func (k *kdc) issueTicket(body messages.KDCReqBody, cname types.PrincipalName) (messages.Ticket, messages.EncKDCRepPart, error) {
+	now := time.Now().UTC().Truncate(time.Second)
+	end := now.Add(time.Hour)
+	flags := types.NewKrbFlags()
+
+	ticket, sessionKey, err := messages.NewTicket(cname, testRealm, body.SName, testRealm, flags, k.keytab, testEType, testKVNO, now, now, end, end)
+	if err != nil {
+		return ticket, messages.EncKDCRepPart{}, err
+	}
+
+	encPart := messages.EncKDCRepPart{
+		Key:       sessionKey,
+		LastReqs:  []messages.LastReq{},
+		Nonce:     body.Nonce,
+		Flags:     flags,
+		AuthTime:  now,
+		StartTime: now,
+		EndTime:   end,
+		RenewTill: end,
+		SRealm:    testRealm,
+		SName:     body.SName,
+	}
+	return ticket, encPart, nil
+}
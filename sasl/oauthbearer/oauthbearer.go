@@ -0,0 +1,235 @@
+// Package oauthbearer implements the OAUTHBEARER SASL mechanism described in
+// RFC 7628, as supported by kafka since KIP-255.
+package oauthbearer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PerchSecurity/kafka-go/sasl"
+)
+
+const (
+	// The separator between the key/value pairs of the client message.
+	kvsep = "\x01"
+
+	// The name of the key carrying the bearer token, it is reserved and cannot
+	// be used as a SASL extension.
+	authKey = "auth"
+)
+
+var (
+	extensionKeyPattern   = regexp.MustCompile(`^[A-Za-z]+$`)
+	extensionValuePattern = regexp.MustCompile(`^[\x21-\x7E \t\r\n]+$`)
+)
+
+// Token represents an OAuth 2 bearer token used to authenticate with kafka
+// brokers.
+type Token struct {
+	// The value of the access token sent to the broker.
+	Value string
+
+	// The time at which the token expires. The zero value means that the
+	// token does not expire.
+	Expiry time.Time
+}
+
+// TokenSource is an interface implemented by types which supply the tokens
+// used to authenticate with kafka brokers.
+//
+// The Token method is called each time a connection is authenticated, or
+// re-authenticated after its session expired. Implementations which obtain
+// the tokens from an identity provider should cache them, for example by
+// using ReuseTokenSource.
+//
+// Implementations must be safe to use concurrently from multiple goroutines.
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// TokenSourceFunc is an implementation of the TokenSource interface which
+// calls the function to obtain tokens.
+type TokenSourceFunc func(context.Context) (*Token, error)
+
+// Token satisfies the TokenSource interface.
+func (f TokenSourceFunc) Token(ctx context.Context) (*Token, error) {
+	return f(ctx)
+}
+
+// StaticTokenSource returns a TokenSource which always returns the same token.
+func StaticTokenSource(token string) TokenSource {
+	t := &Token{Value: token}
+	return TokenSourceFunc(func(context.Context) (*Token, error) { return t, nil })
+}
+
+// ReuseTokenSource returns a TokenSource which caches the tokens returned by
+// src, and only obtains a new token when the cached one is about to expire
+// (within the given margin).
+func ReuseTokenSource(src TokenSource, margin time.Duration) TokenSource {
+	return &reuseTokenSource{src: src, margin: margin}
+}
+
+type reuseTokenSource struct {
+	src    TokenSource
+	margin time.Duration
+	mutex  sync.Mutex
+	token  *Token
+}
+
+func (r *reuseTokenSource) Token(ctx context.Context) (*Token, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if t := r.token; t != nil && (t.Expiry.IsZero() || time.Until(t.Expiry) > r.margin) {
+		return t, nil
+	}
+
+	t, err := r.src.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.token = t
+	return t, nil
+}
+
+// Mechanism implements the OAUTHBEARER SASL mechanism.
+type Mechanism struct {
+	// The source of tokens sent to the kafka brokers.
+	TokenSource TokenSource
+
+	// The optional authorization identity sent in the GS2 header of the
+	// client message.
+	AuthzID string
+
+	// SASL extensions sent to the broker with the token (KIP-342). Keys must
+	// be made of alphabetical characters and cannot be "auth".
+	//
+	// For example, Confluent Cloud uses the "logicalCluster" and
+	// "identityPoolId" extensions.
+	Extensions map[string]string
+}
+
+// Name satisfies the sasl.Mechanism interface.
+func (*Mechanism) Name() string {
+	return "OAUTHBEARER"
+}
+
+// Start satisfies the sasl.Mechanism interface.
+func (m *Mechanism) Start(ctx context.Context) (sasl.StateMachine, []byte, error) {
+	if m.TokenSource == nil {
+		return nil, nil, errors.New("oauthbearer: no token source configured")
+	}
+
+	token, err := m.TokenSource.Token(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("oauthbearer: obtaining token: %w", err)
+	}
+
+	if token == nil || token.Value == "" {
+		return nil, nil, errors.New("oauthbearer: the token source returned an empty token")
+	}
+
+	ir, err := m.initialResponse(token.Value)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &session{}, ir, nil
+}
+
+func (m *Mechanism) initialResponse(token string) ([]byte, error) {
+	keys := make([]string, 0, len(m.Extensions))
+
+	for key, value := range m.Extensions {
+		if key == authKey || !extensionKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("oauthbearer: invalid SASL extension key: %q", key)
+		}
+		if !extensionValuePattern.MatchString(value) {
+			return nil, fmt.Errorf("oauthbearer: invalid value for SASL extension %q", key)
+		}
+		keys = append(keys, key)
+	}
+
+	// Sort the extensions so the messages are deterministic.
+	sort.Strings(keys)
+
+	b := new(strings.Builder)
+	b.WriteString(gs2Header(m.AuthzID))
+	b.WriteString(kvsep)
+	b.WriteString(authKey + "=Bearer " + token + kvsep)
+
+	for _, key := range keys {
+		b.WriteString(key + "=" + m.Extensions[key] + kvsep)
+	}
+
+	b.WriteString(kvsep)
+	return []byte(b.String()), nil
+}
+
+// gs2Header returns the GS2 header of the client message (RFC 5801), the
+// mechanism never uses channel binding.
+func gs2Header(authzID string) string {
+	if authzID == "" {
+		return "n,,"
+	}
+	authzID = strings.ReplaceAll(authzID, "=", "=3D")
+	authzID = strings.ReplaceAll(authzID, ",", "=2C")
+	return "n,a=" + authzID + ","
+}
+
+type session struct {
+	// Error reported by the server, the client acknowledges it and waits for
+	// the server to fail the exchange.
+	err *Error
+}
+
+func (s *session) Next(ctx context.Context, challenge []byte) (bool, []byte, error) {
+	if s.err != nil {
+		return false, nil, s.err
+	}
+
+	// Kafka brokers respond with an empty message when the authentication
+	// succeeded, and with a JSON document describing the error otherwise.
+	if len(challenge) == 0 {
+		return true, nil, nil
+	}
+
+	e := &Error{}
+
+	if err := json.Unmarshal(challenge, e); err != nil {
+		return false, nil, fmt.Errorf("oauthbearer: unexpected server message: %q", challenge)
+	}
+
+	// The client must respond to an error message with a single %x01 control
+	// character, the server then fails the exchange (RFC 7628 section 3.2.3).
+	// Kafka brokers fail it with a SASLAuthenticationFailed error carrying the
+	// same details, servers which send another message instead see the
+	// exchange aborted with the parsed error.
+	s.err = e
+	return false, []byte(kvsep), nil
+}
+
+// Error is returned when the server rejected the token, it carries the details
+// that the server sent in its error message (RFC 7628 section 3.2.2).
+type Error struct {
+	Status              string `json:"status"`
+	Scope               string `json:"scope,omitempty"`
+	OpenIDConfiguration string `json:"openid-configuration,omitempty"`
+}
+
+// Error satisfies the error interface.
+func (e *Error) Error() string {
+	s := "oauthbearer: authentication failed with status " + e.Status
+	if e.Scope != "" {
+		s += " (scope: " + e.Scope + ")"
+	}
+	return s
+}
@@ -0,0 +1,132 @@
+package oauthbearer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMechanismInitialResponse(t *testing.T) {
+	tests := []struct {
+		scenario  string
+		mechanism *Mechanism
+		expected  string
+	}{
+		{
+			scenario:  "token only",
+			mechanism: &Mechanism{TokenSource: StaticTokenSource("abc")},
+			expected:  "n,,\x01auth=Bearer abc\x01\x01",
+		},
+		{
+			scenario:  "authorization identity is escaped",
+			mechanism: &Mechanism{TokenSource: StaticTokenSource("abc"), AuthzID: "user=a,b"},
+			expected:  "n,a=user=3Da=2Cb,\x01auth=Bearer abc\x01\x01",
+		},
+		{
+			scenario: "extensions are sorted",
+			mechanism: &Mechanism{
+				TokenSource: StaticTokenSource("abc"),
+				Extensions:  map[string]string{"logicalCluster": "lkc-123", "identityPoolId": "pool-1"},
+			},
+			expected: "n,,\x01auth=Bearer abc\x01identityPoolId=pool-1\x01logicalCluster=lkc-123\x01\x01",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			_, ir, err := test.mechanism.Start(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(ir) != test.expected {
+				t.Errorf("initial response mismatch:\nwant: %q\ngot:  %q", test.expected, ir)
+			}
+		})
+	}
+}
+
+func TestMechanismInvalidExtensions(t *testing.T) {
+	for _, extensions := range []map[string]string{
+		{"auth": "value"},
+		{"key1": "value"},
+		{"key": ""},
+		{"key": "value\x01"},
+	} {
+		m := &Mechanism{TokenSource: StaticTokenSource("abc"), Extensions: extensions}
+		if _, _, err := m.Start(context.Background()); err == nil {
+			t.Errorf("expected an error for extensions %q", extensions)
+		}
+	}
+}
+
+func TestSessionNext(t *testing.T) {
+	m := &Mechanism{TokenSource: StaticTokenSource("abc")}
+
+	sess, _, err := m.Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done, _, err := sess.Next(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !done {
+		t.Error("expected the authentication to be done after an empty server message")
+	}
+
+	sess, _, _ = m.Start(context.Background())
+	done, res, err := sess.Next(context.Background(), []byte(`{"status":"invalid_token","scope":"kafka"}`))
+	if err != nil || done {
+		t.Fatalf("expected the error message to be acknowledged, got done=%t err=%v", done, err)
+	}
+	if string(res) != "\x01" {
+		t.Errorf("expected the response to an error message to be %q, got %q", "\x01", res)
+	}
+
+	// The error is returned when the server fails the exchange, even with an
+	// empty message which would otherwise complete it.
+	done, _, err = sess.Next(context.Background(), nil)
+	if done {
+		t.Error("expected the authentication to fail after an error message")
+	}
+
+	var e *Error
+	if !errors.As(err, &e) {
+		t.Fatalf("expected an oauthbearer error, got %v", err)
+	}
+	if e.Status != "invalid_token" || e.Scope != "kafka" {
+		t.Errorf("unexpected error details: %+v", e)
+	}
+}
+
+func TestReuseTokenSource(t *testing.T) {
+	calls := 0
+	expiry := time.Now().Add(time.Hour)
+
+	src := ReuseTokenSource(TokenSourceFunc(func(context.Context) (*Token, error) {
+		calls++
+		return &Token{Value: "abc", Expiry: expiry}, nil
+	}), time.Minute)
+
+	for i := 0; i < 3; i++ {
+		if _, err := src.Token(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected the token to be obtained once, got %d calls", calls)
+	}
+
+	// The cached token is now within the expiry margin.
+	expiry = time.Now().Add(30 * time.Second)
+	src.(*reuseTokenSource).token.Expiry = expiry
+
+	if _, err := src.Token(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("expected the token to be refreshed, got %d calls", calls)
+	}
+}
@@ -0,0 +1,181 @@
+package scram
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/PerchSecurity/kafka-go/sasl"
+	"github.com/xdg-go/pbkdf2"
+)
+
+// Kafka brokers refuse SCRAM exchanges that use less iterations than this.
+const minIterations = 4096
+
+type tokenMechanism struct {
+	algo     Algorithm
+	tokenID  string
+	password []byte
+}
+
+type tokenSession struct {
+	mech        *tokenMechanism
+	nonce       string
+	clientFirst string
+	serverSig   []byte
+	step        int
+}
+
+// TokenMechanism returns a new sasl.Mechanism that authenticates to Kafka with
+// a delegation token, using SCRAM with the provided Algorithm.
+//
+// The tokenID and hmac are the values returned by the kafka broker when the
+// token was created (see kafka.(*Client).CreateDelegationToken). The mechanism
+// sends the tokenauth=true extension in the SCRAM exchange so the broker looks
+// up the credentials in its delegation tokens instead of its SCRAM users.
+//
+// Delegation tokens were added to Kafka in 1.1.0, and require the brokers to
+// have a SCRAM listener enabled.
+func TokenMechanism(algo Algorithm, tokenID string, hmac []byte) (sasl.Mechanism, error) {
+	if tokenID == "" {
+		return nil, errors.New("scram: the delegation token ID cannot be empty")
+	}
+	if len(hmac) == 0 {
+		return nil, errors.New("scram: the delegation token HMAC cannot be empty")
+	}
+	return &tokenMechanism{
+		algo:    algo,
+		tokenID: tokenID,
+		// Kafka uses the base64 representation of the token HMAC as password.
+		password: []byte(base64.StdEncoding.EncodeToString(hmac)),
+	}, nil
+}
+
+func (m *tokenMechanism) Name() string {
+	return m.algo.Name()
+}
+
+func (m *tokenMechanism) Start(ctx context.Context) (sasl.StateMachine, []byte, error) {
+	nonce, err := makeNonce()
+	if err != nil {
+		return nil, nil, err
+	}
+	s := &tokenSession{
+		mech:        m,
+		nonce:       nonce,
+		clientFirst: "n=" + encodeName(m.tokenID) + ",r=" + nonce + ",tokenauth=true",
+	}
+	return s, []byte("n,," + s.clientFirst), nil
+}
+
+func (s *tokenSession) Next(ctx context.Context, challenge []byte) (bool, []byte, error) {
+	s.step++
+	switch s.step {
+	case 1:
+		res, err := s.clientFinal(string(challenge))
+		return false, []byte(res), err
+	case 2:
+		return true, nil, s.validateServer(string(challenge))
+	default:
+		return false, nil, errors.New("scram: unexpected challenge after the end of the authentication")
+	}
+}
+
+func (s *tokenSession) clientFinal(serverFirst string) (string, error) {
+	attrs := parseAttributes(serverFirst)
+
+	nonce := attrs["r"]
+	if !strings.HasPrefix(nonce, s.nonce) {
+		return "", errors.New("scram: server nonce did not extend client nonce")
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(attrs["s"])
+	if err != nil {
+		return "", fmt.Errorf("scram: invalid salt in server message: %w", err)
+	}
+
+	iterations, err := strconv.Atoi(attrs["i"])
+	if err != nil {
+		return "", fmt.Errorf("scram: invalid iteration count in server message: %w", err)
+	}
+	if iterations < minIterations {
+		return "", fmt.Errorf("scram: server requested too few iterations (%d)", iterations)
+	}
+
+	// "biws" is the base64 encoding of the "n,," GS2 header.
+	withoutProof := "c=biws,r=" + nonce
+	authMessage := []byte(s.clientFirst + "," + serverFirst + "," + withoutProof)
+
+	saltedPassword := pbkdf2.Key(s.mech.password, salt, iterations, s.mech.algo.Hash().Size(), s.mech.algo.Hash)
+	clientKey := s.hmac(saltedPassword, []byte("Client Key"))
+	storedKey := s.hash(clientKey)
+	clientSig := s.hmac(storedKey, authMessage)
+
+	proof := make([]byte, len(clientKey))
+	for i := range proof {
+		proof[i] = clientKey[i] ^ clientSig[i]
+	}
+
+	serverKey := s.hmac(saltedPassword, []byte("Server Key"))
+	s.serverSig = s.hmac(serverKey, authMessage)
+
+	return withoutProof + ",p=" + base64.StdEncoding.EncodeToString(proof), nil
+}
+
+func (s *tokenSession) validateServer(serverFinal string) error {
+	attrs := parseAttributes(serverFinal)
+
+	if e, ok := attrs["e"]; ok {
+		return fmt.Errorf("scram: server error: %s", e)
+	}
+
+	verifier, err := base64.StdEncoding.DecodeString(attrs["v"])
+	if err != nil {
+		return fmt.Errorf("scram: invalid server signature: %w", err)
+	}
+
+	if !hmac.Equal(verifier, s.serverSig) {
+		return errors.New("scram: server validation failed")
+	}
+
+	return nil
+}
+
+func (s *tokenSession) hmac(key, data []byte) []byte {
+	mac := hmac.New(s.mech.algo.Hash, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+func (s *tokenSession) hash(data []byte) []byte {
+	h := s.mech.algo.Hash()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func makeNonce() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("scram: generating nonce: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+func encodeName(s string) string {
+	return strings.NewReplacer("=", "=3D", ",", "=2C").Replace(s)
+}
+
+func parseAttributes(msg string) map[string]string {
+	attrs := make(map[string]string)
+	for _, field := range strings.Split(msg, ",") {
+		if i := strings.IndexByte(field, '='); i > 0 {
+			attrs[field[:i]] = field[i+1:]
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,95 @@
+package scram
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/xdg-go/scram"
+)
+
+func TestTokenMechanism(t *testing.T) {
+	tests := []struct {
+		algo Algorithm
+		hash scram.HashGeneratorFcn
+	}{
+		{algo: SHA256, hash: scram.SHA256},
+		{algo: SHA512, hash: scram.SHA512},
+	}
+
+	const tokenID = "Vr3Xd1XtRKWe2PEZzPt0Jw"
+	hmac := []byte("0123456789abcdef")
+
+	for _, test := range tests {
+		t.Run(test.algo.Name(), func(t *testing.T) {
+			mech, err := TokenMechanism(test.algo, tokenID, hmac)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			password := mech.(*tokenMechanism).password
+			client, err := test.hash.NewClient(tokenID, string(password), "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			credentials := client.GetStoredCredentials(scram.KeyFactors{
+				Salt:  "salt",
+				Iters: minIterations,
+			})
+
+			server, err := test.hash.NewServer(func(username string) (scram.StoredCredentials, error) {
+				if username != tokenID {
+					t.Errorf("unexpected username: %q", username)
+				}
+				return credentials, nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := context.Background()
+			conv := server.NewConversation()
+
+			sess, clientFirst, err := mech.Start(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := "n,,n=" + tokenID + ","; string(clientFirst[:len(want)]) != want {
+				t.Fatalf("unexpected client first message: %q", clientFirst)
+			}
+			if !strings.HasSuffix(string(clientFirst), ",tokenauth=true") {
+				t.Fatalf("missing tokenauth extension in client first message: %q", clientFirst)
+			}
+
+			serverFirst, err := conv.Step(string(clientFirst))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			done, clientFinal, err := sess.Next(ctx, []byte(serverFirst))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if done {
+				t.Fatal("authentication completed too early")
+			}
+
+			serverFinal, err := conv.Step(string(clientFinal))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !conv.Valid() {
+				t.Fatal("the server rejected the client proof")
+			}
+
+			done, _, err = sess.Next(ctx, []byte(serverFinal))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !done {
+				t.Fatal("authentication did not complete")
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+//go:build go1.18
+// +build go1.18
+
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// BytesSerde is a Serializer and Deserializer of byte slices, which are used
+// as is for the keys or values of messages.
+type BytesSerde struct{}
+
+// Serialize satisfies the Serializer interface.
+func (BytesSerde) Serialize(ctx context.Context, topic string, v []byte) ([]byte, error) {
+	return v, nil
+}
+
+// Deserialize satisfies the Deserializer interface.
+func (BytesSerde) Deserialize(ctx context.Context, topic string, b []byte) ([]byte, error) {
+	return b, nil
+}
+
+// StringSerde is a Serializer and Deserializer of strings, the keys or values
+// of messages are the bytes of the strings.
+type StringSerde struct{}
+
+// Serialize satisfies the Serializer interface.
+func (StringSerde) Serialize(ctx context.Context, topic string, v string) ([]byte, error) {
+	return []byte(v), nil
+}
+
+// Deserialize satisfies the Deserializer interface.
+func (StringSerde) Deserialize(ctx context.Context, topic string, b []byte) (string, error) {
+	return string(b), nil
+}
+
+// JSONSerde is a Serializer and Deserializer of values of type T, encoded in
+// JSON with the encoding/json package.
+type JSONSerde[T any] struct{}
+
+// Serialize satisfies the Serializer interface.
+func (JSONSerde[T]) Serialize(ctx context.Context, topic string, v T) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Deserialize satisfies the Deserializer interface.
+func (JSONSerde[T]) Deserialize(ctx context.Context, topic string, b []byte) (T, error) {
+	var v T
+	err := json.Unmarshal(b, &v)
+	return v, err
+}
+
+var (
+	_ Serializer[[]byte]     = BytesSerde{}
+	_ Deserializer[[]byte]   = BytesSerde{}
+	_ Serializer[string]     = StringSerde{}
+	_ Deserializer[string]   = StringSerde{}
+	_ Serializer[struct{}]   = JSONSerde[struct{}]{}
+	_ Deserializer[struct{}] = JSONSerde[struct{}]{}
+)
@@ -0,0 +1,15 @@
+module github.com/PerchSecurity/kafka-go/serde/protobuf
+
+go 1.18
+
+require (
+	github.com/PerchSecurity/kafka-go v0.4.35
+	google.golang.org/protobuf v1.28.1
+)
+
+require (
+	github.com/klauspost/compress v1.15.9 // indirect
+	github.com/pierrec/lz4/v4 v4.1.15 // indirect
+)
+
+replace github.com/PerchSecurity/kafka-go => ../../
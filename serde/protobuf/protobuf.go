@@ -0,0 +1,72 @@
+// Package protobuf provides a serializer and deserializer of protocol buffer
+// messages for kafka.TypedWriter and kafka.TypedReader.
+//
+//	w := &kafka.TypedWriter[string, *pb.Event]{
+//		Writer:          &kafka.Writer{Addr: kafka.TCP("localhost:9092"), Topic: "events"},
+//		KeySerializer:   kafka.StringSerde{},
+//		ValueSerializer: protobuf.Serde[*pb.Event]{},
+//	}
+//
+// Messages are encoded in the protocol buffer binary format, without any
+// framing. To use the wire format of the Confluent schema registry, see the
+// serde/schemaregistry package instead.
+package protobuf
+
+import (
+	"context"
+	"errors"
+
+	"github.com/PerchSecurity/kafka-go"
+	"google.golang.org/protobuf/proto"
+)
+
+// Serde is a kafka.Serializer and kafka.Deserializer of protocol buffer
+// messages of type T, which is usually a pointer to a generated message type.
+type Serde[T proto.Message] struct {
+	// Options used to encode messages.
+	MarshalOptions proto.MarshalOptions
+
+	// Options used to decode messages.
+	UnmarshalOptions proto.UnmarshalOptions
+
+	// New returns the messages that values are decoded into.
+	//
+	// If nil, the messages are created from the type of T, which must then be
+	// a pointer to a generated message type. New is required when T is an
+	// interface type such as proto.Message.
+	New func() T
+}
+
+// Serialize satisfies the kafka.Serializer interface.
+func (s Serde[T]) Serialize(ctx context.Context, topic string, v T) ([]byte, error) {
+	return s.MarshalOptions.Marshal(v)
+}
+
+// Deserialize satisfies the kafka.Deserializer interface.
+func (s Serde[T]) Deserialize(ctx context.Context, topic string, b []byte) (T, error) {
+	var zero T
+	v, err := s.new()
+	if err != nil {
+		return zero, err
+	}
+	if err := s.UnmarshalOptions.Unmarshal(b, v); err != nil {
+		return zero, err
+	}
+	return v, nil
+}
+
+func (s Serde[T]) new() (T, error) {
+	if s.New != nil {
+		return s.New(), nil
+	}
+	var zero T
+	if any(zero) == nil {
+		return zero, errors.New("protobuf: Serde.New must be set to decode messages into an interface type")
+	}
+	return zero.ProtoReflect().Type().New().Interface().(T), nil
+}
+
+var (
+	_ kafka.Serializer[proto.Message]   = Serde[proto.Message]{}
+	_ kafka.Deserializer[proto.Message] = Serde[proto.Message]{}
+)
@@ -0,0 +1,69 @@
+package protobuf
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestSerde(t *testing.T) {
+	ctx := context.Background()
+
+	value, err := structpb.NewStruct(map[string]interface{}{
+		"name":  "A",
+		"count": 42,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := Serde[*structpb.Struct]{MarshalOptions: proto.MarshalOptions{Deterministic: true}}
+
+	b, err := s.Serialize(ctx, "topic", value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := s.Deserialize(ctx, "topic", b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(v, value) {
+		t.Errorf("message mismatch: %v != %v", v, value)
+	}
+}
+
+func TestSerdeInvalid(t *testing.T) {
+	s := Serde[*wrapperspb.StringValue]{}
+
+	if _, err := s.Deserialize(context.Background(), "topic", []byte{0xFF}); err == nil {
+		t.Error("expected an error decoding an invalid message")
+	}
+}
+
+func TestSerdeInterface(t *testing.T) {
+	ctx := context.Background()
+	value := wrapperspb.String("A")
+
+	s := Serde[proto.Message]{New: func() proto.Message { return &wrapperspb.StringValue{} }}
+
+	b, err := s.Serialize(ctx, "topic", value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := s.Deserialize(ctx, "topic", b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(v, value) {
+		t.Errorf("message mismatch: %v != %v", v, value)
+	}
+
+	if _, err := (Serde[proto.Message]{}).Deserialize(ctx, "topic", b); err == nil {
+		t.Error("expected an error decoding into an interface type without New")
+	}
+}
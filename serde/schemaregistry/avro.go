@@ -0,0 +1,127 @@
+package schemaregistry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/PerchSecurity/kafka-go"
+	"github.com/hamba/avro/v2"
+)
+
+// AvroSerde is a kafka.Serializer and kafka.Deserializer of values of type T
+// encoded in Avro, with the github.com/hamba/avro package.
+//
+// Values are decoded with the schema they were written with, resolved against
+// the schema of the serde when it is set, so messages written with older or
+// newer compatible schemas can be read.
+//
+// AvroSerde values must not be copied after first use.
+type AvroSerde[T any] struct {
+	Config
+
+	// The Avro schema of values, in JSON. It is required to serialize values.
+	Schema string
+
+	once     sync.Once
+	parsed   avro.Schema
+	parseErr error
+	resolved sync.Map // schema ID => avro.Schema
+	compat   *avro.SchemaCompatibility
+}
+
+// Serialize satisfies the kafka.Serializer interface.
+func (s *AvroSerde[T]) Serialize(ctx context.Context, topic string, v T) ([]byte, error) {
+	schema, err := s.schema()
+	if err != nil {
+		return nil, err
+	}
+	if schema == nil {
+		return nil, fmt.Errorf("serializing avro values requires a schema")
+	}
+
+	var recordName string
+	if named, ok := schema.(avro.NamedSchema); ok {
+		recordName = named.FullName()
+	}
+
+	id, err := s.schemaID(ctx, topic, recordName, Schema{Type: Avro, Schema: s.Schema})
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := avro.Marshal(schema, v)
+	if err != nil {
+		return nil, err
+	}
+	return append(appendHeader(make([]byte, 0, headerSize+len(b)), id), b...), nil
+}
+
+// Deserialize satisfies the kafka.Deserializer interface.
+func (s *AvroSerde[T]) Deserialize(ctx context.Context, topic string, b []byte) (T, error) {
+	var v T
+
+	id, payload, err := parseHeader(b)
+	if err != nil {
+		return v, err
+	}
+
+	schema, err := s.writerSchema(ctx, id)
+	if err != nil {
+		return v, err
+	}
+
+	err = avro.Unmarshal(schema, payload, &v)
+	return v, err
+}
+
+func (s *AvroSerde[T]) schema() (avro.Schema, error) {
+	s.once.Do(func() {
+		s.compat = avro.NewSchemaCompatibility()
+		if s.Schema != "" {
+			s.parsed, s.parseErr = avro.Parse(s.Schema)
+		}
+	})
+	return s.parsed, s.parseErr
+}
+
+// writerSchema returns the schema used to decode messages written with the
+// schema of the given ID.
+func (s *AvroSerde[T]) writerSchema(ctx context.Context, id int) (avro.Schema, error) {
+	if schema, ok := s.resolved.Load(id); ok {
+		return schema.(avro.Schema), nil
+	}
+
+	reader, err := s.schema()
+	if err != nil {
+		return nil, err
+	}
+
+	def, err := s.Client.SchemaByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkType(def, Avro); err != nil {
+		return nil, err
+	}
+
+	writer, err := avro.Parse(def.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("parsing avro schema %d: %w", id, err)
+	}
+
+	schema := writer
+	if reader != nil && reader.Fingerprint() != writer.Fingerprint() {
+		if schema, err = s.compat.Resolve(reader, writer); err != nil {
+			return nil, fmt.Errorf("resolving avro schema %d: %w", id, err)
+		}
+	}
+
+	s.resolved.Store(id, schema)
+	return schema, nil
+}
+
+var (
+	_ kafka.Serializer[struct{}]   = (*AvroSerde[struct{}])(nil)
+	_ kafka.Deserializer[struct{}] = (*AvroSerde[struct{}])(nil)
+)
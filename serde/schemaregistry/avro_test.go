@@ -0,0 +1,228 @@
+package schemaregistry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PerchSecurity/kafka-go"
+	"github.com/PerchSecurity/kafka-go/kafkatest"
+)
+
+const userSchemaV1 = `{
+	"type": "record",
+	"name": "User",
+	"namespace": "com.example",
+	"fields": [
+		{"name": "name", "type": "string"},
+		{"name": "age", "type": "int"}
+	]
+}`
+
+const userSchemaV2 = `{
+	"type": "record",
+	"name": "User",
+	"namespace": "com.example",
+	"fields": [
+		{"name": "name", "type": "string"},
+		{"name": "age", "type": "int"},
+		{"name": "email", "type": "string", "default": "unknown"}
+	]
+}`
+
+type userV1 struct {
+	Name string `avro:"name"`
+	Age  int    `avro:"age"`
+}
+
+type userV2 struct {
+	Name  string `avro:"name"`
+	Age   int    `avro:"age"`
+	Email string `avro:"email"`
+}
+
+func TestAvroSerde(t *testing.T) {
+	ctx := context.Background()
+	r, client := newRegistry(t)
+
+	s := &AvroSerde[userV1]{
+		Config: Config{Client: client},
+		Schema: userSchemaV1,
+	}
+
+	b, err := s.Serialize(ctx, "users", userV1{Name: "Alice", Age: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(b, []byte{0, 0, 0, 0, 1}) {
+		t.Errorf("expected the message to start with the ID of the schema, got %v", b)
+	}
+	if schemas := r.subject("users-value"); len(schemas) != 1 {
+		t.Errorf("expected the schema to be registered under users-value, got %+v", schemas)
+	}
+
+	// Deserializers with no schema decode values with the writer schema.
+	v, err := (&AvroSerde[userV1]{Config: Config{Client: client}}).Deserialize(ctx, "users", b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != (userV1{Name: "Alice", Age: 42}) {
+		t.Errorf("value mismatch: %+v", v)
+	}
+
+	if _, err := s.Deserialize(ctx, "users", []byte("{}")); !errors.Is(err, ErrInvalidWireFormat) {
+		t.Errorf("expected an invalid wire format error, got %v", err)
+	}
+}
+
+func TestAvroSerdeSchemaEvolution(t *testing.T) {
+	ctx := context.Background()
+	_, client := newRegistry(t)
+
+	v1 := &AvroSerde[userV1]{Config: Config{Client: client}, Schema: userSchemaV1}
+	v2 := &AvroSerde[userV2]{Config: Config{Client: client}, Schema: userSchemaV2}
+
+	b, err := v1.Serialize(ctx, "users", userV1{Name: "Alice", Age: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u2, err := v2.Deserialize(ctx, "users", b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u2 != (userV2{Name: "Alice", Age: 42, Email: "unknown"}) {
+		t.Errorf("expected the default value of the new field, got %+v", u2)
+	}
+
+	b, err = v2.Serialize(ctx, "users", userV2{Name: "Bob", Age: 24, Email: "bob@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b[4] != 2 {
+		t.Errorf("expected the new schema to have ID 2, got %d", b[4])
+	}
+	u1, err := v1.Deserialize(ctx, "users", b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u1 != (userV1{Name: "Bob", Age: 24}) {
+		t.Errorf("expected the new field to be skipped, got %+v", u1)
+	}
+}
+
+func TestAvroSerdeSubjectNameStrategies(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		strategy SubjectNameStrategy
+		isKey    bool
+		subject  string
+	}{
+		{strategy: nil, isKey: true, subject: "users-key"},
+		{strategy: RecordNameStrategy, subject: "com.example.User"},
+		{strategy: TopicRecordNameStrategy, subject: "users-com.example.User"},
+	}
+
+	for _, test := range tests {
+		r, client := newRegistry(t)
+
+		s := &AvroSerde[userV1]{
+			Config: Config{
+				Client:              client,
+				IsKey:               test.isKey,
+				SubjectNameStrategy: test.strategy,
+			},
+			Schema: userSchemaV1,
+		}
+
+		if _, err := s.Serialize(ctx, "users", userV1{}); err != nil {
+			t.Fatal(err)
+		}
+		if schemas := r.subject(test.subject); len(schemas) != 1 {
+			t.Errorf("expected the schema to be registered under %s", test.subject)
+		}
+	}
+}
+
+func TestAvroSerdeDisableAutoRegister(t *testing.T) {
+	ctx := context.Background()
+	_, client := newRegistry(t)
+
+	s := &AvroSerde[userV1]{
+		Config: Config{Client: client, DisableAutoRegister: true},
+		Schema: userSchemaV1,
+	}
+
+	if _, err := s.Serialize(ctx, "users", userV1{}); !isRegistryError(err, 40401) {
+		t.Fatalf("expected a subject not found error, got %v", err)
+	}
+
+	if _, err := client.Register(ctx, "users-value", Schema{Type: Avro, Schema: userSchemaV1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Serialize(ctx, "users", userV1{}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAvroSerdeTypedWriterReader(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, client := newRegistry(t)
+
+	cluster, err := kafkatest.NewCluster(kafkatest.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cluster.Close()
+
+	if err := cluster.CreateTopic("users", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	serde := &AvroSerde[userV1]{
+		Config: Config{Client: client},
+		Schema: userSchemaV1,
+	}
+
+	w := &kafka.TypedWriter[string, userV1]{
+		Writer: &kafka.Writer{
+			Addr:         cluster.Addr(),
+			Topic:        "users",
+			RequiredAcks: kafka.RequireOne,
+			BatchTimeout: time.Millisecond,
+		},
+		KeySerializer:   kafka.StringSerde{},
+		ValueSerializer: serde,
+	}
+	defer w.Close()
+
+	if err := w.WriteMessages(ctx, kafka.TypedMessage[string, userV1]{
+		Key:   "alice",
+		Value: userV1{Name: "Alice", Age: 42},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &kafka.TypedReader[string, userV1]{
+		Reader: kafka.NewReader(kafka.ReaderConfig{
+			Brokers: cluster.Brokers(),
+			Topic:   "users",
+			MaxWait: 10 * time.Millisecond,
+		}),
+		KeyDeserializer:   kafka.StringSerde{},
+		ValueDeserializer: serde,
+	}
+	defer r.Close()
+
+	m, err := r.ReadMessage(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Key != "alice" || m.Value != (userV1{Name: "Alice", Age: 42}) {
+		t.Errorf("unexpected message: %+v", m)
+	}
+}
@@ -0,0 +1,254 @@
+// Package schemaregistry provides serializers and deserializers for
+// kafka.TypedWriter and kafka.TypedReader which encode messages in the wire
+// format of the Confluent Schema Registry: a zero magic byte and the 4 bytes
+// big-endian ID of the schema in the registry, followed by the payload.
+//
+// Avro, Protobuf, and JSON Schema messages are supported:
+//
+//	client := &schemaregistry.Client{URL: "http://localhost:8081"}
+//
+//	w := &kafka.TypedWriter[string, Event]{
+//		Writer:        &kafka.Writer{Addr: kafka.TCP("localhost:9092"), Topic: "events"},
+//		KeySerializer: kafka.StringSerde{},
+//		ValueSerializer: &schemaregistry.AvroSerde[Event]{
+//			Config: schemaregistry.Config{Client: client},
+//			Schema: eventSchema,
+//		},
+//	}
+//
+// Serializers register their schema under the subject given by their subject
+// name strategy, the schema IDs are cached by the client so the registry is
+// only queried once per subject and schema.
+package schemaregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SchemaType represents the format of schemas stored in the registry.
+type SchemaType string
+
+const (
+	Avro       SchemaType = "AVRO"
+	Protobuf   SchemaType = "PROTOBUF"
+	JSONSchema SchemaType = "JSON"
+)
+
+// Schema represents a schema stored in the registry.
+type Schema struct {
+	// Format of the schema. The registry omits it for Avro schemas, an empty
+	// value is equivalent to Avro.
+	Type SchemaType `json:"schemaType,omitempty"`
+
+	// Definition of the schema.
+	Schema string `json:"schema"`
+
+	// Other schemas that the schema refers to, for example the imports of
+	// Protobuf schemas.
+	References []Reference `json:"references,omitempty"`
+}
+
+// Reference represents a reference from a schema to another schema registered
+// under a subject.
+type Reference struct {
+	// Name used by the schema to refer to the other schema, for example the
+	// path of a Protobuf import.
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+}
+
+// Error is returned by Client methods when the registry responds with an
+// error.
+type Error struct {
+	// HTTP status code of the response.
+	StatusCode int `json:"-"`
+
+	// Error code and message reported by the registry, for example 40401 when
+	// a subject was not found.
+	Code    int    `json:"error_code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("schema registry error %d: %s", e.Code, e.Message)
+}
+
+// Client is a client of the Schema Registry HTTP API.
+//
+// The client caches the schemas and IDs returned by the registry, schemas
+// being immutable once registered. Client values are safe to use
+// concurrently from multiple goroutines.
+type Client struct {
+	// Base URL of the registry, for example http://localhost:8081.
+	URL string
+
+	// Optional credentials sent with basic authentication.
+	Username string
+	Password string
+
+	// The HTTP client used to send requests to the registry.
+	//
+	// Default: http.DefaultClient
+	HTTPClient *http.Client
+
+	mutex      sync.Mutex
+	registered map[subjectSchema]int
+	lookups    map[subjectSchema]lookup
+	schemas    map[int]Schema
+}
+
+type subjectSchema struct {
+	subject string
+	schema  string
+}
+
+type lookup struct {
+	id      int
+	version int
+}
+
+func schemaKey(subject string, schema Schema) subjectSchema {
+	// References are part of the schema identity, encoding the whole schema
+	// gives a unique key.
+	b, _ := json.Marshal(schema)
+	return subjectSchema{subject: subject, schema: string(b)}
+}
+
+// Register registers schema under subject, and returns its ID. Registering a
+// schema which already exists returns the ID of the existing schema.
+func (c *Client) Register(ctx context.Context, subject string, schema Schema) (int, error) {
+	key := schemaKey(subject, schema)
+
+	c.mutex.Lock()
+	id, ok := c.registered[key]
+	c.mutex.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	var res struct {
+		ID int `json:"id"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/subjects/"+url.PathEscape(subject)+"/versions", schema, &res); err != nil {
+		return 0, err
+	}
+
+	c.mutex.Lock()
+	if c.registered == nil {
+		c.registered = make(map[subjectSchema]int)
+	}
+	c.registered[key] = res.ID
+	c.mutex.Unlock()
+	return res.ID, nil
+}
+
+// Lookup returns the ID and version of schema under subject. The method
+// returns an *Error with code 40401 or 40403 if the subject or schema do not
+// exist.
+func (c *Client) Lookup(ctx context.Context, subject string, schema Schema) (id, version int, err error) {
+	key := schemaKey(subject, schema)
+
+	c.mutex.Lock()
+	l, ok := c.lookups[key]
+	c.mutex.Unlock()
+	if ok {
+		return l.id, l.version, nil
+	}
+
+	var res struct {
+		ID      int `json:"id"`
+		Version int `json:"version"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/subjects/"+url.PathEscape(subject), schema, &res); err != nil {
+		return 0, 0, err
+	}
+
+	c.mutex.Lock()
+	if c.lookups == nil {
+		c.lookups = make(map[subjectSchema]lookup)
+	}
+	c.lookups[key] = lookup{id: res.ID, version: res.Version}
+	c.mutex.Unlock()
+	return res.ID, res.Version, nil
+}
+
+// SchemaByID returns the schema with the given ID.
+func (c *Client) SchemaByID(ctx context.Context, id int) (Schema, error) {
+	c.mutex.Lock()
+	schema, ok := c.schemas[id]
+	c.mutex.Unlock()
+	if ok {
+		return schema, nil
+	}
+
+	if err := c.do(ctx, http.MethodGet, "/schemas/ids/"+strconv.Itoa(id), nil, &schema); err != nil {
+		return Schema{}, err
+	}
+
+	c.mutex.Lock()
+	if c.schemas == nil {
+		c.schemas = make(map[int]Schema)
+	}
+	c.schemas[id] = schema
+	c.mutex.Unlock()
+	return schema, nil
+}
+
+const contentType = "application/vnd.schemaregistry.v1+json"
+
+func (c *Client) do(ctx context.Context, method, path string, body, result interface{}) error {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.URL, "/")+path, r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", contentType)
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.Username != "" || c.Password != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		e := &Error{StatusCode: res.StatusCode}
+		if err := json.NewDecoder(res.Body).Decode(e); err != nil || e.Message == "" {
+			e.Message = res.Status
+		}
+		return e
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(result); err != nil {
+		return fmt.Errorf("decoding schema registry response to %s %s: %w", method, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// registry is an in-memory implementation of the parts of the schema
+// registry API used by the package.
+type registry struct {
+	mutex    sync.Mutex
+	schemas  []Schema         // schema ID - 1 => schema
+	subjects map[string][]int // subject => schema IDs of each version
+	requests int
+}
+
+func newRegistry(t *testing.T) (*registry, *Client) {
+	r := &registry{subjects: make(map[string][]int)}
+	s := httptest.NewServer(r)
+	t.Cleanup(s.Close)
+	return r, &Client{URL: s.URL}
+}
+
+func (r *registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.requests++
+
+	var path []string
+	for _, s := range strings.Split(strings.Trim(req.URL.EscapedPath(), "/"), "/") {
+		s, _ = url.PathUnescape(s)
+		path = append(path, s)
+	}
+
+	switch {
+	case req.Method == http.MethodGet && len(path) == 3 && path[0] == "schemas" && path[1] == "ids":
+		id, _ := strconv.Atoi(path[2])
+		if id < 1 || id > len(r.schemas) {
+			writeError(w, http.StatusNotFound, 40403, "Schema not found")
+			return
+		}
+		writeJSON(w, r.schemas[id-1])
+
+	case req.Method == http.MethodPost && len(path) == 3 && path[0] == "subjects" && path[2] == "versions":
+		schema, ok := readSchema(w, req)
+		if !ok {
+			return
+		}
+		id, _ := r.find(path[1], schema)
+		if id == 0 {
+			id = r.id(schema)
+			r.subjects[path[1]] = append(r.subjects[path[1]], id)
+		}
+		writeJSON(w, map[string]int{"id": id})
+
+	case req.Method == http.MethodPost && len(path) == 2 && path[0] == "subjects":
+		schema, ok := readSchema(w, req)
+		if !ok {
+			return
+		}
+		if _, ok := r.subjects[path[1]]; !ok {
+			writeError(w, http.StatusNotFound, 40401, "Subject not found")
+			return
+		}
+		id, version := r.find(path[1], schema)
+		if id == 0 {
+			writeError(w, http.StatusNotFound, 40403, "Schema not found")
+			return
+		}
+		writeJSON(w, map[string]interface{}{"subject": path[1], "id": id, "version": version})
+
+	default:
+		writeError(w, http.StatusNotFound, 404, "Not found")
+	}
+}
+
+func (r *registry) find(subject string, schema Schema) (id, version int) {
+	for i, id := range r.subjects[subject] {
+		if reflect.DeepEqual(r.schemas[id-1], schema) {
+			return id, i + 1
+		}
+	}
+	return 0, 0
+}
+
+func (r *registry) id(schema Schema) int {
+	for i, s := range r.schemas {
+		if reflect.DeepEqual(s, schema) {
+			return i + 1
+		}
+	}
+	r.schemas = append(r.schemas, schema)
+	return len(r.schemas)
+}
+
+func (r *registry) subject(name string) []Schema {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var schemas []Schema
+	for _, id := range r.subjects[name] {
+		schemas = append(schemas, r.schemas[id-1])
+	}
+	return schemas
+}
+
+func (r *registry) requestCount() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.requests
+}
+
+func readSchema(w http.ResponseWriter, req *http.Request) (Schema, bool) {
+	var schema Schema
+	if err := json.NewDecoder(req.Body).Decode(&schema); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, 42201, err.Error())
+		return schema, false
+	}
+	// The registry normalizes Avro schemas to have no type.
+	if schema.Type == Avro {
+		schema.Type = ""
+	}
+	return schema, true
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", contentType)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status, code int, message string) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(&Error{Code: code, Message: message})
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	r, client := newRegistry(t)
+
+	schema := Schema{Type: JSONSchema, Schema: `{"type":"string"}`}
+
+	if _, _, err := client.Lookup(ctx, "A", schema); !isRegistryError(err, 40401) {
+		t.Errorf("expected a subject not found error, got %v", err)
+	}
+
+	id, err := client.Register(ctx, "A", schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 1 {
+		t.Errorf("expected schema ID 1, got %d", id)
+	}
+
+	// Registering the same schema is served from the cache.
+	n := r.requestCount()
+	if id, err := client.Register(ctx, "A", schema); err != nil || id != 1 {
+		t.Errorf("unexpected result of registering the schema again: %d, %v", id, err)
+	}
+	if r.requestCount() != n {
+		t.Error("expected the schema ID to be cached")
+	}
+
+	if id, version, err := client.Lookup(ctx, "A", schema); err != nil || id != 1 || version != 1 {
+		t.Errorf("unexpected lookup result: %d, %d, %v", id, version, err)
+	}
+	if _, _, err := client.Lookup(ctx, "A", Schema{Type: JSONSchema, Schema: `{}`}); !isRegistryError(err, 40403) {
+		t.Errorf("expected a schema not found error, got %v", err)
+	}
+
+	got, err := client.SchemaByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, schema) {
+		t.Errorf("schema mismatch: %+v != %+v", got, schema)
+	}
+	if _, err := client.SchemaByID(ctx, 42); !isRegistryError(err, 40403) {
+		t.Errorf("expected a schema not found error, got %v", err)
+	}
+}
+
+func TestClientBasicAuth(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if user, pass, ok := req.BasicAuth(); !ok || user != "user" || pass != "pass" {
+			writeError(w, http.StatusUnauthorized, 40101, "Unauthorized")
+			return
+		}
+		writeJSON(w, Schema{Schema: `"string"`})
+	}))
+	defer s.Close()
+
+	client := &Client{URL: s.URL + "/", Username: "user", Password: "pass"}
+	if _, err := client.SchemaByID(context.Background(), 1); err != nil {
+		t.Error(err)
+	}
+
+	client = &Client{URL: s.URL}
+	if _, err := client.SchemaByID(context.Background(), 1); !isRegistryError(err, 40101) {
+		t.Errorf("expected an unauthorized error, got %v", err)
+	}
+}
+
+func TestSubjectNameStrategies(t *testing.T) {
+	tests := []struct {
+		strategy   SubjectNameStrategy
+		isKey      bool
+		recordName string
+		subject    string
+	}{
+		{strategy: TopicNameStrategy, subject: "topic-value"},
+		{strategy: TopicNameStrategy, isKey: true, subject: "topic-key"},
+		{strategy: RecordNameStrategy, recordName: "com.example.Event", subject: "com.example.Event"},
+		{strategy: TopicRecordNameStrategy, recordName: "com.example.Event", subject: "topic-com.example.Event"},
+		{strategy: RecordNameStrategy},
+		{strategy: TopicRecordNameStrategy},
+	}
+
+	for _, test := range tests {
+		subject, err := test.strategy("topic", test.isKey, test.recordName)
+		if test.subject == "" {
+			if err == nil {
+				t.Errorf("expected an error for an unnamed schema, got %q", subject)
+			}
+		} else if subject != test.subject {
+			t.Errorf("subject mismatch: %q != %q (%v)", subject, test.subject, err)
+		}
+	}
+}
+
+func isRegistryError(err error, code int) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Code == code
+}
@@ -0,0 +1,20 @@
+module github.com/PerchSecurity/kafka-go/serde/schemaregistry
+
+go 1.20
+
+require (
+	github.com/PerchSecurity/kafka-go v0.4.35
+	github.com/hamba/avro/v2 v2.20.1
+	google.golang.org/protobuf v1.28.1
+)
+
+require (
+	github.com/json-iterator/go v1.1.12 // indirect
+	github.com/klauspost/compress v1.17.9 // indirect
+	github.com/mitchellh/mapstructure v1.5.0 // indirect
+	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
+	github.com/modern-go/reflect2 v1.0.2 // indirect
+	github.com/pierrec/lz4/v4 v4.1.15 // indirect
+)
+
+replace github.com/PerchSecurity/kafka-go => ../../
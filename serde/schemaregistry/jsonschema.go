@@ -0,0 +1,77 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/PerchSecurity/kafka-go"
+)
+
+// JSONSchemaSerde is a kafka.Serializer and kafka.Deserializer of values of
+// type T encoded in JSON with the encoding/json package, and registered with a
+// JSON schema.
+//
+// The serde does not validate values against the schema, programs which need
+// validation can wrap it with a validating serializer.
+//
+// JSONSchemaSerde values must not be copied after first use.
+type JSONSchemaSerde[T any] struct {
+	Config
+
+	// The JSON schema of values. It is required to serialize values, its
+	// title is used as record name by subject name strategies.
+	Schema string
+
+	once     sync.Once
+	title    string
+	parseErr error
+}
+
+// Serialize satisfies the kafka.Serializer interface.
+func (s *JSONSchemaSerde[T]) Serialize(ctx context.Context, topic string, v T) ([]byte, error) {
+	title, err := s.recordName()
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.schemaID(ctx, topic, title, Schema{Type: JSONSchema, Schema: s.Schema})
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append(appendHeader(make([]byte, 0, headerSize+len(b)), id), b...), nil
+}
+
+// Deserialize satisfies the kafka.Deserializer interface.
+func (s *JSONSchemaSerde[T]) Deserialize(ctx context.Context, topic string, b []byte) (T, error) {
+	var v T
+
+	_, payload, err := parseHeader(b)
+	if err != nil {
+		return v, err
+	}
+
+	err = json.Unmarshal(payload, &v)
+	return v, err
+}
+
+func (s *JSONSchemaSerde[T]) recordName() (string, error) {
+	s.once.Do(func() {
+		var schema struct {
+			Title string `json:"title"`
+		}
+		s.parseErr = json.Unmarshal([]byte(s.Schema), &schema)
+		s.title = schema.Title
+	})
+	return s.title, s.parseErr
+}
+
+var (
+	_ kafka.Serializer[struct{}]   = (*JSONSchemaSerde[struct{}])(nil)
+	_ kafka.Deserializer[struct{}] = (*JSONSchemaSerde[struct{}])(nil)
+)
@@ -0,0 +1,52 @@
+package schemaregistry
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+const userJSONSchema = `{
+	"title": "User",
+	"type": "object",
+	"properties": {
+		"name": {"type": "string"},
+		"age": {"type": "integer"}
+	}
+}`
+
+type jsonUser struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func TestJSONSchemaSerde(t *testing.T) {
+	ctx := context.Background()
+	r, client := newRegistry(t)
+
+	s := &JSONSchemaSerde[jsonUser]{
+		Config: Config{Client: client, SubjectNameStrategy: TopicRecordNameStrategy},
+		Schema: userJSONSchema,
+	}
+
+	b, err := s.Serialize(ctx, "users", jsonUser{Name: "Alice", Age: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte("\x00\x00\x00\x00\x01{\"name\":\"Alice\",\"age\":42}"); !bytes.Equal(b, want) {
+		t.Errorf("unexpected message: %q", b)
+	}
+
+	schemas := r.subject("users-User")
+	if len(schemas) != 1 || schemas[0].Type != JSONSchema || schemas[0].Schema != userJSONSchema {
+		t.Errorf("expected the schema to be registered under users-User, got %+v", schemas)
+	}
+
+	v, err := s.Deserialize(ctx, "users", b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != (jsonUser{Name: "Alice", Age: 42}) {
+		t.Errorf("value mismatch: %+v", v)
+	}
+}
@@ -0,0 +1,209 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/PerchSecurity/kafka-go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ProtobufSerde is a kafka.Serializer and kafka.Deserializer of Protobuf
+// messages of type T, which is usually a pointer to a generated message type.
+//
+// The schema registered for a message is the file that the message type is
+// declared in, sent to the registry as a base64-encoded file descriptor. The
+// files imported by the schema are registered as references under subjects
+// named after their path, except the well-known types of the google/protobuf
+// directory which the registry provides.
+//
+// After the schema ID, the wire format of Protobuf messages has the indexes
+// leading to the message type in the file, as encoded by the Confluent
+// serializers. Deserializing skips them and decodes messages as type T.
+//
+// ProtobufSerde values must not be copied after first use.
+type ProtobufSerde[T proto.Message] struct {
+	Config
+
+	// New returns the messages that values are decoded into.
+	//
+	// If nil, the messages are created from the type of T, which must then be
+	// a pointer to a generated message type. New is required when T is an
+	// interface type such as proto.Message.
+	New func() T
+
+	schemas sync.Map // file path => Schema
+}
+
+// Serialize satisfies the kafka.Serializer interface.
+func (s *ProtobufSerde[T]) Serialize(ctx context.Context, topic string, v T) ([]byte, error) {
+	desc := v.ProtoReflect().Descriptor()
+
+	schema, err := s.schema(ctx, desc.ParentFile())
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.schemaID(ctx, topic, string(desc.FullName()), schema)
+	if err != nil {
+		return nil, err
+	}
+
+	b := appendHeader(nil, id)
+	b = appendMessageIndexes(b, desc)
+	return proto.MarshalOptions{}.MarshalAppend(b, v)
+}
+
+// Deserialize satisfies the kafka.Deserializer interface.
+func (s *ProtobufSerde[T]) Deserialize(ctx context.Context, topic string, b []byte) (T, error) {
+	var zero T
+
+	_, payload, err := parseHeader(b)
+	if err != nil {
+		return zero, err
+	}
+	if payload, err = skipMessageIndexes(payload); err != nil {
+		return zero, err
+	}
+
+	v, err := s.new()
+	if err != nil {
+		return zero, err
+	}
+	if err := proto.Unmarshal(payload, v); err != nil {
+		return zero, err
+	}
+	return v, nil
+}
+
+func (s *ProtobufSerde[T]) new() (T, error) {
+	if s.New != nil {
+		return s.New(), nil
+	}
+	var zero T
+	if any(zero) == nil {
+		return zero, errors.New("schemaregistry: ProtobufSerde.New must be set to decode messages into an interface type")
+	}
+	return zero.ProtoReflect().Type().New().Interface().(T), nil
+}
+
+func (s *ProtobufSerde[T]) schema(ctx context.Context, file protoreflect.FileDescriptor) (Schema, error) {
+	if schema, ok := s.schemas.Load(file.Path()); ok {
+		return schema.(Schema), nil
+	}
+
+	schema, err := s.fileSchema(ctx, file)
+	if err != nil {
+		return Schema{}, err
+	}
+
+	s.schemas.Store(file.Path(), schema)
+	return schema, nil
+}
+
+func (s *ProtobufSerde[T]) fileSchema(ctx context.Context, file protoreflect.FileDescriptor) (Schema, error) {
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(protodesc.ToFileDescriptorProto(file))
+	if err != nil {
+		return Schema{}, err
+	}
+
+	schema := Schema{
+		Type:   Protobuf,
+		Schema: base64.StdEncoding.EncodeToString(b),
+	}
+
+	imports := file.Imports()
+	for i := 0; i < imports.Len(); i++ {
+		dep := imports.Get(i).FileDescriptor
+		path := dep.Path()
+		if strings.HasPrefix(path, "google/protobuf/") {
+			continue
+		}
+
+		version, err := s.registerReference(ctx, dep)
+		if err != nil {
+			return Schema{}, fmt.Errorf("registering %s: %w", path, err)
+		}
+
+		schema.References = append(schema.References, Reference{
+			Name:    path,
+			Subject: path,
+			Version: version,
+		})
+	}
+
+	return schema, nil
+}
+
+// registerReference registers the schema of a file imported by another one,
+// and returns its version.
+func (s *ProtobufSerde[T]) registerReference(ctx context.Context, file protoreflect.FileDescriptor) (int, error) {
+	schema, err := s.fileSchema(ctx, file)
+	if err != nil {
+		return 0, err
+	}
+
+	subject := file.Path()
+	if !s.DisableAutoRegister {
+		if _, err := s.Client.Register(ctx, subject, schema); err != nil {
+			return 0, err
+		}
+	}
+
+	_, version, err := s.Client.Lookup(ctx, subject, schema)
+	return version, err
+}
+
+// appendMessageIndexes appends the path of indexes leading to the message type
+// in its file. The common case of the first message of the file is encoded as
+// a single zero.
+func appendMessageIndexes(b []byte, desc protoreflect.MessageDescriptor) []byte {
+	var indexes []int
+	for d := protoreflect.Descriptor(desc); d != nil; d = d.Parent() {
+		if _, ok := d.(protoreflect.FileDescriptor); ok {
+			break
+		}
+		indexes = append(indexes, d.Index())
+	}
+
+	if len(indexes) == 1 && indexes[0] == 0 {
+		return append(b, 0)
+	}
+
+	b = binary.AppendVarint(b, int64(len(indexes)))
+	for i := len(indexes) - 1; i >= 0; i-- {
+		b = binary.AppendVarint(b, int64(indexes[i]))
+	}
+	return b
+}
+
+var errInvalidMessageIndexes = errors.New("invalid protobuf message indexes")
+
+func skipMessageIndexes(b []byte) ([]byte, error) {
+	n, size := binary.Varint(b)
+	if size <= 0 || n < 0 {
+		return nil, errInvalidMessageIndexes
+	}
+	b = b[size:]
+
+	for i := int64(0); i < n; i++ {
+		if _, size = binary.Varint(b); size <= 0 {
+			return nil, errInvalidMessageIndexes
+		}
+		b = b[size:]
+	}
+
+	return b, nil
+}
+
+var (
+	_ kafka.Serializer[proto.Message]   = (*ProtobufSerde[proto.Message])(nil)
+	_ kafka.Deserializer[proto.Message] = (*ProtobufSerde[proto.Message])(nil)
+)
@@ -0,0 +1,230 @@
+package schemaregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestProtobufSerde(t *testing.T) {
+	ctx := context.Background()
+	r, client := newRegistry(t)
+
+	s := &ProtobufSerde[*structpb.Value]{Config: Config{Client: client}}
+
+	value := structpb.NewStringValue("hello")
+	b, err := s.Serialize(ctx, "values", value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Value is the second message of google/protobuf/struct.proto, its index
+	// is encoded as an array of one element.
+	if !bytes.HasPrefix(b, []byte{0, 0, 0, 0, 1, 2, 2}) {
+		t.Errorf("unexpected message header: %v", b)
+	}
+
+	schemas := r.subject("values-value")
+	if len(schemas) != 1 || schemas[0].Type != Protobuf {
+		t.Fatalf("expected a protobuf schema to be registered under values-value, got %+v", schemas)
+	}
+	file := decodeFileDescriptor(t, schemas[0].Schema)
+	if file.GetName() != "google/protobuf/struct.proto" {
+		t.Errorf("unexpected schema file: %s", file.GetName())
+	}
+
+	v, err := s.Deserialize(ctx, "values", b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(v, value) {
+		t.Errorf("value mismatch: %v != %v", v, value)
+	}
+}
+
+func TestProtobufSerdeInterface(t *testing.T) {
+	ctx := context.Background()
+	_, client := newRegistry(t)
+
+	s := &ProtobufSerde[proto.Message]{
+		Config: Config{Client: client},
+		New:    func() proto.Message { return &structpb.Value{} },
+	}
+
+	value := structpb.NewStringValue("hello")
+	b, err := s.Serialize(ctx, "values", value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := s.Deserialize(ctx, "values", b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(v, value) {
+		t.Errorf("value mismatch: %v != %v", v, value)
+	}
+
+	s.New = nil
+	if _, err := s.Deserialize(ctx, "values", b); err == nil {
+		t.Error("expected an error decoding into an interface type without New")
+	}
+}
+
+func TestProtobufSerdeFirstMessage(t *testing.T) {
+	ctx := context.Background()
+	_, client := newRegistry(t)
+
+	s := &ProtobufSerde[*structpb.Struct]{Config: Config{Client: client}}
+
+	value, err := structpb.NewStruct(map[string]interface{}{"name": "A"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := s.Serialize(ctx, "values", value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The index of the first message of a file is a single zero.
+	if !bytes.HasPrefix(b, []byte{0, 0, 0, 0, 1, 0}) {
+		t.Errorf("unexpected message header: %v", b)
+	}
+
+	v, err := s.Deserialize(ctx, "values", b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(v, value) {
+		t.Errorf("value mismatch: %v != %v", v, value)
+	}
+}
+
+func TestProtobufSerdeReferences(t *testing.T) {
+	ctx := context.Background()
+	r, client := newRegistry(t)
+
+	files := new(protoregistry.Files)
+	for _, file := range []*descriptorpb.FileDescriptorProto{
+		{
+			Name:    proto.String("common/address.proto"),
+			Package: proto.String("common"),
+			Syntax:  proto.String("proto3"),
+			MessageType: []*descriptorpb.DescriptorProto{{
+				Name: proto.String("Address"),
+				Field: []*descriptorpb.FieldDescriptorProto{{
+					Name:   proto.String("city"),
+					Number: proto.Int32(1),
+					Type:   descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+					Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				}},
+			}},
+		},
+		{
+			Name:       proto.String("user.proto"),
+			Package:    proto.String("example"),
+			Syntax:     proto.String("proto3"),
+			Dependency: []string{"common/address.proto"},
+			MessageType: []*descriptorpb.DescriptorProto{{
+				Name: proto.String("User"),
+				Field: []*descriptorpb.FieldDescriptorProto{{
+					Name:     proto.String("address"),
+					Number:   proto.Int32(1),
+					Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+					Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+					TypeName: proto.String(".common.Address"),
+				}},
+				NestedType: []*descriptorpb.DescriptorProto{{
+					Name: proto.String("Nested"),
+				}},
+			}},
+		},
+	} {
+		fd, err := protodesc.NewFile(file, files)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := files.RegisterFile(fd); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	desc, err := files.FindDescriptorByName("example.User.Nested")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &ProtobufSerde[*dynamicpb.Message]{Config: Config{
+		Client:              client,
+		SubjectNameStrategy: RecordNameStrategy,
+	}}
+
+	b, err := s.Serialize(ctx, "users", dynamicpb.NewMessage(desc.(protoreflect.MessageDescriptor)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Nested is the first message nested in the first message of the file.
+	if !bytes.Equal(b, []byte{0, 0, 0, 0, 2, 4, 0, 0}) {
+		t.Errorf("unexpected message: %v", b)
+	}
+
+	if schemas := r.subject("common/address.proto"); len(schemas) != 1 {
+		t.Fatalf("expected the imported file to be registered, got %+v", schemas)
+	}
+
+	schemas := r.subject("example.User.Nested")
+	if len(schemas) != 1 {
+		t.Fatalf("expected the schema to be registered under the message name, got %+v", schemas)
+	}
+	refs := schemas[0].References
+	if len(refs) != 1 || refs[0] != (Reference{Name: "common/address.proto", Subject: "common/address.proto", Version: 1}) {
+		t.Errorf("unexpected schema references: %+v", refs)
+	}
+	if file := decodeFileDescriptor(t, schemas[0].Schema); file.GetName() != "user.proto" {
+		t.Errorf("unexpected schema file: %s", file.GetName())
+	}
+}
+
+func TestSkipMessageIndexes(t *testing.T) {
+	for _, b := range [][]byte{
+		{0, 42},
+		{2, 2, 42},
+		{4, 0, 6, 42},
+	} {
+		payload, err := skipMessageIndexes(b)
+		if err != nil {
+			t.Errorf("%v: %v", b, err)
+		} else if !bytes.Equal(payload, []byte{42}) {
+			t.Errorf("%v: unexpected payload %v", b, payload)
+		}
+	}
+
+	for _, b := range [][]byte{{}, {1}, {4, 0}} {
+		if _, err := skipMessageIndexes(b); err == nil {
+			t.Errorf("%v: expected an error", b)
+		}
+	}
+}
+
+func decodeFileDescriptor(t *testing.T, schema string) *descriptorpb.FileDescriptorProto {
+	t.Helper()
+
+	b, err := base64.StdEncoding.DecodeString(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := new(descriptorpb.FileDescriptorProto)
+	if err := proto.Unmarshal(b, file); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
@@ -0,0 +1,115 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// SubjectNameStrategy is the type of functions which determine the subject
+// that the schemas of message keys or values are registered under.
+//
+// The record name is the fully qualified name of the Avro record or Protobuf
+// message, or the title of the JSON schema. It is empty when the schema has no
+// name.
+type SubjectNameStrategy func(topic string, isKey bool, recordName string) (string, error)
+
+// TopicNameStrategy registers schemas under the name of the topic suffixed
+// with "-key" or "-value". It is the default strategy, and implies that all
+// messages of a topic use the same schema.
+func TopicNameStrategy(topic string, isKey bool, recordName string) (string, error) {
+	if isKey {
+		return topic + "-key", nil
+	}
+	return topic + "-value", nil
+}
+
+// RecordNameStrategy registers schemas under the name of their record,
+// allowing topics to mix messages of different types.
+func RecordNameStrategy(topic string, isKey bool, recordName string) (string, error) {
+	if recordName == "" {
+		return "", errors.New("the record name strategy requires a named schema")
+	}
+	return recordName, nil
+}
+
+// TopicRecordNameStrategy registers schemas under the name of the topic and
+// the name of their record, separated by a dash.
+func TopicRecordNameStrategy(topic string, isKey bool, recordName string) (string, error) {
+	if recordName == "" {
+		return "", errors.New("the topic record name strategy requires a named schema")
+	}
+	return topic + "-" + recordName, nil
+}
+
+// Config carries the configuration common to the serializers and
+// deserializers of the package.
+type Config struct {
+	// The client used to register and fetch schemas.
+	Client *Client
+
+	// Whether the serializer is used for message keys instead of values.
+	IsKey bool
+
+	// The strategy determining the subjects that schemas are registered
+	// under.
+	//
+	// Default: TopicNameStrategy
+	SubjectNameStrategy SubjectNameStrategy
+
+	// When set, serializers look up the IDs of their schema in the registry
+	// instead of registering them, and fail if the schema was not registered
+	// beforehand.
+	DisableAutoRegister bool
+}
+
+func (c *Config) schemaID(ctx context.Context, topic, recordName string, schema Schema) (int, error) {
+	strategy := c.SubjectNameStrategy
+	if strategy == nil {
+		strategy = TopicNameStrategy
+	}
+
+	subject, err := strategy(topic, c.IsKey, recordName)
+	if err != nil {
+		return 0, err
+	}
+
+	if c.DisableAutoRegister {
+		id, _, err := c.Client.Lookup(ctx, subject, schema)
+		return id, err
+	}
+	return c.Client.Register(ctx, subject, schema)
+}
+
+// ErrInvalidWireFormat is returned by deserializers when a message is not in
+// the wire format of the schema registry.
+var ErrInvalidWireFormat = errors.New("message is not in the schema registry wire format")
+
+const (
+	magicByte  = 0
+	headerSize = 5
+)
+
+func appendHeader(b []byte, id int) []byte {
+	b = append(b, magicByte)
+	return binary.BigEndian.AppendUint32(b, uint32(id))
+}
+
+func parseHeader(b []byte) (id int, payload []byte, err error) {
+	if len(b) < headerSize || b[0] != magicByte {
+		return 0, nil, ErrInvalidWireFormat
+	}
+	return int(binary.BigEndian.Uint32(b[1:])), b[headerSize:], nil
+}
+
+func checkType(schema Schema, typ SchemaType) error {
+	t := schema.Type
+	if t == "" {
+		t = Avro
+	}
+	if t != typ {
+		return fmt.Errorf("expected a schema of type %s, got %s", typ, t)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package kafka
+
+import (
+	"context"
+
+	"github.com/PerchSecurity/kafka-go/protocol"
+)
+
+// Tracer is an interface implemented by types which trace the operations of
+// kafka-go, for example to export spans to a distributed tracing system.
+//
+// The kafka-go/otel package provides an implementation of this interface for
+// OpenTelemetry.
+//
+// The Start methods return the context used for the rest of the operation and
+// a function called with the outcome of the operation when it completes.
+//
+// Tracer implementations must be safe to use concurrently from multiple
+// goroutines.
+type Tracer interface {
+	// StartWrite is called by Writer.WriteMessages before messages are
+	// assigned to partitions.
+	//
+	// msgs is a copy of the list passed to WriteMessages, implementations
+	// may add headers to the messages to propagate the trace context to the
+	// consumers, but must not modify the Headers slices in place.
+	StartWrite(ctx context.Context, msgs []Message) (context.Context, func(error))
+
+	// StartProduce is called by Writer before sending the produce request for
+	// a batch of messages written to a partition. Messages in the batch may
+	// come from different WriteMessages calls.
+	StartProduce(ctx context.Context, topic string, partition int, msgs []Message) (context.Context, func(error))
+
+	// StartRoundTrip is called by Transport before sending req to the broker
+	// at the given address, including the requests issued internally by the
+	// transport (e.g. to refresh the cluster metadata).
+	StartRoundTrip(ctx context.Context, broker string, req protocol.Message) (context.Context, func(error))
+
+	// Consume is called by Reader when it returns msg to the program from
+	// ReadMessage or FetchMessage.
+	Consume(ctx context.Context, msg Message)
+}
@@ -52,6 +52,10 @@ type RoundTripper interface {
 //
 // Transport values are safe to use concurrently from multiple goroutines.
 //
+// When a broker throttles the client because it exceeded a quota, the transport
+// delays the requests sent to this broker for the throttle time carried by the
+// response (KIP-219).
+//
 // Note: The intent is for the Transport to become the underlying layer of the
 // kafka.Reader and kafka.Writer types.
 type Transport struct {
@@ -89,6 +93,18 @@ type Transport struct {
 	// sends requests.
 	ClientID string
 
+	// When set, the transport verifies that the cluster it is connected to has
+	// this unique identifier, and fails all requests otherwise. This protects
+	// programs from accidentally talking to the wrong cluster, for example when
+	// a DNS record points to a different cluster than expected.
+	//
+	// The returned errors wrap InconsistentClusterID, programs may use the
+	// standard errors.Is function to test for it.
+	//
+	// Requires kafka 0.10.1 or above, older brokers do not report their
+	// cluster ID.
+	ClusterID string
+
 	// An optional configuration for TLS connections established by this
 	// transport.
 	//
@@ -117,10 +133,135 @@ type Transport struct {
 	// If nil, context.Background() is used instead.
 	Context context.Context
 
+	// Configures automatic retries of idempotent requests (e.g. Fetch,
+	// ListOffsets, or Describe* requests) which failed with a retriable error.
+	// Requests that modify the state of the cluster (e.g. Produce or
+	// CreateTopics) are never retried by the transport, nor are requests sent
+	// by a Client which has a RetryPolicy.
+	//
+	// If nil, the transport does not retry requests.
+	Retry *TransportRetry
+
+	// If not nil, specifies a recorder used to report metrics about the
+	// connections and requests of the transport, labeled by broker address and
+	// API key.
+	Metrics MetricsRecorder
+
+	// If not nil, specifies a tracer used to trace the requests sent by the
+	// transport to each broker.
+	Tracer Tracer
+
+	// If not nil, specifies functions called around every request exchanged
+	// with the kafka brokers, including the metadata and SASL requests sent
+	// internally by the transport.
+	Hooks *TransportHooks
+
 	mutex sync.RWMutex
 	pools map[networkAddress]*connPool
 }
 
+// TransportHooks carries functions called by a Transport around each request
+// that it sends to a kafka broker. They can be used to record slow requests,
+// dump the protocol traffic, or inject faults in tests.
+//
+// The functions may be called concurrently from multiple goroutines, but never
+// concurrently for requests sent on the same connection.
+type TransportHooks struct {
+	// If not nil, BeforeRoundTrip is called before sending a request, with the
+	// Response, Duration and Err fields of info left empty.
+	//
+	// If the function returns a non-nil response or error, the request is not
+	// sent to the broker and the round trip completes with the returned values
+	// instead. Returned errors are handled like network errors, they cause the
+	// transport to close the connection.
+	BeforeRoundTrip func(ctx context.Context, info RoundTripInfo) (Response, error)
+
+	// If not nil, AfterRoundTrip is called after each round trip completed,
+	// including those completed by BeforeRoundTrip.
+	AfterRoundTrip func(ctx context.Context, info RoundTripInfo)
+}
+
+// RoundTripInfo describes a request exchanged with a kafka broker, it is
+// passed to the functions of TransportHooks.
+type RoundTripInfo struct {
+	// Address of the broker that the request is sent to.
+	Broker string
+
+	// API key and version of the request.
+	ApiKey  protocol.ApiKey
+	Version int16
+
+	// The request, and the response received from the broker, if any.
+	//
+	// SaslAuthenticate requests and responses are passed as copies with their
+	// AuthBytes field cleared, so the hooks never observe the credentials or
+	// session tokens exchanged with the broker.
+	Request  Request
+	Response Response
+
+	// Time spent waiting for the response.
+	Duration time.Duration
+
+	// Error that the round trip failed with, if any. Kafka error codes carried
+	// in the response are not reported here.
+	Err error
+}
+
+// TransportRetry configures the automatic retries of a Transport.
+//
+// Retries are throttled by a retry budget: every request sent by the transport
+// adds Budget to the number of retries available (up to 10), and every retry
+// consumes one. This prevents retries from piling up on a kafka cluster which
+// is already struggling to serve requests.
+type TransportRetry struct {
+	// Maximum number of attempts made to send a request, including the first
+	// one.
+	//
+	// Defaults to 3.
+	MaxAttempts int
+
+	// Bounds of the exponential backoff applied between attempts. The actual
+	// delays are randomized to avoid synchronizing retries of concurrent
+	// requests.
+	//
+	// Default to 100ms and 1s.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// Ratio of retries to requests allowed by the retry budget.
+	//
+	// Defaults to 0.1 (one retry every ten requests).
+	Budget float64
+}
+
+func (r *TransportRetry) maxAttempts() int {
+	if r.MaxAttempts > 0 {
+		return r.MaxAttempts
+	}
+	return 3
+}
+
+func (r *TransportRetry) minBackoff() time.Duration {
+	if r.MinBackoff > 0 {
+		return r.MinBackoff
+	}
+	return 100 * time.Millisecond
+}
+
+func (r *TransportRetry) maxBackoff() time.Duration {
+	if r.MaxBackoff > 0 {
+		return r.MaxBackoff
+	}
+	return 1 * time.Second
+}
+
+func (r *TransportRetry) budget() float64 {
+	if r.Budget > 0 {
+		return r.Budget
+	}
+	return 0.1
+}
+
 // DefaultTransport is the default transport used by kafka clients in this
 // package.
 var DefaultTransport RoundTripper = &Transport{
@@ -245,12 +386,19 @@ func (t *Transport) grabPool(addr net.Addr) *connPool {
 		metadataTTL:    t.metadataTTL(),
 		metadataTopics: t.MetadataTopics,
 		clientID:       t.ClientID,
+		clusterID:      t.ClusterID,
 		tls:            t.TLS,
 		sasl:           t.SASL,
 		resolver:       t.Resolver,
+		retry:          t.Retry,
+		retryTokens:    maxRetryTokens,
+		metrics:        metrics{recorder: t.Metrics},
+		tracer:         t.Tracer,
+		hooks:          t.Hooks,
 
 		ready:  make(event),
 		wake:   make(chan event),
+		stale:  make(chan staleTopics),
 		conns:  make(map[int32]*connGroup),
 		cancel: cancel,
 	}
@@ -287,20 +435,29 @@ type connPool struct {
 	metadataTTL    time.Duration
 	metadataTopics []string
 	clientID       string
+	clusterID      string
 	tls            *tls.Config
 	sasl           sasl.Mechanism
 	resolver       BrokerResolver
+	retry          *TransportRetry
+	metrics        metrics
+	tracer         Tracer
+	hooks          *TransportHooks
 	// Signaling mechanisms to orchestrate communications between the pool and
 	// the rest of the program.
-	once   sync.Once  // ensure that `ready` is triggered only once
-	ready  event      // triggered after the first metadata update
-	wake   chan event // used to force metadata updates
+	once   sync.Once        // ensure that `ready` is triggered only once
+	ready  event            // triggered after the first metadata update
+	wake   chan event       // used to force metadata updates
+	stale  chan staleTopics // used to update the metadata of stale topics
 	cancel context.CancelFunc
 	// Mutable fields of the connection pool, access must be synchronized.
 	mutex sync.RWMutex
 	conns map[int32]*connGroup // data connections used for produce/fetch/etc...
 	ctrl  *connGroup           // control connections used for metadata requests
 	state atomic.Value         // cached cluster state
+	// Retry budget shared by all requests sent through the pool.
+	retryMutex  sync.Mutex
+	retryTokens float64
 }
 
 type connPoolState struct {
@@ -337,6 +494,106 @@ func (p *connPool) unref() {
 }
 
 func (p *connPool) roundTrip(ctx context.Context, req Request) (Response, error) {
+	if p.retry == nil || !isIdempotent(req) || retriedByClient(ctx) {
+		return p.roundTripOnce(ctx, req)
+	}
+
+	p.depositRetryBudget()
+	backoff := p.retry.minBackoff()
+
+	for attempt := 1; ; attempt++ {
+		r, err := p.roundTripOnce(ctx, req)
+
+		if attempt >= p.retry.maxAttempts() || !isRetriable(req, r, err) || ctx.Err() != nil {
+			return r, err
+		}
+
+		if !p.withdrawRetryBudget() {
+			return r, err
+		}
+
+		timer := time.NewTimer(time.Duration(rand.Int63n(int64(backoff))))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return r, err
+		}
+
+		if backoff *= 2; backoff > p.retry.maxBackoff() {
+			backoff = p.retry.maxBackoff()
+		}
+	}
+}
+
+func (p *connPool) depositRetryBudget() {
+	p.retryMutex.Lock()
+	defer p.retryMutex.Unlock()
+
+	if p.retryTokens += p.retry.budget(); p.retryTokens > maxRetryTokens {
+		p.retryTokens = maxRetryTokens
+	}
+}
+
+func (p *connPool) withdrawRetryBudget() bool {
+	p.retryMutex.Lock()
+	defer p.retryMutex.Unlock()
+
+	if p.retryTokens < 1 {
+		return false
+	}
+	p.retryTokens--
+	return true
+}
+
+// Maximum number of retries that can be accumulated in the retry budget of a
+// connection pool.
+const maxRetryTokens = 10
+
+// isIdempotent returns true if req can be sent multiple times to kafka brokers
+// without side effects.
+func isIdempotent(req Request) bool {
+	switch req.ApiKey() {
+	case protocol.Metadata,
+		protocol.Fetch,
+		protocol.ListOffsets,
+		protocol.OffsetFetch,
+		protocol.FindCoordinator,
+		protocol.DescribeGroups,
+		protocol.ListGroups,
+		protocol.ApiVersions,
+		protocol.OffsetForLeaderEpoch,
+		protocol.DescribeAcls,
+		protocol.DescribeConfigs,
+		protocol.DescribeLogDirs,
+		protocol.DescribeDelegationToken,
+		protocol.ListPartitionReassignments,
+		protocol.DescribeClientQuotas,
+		protocol.DescribeUserScramCredentials,
+		protocol.DescribeCluster,
+		protocol.DescribeProducers,
+		protocol.DescribeTransactions,
+		protocol.ListTransactions:
+		return true
+	default:
+		return false
+	}
+}
+
+// isRetriable returns true if the result of a round trip indicates that the
+// request may succeed if it was sent again.
+func isRetriable(req Request, r Response, err error) bool {
+	if err == nil {
+		resErr := makeResponseError(r)
+		if resErr == nil {
+			return false
+		}
+		err = resErr
+	}
+	return isRetriableRequestError(req, err)
+}
+
+func (p *connPool) roundTripOnce(ctx context.Context, req Request) (Response, error) {
 	// This first select should never block after the first metadata response
 	// that would mark the pool as `ready`.
 	select {
@@ -348,6 +605,12 @@ func (p *connPool) roundTrip(ctx context.Context, req Request) (Response, error)
 	state := p.grabState()
 	var response promise
 
+	if p.clusterID != "" && state.err != nil && state.metadata == nil {
+		// The identity of the cluster could not be verified, requests must
+		// not be sent to brokers that may not belong to the expected cluster.
+		return nil, state.err
+	}
+
 	switch m := req.(type) {
 	case *meta.Request:
 		// We serve metadata requests directly from the transport cache unless
@@ -398,9 +661,26 @@ func (p *connPool) roundTrip(ctx context.Context, req Request) (Response, error)
 
 	r, err := response.await(ctx)
 	if err != nil {
+		var noLeader *protocol.TopicPartitionError
+		if errors.As(err, &noLeader) && errors.Is(noLeader, protocol.ErrNoLeader) {
+			// The partition leader was unknown when the request was routed, a
+			// leader may have been elected since the last metadata update.
+			p.refreshStaleTopics(ctx, []string{noLeader.Topic})
+		}
 		return r, err
 	}
 
+	if res, ok := r.(protocol.PartitionErrorer); ok {
+		if topics := staleMetadataTopics(res, state.layout); len(topics) != 0 {
+			// The broker reported that the cached metadata used to route the
+			// request is out of date (e.g. after a change of partition
+			// leader), refresh it immediately so the next requests are sent to
+			// the right brokers instead of waiting for the metadata TTL to
+			// expire.
+			p.refreshStaleTopics(ctx, topics)
+		}
+	}
+
 	switch resp := r.(type) {
 	case *createtopics.Response:
 		// Force an update of the metadata when adding topics,
@@ -442,6 +722,65 @@ func (p *connPool) roundTrip(ctx context.Context, req Request) (Response, error)
 	return r, nil
 }
 
+// staleMetadataTopics returns the topics of res which have error codes
+// indicating that the metadata used to route the request was stale.
+//
+// Responses which identify topics by ID (e.g. Fetch v13+) report the string
+// representation of the IDs, they are mapped back to topic names with the
+// cluster layout that the request was routed with.
+func staleMetadataTopics(res protocol.PartitionErrorer, layout protocol.Cluster) []string {
+	var topics []string
+
+	res.PartitionErrors(func(topic string, partition int32, errorCode int16) {
+		switch Error(errorCode) {
+		case NotLeaderForPartition, LeaderNotAvailable, UnknownTopicOrPartition, UnknownTopicID, InconsistentTopicID:
+			if _, ok := layout.Topics[topic]; !ok {
+				if id, err := protocol.ParseUUID(topic); err == nil {
+					if t, ok := layout.TopicByID(id); ok {
+						topic = t.Name
+					}
+				}
+			}
+			if n := len(topics); n == 0 || topics[n-1] != topic {
+				topics = append(topics, topic)
+			}
+		}
+	})
+
+	return topics
+}
+
+// Minimum delay between two updates of the metadata of stale topics, which
+// prevents a cluster going through leader elections from being flooded with
+// metadata requests.
+const minStaleTopicsRefreshInterval = 100 * time.Millisecond
+
+// staleTopics is a request to update the cached metadata of topics that
+// brokers reported as stale, done is triggered once the update completed.
+type staleTopics struct {
+	topics []string
+	done   event
+}
+
+// refreshStaleTopics requests an update of the cached metadata of topics, and
+// waits for it to complete. Concurrent requests are coalesced into a single
+// metadata request for all the topics, which only invalidates the metadata of
+// these topics.
+func (p *connPool) refreshStaleTopics(ctx context.Context, topics []string) {
+	req := staleTopics{topics: topics, done: make(event)}
+
+	select {
+	case p.stale <- req:
+	case <-ctx.Done():
+		return
+	}
+
+	select {
+	case <-req.done:
+	case <-ctx.Done():
+	}
+}
+
 // refreshMetadata forces an update of the cached cluster metadata, and waits
 // for the given list of topics to appear. This waiting mechanism is necessary
 // to account for the fact that topic creation is asynchronous in kafka, and
@@ -522,7 +861,16 @@ func (p *connPool) update(ctx context.Context, metadata *meta.Response, err erro
 	addBrokers := make(map[int32]struct{})
 	delBrokers := make(map[int32]struct{})
 
-	if err != nil {
+	if metadata != nil && p.clusterID != "" && metadata.ClusterID != p.clusterID {
+		// The transport is connected to a different cluster than the one it
+		// was configured for, forget everything known about the cluster so
+		// no requests get routed to its brokers.
+		for id := range state.layout.Brokers {
+			delBrokers[id] = struct{}{}
+		}
+		state.metadata, state.layout = nil, protocol.Cluster{}
+		state.err = fmt.Errorf("%w: expected cluster ID %q but the kafka cluster reported %q", InconsistentClusterID, p.clusterID, metadata.ClusterID)
+	} else if err != nil {
 		// Only update the error on the transport if the cluster layout was
 		// unknown. This ensures that we prioritize a previously known state
 		// of the cluster to reduce the impact of transient failures.
@@ -596,6 +944,7 @@ func (p *connPool) discover(ctx context.Context, wake <-chan event) {
 	defer timer.Stop()
 
 	var notify event
+	var lastStaleUpdate time.Time
 	done := ctx.Done()
 
 	req := &meta.Request{
@@ -628,14 +977,114 @@ func (p *connPool) discover(ctx context.Context, wake <-chan event) {
 			notify = nil
 		}
 
+	wait:
+		for {
+			select {
+			case <-timer.C:
+				timer.Reset(metadataTTL())
+				break wait
+			case <-done:
+				return
+			case notify = <-wake:
+				break wait
+			case stale := <-p.stale:
+				p.updateStaleTopics(ctx, stale, lastStaleUpdate)
+				lastStaleUpdate = time.Now()
+			}
+		}
+	}
+}
+
+// updateStaleTopics is called by the goroutine running the discover method to
+// update the metadata of the topics of req, and of all the other requests that
+// are pending. The update is delayed until minStaleTopicsRefreshInterval has
+// elapsed since the last one.
+func (p *connPool) updateStaleTopics(ctx context.Context, req staleTopics, lastUpdate time.Time) {
+	reqs := []staleTopics{req}
+	defer func() {
+		for _, r := range reqs {
+			r.done.trigger()
+		}
+	}()
+
+	if delay := minStaleTopicsRefreshInterval - time.Since(lastUpdate); delay > 0 {
+		timer := time.NewTimer(delay)
 		select {
 		case <-timer.C:
-			timer.Reset(metadataTTL())
-		case <-done:
+		case <-ctx.Done():
+			timer.Stop()
 			return
-		case notify = <-wake:
 		}
 	}
+
+	for pending := true; pending; {
+		select {
+		case r := <-p.stale:
+			reqs = append(reqs, r)
+		default:
+			pending = false
+		}
+	}
+
+	topics := make(map[string]struct{})
+	for _, r := range reqs {
+		for _, topic := range r.topics {
+			topics[topic] = struct{}{}
+		}
+	}
+
+	topicNames := make([]string, 0, len(topics))
+	for topic := range topics {
+		topicNames = append(topicNames, topic)
+	}
+	sort.Strings(topicNames)
+
+	c, err := p.grabClusterConn(ctx)
+	if err != nil {
+		return
+	}
+
+	res := make(async, 1)
+	deadline, cancel := context.WithTimeout(ctx, p.metadataTTL)
+	defer cancel()
+	c.reqs <- connRequest{
+		ctx: deadline,
+		req: &meta.Request{TopicNames: topicNames},
+		res: res,
+	}
+
+	r, err := res.await(deadline)
+	if err != nil {
+		// Errors are not reported, the next full update will refresh the
+		// metadata of the topics.
+		return
+	}
+
+	if metadata := p.grabState().metadata; metadata != nil {
+		p.update(ctx, mergeMetadataTopics(metadata, r.(*meta.Response)), nil)
+	}
+}
+
+// mergeMetadataTopics returns a copy of metadata where the brokers, and the
+// topics present in update, are replaced by those of update.
+func mergeMetadataTopics(metadata, update *meta.Response) *meta.Response {
+	merged := *update
+	merged.Topics = make([]meta.ResponseTopic, 0, len(metadata.Topics)+len(update.Topics))
+
+	updated := make(map[string]struct{}, len(update.Topics))
+	for _, t := range update.Topics {
+		updated[t.Name] = struct{}{}
+		sortMetadataPartitions(t.Partitions)
+	}
+
+	for _, t := range metadata.Topics {
+		if _, ok := updated[t.Name]; !ok {
+			merged.Topics = append(merged.Topics, t)
+		}
+	}
+
+	merged.Topics = append(merged.Topics, update.Topics...)
+	return &merged
 }
 
 // grabBrokerConn returns a connection to a specific broker represented by the
@@ -731,18 +1180,21 @@ func (p *connPool) sendRequest(ctx context.Context, req Request, state connPoolS
 func filterMetadataResponse(req *meta.Request, res *meta.Response) *meta.Response {
 	ret := *res
 
-	if req.TopicNames != nil {
-		ret.Topics = make([]meta.ResponseTopic, len(req.TopicNames))
+	if req.TopicNames != nil || req.Topics != nil {
+		ret.Topics = make([]meta.ResponseTopic, 0, len(req.TopicNames)+len(req.Topics))
 
-		for i, topicName := range req.TopicNames {
-			j, ok := findMetadataTopic(res.Topics, topicName)
-			if ok {
-				ret.Topics[i] = res.Topics[j]
-			} else {
-				ret.Topics[i] = meta.ResponseTopic{
-					ErrorCode: int16(UnknownTopicOrPartition),
-					Name:      topicName,
+		for _, topicName := range req.TopicNames {
+			ret.Topics = append(ret.Topics, findMetadataTopicByName(res.Topics, topicName))
+		}
+
+		for _, t := range req.Topics {
+			switch {
+			case t.Name != "":
+				if !containsString(req.TopicNames, t.Name) {
+					ret.Topics = append(ret.Topics, findMetadataTopicByName(res.Topics, t.Name))
 				}
+			default:
+				ret.Topics = append(ret.Topics, findMetadataTopicByID(res.Topics, t.TopicID))
 			}
 		}
 	}
@@ -750,6 +1202,37 @@ func filterMetadataResponse(req *meta.Request, res *meta.Response) *meta.Respons
 	return &ret
 }
 
+func containsString(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+func findMetadataTopicByName(topics []meta.ResponseTopic, topicName string) meta.ResponseTopic {
+	if i, ok := findMetadataTopic(topics, topicName); ok {
+		return topics[i]
+	}
+	return meta.ResponseTopic{
+		ErrorCode: int16(UnknownTopicOrPartition),
+		Name:      topicName,
+	}
+}
+
+func findMetadataTopicByID(topics []meta.ResponseTopic, topicID protocol.UUID) meta.ResponseTopic {
+	for _, t := range topics {
+		if t.TopicID == topicID {
+			return t
+		}
+	}
+	return meta.ResponseTopic{
+		ErrorCode: int16(UnknownTopicID),
+		TopicID:   topicID,
+	}
+}
+
 func findMetadataTopic(topics []meta.ResponseTopic, topicName string) (int, bool) {
 	i := sort.Search(len(topics), func(i int) bool {
 		return topics[i].Name >= topicName
@@ -796,6 +1279,7 @@ func makeLayout(metadataResponse *meta.Response) protocol.Cluster {
 			continue // TODO: do we need to expose those?
 		}
 		layout.Topics[topic.Name] = protocol.Topic{
+			ID:         topic.TopicID,
 			Name:       topic.Name,
 			Error:      topic.ErrorCode,
 			Partitions: makePartitions(topic.Partitions),
@@ -965,6 +1449,40 @@ type connGroup struct {
 	mutex     sync.Mutex
 	closed    bool
 	idleConns []*conn // stack of idle connections
+	// Time until which requests to the broker are delayed because it throttled
+	// a previous response (KIP-219).
+	throttleUntil time.Time
+}
+
+// throttle delays the requests sent to the broker of g for the throttle time
+// carried by res, if the broker expects the client to throttle itself.
+func (g *connGroup) throttle(res Response, version int16) {
+	throttle := protocol.ThrottleTime(res)
+	if throttle <= 0 || !res.ApiKey().ClientSideThrottling(version) {
+		return
+	}
+	until := time.Now().Add(throttle)
+	g.mutex.Lock()
+	if until.After(g.throttleUntil) {
+		g.throttleUntil = until
+	}
+	g.mutex.Unlock()
+}
+
+// waitThrottle blocks until the broker of g stops throttling the client, or
+// ctx is canceled.
+func (g *connGroup) waitThrottle(ctx context.Context) error {
+	g.mutex.Lock()
+	delay := time.Until(g.throttleUntil)
+	g.mutex.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	if !sleep(ctx, delay) {
+		return ctx.Err()
+	}
+	return nil
 }
 
 func (g *connGroup) closeIdleConns() {
@@ -1019,13 +1537,17 @@ func (g *connGroup) grabConnOrConnect(ctx context.Context) (*conn, error) {
 		errChan := make(chan error)
 
 		go func() {
+			start := time.Now()
 			c, err := g.connect(ctx, addr)
+			g.pool.metrics.recordDuration(transportConnectDuration, time.Since(start), addr.String())
 			if err != nil {
+				g.pool.metrics.add(transportConnectionErrors, 1, addr.String())
 				select {
 				case errChan <- err:
 				case <-ctx.Done():
 				}
 			} else {
+				g.pool.metrics.add(transportConnections, 1, addr.String())
 				select {
 				case connChan <- c:
 				case <-ctx.Done():
@@ -1192,7 +1714,7 @@ func (g *connGroup) connect(ctx context.Context, addr net.Addr) (*conn, error) {
 	pc := protocol.NewConn(netConn, g.pool.clientID)
 	pc.SetDeadline(deadline)
 
-	r, err := pc.RoundTrip(new(apiversions.Request))
+	r, err := g.pool.exchange(ctx, netAddr.String(), pc, new(apiversions.Request))
 	if err != nil {
 		return nil, err
 	}
@@ -1211,26 +1733,36 @@ func (g *connGroup) connect(ctx context.Context, addr net.Addr) (*conn, error) {
 	pc.SetVersions(ver)
 	pc.SetDeadline(time.Time{})
 
+	var saslMetadata *sasl.Metadata
+	var reauthTime time.Time
+
 	if g.pool.sasl != nil {
 		host, port, err := splitHostPortNumber(netAddr.String())
 		if err != nil {
 			return nil, err
 		}
-		metadata := &sasl.Metadata{
+		saslMetadata = &sasl.Metadata{
 			Host: host,
 			Port: port,
 		}
-		if err := authenticateSASL(sasl.WithMetadata(ctx, metadata), pc, g.pool.sasl); err != nil {
+		exchange := func(req Request) (Response, error) {
+			return g.pool.exchange(ctx, netAddr.String(), pc, req)
+		}
+		sessionLifetime, err := authenticateSASL(sasl.WithMetadata(ctx, saslMetadata), exchange, g.pool.sasl)
+		if err != nil {
 			return nil, err
 		}
+		reauthTime = reauthenticationTime(time.Now(), sessionLifetime)
 	}
 
 	reqs := make(chan connRequest)
 	c := &conn{
-		network: netAddr.Network(),
-		address: netAddr.String(),
-		reqs:    reqs,
-		group:   g,
+		network:      netAddr.Network(),
+		address:      netAddr.String(),
+		reqs:         reqs,
+		group:        g,
+		saslMetadata: saslMetadata,
+		reauthTime:   reauthTime,
 	}
 	go c.run(pc, reqs)
 
@@ -1245,6 +1777,12 @@ type conn struct {
 	once    sync.Once
 	group   *connGroup
 	timer   *time.Timer
+
+	// When the broker reported a lifetime for the SASL session, reauthTime is
+	// the time after which the connection must be re-authenticated before
+	// sending more requests (KIP-368).
+	saslMetadata *sasl.Metadata
+	reauthTime   time.Time
 }
 
 func (c *conn) close() {
@@ -1255,13 +1793,35 @@ func (c *conn) run(pc *protocol.Conn, reqs <-chan connRequest) {
 	defer pc.Close()
 
 	for cr := range reqs {
-		r, err := c.roundTrip(cr.ctx, pc, cr.req)
+		if err := c.reauthenticate(cr.ctx, pc); err != nil {
+			cr.res.reject(err)
+			break
+		}
+
+		if err := c.group.waitThrottle(cr.ctx); err != nil {
+			cr.res.reject(err)
+			if !c.group.releaseConn(c) {
+				break
+			}
+			continue
+		}
+
+		ctx, finish := cr.ctx, func(error) {}
+		if tracer := c.group.pool.tracer; tracer != nil {
+			ctx, finish = tracer.StartRoundTrip(ctx, c.group.addr.String(), cr.req)
+		}
+
+		start := time.Now()
+		r, err := c.roundTrip(ctx, pc, cr.req)
+		c.observeRequest(cr.req, time.Since(start), err)
+		finish(err)
 		if err != nil {
 			cr.res.reject(err)
 			if !errors.Is(err, protocol.ErrNoRecord) {
 				break
 			}
 		} else {
+			c.group.throttle(r, pc.ApiVersion(r.ApiKey()))
 			cr.res.resolve(r)
 		}
 		if !c.group.releaseConn(c) {
@@ -1279,42 +1839,158 @@ func (c *conn) roundTrip(ctx context.Context, pc *protocol.Conn, req Request) (R
 		defer pc.SetDeadline(time.Time{})
 	}
 
-	return pc.RoundTrip(req)
+	return c.group.pool.exchange(ctx, c.address, pc, req)
+}
+
+// exchange sends req to the broker at the other end of pc and returns its
+// response, calling the hooks of the pool around the round trip.
+func (p *connPool) exchange(ctx context.Context, broker string, pc *protocol.Conn, req Request) (Response, error) {
+	hooks := p.hooks
+	if hooks == nil {
+		return pc.RoundTrip(req)
+	}
+
+	info := RoundTripInfo{
+		Broker:  broker,
+		ApiKey:  req.ApiKey(),
+		Version: pc.ApiVersion(req.ApiKey()),
+		Request: redactRequest(req),
+	}
+
+	start := time.Now()
+	var res Response
+	var err error
+
+	if hooks.BeforeRoundTrip != nil {
+		res, err = hooks.BeforeRoundTrip(ctx, info)
+	}
+	if res == nil && err == nil {
+		res, err = pc.RoundTrip(req)
+	}
+
+	if hooks.AfterRoundTrip != nil {
+		info.Response, info.Duration, info.Err = redactResponse(res), time.Since(start), err
+		hooks.AfterRoundTrip(ctx, info)
+	}
+	return res, err
+}
+
+// redactRequest returns a copy of req without the SASL authentication bytes
+// when it is a SaslAuthenticate request, or req itself otherwise.
+func redactRequest(req Request) Request {
+	if r, ok := req.(*saslauthenticate.Request); ok {
+		redacted := *r
+		redacted.AuthBytes = nil
+		return &redacted
+	}
+	return req
+}
+
+// redactResponse is the counterpart of redactRequest for responses.
+func redactResponse(res Response) Response {
+	if r, ok := res.(*saslauthenticate.Response); ok {
+		redacted := *r
+		redacted.AuthBytes = nil
+		return &redacted
+	}
+	return res
+}
+
+func (c *conn) observeRequest(req Request, duration time.Duration, err error) {
+	metrics := c.group.pool.metrics
+	if metrics.recorder == nil {
+		return
+	}
+	broker, apiKey := c.group.addr.String(), req.ApiKey().String()
+	metrics.add(transportRequests, 1, broker, apiKey)
+	metrics.recordDuration(transportRequestDuration, duration, broker, apiKey)
+	if err != nil {
+		metrics.add(transportErrors, 1, broker, apiKey)
+	}
+}
+
+// reauthenticate runs the SASL authentication again on pc if the session
+// lifetime reported by the broker is about to expire. Requests are serialized
+// on the connection, so none are in flight when the exchange happens.
+func (c *conn) reauthenticate(ctx context.Context, pc *protocol.Conn) error {
+	if c.reauthTime.IsZero() || time.Now().Before(c.reauthTime) {
+		return nil
+	}
+
+	if deadline, hasDeadline := ctx.Deadline(); hasDeadline {
+		pc.SetDeadline(deadline)
+		defer pc.SetDeadline(time.Time{})
+	}
+
+	exchange := func(req Request) (Response, error) {
+		return c.group.pool.exchange(ctx, c.address, pc, req)
+	}
+	sessionLifetime, err := authenticateSASL(sasl.WithMetadata(ctx, c.saslMetadata), exchange, c.group.pool.sasl)
+	if err != nil {
+		return fmt.Errorf("re-authenticating SASL session with kafka broker at %s: %w", c.address, err)
+	}
+
+	c.reauthTime = reauthenticationTime(time.Now(), sessionLifetime)
+	return nil
+}
+
+// reauthenticationTime returns the time at which a SASL session created at now
+// should be re-authenticated. Like the Java client, it picks a random point
+// between 85% and 95% of the session lifetime so connections opened at the
+// same time do not all re-authenticate at once, and requests sent shortly
+// before re-authenticating complete before the session expires.
+//
+// A zero time is returned if the session does not expire.
+func reauthenticationTime(now time.Time, sessionLifetime time.Duration) time.Time {
+	if sessionLifetime <= 0 {
+		return time.Time{}
+	}
+	return now.Add(time.Duration(float64(sessionLifetime) * (0.85 + 0.1*rand.Float64())))
 }
 
+// exchangeFunc is the signature of functions sending requests on a connection
+// to a kafka broker.
+type exchangeFunc func(Request) (Response, error)
+
 // authenticateSASL performs all of the required requests to authenticate this
 // connection.  If any step fails, this function returns with an error.  A nil
 // error indicates successful authentication.
-func authenticateSASL(ctx context.Context, pc *protocol.Conn, mechanism sasl.Mechanism) error {
-	if err := saslHandshakeRoundTrip(pc, mechanism.Name()); err != nil {
-		return err
+//
+// The returned duration is the session lifetime reported by the broker, it is
+// zero if the broker did not require the connection to re-authenticate.
+func authenticateSASL(ctx context.Context, exchange exchangeFunc, mechanism sasl.Mechanism) (time.Duration, error) {
+	if err := saslHandshakeRoundTrip(exchange, mechanism.Name()); err != nil {
+		return 0, err
 	}
 
 	sess, state, err := mechanism.Start(ctx)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
+	var sessionLifetime time.Duration
+
 	for completed := false; !completed; {
-		challenge, err := saslAuthenticateRoundTrip(pc, state)
+		challenge, lifetime, err := saslAuthenticateRoundTrip(exchange, state)
 		if err != nil {
 			if errors.Is(err, io.EOF) {
 				// the broker may communicate a failed exchange by closing the
 				// connection (esp. in the case where we're passing opaque sasl
 				// data over the wire since there's no protocol info).
-				return SASLAuthenticationFailed
+				return 0, SASLAuthenticationFailed
 			}
 
-			return err
+			return 0, err
 		}
 
+		sessionLifetime = lifetime
 		completed, state, err = sess.Next(ctx, challenge)
 		if err != nil {
-			return err
+			return 0, err
 		}
 	}
 
-	return nil
+	return sessionLifetime, nil
 }
 
 // saslHandshake sends the SASL handshake message.  This will determine whether
@@ -1328,8 +2004,8 @@ func authenticateSASL(ctx context.Context, pc *protocol.Conn, mechanism sasl.Mec
 // therefore the client should already know which mechanisms are supported.
 //
 // See http://kafka.apache.org/protocol.html#The_Messages_SaslHandshake
-func saslHandshakeRoundTrip(pc *protocol.Conn, mechanism string) error {
-	msg, err := pc.RoundTrip(&saslhandshake.Request{
+func saslHandshakeRoundTrip(exchange exchangeFunc, mechanism string) error {
+	msg, err := exchange(&saslhandshake.Request{
 		Mechanism: mechanism,
 	})
 	if err != nil {
@@ -1345,19 +2021,21 @@ func saslHandshakeRoundTrip(pc *protocol.Conn, mechanism string) error {
 // saslAuthenticate sends the SASL authenticate message.  This function must
 // be immediately preceded by a successful saslHandshake.
 //
+// The session lifetime is only reported by brokers supporting v1 of the API.
+//
 // See http://kafka.apache.org/protocol.html#The_Messages_SaslAuthenticate
-func saslAuthenticateRoundTrip(pc *protocol.Conn, data []byte) ([]byte, error) {
-	msg, err := pc.RoundTrip(&saslauthenticate.Request{
+func saslAuthenticateRoundTrip(exchange exchangeFunc, data []byte) ([]byte, time.Duration, error) {
+	msg, err := exchange(&saslauthenticate.Request{
 		AuthBytes: data,
 	})
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	res := msg.(*saslauthenticate.Response)
 	if res.ErrorCode != 0 {
 		err = makeError(res.ErrorCode, res.ErrorMessage)
 	}
-	return res.AuthBytes, err
+	return res.AuthBytes, time.Duration(res.SessionLifetimeMs) * time.Millisecond, err
 }
 
 var _ RoundTripper = (*Transport)(nil)
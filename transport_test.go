@@ -5,12 +5,21 @@ import (
 	"crypto/tls"
 	"errors"
 	"net"
+	"reflect"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/PerchSecurity/kafka-go/kafkatest"
 	"github.com/PerchSecurity/kafka-go/protocol"
+	"github.com/PerchSecurity/kafka-go/protocol/apiversions"
 	"github.com/PerchSecurity/kafka-go/protocol/createtopics"
+	fetchAPI "github.com/PerchSecurity/kafka-go/protocol/fetch"
 	meta "github.com/PerchSecurity/kafka-go/protocol/metadata"
+	"github.com/PerchSecurity/kafka-go/protocol/saslauthenticate"
+	"github.com/PerchSecurity/kafka-go/protocol/saslhandshake"
+	"github.com/PerchSecurity/kafka-go/sasl"
+	"github.com/PerchSecurity/kafka-go/sasl/plain"
 )
 
 func TestIssue477(t *testing.T) {
@@ -304,3 +313,487 @@ func TestIssue806(t *testing.T) {
 		t.Fatalf("expected a meta.Response but got %T", r)
 	}
 }
+
+func TestTransportClusterIDMismatch(t *testing.T) {
+	ctx := context.Background()
+
+	pool := &connPool{
+		clusterID: "expected",
+		ready:     make(event),
+		conns:     make(map[int32]*connGroup),
+	}
+
+	brokers := []meta.ResponseBroker{
+		{NodeID: 1, Host: "localhost", Port: 9092},
+	}
+
+	pool.update(ctx, &meta.Response{ClusterID: "expected", Brokers: brokers}, nil)
+
+	if _, err := pool.roundTrip(ctx, &meta.Request{}); err != nil {
+		t.Fatal("unexpected error when the cluster ID matches:", err)
+	}
+	if len(pool.conns) != 1 {
+		t.Fatalf("expected 1 broker in the connection pool, got %d", len(pool.conns))
+	}
+
+	pool.update(ctx, &meta.Response{ClusterID: "unexpected", Brokers: brokers}, nil)
+
+	if _, err := pool.roundTrip(ctx, &meta.Request{}); !errors.Is(err, InconsistentClusterID) {
+		t.Fatal("expected an inconsistent cluster ID error but got:", err)
+	}
+	if len(pool.conns) != 0 {
+		t.Fatalf("expected no brokers in the connection pool, got %d", len(pool.conns))
+	}
+
+	// Transient failures must not hide the fact that the cluster could not be
+	// verified.
+	pool.update(ctx, nil, errors.New("oops"))
+
+	if _, err := pool.roundTrip(ctx, &createtopics.Request{}); err == nil {
+		t.Fatal("expected an error when the cluster ID could not be verified")
+	}
+}
+
+func TestTransportRefreshMetadataAndRetryOnStaleLeader(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	const topic = "topic-1"
+
+	// count the number of metadata refreshes requested by the pool
+	refreshes := make(chan []string, 10)
+	stale := make(chan staleTopics)
+	defer close(stale)
+	go func() {
+		for r := range stale {
+			refreshes <- r.topics
+			r.done.trigger()
+		}
+	}()
+
+	// the first fetch fails because the broker is not the partition leader
+	// anymore, the second succeeds
+	requests := make(chan connRequest)
+	defer close(requests)
+	go func() {
+		errorCodes := []int16{int16(NotLeaderForPartition), 0}
+		for _, errorCode := range errorCodes {
+			request, ok := <-requests
+			if !ok {
+				return
+			}
+			request.res.resolve(&fetchAPI.Response{
+				Topics: []fetchAPI.ResponseTopic{{
+					Topic: topic,
+					Partitions: []fetchAPI.ResponsePartition{{
+						Partition: 0,
+						ErrorCode: errorCode,
+					}},
+				}},
+			})
+		}
+	}()
+
+	ready := make(event)
+	ready.trigger()
+
+	pool := &connPool{
+		ready:       ready,
+		stale:       stale,
+		conns:       map[int32]*connGroup{},
+		retry:       &TransportRetry{MinBackoff: time.Millisecond},
+		retryTokens: maxRetryTokens,
+	}
+
+	pool.setState(connPoolState{
+		layout: protocol.Cluster{
+			Brokers: map[int32]protocol.Broker{
+				0: {ID: 0},
+			},
+			Topics: map[string]protocol.Topic{
+				topic: {
+					Name: topic,
+					Partitions: map[int32]protocol.Partition{
+						0: {ID: 0, Leader: 0},
+					},
+				},
+			},
+		},
+	})
+
+	pool.conns[0] = &connGroup{
+		pool:      pool,
+		broker:    Broker{},
+		idleConns: []*conn{{reqs: requests}, {reqs: requests}},
+	}
+
+	r, err := pool.roundTrip(ctx, &fetchAPI.Request{
+		Topics: []fetchAPI.RequestTopic{{
+			Topic:      topic,
+			Partitions: []fetchAPI.RequestPartition{{Partition: 0}},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := r.(*fetchAPI.Response)
+	if errorCode := res.Topics[0].Partitions[0].ErrorCode; errorCode != 0 {
+		t.Errorf("expected the retried request to succeed, got error code %d", errorCode)
+	}
+
+	if n := len(refreshes); n != 1 {
+		t.Fatalf("expected 1 metadata refresh, got %d", n)
+	}
+	if topics := <-refreshes; !reflect.DeepEqual(topics, []string{topic}) {
+		t.Errorf("expected only %s to be refreshed, got %v", topic, topics)
+	}
+}
+
+func TestTransportCoalesceStaleTopicsRefreshes(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cluster, err := kafkatest.NewCluster(kafkatest.Config{Brokers: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cluster.Close()
+
+	const topic = "stale"
+	if err := cluster.CreateTopic(topic, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	var mutex sync.Mutex
+	var refreshes [][]string
+
+	transport := &Transport{
+		Hooks: &TransportHooks{
+			BeforeRoundTrip: func(ctx context.Context, info RoundTripInfo) (Response, error) {
+				if req, ok := info.Request.(*meta.Request); ok {
+					mutex.Lock()
+					refreshes = append(refreshes, req.TopicNames)
+					mutex.Unlock()
+				}
+				return nil, nil
+			},
+		},
+	}
+	defer transport.CloseIdleConnections()
+
+	client := &Client{Addr: cluster.Addr(), Transport: transport}
+
+	// listOffsets returns the error reported on the partition.
+	listOffsets := func() error {
+		res, err := client.ListOffsets(ctx, &ListOffsetsRequest{
+			Topics: map[string][]OffsetRequest{topic: {FirstOffsetOf(0)}},
+		})
+		if err != nil {
+			t.Error(err)
+			return nil
+		}
+		return res.Topics[topic][0].Error
+	}
+
+	res, err := client.Metadata(ctx, &MetadataRequest{Topics: []string{topic}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	leader := res.Topics[0].Partitions[0].Leader.ID
+
+	if err := cluster.MoveLeader(topic, 0, 1-leader); err != nil {
+		t.Fatal(err)
+	}
+
+	mutex.Lock()
+	refreshes = nil
+	mutex.Unlock()
+
+	// All the requests are routed to the previous leader, the refreshes of
+	// the stale metadata that they trigger are coalesced.
+	const concurrency = 10
+	wg := sync.WaitGroup{}
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			listOffsets()
+		}()
+	}
+	wg.Wait()
+
+	mutex.Lock()
+	n := len(refreshes)
+	for _, topics := range refreshes {
+		if !reflect.DeepEqual(topics, []string{topic}) {
+			t.Errorf("expected only %s to be refreshed, got %v", topic, topics)
+		}
+	}
+	mutex.Unlock()
+
+	if n == 0 || n >= concurrency {
+		t.Errorf("expected the metadata refreshes to be coalesced, got %d refreshes for %d requests", n, concurrency)
+	}
+
+	if err := listOffsets(); err != nil {
+		t.Errorf("expected the request to be routed to the new leader, got %v", err)
+	}
+}
+
+func TestTransportReauthenticateSASL(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, server := net.Pipe()
+	defer client.Close()
+
+	served := make(chan protocol.ApiKey, 10)
+	go serveSASLBroker(server, served, time.Hour)
+
+	pc := protocol.NewConn(client, "test")
+	pc.SetVersions(map[protocol.ApiKey]int16{
+		protocol.SaslHandshake:    1,
+		protocol.SaslAuthenticate: 1,
+	})
+
+	reqs := make(chan connRequest)
+	c := &conn{
+		reqs: reqs,
+		group: &connGroup{
+			pool: &connPool{
+				sasl:        plain.Mechanism{Username: "user", Password: "pass"},
+				idleTimeout: time.Minute,
+			},
+		},
+		saslMetadata: &sasl.Metadata{Host: "localhost", Port: 9092},
+		reauthTime:   time.Now().Add(-time.Second),
+	}
+	defer c.close()
+	go c.run(pc, reqs)
+
+	for i := 0; i < 2; i++ {
+		res := make(async, 1)
+		reqs <- connRequest{ctx: ctx, req: &apiversions.Request{}, res: res}
+		if _, err := res.await(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the connection re-authenticated before the first request only, since
+	// the new session does not expire before the second request
+	expected := []protocol.ApiKey{
+		protocol.SaslHandshake,
+		protocol.SaslAuthenticate,
+		protocol.ApiVersions,
+		protocol.ApiVersions,
+	}
+	for _, apiKey := range expected {
+		if served := <-served; served != apiKey {
+			t.Fatalf("expected the broker to receive a %s request, got %s", apiKey, served)
+		}
+	}
+
+	if lifetime := time.Until(c.reauthTime); lifetime < 50*time.Minute || lifetime > 58*time.Minute {
+		t.Errorf("re-authentication scheduled outside of the session lifetime bounds: %s", lifetime)
+	}
+}
+
+func TestTransportHooks(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, server := net.Pipe()
+	defer client.Close()
+
+	served := make(chan protocol.ApiKey, 10)
+	go serveSASLBroker(server, served, time.Hour)
+
+	pc := protocol.NewConn(client, "test")
+	pc.SetVersions(map[protocol.ApiKey]int16{
+		protocol.SaslHandshake:    1,
+		protocol.SaslAuthenticate: 1,
+	})
+
+	var infos []RoundTripInfo
+	var fault error
+
+	reqs := make(chan connRequest)
+	c := &conn{
+		reqs:    reqs,
+		address: "localhost:9092",
+		group: &connGroup{
+			pool: &connPool{
+				sasl:        plain.Mechanism{Username: "user", Password: "pass"},
+				idleTimeout: time.Minute,
+				hooks: &TransportHooks{
+					BeforeRoundTrip: func(ctx context.Context, info RoundTripInfo) (Response, error) {
+						return nil, fault
+					},
+					AfterRoundTrip: func(ctx context.Context, info RoundTripInfo) {
+						infos = append(infos, info)
+					},
+				},
+			},
+		},
+		saslMetadata: &sasl.Metadata{Host: "localhost", Port: 9092},
+		reauthTime:   time.Now().Add(-time.Second),
+	}
+	defer c.close()
+	go c.run(pc, reqs)
+
+	res := make(async, 1)
+	reqs <- connRequest{ctx: ctx, req: &apiversions.Request{}, res: res}
+	if _, err := res.await(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// the hooks are called for the SASL requests sent internally by the
+	// transport as well
+	expected := []struct {
+		apiKey  protocol.ApiKey
+		version int16
+	}{
+		{protocol.SaslHandshake, 1},
+		{protocol.SaslAuthenticate, 1},
+		{protocol.ApiVersions, 0},
+	}
+	if len(infos) != len(expected) {
+		t.Fatalf("expected the hooks to be called %d times, got %d", len(expected), len(infos))
+	}
+	for i, info := range infos {
+		if info.ApiKey != expected[i].apiKey || info.Version != expected[i].version {
+			t.Errorf("expected a %s v%d round trip, got %s v%d", expected[i].apiKey, expected[i].version, info.ApiKey, info.Version)
+		}
+		if info.Broker != "localhost:9092" {
+			t.Errorf("unexpected broker address: %q", info.Broker)
+		}
+		if info.Request == nil || info.Response == nil || info.Err != nil {
+			t.Errorf("unexpected %s round trip: request=%v response=%v err=%v", info.ApiKey, info.Request, info.Response, info.Err)
+		}
+		if served := <-served; served != info.ApiKey {
+			t.Errorf("expected the broker to receive a %s request, got %s", info.ApiKey, served)
+		}
+	}
+
+	// the credentials exchanged in SASL authentication are not exposed
+	if req, ok := infos[1].Request.(*saslauthenticate.Request); !ok || req.AuthBytes != nil {
+		t.Errorf("expected the SASL authentication request to be redacted, got %+v", infos[1].Request)
+	}
+	if res, ok := infos[1].Response.(*saslauthenticate.Response); !ok || res.AuthBytes != nil {
+		t.Errorf("expected the SASL authentication response to be redacted, got %+v", infos[1].Response)
+	}
+
+	// errors returned by BeforeRoundTrip fail the request without sending it
+	fault = errors.New("injected fault")
+	infos = nil
+
+	res = make(async, 1)
+	reqs <- connRequest{ctx: ctx, req: &apiversions.Request{}, res: res}
+	if _, err := res.await(ctx); !errors.Is(err, fault) {
+		t.Fatalf("expected the injected error, got %v", err)
+	}
+	if len(infos) != 1 || !errors.Is(infos[0].Err, fault) {
+		t.Errorf("expected AfterRoundTrip to observe the injected error, got %+v", infos)
+	}
+	select {
+	case apiKey := <-served:
+		t.Errorf("unexpected %s request sent to the broker", apiKey)
+	default:
+	}
+}
+
+func TestTransportThrottle(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	const throttle = 200 * time.Millisecond
+
+	for _, test := range []struct {
+		scenario string
+		version  int16
+		delayed  bool
+	}{
+		{scenario: "broker-side throttling", version: 1, delayed: false},
+		{scenario: "client-side throttling", version: 2, delayed: true},
+	} {
+		t.Run(test.scenario, func(t *testing.T) {
+			pc := protocol.NewConn(client, "test")
+			pc.SetVersions(map[protocol.ApiKey]int16{
+				protocol.ApiVersions: test.version,
+			})
+
+			reqs := make(chan connRequest)
+			c := &conn{
+				reqs:    reqs,
+				address: "localhost:9092",
+				group: &connGroup{
+					pool: &connPool{
+						idleTimeout: time.Minute,
+						hooks: &TransportHooks{
+							// the responses are produced by the hook, the
+							// requests are never sent on the connection
+							BeforeRoundTrip: func(context.Context, RoundTripInfo) (Response, error) {
+								return &apiversions.Response{ThrottleTimeMs: int32(throttle / time.Millisecond)}, nil
+							},
+						},
+					},
+				},
+			}
+			defer c.close()
+			go c.run(pc, reqs)
+
+			start := time.Now()
+			for i := 0; i < 2; i++ {
+				res := make(async, 1)
+				reqs <- connRequest{ctx: ctx, req: &apiversions.Request{}, res: res}
+				if _, err := res.await(ctx); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if elapsed := time.Since(start); test.delayed != (elapsed >= throttle) {
+				t.Errorf("unexpected time to send the second request after a throttled response: %s", elapsed)
+			}
+		})
+	}
+}
+
+// serveSASLBroker runs a fake kafka broker on conn, which records the requests
+// it receives to served, and reports the given session lifetime when SASL
+// authenticating connections.
+func serveSASLBroker(conn net.Conn, served chan<- protocol.ApiKey, sessionLifetime time.Duration) {
+	defer conn.Close()
+
+	for {
+		apiVersion, correlationID, _, msg, err := protocol.ReadRequest(conn)
+		if err != nil {
+			return
+		}
+		served <- msg.ApiKey()
+
+		var res protocol.Message
+		switch msg.(type) {
+		case *saslhandshake.Request:
+			res = &saslhandshake.Response{Mechanisms: []string{"PLAIN"}}
+		case *saslauthenticate.Request:
+			res = &saslauthenticate.Response{SessionLifetimeMs: int64(sessionLifetime / time.Millisecond)}
+		default:
+			res = &apiversions.Response{
+				ApiKeys: []apiversions.ApiKeyResponse{
+					{ApiKey: int16(protocol.ApiVersions), MinVersion: 0, MaxVersion: 0},
+					{ApiKey: int16(protocol.SaslHandshake), MinVersion: 0, MaxVersion: 1},
+					{ApiKey: int16(protocol.SaslAuthenticate), MinVersion: 0, MaxVersion: 1},
+				},
+			}
+		}
+
+		if err := protocol.WriteResponse(conn, apiVersion, correlationID, res); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,258 @@
+//go:build go1.18
+// +build go1.18
+
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Serializer is an interface implemented by types which encode keys or values
+// of type T to the bytes of kafka messages.
+//
+// Serializer implementations must be safe to use concurrently from multiple
+// goroutines.
+type Serializer[T any] interface {
+	// Serialize encodes v for a message written to topic.
+	Serialize(ctx context.Context, topic string, v T) ([]byte, error)
+}
+
+// Deserializer is an interface implemented by types which decode keys or
+// values of type T from the bytes of kafka messages.
+//
+// Deserializer implementations must be safe to use concurrently from multiple
+// goroutines.
+type Deserializer[T any] interface {
+	// Deserialize decodes b from a message read from topic.
+	Deserialize(ctx context.Context, topic string, b []byte) (T, error)
+}
+
+// TypedMessage is the equivalent of Message used by TypedWriter and
+// TypedReader, with a key and value of types K and V.
+type TypedMessage[K, V any] struct {
+	// Topic of the message, see Message.Topic.
+	Topic string
+
+	// Read-only fields set by TypedReader, see Message.
+	Partition     int
+	Offset        int64
+	HighWaterMark int64
+
+	Key     K
+	Value   V
+	Headers []Header
+
+	// If not set at the creation, Time will be automatically set when
+	// writing the message.
+	Time time.Time
+}
+
+// TypedWriter wraps a Writer to write messages with keys and values of types K
+// and V, encoded by the key and value serializers.
+//
+// Usage:
+//
+//	w := &kafka.TypedWriter[string, Event]{
+//		Writer: &kafka.Writer{
+//			Addr:  kafka.TCP("localhost:9092"),
+//			Topic: "events",
+//		},
+//		KeySerializer:   kafka.StringSerde{},
+//		ValueSerializer: kafka.JSONSerde[Event]{},
+//	}
+//
+//	err := w.WriteMessages(ctx, kafka.TypedMessage[string, Event]{
+//		Key:   event.ID,
+//		Value: event,
+//	})
+type TypedWriter[K, V any] struct {
+	// The writer that messages are written to.
+	Writer *Writer
+
+	// Serializer of message keys. When nil, messages are written without
+	// keys.
+	KeySerializer Serializer[K]
+
+	// Serializer of message values. When nil, messages are written without
+	// values.
+	ValueSerializer Serializer[V]
+}
+
+// WriteMessages encodes msgs and writes them with the underlying Writer. The
+// messages are not written if any of them could not be encoded.
+func (w *TypedWriter[K, V]) WriteMessages(ctx context.Context, msgs ...TypedMessage[K, V]) error {
+	raw := make([]Message, len(msgs))
+
+	for i, msg := range msgs {
+		topic := msg.Topic
+		if topic == "" {
+			topic = w.Writer.Topic
+		}
+
+		raw[i] = Message{
+			Topic:   msg.Topic,
+			Headers: msg.Headers,
+			Time:    msg.Time,
+		}
+
+		var err error
+		if w.KeySerializer != nil {
+			if raw[i].Key, err = w.KeySerializer.Serialize(ctx, topic, msg.Key); err != nil {
+				return fmt.Errorf("serializing key of message %d: %w", i, err)
+			}
+		}
+		if w.ValueSerializer != nil {
+			if raw[i].Value, err = w.ValueSerializer.Serialize(ctx, topic, msg.Value); err != nil {
+				return fmt.Errorf("serializing value of message %d: %w", i, err)
+			}
+		}
+	}
+
+	return w.Writer.WriteMessages(ctx, raw...)
+}
+
+// Close closes the underlying Writer.
+func (w *TypedWriter[K, V]) Close() error {
+	return w.Writer.Close()
+}
+
+// TypedReader wraps a Reader to read messages with keys and values of types K
+// and V, decoded by the key and value deserializers.
+//
+// When a message cannot be decoded, the read methods return an error of type
+// *DeserializeError carrying the raw message, for example to write it to a
+// dead-letter topic. The returned TypedMessage still has the topic, partition,
+// and offset of the message, so it can be committed.
+//
+// Usage:
+//
+//	r := &kafka.TypedReader[string, Event]{
+//		Reader: kafka.NewReader(kafka.ReaderConfig{
+//			Brokers: []string{"localhost:9092"},
+//			GroupID: "consumer-group-id",
+//			Topic:   "events",
+//		}),
+//		KeyDeserializer:   kafka.StringSerde{},
+//		ValueDeserializer: kafka.JSONSerde[Event]{},
+//	}
+//
+//	for {
+//		m, err := r.FetchMessage(ctx)
+//		var derr *kafka.DeserializeError
+//		if errors.As(err, &derr) {
+//			deadLetters.WriteMessages(ctx, kafka.Message{
+//				Key:   derr.Message.Key,
+//				Value: derr.Message.Value,
+//			})
+//		} else if err != nil {
+//			break
+//		}
+//		...
+//		r.CommitMessages(ctx, m)
+//	}
+type TypedReader[K, V any] struct {
+	// The reader that messages are read from.
+	Reader *Reader
+
+	// Deserializer of message keys. When nil, the keys of messages are left
+	// to their zero value.
+	KeyDeserializer Deserializer[K]
+
+	// Deserializer of message values. When nil, the values of messages are
+	// left to their zero value.
+	ValueDeserializer Deserializer[V]
+}
+
+// ReadMessage reads and decodes the next message, see Reader.ReadMessage.
+//
+// When the reader is part of a consumer group, the message is committed even
+// if it could not be decoded.
+func (r *TypedReader[K, V]) ReadMessage(ctx context.Context) (TypedMessage[K, V], error) {
+	msg, err := r.Reader.ReadMessage(ctx)
+	if err != nil {
+		return TypedMessage[K, V]{}, err
+	}
+	return r.decode(ctx, msg)
+}
+
+// FetchMessage reads and decodes the next message without committing it, see
+// Reader.FetchMessage.
+func (r *TypedReader[K, V]) FetchMessage(ctx context.Context) (TypedMessage[K, V], error) {
+	msg, err := r.Reader.FetchMessage(ctx)
+	if err != nil {
+		return TypedMessage[K, V]{}, err
+	}
+	return r.decode(ctx, msg)
+}
+
+// CommitMessages commits the offsets of msgs, see Reader.CommitMessages.
+func (r *TypedReader[K, V]) CommitMessages(ctx context.Context, msgs ...TypedMessage[K, V]) error {
+	raw := make([]Message, len(msgs))
+	for i, msg := range msgs {
+		raw[i] = Message{
+			Topic:     msg.Topic,
+			Partition: msg.Partition,
+			Offset:    msg.Offset,
+		}
+	}
+	return r.Reader.CommitMessages(ctx, raw...)
+}
+
+// Close closes the underlying Reader.
+func (r *TypedReader[K, V]) Close() error {
+	return r.Reader.Close()
+}
+
+func (r *TypedReader[K, V]) decode(ctx context.Context, msg Message) (TypedMessage[K, V], error) {
+	m := TypedMessage[K, V]{
+		Topic:         msg.Topic,
+		Partition:     msg.Partition,
+		Offset:        msg.Offset,
+		HighWaterMark: msg.HighWaterMark,
+		Headers:       msg.Headers,
+		Time:          msg.Time,
+	}
+
+	var err error
+	if r.KeyDeserializer != nil {
+		if m.Key, err = r.KeyDeserializer.Deserialize(ctx, msg.Topic, msg.Key); err != nil {
+			return m, &DeserializeError{Message: msg, IsKey: true, Err: err}
+		}
+	}
+	if r.ValueDeserializer != nil {
+		if m.Value, err = r.ValueDeserializer.Deserialize(ctx, msg.Topic, msg.Value); err != nil {
+			return m, &DeserializeError{Message: msg, Err: err}
+		}
+	}
+
+	return m, nil
+}
+
+// DeserializeError is returned by TypedReader when the key or value of a
+// message could not be decoded.
+type DeserializeError struct {
+	// The raw message which could not be decoded.
+	Message Message
+
+	// True if the key of the message could not be decoded, false if it was
+	// the value.
+	IsKey bool
+
+	// The error returned by the deserializer.
+	Err error
+}
+
+func (e *DeserializeError) Error() string {
+	part := "value"
+	if e.IsKey {
+		part = "key"
+	}
+	return fmt.Sprintf("deserializing %s of message at offset %d of %s/%d: %v",
+		part, e.Message.Offset, e.Message.Topic, e.Message.Partition, e.Err)
+}
+
+func (e *DeserializeError) Unwrap() error {
+	return e.Err
+}
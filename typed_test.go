@@ -0,0 +1,133 @@
+//go:build go1.18
+// +build go1.18
+
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PerchSecurity/kafka-go/kafkatest"
+)
+
+type typedTestEvent struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Valid bool   `json:"valid"`
+}
+
+func TestTypedWriterReader(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cluster, err := kafkatest.NewCluster(kafkatest.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cluster.Close()
+
+	const topic = "typed"
+	if err := cluster.CreateTopic(topic, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	raw := &Writer{
+		Addr:         cluster.Addr(),
+		Topic:        topic,
+		RequiredAcks: RequireOne,
+		BatchTimeout: time.Millisecond,
+	}
+	defer raw.Close()
+
+	w := &TypedWriter[string, typedTestEvent]{
+		Writer:          raw,
+		KeySerializer:   StringSerde{},
+		ValueSerializer: JSONSerde[typedTestEvent]{},
+	}
+
+	if err := w.WriteMessages(ctx,
+		TypedMessage[string, typedTestEvent]{Key: "A", Value: typedTestEvent{ID: 1, Name: "A", Valid: true}},
+		TypedMessage[string, typedTestEvent]{Key: "B", Value: typedTestEvent{ID: 2, Name: "B"}},
+	); err != nil {
+		t.Fatal(err)
+	}
+	// A message which does not decode as JSON, it must be reported by the
+	// reader without preventing the next messages from being read.
+	if err := raw.WriteMessages(ctx, Message{Key: []byte("C"), Value: []byte("{")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteMessages(ctx,
+		TypedMessage[string, typedTestEvent]{Key: "D", Value: typedTestEvent{ID: 4, Name: "D"}},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &TypedReader[string, typedTestEvent]{
+		Reader: NewReader(ReaderConfig{
+			Brokers: cluster.Brokers(),
+			Topic:   topic,
+			MaxWait: 10 * time.Millisecond,
+		}),
+		KeyDeserializer:   StringSerde{},
+		ValueDeserializer: JSONSerde[typedTestEvent]{},
+	}
+	defer r.Close()
+
+	for i, want := range []typedTestEvent{
+		{ID: 1, Name: "A", Valid: true},
+		{ID: 2, Name: "B"},
+	} {
+		m, err := r.FetchMessage(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.Offset != int64(i) || m.Key != want.Name || m.Value != want {
+			t.Errorf("message %d mismatch: %+v", i, m)
+		}
+	}
+
+	m, err := r.FetchMessage(ctx)
+	var derr *DeserializeError
+	if !errors.As(err, &derr) {
+		t.Fatalf("expected a deserialize error, got %v", err)
+	}
+	if derr.IsKey || string(derr.Message.Key) != "C" || string(derr.Message.Value) != "{" {
+		t.Errorf("unexpected deserialize error: %+v", derr)
+	}
+	if m.Topic != topic || m.Offset != 2 {
+		t.Errorf("expected the message to carry its position, got %+v", m)
+	}
+
+	m, err = r.FetchMessage(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Key != "D" || m.Value.ID != 4 {
+		t.Errorf("unexpected message: %+v", m)
+	}
+}
+
+type failingSerde struct{ err error }
+
+func (s failingSerde) Serialize(context.Context, string, string) ([]byte, error) {
+	return nil, s.err
+}
+
+func TestTypedWriterSerializeError(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	w := &TypedWriter[string, string]{
+		Writer:          &Writer{Topic: "typed"},
+		KeySerializer:   StringSerde{},
+		ValueSerializer: failingSerde{err: errFailed},
+	}
+
+	// The writer has no address, it would fail to write if serialization
+	// succeeded.
+	err := w.WriteMessages(context.Background(), TypedMessage[string, string]{Key: "A", Value: "A"})
+	if !errors.Is(err, errFailed) {
+		t.Errorf("expected the serializer error, got %v", err)
+	}
+}
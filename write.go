@@ -243,7 +243,7 @@ func (wb *writeBuffer) writeFetchRequestV5(correlationID int32, clientID, topic
 	return wb.Flush()
 }
 
-func (wb *writeBuffer) writeFetchRequestV10(correlationID int32, clientID, topic string, partition int32, offset int64, minBytes, maxBytes int, maxWait time.Duration, isolationLevel int8) error {
+func (wb *writeBuffer) writeFetchRequestV10(correlationID int32, clientID, topic string, partition int32, offset int64, minBytes, maxBytes int, maxWait time.Duration, isolationLevel int8, leaderEpoch int32) error {
 	h := requestHeader{
 		ApiKey:        int16(fetch),
 		ApiVersion:    int16(v10),
@@ -284,7 +284,7 @@ func (wb *writeBuffer) writeFetchRequestV10(correlationID int32, clientID, topic
 	// partition array
 	wb.writeArrayLen(1)
 	wb.writeInt32(partition)
-	wb.writeInt32(-1) //FIXME
+	wb.writeInt32(leaderEpoch) // current leader epoch
 	wb.writeInt64(offset)
 	wb.writeInt64(int64(0)) // log start offset only used when is sent by follower
 	wb.writeInt32(int32(maxBytes))
@@ -6,10 +6,12 @@ import (
 	"errors"
 	"io"
 	"net"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/PerchSecurity/kafka-go/protocol"
 	metadataAPI "github.com/PerchSecurity/kafka-go/protocol/metadata"
 )
 
@@ -184,6 +186,22 @@ type Writer struct {
 	// Compression set the compression codec to be used to compress messages.
 	Compression Compression
 
+	// CompressionCodec sets the codec used to compress messages, it takes
+	// precedence over Compression when not nil. It is used to configure the
+	// compression beyond the defaults of the codecs installed in the
+	// compress.Codecs table, for example to compress with a zstd dictionary.
+	CompressionCodec CompressionCodec
+
+	// CompressionWorkers is the number of batches that the writer compresses
+	// concurrently, ahead of sending them to kafka. Batches of a partition are
+	// still sent one at a time and in order, but the next batches can be
+	// compressed while a produce request is in flight.
+	//
+	// The default is to compress each batch when its produce request is sent.
+	// Batches compressed ahead are encoded in the record batch format, which
+	// requires kafka 0.11 or later.
+	CompressionWorkers int
+
 	// If not nil, specifies a logger used to report internal changes within the
 	// writer.
 	Logger Logger
@@ -192,6 +210,11 @@ type Writer struct {
 	// back to using Logger instead.
 	ErrorLogger Logger
 
+	// If not nil, specifies a logger used to report events with attributes
+	// (topic, partition, error code, etc...). When set, Logger and ErrorLogger
+	// are not used.
+	StructuredLogger StructuredLogger
+
 	// A transport used to send messages to kafka clusters.
 	//
 	// If nil, DefaultTransport is used.
@@ -200,6 +223,14 @@ type Writer struct {
 	// AllowAutoTopicCreation notifies writer to create topic if missing.
 	AllowAutoTopicCreation bool
 
+	// If not nil, specifies a recorder used to report metrics about the
+	// produce requests sent by the writer, labeled by topic and partition.
+	Metrics MetricsRecorder
+
+	// If not nil, specifies a tracer used to trace the calls to WriteMessages
+	// and the produce requests sent by the writer.
+	Tracer Tracer
+
 	// Manages the current set of partition-topic writers.
 	group   sync.WaitGroup
 	mutex   sync.Mutex
@@ -213,6 +244,11 @@ type Writer struct {
 	once sync.Once
 	*writerStats
 
+	// Limits the number of batches compressed concurrently when batches are
+	// compressed ahead of sending them, lazily created on first use.
+	compressOnce  sync.Once
+	compressSlots chan struct{}
+
 	// If no balancer is configured, the writer uses this one. RoundRobin values
 	// are safe to use concurrently from multiple goroutines, there is no need
 	// for extra synchronization to access this field.
@@ -356,6 +392,12 @@ type WriterStats struct {
 	BatchSize      SummaryStats  `metric:"kafka.writer.batch.size"`
 	BatchBytes     SummaryStats  `metric:"kafka.writer.batch.bytes"`
 
+	// Throttles counts the produce responses throttled by the brokers because
+	// the writer exceeded a quota, and ThrottleTime reports the throttle times
+	// of these responses. Unlike WaitTime, it ignores unthrottled responses.
+	Throttles    int64         `metric:"kafka.writer.throttle.count" type:"counter"`
+	ThrottleTime DurationStats `metric:"kafka.writer.throttle.seconds"`
+
 	MaxAttempts     int64         `metric:"kafka.writer.attempts.max"  type:"gauge"`
 	WriteBackoffMin time.Duration `metric:"kafka.writer.backoff.min"   type:"gauge"`
 	WriteBackoffMax time.Duration `metric:"kafka.writer.backoff.max"   type:"gauge"`
@@ -405,6 +447,8 @@ type writerStats struct {
 	retries        counter
 	batchSize      summary
 	batchSizeBytes summary
+	throttles      counter
+	throttleTime   summary
 }
 
 // NewWriter creates and returns a new Writer configured with config.
@@ -513,6 +557,7 @@ func NewWriter(config WriterConfig) *Writer {
 
 	if config.CompressionCodec != nil {
 		w.Compression = Compression(config.CompressionCodec.Code())
+		w.CompressionCodec = config.CompressionCodec
 	}
 
 	return w
@@ -620,6 +665,20 @@ func (w *Writer) WriteMessages(ctx context.Context, msgs ...Message) error {
 		return nil
 	}
 
+	if w.Tracer == nil {
+		return w.writeMessages(ctx, msgs)
+	}
+
+	// The tracer may add headers to the messages, work on a copy to leave
+	// the list owned by the program untouched.
+	msgs = append(make([]Message, 0, len(msgs)), msgs...)
+	ctx, finish := w.Tracer.StartWrite(ctx, msgs)
+	err := w.writeMessages(ctx, msgs)
+	finish(err)
+	return err
+}
+
+func (w *Writer) writeMessages(ctx context.Context, msgs []Message) error {
 	balancer := w.balancer()
 	batchBytes := w.batchBytes()
 
@@ -724,23 +783,82 @@ func (w *Writer) batchMessages(messages []Message, assignments map[topicPartitio
 	return batches
 }
 
-func (w *Writer) produce(key topicPartition, batch *writeBatch) (*ProduceResponse, error) {
+func (w *Writer) produce(ctx context.Context, key topicPartition, batch *writeBatch) (*ProduceResponse, error) {
 	timeout := w.writeTimeout()
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
+	if batch.compressed != nil {
+		<-batch.compressed
+
+		if batch.compressErr != nil {
+			return nil, batch.compressErr
+		}
+
+		return w.client(timeout).RawProduce(ctx, &RawProduceRequest{
+			Partition:    int(key.partition),
+			Topic:        key.topic,
+			RequiredAcks: w.RequiredAcks,
+			RawRecords: protocol.RawRecordSet{
+				Reader: bytes.NewReader(batch.records),
+			},
+		})
+	}
+
 	return w.client(timeout).Produce(ctx, &ProduceRequest{
-		Partition:    int(key.partition),
-		Topic:        key.topic,
-		RequiredAcks: w.RequiredAcks,
-		Compression:  w.Compression,
+		Partition:        int(key.partition),
+		Topic:            key.topic,
+		RequiredAcks:     w.RequiredAcks,
+		Compression:      w.Compression,
+		CompressionCodec: w.CompressionCodec,
 		Records: &writerRecords{
 			msgs: batch.msgs,
 		},
 	})
 }
 
+// compress encodes the records of batch in a compressed record set, which is
+// sent as is by produce. The number of batches compressed concurrently is
+// limited to the number of compression workers.
+func (w *Writer) compress(batch *writeBatch) {
+	defer close(batch.compressed)
+
+	w.compressOnce.Do(func() {
+		w.compressSlots = make(chan struct{}, w.CompressionWorkers)
+	})
+	w.compressSlots <- struct{}{}
+	defer func() { <-w.compressSlots }()
+
+	codec := w.compressionCodec()
+	records := protocol.RecordSet{
+		Version:    2,
+		Attributes: protocol.Attributes(codec.Code()) & 0x7,
+		Records:    &writerRecords{msgs: batch.msgs},
+		Codec:      codec,
+	}
+
+	buffer := new(bytes.Buffer)
+	if _, err := records.WriteTo(buffer); err != nil {
+		batch.compressErr = err
+	} else {
+		batch.records = buffer.Bytes()
+	}
+}
+
+// compressAhead returns true if batches are compressed by compression workers
+// before being sent.
+func (w *Writer) compressAhead() bool {
+	return w.CompressionWorkers > 0 && w.compressionCodec() != nil
+}
+
+func (w *Writer) compressionCodec() CompressionCodec {
+	if w.CompressionCodec != nil {
+		return w.CompressionCodec
+	}
+	return w.Compression.Codec()
+}
+
 func (w *Writer) partitions(ctx context.Context, topic string) (int, error) {
 	client := w.client(w.readTimeout())
 	// Here we use the transport directly as an optimization to avoid the
@@ -843,17 +961,11 @@ func (w *Writer) writeTimeout() time.Duration {
 	return 10 * time.Second
 }
 
-func (w *Writer) withLogger(do func(Logger)) {
-	if w.Logger != nil {
-		do(w.Logger)
-	}
-}
-
-func (w *Writer) withErrorLogger(do func(Logger)) {
-	if w.ErrorLogger != nil {
-		do(w.ErrorLogger)
-	} else {
-		w.withLogger(do)
+func (w *Writer) log() logger {
+	return logger{
+		structured:  w.StructuredLogger,
+		logger:      w.Logger,
+		errorLogger: w.ErrorLogger,
 	}
 }
 
@@ -891,6 +1003,8 @@ func (w *Writer) Stats() WriterStats {
 		Retries:         stats.retries.snapshot(),
 		BatchSize:       stats.batchSize.snapshot(),
 		BatchBytes:      stats.batchSizeBytes.snapshot(),
+		Throttles:       stats.throttles.snapshot(),
+		ThrottleTime:    stats.throttleTime.snapshotDuration(),
 		MaxAttempts:     int64(w.maxAttempts()),
 		WriteBackoffMin: w.writeBackoffMin(),
 		WriteBackoffMax: w.writeBackoffMax(),
@@ -1044,14 +1158,14 @@ func (ptw *partitionWriter) writeMessages(msgs []Message, indexes []int32) map[*
 		}
 		if !batch.add(msgs[i], batchSize, batchBytes) {
 			batch.trigger()
-			ptw.queue.Put(batch)
+			ptw.enqueue(batch)
 			ptw.currBatch = nil
 			goto assignMessage
 		}
 
 		if batch.full(batchSize, batchBytes) {
 			batch.trigger()
-			ptw.queue.Put(batch)
+			ptw.enqueue(batch)
 			ptw.currBatch = nil
 		}
 
@@ -1062,6 +1176,19 @@ func (ptw *partitionWriter) writeMessages(msgs []Message, indexes []int32) map[*
 	return batches
 }
 
+// enqueue queues the batch for writing. When the writer compresses batches
+// ahead of sending them, the compression of the batch starts immediately.
+//
+// This is called with the lock ptw.mutex already held, once the batch does not
+// receive messages anymore.
+func (ptw *partitionWriter) enqueue(batch *writeBatch) {
+	if ptw.w.compressAhead() {
+		batch.compressed = make(chan struct{})
+		ptw.w.spawn(func() { ptw.w.compress(batch) })
+	}
+	ptw.queue.Put(batch)
+}
+
 // ptw.w can be accessed here because this is called with the lock ptw.mutex already held.
 func (ptw *partitionWriter) newWriteBatch() *writeBatch {
 	batch := newWriteBatch(time.Now(), ptw.w.batchTimeout())
@@ -1084,7 +1211,7 @@ func (ptw *partitionWriter) awaitBatch(batch *writeBatch) {
 		// pw.currBatch != batch so we just move on.
 		// Otherwise, we detach the batch from the ptWriter and enqueue it for writing.
 		if ptw.currBatch == batch {
-			ptw.queue.Put(batch)
+			ptw.enqueue(batch)
 			ptw.currBatch = nil
 		}
 		ptw.mutex.Unlock()
@@ -1107,9 +1234,15 @@ func (ptw *partitionWriter) writeBatch(batch *writeBatch) {
 	var res *ProduceResponse
 	var err error
 	key := ptw.meta
+	metrics := metrics{recorder: ptw.w.Metrics}
+	topic, partition := key.topic, strconv.Itoa(int(key.partition))
+	log := ptw.w.log().with(topicAttr(key.topic), partitionAttr(int(key.partition)))
+	metrics.record(writerBatchSize, float64(len(batch.msgs)), topic, partition)
+	metrics.record(writerBatchBytes, float64(batch.bytes), topic, partition)
 	for attempt, maxAttempts := 0, ptw.w.maxAttempts(); attempt < maxAttempts; attempt++ {
 		if attempt != 0 {
 			stats.retries.observe(1)
+			metrics.add(writerRetries, 1, topic, partition)
 			// TODO: should there be a way to asynchronously cancel this
 			// operation?
 			//
@@ -1121,18 +1254,19 @@ func (ptw *partitionWriter) writeBatch(batch *writeBatch) {
 			//   on close.
 			//
 			delay := backoff(attempt, ptw.w.writeBackoffMin(), ptw.w.writeBackoffMax())
-			ptw.w.withLogger(func(log Logger) {
-				log.Printf("backing off %s writing %d messages to %s (partition: %d)", delay, len(batch.msgs), key.topic, key.partition)
-			})
+			log.debugf("backing off %s writing %d messages to %s (partition: %d)", delay, len(batch.msgs), key.topic, key.partition)
 			time.Sleep(delay)
 		}
 
-		ptw.w.withLogger(func(log Logger) {
-			log.Printf("writing %d messages to %s (partition: %d)", len(batch.msgs), key.topic, key.partition)
-		})
+		log.debugf("writing %d messages to %s (partition: %d)", len(batch.msgs), key.topic, key.partition)
+
+		ctx, finish := context.Background(), func(error) {}
+		if tracer := ptw.w.Tracer; tracer != nil {
+			ctx, finish = tracer.StartProduce(ctx, key.topic, int(key.partition), batch.msgs)
+		}
 
 		start := time.Now()
-		res, err = ptw.w.produce(key, batch)
+		res, err = ptw.w.produce(ctx, key, batch)
 
 		stats.writes.observe(1)
 		stats.messages.observe(int64(len(batch.msgs)))
@@ -1143,23 +1277,40 @@ func (ptw *partitionWriter) writeBatch(batch *writeBatch) {
 		// duration of produce requests, and changed the stats.waitTime value to
 		// report the time that kafka has throttled the requests for.
 		stats.writeTime.observe(int64(time.Since(start)))
+		metrics.add(writerWrites, 1, topic, partition)
+		metrics.recordDuration(writerWriteDuration, time.Since(start), topic, partition)
 
 		if res != nil {
 			err = res.Error
 			stats.waitTime.observe(int64(res.Throttle))
+			if res.Throttle > 0 {
+				stats.throttles.observe(1)
+				stats.throttleTime.observe(int64(res.Throttle))
+				metrics.recordDuration(writerThrottleDuration, res.Throttle, topic, partition)
+				log.debugf("produce request to %s (partition: %d) throttled by the broker for %s", key.topic, key.partition, res.Throttle)
+			}
 		}
+		finish(err)
 
 		if err == nil {
+			// Messages are only counted once written, retries of failed
+			// attempts must not inflate the counters.
+			metrics.add(writerMessages, int64(len(batch.msgs)), topic, partition)
+			metrics.add(writerBytes, batch.bytes, topic, partition)
 			break
 		}
 
 		stats.errors.observe(1)
+		metrics.add(writerErrors, 1, topic, partition)
 
-		ptw.w.withErrorLogger(func(log Logger) {
-			log.Printf("error writing messages to %s (partition %d, attempt %d): %s", key.topic, key.partition, attempt, err)
-		})
+		retry := (isTemporary(err) || isTransientNetworkError(err)) && attempt+1 < maxAttempts
+		level := LogLevelWarn
+		if !retry {
+			level = LogLevelError
+		}
+		log.withError(err).logf(level, "error writing messages to %s (partition %d, attempt %d): %s", key.topic, key.partition, attempt, err)
 
-		if !isTemporary(err) && !isTransientNetworkError(err) {
+		if !retry {
 			break
 		}
 	}
@@ -1190,7 +1341,7 @@ func (ptw *partitionWriter) close() {
 
 	if ptw.currBatch != nil {
 		batch := ptw.currBatch
-		ptw.queue.Put(batch)
+		ptw.enqueue(batch)
 		ptw.currBatch = nil
 		batch.trigger()
 	}
@@ -1207,6 +1358,12 @@ type writeBatch struct {
 	done  chan struct{}
 	timer *time.Timer
 	err   error // result of the batch completion
+	// When the batch is compressed ahead of being sent, compressed is closed
+	// once records holds the encoded record set or compressErr the error that
+	// occurred while encoding it.
+	compressed  chan struct{}
+	records     []byte
+	compressErr error
 }
 
 func newWriteBatch(now time.Time, timeout time.Duration) *writeBatch {
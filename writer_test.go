@@ -5,13 +5,21 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math"
+	"net"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/PerchSecurity/kafka-go/compress/zstd"
+	"github.com/PerchSecurity/kafka-go/kafkatest"
+	"github.com/PerchSecurity/kafka-go/protocol"
+	metadataAPI "github.com/PerchSecurity/kafka-go/protocol/metadata"
+	produceAPI "github.com/PerchSecurity/kafka-go/protocol/produce"
 	"github.com/PerchSecurity/kafka-go/sasl/plain"
 )
 
@@ -1037,3 +1045,219 @@ type staticBalancer struct {
 func (b *staticBalancer) Balance(_ Message, partitions ...int) int {
 	return b.partition
 }
+
+func TestWriterThrottleStats(t *testing.T) {
+	transport := roundTripFunc(func(ctx context.Context, addr net.Addr, msg protocol.Message) (protocol.Message, error) {
+		switch req := msg.(type) {
+		case *metadataAPI.Request:
+			return &metadataAPI.Response{
+				Brokers: []metadataAPI.ResponseBroker{{NodeID: 1, Host: "localhost", Port: 9092}},
+				Topics: []metadataAPI.ResponseTopic{{
+					Name:       "topic-A",
+					Partitions: []metadataAPI.ResponsePartition{{PartitionIndex: 0, LeaderID: 1}},
+				}},
+			}, nil
+		case *produceAPI.Request:
+			return &produceAPI.Response{
+				ThrottleTimeMs: 100,
+				Topics: []produceAPI.ResponseTopic{{
+					Topic:      req.Topics[0].Topic,
+					Partitions: []produceAPI.ResponsePartition{{Partition: 0}},
+				}},
+			}, nil
+		default:
+			return nil, fmt.Errorf("unexpected request: %T", msg)
+		}
+	})
+
+	w := &Writer{
+		Addr:         TCP("localhost:9092"),
+		Topic:        "topic-A",
+		RequiredAcks: RequireOne,
+		Transport:    transport,
+	}
+	defer w.Close()
+
+	if err := w.WriteMessages(context.Background(), Message{Value: []byte("hello")}); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := w.Stats()
+	if stats.Throttles != 1 {
+		t.Errorf("expected 1 throttled response, got %d", stats.Throttles)
+	}
+	if stats.ThrottleTime.Max != 100*time.Millisecond {
+		t.Errorf("expected a throttle time of 100ms, got %s", stats.ThrottleTime.Max)
+	}
+}
+
+type countingCodec struct {
+	CompressionCodec
+	writers int32
+}
+
+func (c *countingCodec) NewWriter(w io.Writer) io.WriteCloser {
+	atomic.AddInt32(&c.writers, 1)
+	return c.CompressionCodec.NewWriter(w)
+}
+
+func TestWriterCompressionCodec(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	dict, err := ioutil.ReadFile("compress/zstd/testdata/events.dict")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The broker decodes the produced records, which it can only do with the
+	// dictionary registered.
+	if err := zstd.RegisterDictionary(dict); err != nil {
+		t.Fatal(err)
+	}
+
+	cluster, err := kafkatest.NewCluster(kafkatest.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cluster.Close()
+
+	const topic = "compression-codec"
+	if err := cluster.CreateTopic(topic, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	codec := &countingCodec{CompressionCodec: &zstd.Codec{Dictionary: dict}}
+	w := &Writer{
+		Addr:             cluster.Addr(),
+		Topic:            topic,
+		RequiredAcks:     RequireOne,
+		BatchTimeout:     time.Millisecond,
+		CompressionCodec: codec,
+	}
+	defer w.Close()
+
+	want := makeTestSequence(10)
+	if err := w.WriteMessages(ctx, want...); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&codec.writers) == 0 {
+		t.Error("the messages were not compressed with the writer's codec")
+	}
+
+	r := NewReader(ReaderConfig{
+		Brokers: cluster.Brokers(),
+		Topic:   topic,
+		MaxWait: 10 * time.Millisecond,
+	})
+	defer r.Close()
+
+	for i := range want {
+		m, err := r.FetchMessage(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(m.Value) != string(want[i].Value) {
+			t.Errorf("message %d mismatch: got %q, want %q", i, m.Value, want[i].Value)
+		}
+	}
+}
+
+// trackingCodec tracks the number of writers of the wrapped codec in use
+// concurrently.
+type trackingCodec struct {
+	CompressionCodec
+	mutex  sync.Mutex
+	active int
+	max    int
+	total  int
+}
+
+func (c *trackingCodec) NewWriter(w io.Writer) io.WriteCloser {
+	c.mutex.Lock()
+	c.active++
+	c.total++
+	if c.active > c.max {
+		c.max = c.active
+	}
+	c.mutex.Unlock()
+	return &trackingWriter{WriteCloser: c.CompressionCodec.NewWriter(w), codec: c}
+}
+
+type trackingWriter struct {
+	io.WriteCloser
+	codec *trackingCodec
+}
+
+func (w *trackingWriter) Close() error {
+	// Hold on to the writer a little to give other workers a chance to run
+	// concurrently.
+	time.Sleep(time.Millisecond)
+	w.codec.mutex.Lock()
+	w.codec.active--
+	w.codec.mutex.Unlock()
+	return w.WriteCloser.Close()
+}
+
+func TestWriterCompressionWorkers(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cluster, err := kafkatest.NewCluster(kafkatest.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cluster.Close()
+
+	const topic = "compression-workers"
+	if err := cluster.CreateTopic(topic, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	const workers = 2
+	codec := &trackingCodec{CompressionCodec: Zstd.Codec()}
+	w := &Writer{
+		Addr:               cluster.Addr(),
+		Topic:              topic,
+		RequiredAcks:       RequireOne,
+		BatchSize:          10,
+		BatchTimeout:       time.Millisecond,
+		CompressionCodec:   codec,
+		CompressionWorkers: workers,
+	}
+	defer w.Close()
+
+	want := makeTestSequence(200)
+	if err := w.WriteMessages(ctx, want...); err != nil {
+		t.Fatal(err)
+	}
+
+	codec.mutex.Lock()
+	total, max := codec.total, codec.max
+	codec.mutex.Unlock()
+
+	if total != len(want)/w.BatchSize {
+		t.Errorf("wrong number of batches compressed: %d", total)
+	}
+	// All batches are sealed at once, the compression workers should all
+	// have been busy at the same time, but never more than configured.
+	if max != workers {
+		t.Errorf("wrong number of batches compressed concurrently: %d != %d", max, workers)
+	}
+
+	r := NewReader(ReaderConfig{
+		Brokers: cluster.Brokers(),
+		Topic:   topic,
+		MaxWait: 10 * time.Millisecond,
+	})
+	defer r.Close()
+
+	for i := range want {
+		m, err := r.FetchMessage(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.Offset != int64(i) || string(m.Value) != string(want[i].Value) {
+			t.Fatalf("message %d out of order: got offset %d and value %q", i, m.Offset, m.Value)
+		}
+	}
+}